@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dshills/realitycheck"
+	"github.com/dshills/realitycheck/internal/aggregate"
+	"github.com/dshills/realitycheck/internal/render"
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+type aggregateFlags struct {
+	format string
+	out    string
+}
+
+func newAggregateCmd() *cobra.Command {
+	var f aggregateFlags
+
+	cmd := &cobra.Command{
+		Use:          "aggregate <report.json>...",
+		Short:        "Roll several JSON reports up into one summary",
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAggregate(args, f)
+		},
+	}
+
+	cmd.Flags().StringVar(&f.format, "format", "json", "output format: json, yaml, md, or csv")
+	cmd.Flags().StringVar(&f.out, "out", "", "write output to this file instead of stdout")
+
+	return cmd
+}
+
+func runAggregate(paths []string, f aggregateFlags) error {
+	switch f.format {
+	case "json", "yaml", "md", "csv":
+		// valid
+	default:
+		return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: --format must be one of json, yaml, md, csv; got %q", f.format)}
+	}
+
+	reports := make([]schema.Report, len(paths))
+	for i, path := range paths {
+		report, err := loadReport(path)
+		if err != nil {
+			return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: %v", err)}
+		}
+		reports[i] = report
+	}
+
+	agg := aggregate.Combine(paths, reports)
+	agg.Tool = "realitycheck"
+	agg.Version = realitycheck.Version
+
+	var output []byte
+	var err error
+	switch f.format {
+	case "yaml":
+		output, err = render.RenderAggregateYAML(agg)
+	case "md":
+		output = []byte(render.RenderAggregateMarkdown(agg))
+	case "csv":
+		output, err = render.RenderAggregateCSV(agg)
+	default:
+		output, err = render.RenderAggregateJSON(agg)
+	}
+	if err != nil {
+		return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: render: %v", err)}
+	}
+	if len(output) > 0 && output[len(output)-1] != '\n' {
+		output = append(output, '\n')
+	}
+
+	if f.out != "" {
+		if writeErr := atomicWrite(f.out, output); writeErr != nil {
+			return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: write output: %v", writeErr)}
+		}
+	} else {
+		if _, writeErr := os.Stdout.Write(output); writeErr != nil {
+			return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: write stdout: %v", writeErr)}
+		}
+	}
+	return nil
+}
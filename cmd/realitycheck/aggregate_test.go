@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+func TestRunAggregate(t *testing.T) {
+	dir := t.TempDir()
+	a := writeReport(t, dir, "billing.json", schema.Report{Tool: "realitycheck", Summary: schema.Summary{Verdict: schema.VerdictAligned, Score: 100}})
+	b := writeReport(t, dir, "auth.json", schema.Report{Tool: "realitycheck", Summary: schema.Summary{Verdict: schema.VerdictDriftDetected, Score: 93, WarnCount: 1}})
+	out := filepath.Join(dir, "aggregate.json")
+
+	if err := runAggregate([]string{a, b}, aggregateFlags{format: "json", out: out}); err != nil {
+		t.Fatalf("runAggregate: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var agg schema.AggregateReport
+	if err := json.Unmarshal(data, &agg); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+	if agg.Tool != "realitycheck" || agg.Verdict != schema.VerdictDriftDetected || agg.AverageScore != 96.5 || agg.WarnCount != 1 {
+		t.Errorf("aggregate = %+v", agg)
+	}
+	if len(agg.Reports) != 2 || agg.Reports[0].Path != a {
+		t.Errorf("reports = %+v, want one entry per file in argument order", agg.Reports)
+	}
+}
+
+func TestRunAggregate_BadInput(t *testing.T) {
+	dir := t.TempDir()
+	good := writeReport(t, dir, "good.json", schema.Report{Tool: "realitycheck"})
+	cases := map[string]struct {
+		paths  []string
+		format string
+	}{
+		"missing file": {[]string{good, filepath.Join(dir, "missing.json")}, "json"},
+		"bad format":   {[]string{good}, "sarif"},
+	}
+	for name, c := range cases {
+		err := runAggregate(c.paths, aggregateFlags{format: c.format})
+		var ee *exitError
+		if !errors.As(err, &ee) || ee.code != exitCodeBadInput {
+			t.Errorf("%s: err = %v, want exit code %d", name, err, exitCodeBadInput)
+		}
+	}
+}
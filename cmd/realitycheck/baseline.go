@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dshills/realitycheck/internal/baseline"
+)
+
+func newBaselineCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "baseline",
+		Short: "Manage baseline files of accepted findings",
+	}
+	cmd.AddCommand(newBaselineWriteCmd())
+	return cmd
+}
+
+func newBaselineWriteCmd() *cobra.Command {
+	var out string
+
+	cmd := &cobra.Command{
+		Use:          "write <report.json>",
+		Short:        "Write a baseline accepting every drift finding and violation in a JSON report",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBaselineWrite(args[0], out)
+		},
+	}
+
+	cmd.Flags().StringVar(&out, "out", "", "write the baseline to this file instead of stdout")
+
+	return cmd
+}
+
+func runBaselineWrite(path, out string) error {
+	report, err := loadReportStrict(path)
+	if err != nil {
+		return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: %v", err)}
+	}
+	output, err := baseline.FromReport(report).Marshal()
+	if err != nil {
+		return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: render: %v", err)}
+	}
+	output = append(output, '\n')
+
+	if out != "" {
+		if writeErr := atomicWrite(out, output); writeErr != nil {
+			return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: write output: %v", writeErr)}
+		}
+	} else {
+		if _, writeErr := os.Stdout.Write(output); writeErr != nil {
+			return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: write stdout: %v", writeErr)}
+		}
+	}
+	return nil
+}
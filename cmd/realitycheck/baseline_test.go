@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/baseline"
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+func TestRunBaselineWrite(t *testing.T) {
+	dir := t.TempDir()
+	r := schema.Report{Tool: "realitycheck", Summary: schema.Summary{Verdict: schema.VerdictDriftDetected, Score: 93}}
+	r.Drift = []schema.DriftFinding{{ID: "DRIFT-001", Severity: schema.SeverityWarn, Description: "Undocumented retry loop", Evidence: []schema.Evidence{{Path: "client.go"}}}}
+	in := writeReport(t, dir, "report.json", r)
+	out := filepath.Join(dir, "baseline.json")
+
+	if err := runBaselineWrite(in, out); err != nil {
+		t.Fatalf("runBaselineWrite: %v", err)
+	}
+	f, err := baseline.Load(out)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := baseline.Fingerprint(schema.SeverityWarn, "Undocumented retry loop", r.Drift[0].Evidence)
+	if len(f.Findings) != 1 || f.Findings[0].Fingerprint != want {
+		t.Errorf("findings = %+v, want one entry for DRIFT-001", f.Findings)
+	}
+
+	err = runBaselineWrite(filepath.Join(dir, "missing.json"), out)
+	var ee *exitError
+	if !errors.As(err, &ee) || ee.code != exitCodeBadInput {
+		t.Errorf("missing report: expected exit code %d, got %v", exitCodeBadInput, err)
+	}
+}
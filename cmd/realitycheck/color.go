@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+// ANSI SGR sequences used for verdict log records.
+const (
+	ansiReset  = "\x1b[0m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+)
+
+// useColor reports whether escape codes may be written to f: never with
+// --no-color, never when NO_COLOR is set to a non-empty value
+// (https://no-color.org), and never when f is not a terminal.
+func useColor(f *os.File, noColor bool) bool {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorVerdict wraps s in the color for v: green for ALIGNED, yellow for
+// PARTIALLY_ALIGNED and DRIFT_DETECTED, red for VIOLATION. s is returned
+// unchanged when enabled is false or v is unknown.
+func colorVerdict(v schema.Verdict, s string, enabled bool) string {
+	if !enabled {
+		return s
+	}
+	var code string
+	switch v {
+	case schema.VerdictAligned:
+		code = ansiGreen
+	case schema.VerdictPartiallyAligned, schema.VerdictDriftDetected:
+		code = ansiYellow
+	case schema.VerdictViolation:
+		code = ansiRed
+	default:
+		return s
+	}
+	return code + s + ansiReset
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+func TestColorVerdict(t *testing.T) {
+	cases := []struct {
+		v    schema.Verdict
+		want string
+	}{
+		{schema.VerdictAligned, ansiGreen + "x" + ansiReset},
+		{schema.VerdictPartiallyAligned, ansiYellow + "x" + ansiReset},
+		{schema.VerdictDriftDetected, ansiYellow + "x" + ansiReset},
+		{schema.VerdictViolation, ansiRed + "x" + ansiReset},
+		{"BOGUS", "x"},
+	}
+	for _, c := range cases {
+		if got := colorVerdict(c.v, "x", true); got != c.want {
+			t.Errorf("colorVerdict(%s) = %q, want %q", c.v, got, c.want)
+		}
+		if got := colorVerdict(c.v, "x", false); got != "x" {
+			t.Errorf("colorVerdict(%s) with color disabled = %q, want plain", c.v, got)
+		}
+	}
+}
+
+func TestUseColor_NotATerminal(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	f, err := os.Create(filepath.Join(t.TempDir(), "trace.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if useColor(f, false) {
+		t.Error("useColor should be false for a regular file")
+	}
+}
+
+func TestUseColor_Disabled(t *testing.T) {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		t.Skip("no controlling terminal")
+	}
+	defer tty.Close()
+
+	t.Setenv("NO_COLOR", "")
+	if !useColor(tty, false) {
+		t.Error("useColor should be true for a terminal")
+	}
+	if useColor(tty, true) {
+		t.Error("--no-color should disable color")
+	}
+	t.Setenv("NO_COLOR", "1")
+	if useColor(tty, false) {
+		t.Error("NO_COLOR should disable color")
+	}
+}
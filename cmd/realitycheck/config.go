@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/dshills/realitycheck/internal/rules"
+)
+
+// defaultConfigFile is the config file discovered in the working directory
+// when --config is not given.
+const defaultConfigFile = ".realitycheck.yaml"
+
+// rulesKey is the config key holding deterministic rules. It is the only key
+// that is not a flag name.
+const rulesKey = "rules"
+
+// applyConfig pre-populates cmd's flags from a YAML config file. Keys are
+// flag names (spec, code-root, fail-on, ...). A flag set explicitly on the
+// command line keeps its value, so the precedence is flag > config > default.
+// The rules key, a list of rule mappings, is returned rather than applied.
+//
+// If path is empty, defaultConfigFile is used when it exists in the working
+// directory; a missing default file is not an error. Unknown keys, and keys
+// naming flags that cannot be configured, are rejected.
+func applyConfig(cmd *cobra.Command, path string) ([]rules.Config, error) {
+	explicit := path != ""
+	if !explicit {
+		path = defaultConfigFile
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("config: read %s: %w", path, err)
+	}
+
+	var values map[string]any
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("config: parse %s: %w", path, err)
+	}
+
+	// Apply keys in sorted order so errors are reported deterministically.
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var ruleCfgs []rules.Config
+	flags := cmd.Flags()
+	for _, key := range keys {
+		if key == rulesKey {
+			if ruleCfgs, err = decodeRules(values[key]); err != nil {
+				return nil, fmt.Errorf("config: key %q in %s: %w", key, path, err)
+			}
+			continue
+		}
+		fl := flags.Lookup(key)
+		if fl == nil || key == "config" || key == "help" {
+			return nil, fmt.Errorf("config: unknown key %q in %s", key, path)
+		}
+		if fl.Changed {
+			continue
+		}
+		var raw []any
+		switch v := values[key].(type) {
+		case nil:
+			continue
+		case []any:
+			if !strings.HasSuffix(fl.Value.Type(), "Array") {
+				return nil, fmt.Errorf("config: key %q in %s takes a single value, not a list", key, path)
+			}
+			raw = v
+		case map[string]any:
+			return nil, fmt.Errorf("config: key %q in %s takes a scalar value, not a mapping", key, path)
+		default:
+			raw = []any{v}
+		}
+		for _, item := range raw {
+			if err := flags.Set(key, fmt.Sprint(item)); err != nil {
+				return nil, fmt.Errorf("config: key %q in %s: %w", key, path, err)
+			}
+		}
+	}
+	return ruleCfgs, nil
+}
+
+// decodeRules converts the generic YAML value of the rules key into rule
+// configs, rejecting unknown fields so a misspelled setting is not silently
+// ignored.
+func decodeRules(v any) ([]rules.Config, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if _, ok := v.([]any); !ok {
+		return nil, errors.New("must be a list of rules")
+	}
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out []rules.Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
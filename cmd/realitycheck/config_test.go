@@ -0,0 +1,147 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// parseWithConfig builds a check command, parses args as the command line,
+// and applies a config file containing cfg (if non-empty).
+func parseWithConfig(t *testing.T, cfg string, args []string) (*cobra.Command, error) {
+	t.Helper()
+	cmd := newCheckCmd()
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatalf("ParseFlags(%v): %v", args, err)
+	}
+	path := ""
+	if cfg != "" {
+		path = filepath.Join(t.TempDir(), "realitycheck.yaml")
+		if err := os.WriteFile(path, []byte(cfg), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := applyConfig(cmd, path)
+	return cmd, err
+}
+
+func TestApplyConfig_Precedence(t *testing.T) {
+	cases := []struct {
+		name   string
+		config string
+		args   []string
+		flag   string
+		want   string
+	}{
+		{"default only", "", nil, "provider", "anthropic"},
+		{"config overrides default", "provider: openai\n", nil, "provider", "openai"},
+		{"flag overrides config", "provider: openai\n", []string{"--provider", "google"}, "provider", "google"},
+		{"flag overrides default", "", []string{"--fail-on", "VIOLATION"}, "fail-on", "VIOLATION"},
+		{"config bool", "strict: true\n", nil, "strict", "true"},
+		{"flag bool overrides config", "strict: true\n", []string{"--strict=false"}, "strict", "false"},
+		{"config int", "max-tokens: 8192\n", nil, "max-tokens", "8192"},
+		{"config duration", "retry-base-delay: 2s\n", nil, "retry-base-delay", "2s"},
+		{"config list", "spec:\n  - a.md\n  - b.md\n", nil, "spec", "[a.md,b.md]"},
+		{"config scalar for list flag", "spec: a.md\n", nil, "spec", "[a.md]"},
+		{"flag list replaces config list", "spec: [a.md, b.md]\n", []string{"--spec", "c.md"}, "spec", "[c.md]"},
+		{"other config keys still apply", "code-root: src\nprovider: openai\n", []string{"--provider", "google"}, "code-root", "src"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd, err := parseWithConfig(t, c.config, c.args)
+			if err != nil {
+				t.Fatalf("applyConfig: %v", err)
+			}
+			if got := cmd.Flags().Lookup(c.flag).Value.String(); got != c.want {
+				t.Errorf("--%s = %q, want %q", c.flag, got, c.want)
+			}
+		})
+	}
+}
+
+func TestApplyConfig_Errors(t *testing.T) {
+	cases := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{"unknown key", "colour: blue\n", `unknown key "colour"`},
+		{"config key", "config: other.yaml\n", `unknown key "config"`},
+		{"list for scalar flag", "provider: [openai, google]\n", "not a list"},
+		{"mapping value", "provider:\n  name: openai\n", "not a mapping"},
+		{"bad int", "max-tokens: lots\n", `key "max-tokens"`},
+		{"malformed yaml", "provider: [openai\n", "parse"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := parseWithConfig(t, c.config, nil)
+			if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyConfig_Discovery(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	// No default file: not an error.
+	cmd := newCheckCmd()
+	if _, err := applyConfig(cmd, ""); err != nil {
+		t.Fatalf("missing default config should be ignored: %v", err)
+	}
+
+	if err := os.WriteFile(defaultConfigFile, []byte("provider: google\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd = newCheckCmd()
+	if _, err := applyConfig(cmd, ""); err != nil {
+		t.Fatalf("applyConfig: %v", err)
+	}
+	if got := cmd.Flags().Lookup("provider").Value.String(); got != "google" {
+		t.Errorf("provider = %q, want %q from %s", got, "google", defaultConfigFile)
+	}
+
+	// An explicit --config path that does not exist is an error.
+	if _, err := applyConfig(newCheckCmd(), filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("expected error for missing explicit config file")
+	}
+}
+
+func TestApplyConfig_Rules(t *testing.T) {
+	cfg := `provider: openai
+rules:
+  - name: no-legacy
+    type: evidence-path
+    paths: [legacy/**]
+    severity: critical
+  - name: no-debug
+    type: symbol-pattern
+    pattern: ^Debug
+    exported_only: true
+`
+	path := filepath.Join(t.TempDir(), "realitycheck.yaml")
+	if err := os.WriteFile(path, []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := applyConfig(newCheckCmd(), path)
+	if err != nil {
+		t.Fatalf("applyConfig: %v", err)
+	}
+	if len(got) != 2 || got[0].Name != "no-legacy" || got[0].Paths[0] != "legacy/**" || !got[1].ExportedOnly {
+		t.Errorf("rules = %+v", got)
+	}
+
+	for name, bad := range map[string]string{
+		"not a list":    "rules:\n  name: x\n",
+		"unknown field": "rules:\n  - name: x\n    type: evidence-path\n    pathz: [a]\n",
+	} {
+		if _, err := parseWithConfig(t, bad, nil); err == nil || !strings.Contains(err.Error(), `key "rules"`) {
+			t.Errorf("%s: error = %v, want a rules key error", name, err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dshills/realitycheck/internal/render"
+	"github.com/dshills/realitycheck/internal/reportdiff"
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+type diffFlags struct {
+	format           string
+	out              string
+	failOnRegression bool
+}
+
+func newDiffCmd() *cobra.Command {
+	var f diffFlags
+
+	cmd := &cobra.Command{
+		Use:          "diff <old.json> <new.json>",
+		Short:        "Compare two JSON reports and show what regressed",
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDiff(args[0], args[1], f)
+		},
+	}
+
+	cmd.Flags().StringVar(&f.format, "format", "json", "output format: json or md")
+	cmd.Flags().StringVar(&f.out, "out", "", "write output to this file instead of stdout")
+	cmd.Flags().BoolVar(&f.failOnRegression, "fail-on-regression", false, "exit 2 if the new report is worse than the old one")
+
+	return cmd
+}
+
+func runDiff(oldPath, newPath string, f diffFlags) error {
+	switch f.format {
+	case "json", "md":
+		// valid
+	default:
+		return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: --format must be one of json, md; got %q", f.format)}
+	}
+
+	oldReport, err := loadReport(oldPath)
+	if err != nil {
+		return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: %v", err)}
+	}
+	newReport, err := loadReport(newPath)
+	if err != nil {
+		return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: %v", err)}
+	}
+
+	res := reportdiff.Compare(oldReport, newReport)
+
+	var output []byte
+	if f.format == "md" {
+		output = []byte(render.RenderDiffMarkdown(res))
+	} else {
+		output, err = render.RenderDiffJSON(res)
+		if err != nil {
+			return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: render: %v", err)}
+		}
+	}
+	if len(output) > 0 && output[len(output)-1] != '\n' {
+		output = append(output, '\n')
+	}
+
+	if f.out != "" {
+		if writeErr := atomicWrite(f.out, output); writeErr != nil {
+			return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: write output: %v", writeErr)}
+		}
+	} else {
+		if _, writeErr := os.Stdout.Write(output); writeErr != nil {
+			return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: write stdout: %v", writeErr)}
+		}
+	}
+
+	if f.failOnRegression && res.Regressed() {
+		return &exitError{exitCodeFailOn, reasonRegression, fmt.Sprintf("regression: score %d → %d, %d new drift, %d new violations, %d coverage regressions",
+			res.OldScore, res.NewScore, len(res.NewDrift), len(res.NewViolations), len(res.CoverageRegressions))}
+	}
+	return nil
+}
+
+// loadReport reads a JSON report previously written by `realitycheck check`.
+func loadReport(path string) (schema.Report, error) {
+	var report schema.Report
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return report, fmt.Errorf("read report %q: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return report, fmt.Errorf("parse report %q: %w", path, err)
+	}
+	if report.Tool != "realitycheck" {
+		return report, fmt.Errorf("%q is not a realitycheck JSON report", path)
+	}
+	return report, nil
+}
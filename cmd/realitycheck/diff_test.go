@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+func writeReport(t *testing.T, dir, name string, r schema.Report) string {
+	t.Helper()
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunDiff_FailOnRegression(t *testing.T) {
+	dir := t.TempDir()
+	oldR := schema.Report{Tool: "realitycheck", Summary: schema.Summary{Verdict: schema.VerdictAligned, Score: 100}}
+	newR := oldR
+	newR.Summary = schema.Summary{Verdict: schema.VerdictDriftDetected, Score: 93}
+	newR.Drift = []schema.DriftFinding{{ID: "DRIFT-001", Severity: schema.SeverityWarn, Description: "Undocumented retry loop"}}
+	oldPath := writeReport(t, dir, "old.json", oldR)
+	newPath := writeReport(t, dir, "new.json", newR)
+	out := filepath.Join(dir, "diff.json")
+
+	// Without the flag, a regression is reported but not an error.
+	if err := runDiff(oldPath, newPath, diffFlags{format: "json", out: out}); err != nil {
+		t.Fatalf("runDiff without --fail-on-regression: %v", err)
+	}
+
+	err := runDiff(oldPath, newPath, diffFlags{format: "json", out: out, failOnRegression: true})
+	var ee *exitError
+	if !errors.As(err, &ee) || ee.code != exitCodeFailOn {
+		t.Errorf("expected exit code %d on regression, got %v", exitCodeFailOn, err)
+	}
+
+	// Reversed, the comparison is an improvement.
+	if err := runDiff(newPath, oldPath, diffFlags{format: "md", out: out, failOnRegression: true}); err != nil {
+		t.Errorf("improvement should not fail: %v", err)
+	}
+}
+
+func TestRunDiff_BadInput(t *testing.T) {
+	dir := t.TempDir()
+	notReport := filepath.Join(dir, "other.json")
+	if err := os.WriteFile(notReport, []byte(`{"name":"x"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name     string
+		old, new string
+		format   string
+	}{
+		{"missing file", filepath.Join(dir, "missing.json"), notReport, "json"},
+		{"not a report", notReport, notReport, "json"},
+		{"bad format", notReport, notReport, "sarif"},
+	}
+	for _, c := range cases {
+		err := runDiff(c.old, c.new, diffFlags{format: c.format})
+		var ee *exitError
+		if !errors.As(err, &ee) || ee.code != exitCodeBadInput {
+			t.Errorf("%s: expected exit code %d, got %v", c.name, exitCodeBadInput, err)
+		}
+	}
+}
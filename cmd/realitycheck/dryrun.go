@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/dshills/realitycheck"
+	"github.com/dshills/realitycheck/internal/llm"
+)
+
+// runDryRun writes the prompts a check would send to w and returns without
+// calling the provider. The token estimate is llm.TokenizerFor's for the
+// provider; actual counts vary by model.
+func runDryRun(f checkFlags, w io.Writer) error {
+	if err := expandInputGlobs(&f); err != nil {
+		return err
+	}
+	prompts, err := realitycheck.Prompts(runConfig(f))
+	if err != nil {
+		return runError(err)
+	}
+
+	tok := llm.TokenizerFor(f.provider)
+	chars, tokens := 0, 0
+	for _, p := range prompts {
+		if p.Partition != "" {
+			fmt.Fprintf(w, "=== partition: %s ===\n", p.Partition)
+		}
+		fmt.Fprintf(w, "=== system prompt ===\n%s\n", p.System)
+		fmt.Fprintf(w, "=== user prompt ===\n%s\n", p.User)
+		chars += len(p.System) + len(p.User)
+		tokens += tok.CountTokens(p.System) + tok.CountTokens(p.User)
+	}
+	fmt.Fprintf(w, "=== %d prompt(s), %d characters, ~%d input tokens ===\n", len(prompts), chars, tokens)
+	return nil
+}
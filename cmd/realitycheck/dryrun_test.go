@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/llm"
+)
+
+func TestRunDryRun(t *testing.T) {
+	// No API key and no --offline: a dry run must not need either.
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	orig := llm.NewProvider
+	llm.NewProvider = func(string, string) (llm.Provider, error) {
+		t.Error("dry run created a provider")
+		return nil, errors.New("unexpected")
+	}
+	t.Cleanup(func() { llm.NewProvider = orig })
+
+	f := checkFlags{
+		specFiles:   []string{"../../testdata/aligned/SPEC.md"},
+		planFiles:   []string{"../../testdata/aligned/PLAN.md"},
+		codeRoot:    "../../testdata/aligned",
+		profileName: "general",
+		provider:    "anthropic",
+	}
+	var out bytes.Buffer
+	if err := runDryRun(f, &out); err != nil {
+		t.Fatalf("runDryRun: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"=== system prompt ===\nYou are RealityCheck",
+		"=== user prompt ===\nSPEC.md (with line numbers):",
+		"store.go",
+		"=== 1 prompt(s), ",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("dry-run output missing %q", want)
+		}
+	}
+	if strings.Contains(got, "=== partition:") {
+		t.Error("unchunked dry run should not print partition headers")
+	}
+}
+
+func TestRunDryRun_BadInput(t *testing.T) {
+	f := checkFlags{specFiles: []string{"missing.md"}, planFiles: []string{"missing.md"}}
+	err := runDryRun(f, &bytes.Buffer{})
+	var ee *exitError
+	if !errors.As(err, &ee) || ee.code != exitCodeBadInput {
+		t.Errorf("err = %v, want exit code %d", err, exitCodeBadInput)
+	}
+}
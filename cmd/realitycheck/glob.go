@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dshills/realitycheck/internal/codeindex"
+)
+
+// expandInputGlobs appends the files matched by --spec-glob and --plan-glob
+// to f's spec and plan files. Each glob's matches are sorted by path so that
+// item IDs are stable across runs; a glob that matches nothing is an error.
+func expandInputGlobs(f *checkFlags) *exitError {
+	var err error
+	if f.specFiles, err = appendGlobMatches(f.specFiles, f.specGlobs); err != nil {
+		return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: --spec-glob %v", err)}
+	}
+	if f.planFiles, err = appendGlobMatches(f.planFiles, f.planGlobs); err != nil {
+		return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: --plan-glob %v", err)}
+	}
+	return nil
+}
+
+// appendGlobMatches appends the files matching each glob to files, skipping
+// files already listed.
+func appendGlobMatches(files, globs []string) ([]string, error) {
+	if len(globs) == 0 {
+		return files, nil
+	}
+	seen := make(map[string]bool, len(files))
+	for _, p := range files {
+		seen[filepath.Clean(p)] = true
+	}
+	out := append([]string{}, files...)
+	for _, g := range globs {
+		matches, err := globFiles(g)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", g, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("%q matched no files", g)
+		}
+		for _, m := range matches {
+			if !seen[m] {
+				seen[m] = true
+				out = append(out, m)
+			}
+		}
+	}
+	return out, nil
+}
+
+// globFiles returns the regular files matching glob, sorted. The glob uses
+// the code index syntax, so "**" matches any number of directories. The
+// walk starts at the longest leading directory without wildcards.
+func globFiles(glob string) ([]string, error) {
+	slashed := filepath.ToSlash(glob)
+	parts := strings.Split(slashed, "/")
+	n := 0
+	for n < len(parts)-1 && !strings.ContainsAny(parts[n], "*?[") {
+		n++
+	}
+	base := strings.Join(parts[:n], "/")
+	switch {
+	case base == "" && strings.HasPrefix(slashed, "/"):
+		base = "/"
+	case base == "":
+		base = "."
+	}
+	re, err := codeindex.CompileGlob(strings.Join(parts[n:], "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	var out []string
+	root := filepath.FromSlash(base)
+	if _, err := os.Stat(root); err != nil {
+		return nil, nil
+	}
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if re.MatchString(filepath.ToSlash(rel)) {
+			out = append(out, filepath.Clean(path))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(out)
+	return out, nil
+}
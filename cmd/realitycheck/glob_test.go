@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpandInputGlobs(t *testing.T) {
+	dir := t.TempDir()
+	for _, rel := range []string{"spec/b.md", "spec/a.md", "spec/auth/login.md", "spec/notes.txt", "plan/PLAN.md"} {
+		p := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("1. Item.\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	join := func(rel string) string { return filepath.Join(dir, filepath.FromSlash(rel)) }
+
+	f := checkFlags{
+		specFiles: []string{join("spec/b.md")},
+		specGlobs: []string{filepath.ToSlash(dir) + "/spec/**/*.md"},
+		planGlobs: []string{filepath.ToSlash(dir) + "/plan/PLAN.md"},
+	}
+	if err := expandInputGlobs(&f); err != nil {
+		t.Fatalf("expandInputGlobs: %v", err)
+	}
+	// Explicit files come first; matches follow in path order without
+	// repeating them.
+	wantSpec := []string{join("spec/b.md"), join("spec/a.md"), join("spec/auth/login.md")}
+	if !reflect.DeepEqual(f.specFiles, wantSpec) {
+		t.Errorf("specFiles = %v, want %v", f.specFiles, wantSpec)
+	}
+	if want := []string{join("plan/PLAN.md")}; !reflect.DeepEqual(f.planFiles, want) {
+		t.Errorf("planFiles = %v, want %v", f.planFiles, want)
+	}
+
+	for _, glob := range []string{filepath.ToSlash(dir) + "/spec/*.rst", filepath.ToSlash(dir) + "/missing/*.md"} {
+		f := checkFlags{specGlobs: []string{glob}}
+		if err := expandInputGlobs(&f); err == nil || err.code != exitCodeBadInput {
+			t.Errorf("glob %q: err = %v, want a bad-input error", glob, err)
+		}
+	}
+}
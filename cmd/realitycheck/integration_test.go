@@ -8,11 +8,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/dshills/realitycheck/internal/llm"
 	"github.com/dshills/realitycheck/internal/schema"
+	"github.com/dshills/realitycheck/internal/verdict"
 )
 
 // alignedMockResponse is the canned response for the aligned fixture.
@@ -58,20 +64,29 @@ type mockMultiProvider struct {
 	idx       int
 }
 
-func (m *mockMultiProvider) Complete(ctx context.Context, system, user string, maxTokens int, temp float64) (string, error) {
+func (m *mockMultiProvider) Complete(ctx context.Context, system, user string, maxTokens int, temp float64) (llm.CompletionResult, error) {
 	if m.idx >= len(m.responses) {
-		return "", fmt.Errorf("mock: no more responses")
+		return llm.CompletionResult{}, fmt.Errorf("mock: no more responses")
 	}
 	r := m.responses[m.idx]
 	m.idx++
-	return r, nil
+	return llm.CompletionResult{Text: r}, nil
 }
 
 // errorProvider always returns an error from Complete.
 type errorProvider struct{}
 
-func (e *errorProvider) Complete(ctx context.Context, system, user string, maxTokens int, temp float64) (string, error) {
-	return "", fmt.Errorf("simulated API error")
+func (e *errorProvider) Complete(ctx context.Context, system, user string, maxTokens int, temp float64) (llm.CompletionResult, error) {
+	return llm.CompletionResult{}, fmt.Errorf("simulated API error")
+}
+
+// hangingProvider blocks until its context is done, like a provider that
+// never responds.
+type hangingProvider struct{}
+
+func (h *hangingProvider) Complete(ctx context.Context, system, user string, maxTokens int, temp float64) (llm.CompletionResult, error) {
+	<-ctx.Done()
+	return llm.CompletionResult{}, ctx.Err()
 }
 
 func injectMock(t *testing.T, responses []string) {
@@ -96,8 +111,8 @@ func injectErrProvider(t *testing.T) {
 func baseFlags(t *testing.T, fixture string) checkFlags {
 	t.Helper()
 	return checkFlags{
-		specFile:    "../../testdata/" + fixture + "/SPEC.md",
-		planFile:    "../../testdata/" + fixture + "/PLAN.md",
+		specFiles:   []string{"../../testdata/" + fixture + "/SPEC.md"},
+		planFiles:   []string{"../../testdata/" + fixture + "/PLAN.md"},
 		codeRoot:    "../../testdata/" + fixture,
 		format:      "json",
 		out:         tempOut(t),
@@ -107,6 +122,13 @@ func baseFlags(t *testing.T, fixture string) checkFlags {
 		maxTokens:   4096,
 		temperature: 0.2,
 		offline:     true, // skip API key pre-flight in tests
+
+		failOnExitCode: exitCodeFailOn,
+		maxCritical:    -1,
+		maxWarn:        -1,
+		maxInfo:        -1,
+
+		postContentType: "application/json",
 	}
 }
 
@@ -177,9 +199,186 @@ func TestIntegration_FailOn(t *testing.T) {
 	}
 }
 
+func TestIntegration_MinScore(t *testing.T) {
+	injectMock(t, []string{driftMockResponse, driftMockResponse})
+	f := baseFlags(t, "drift")
+	f.scoreWeights = verdict.DefaultScoreWeights
+
+	// One CRITICAL finding scores 80.
+	f.minScore = 85
+	err := runCheck(context.Background(), f)
+	if code := exitCode(err); code != exitCodeFailOn {
+		t.Errorf("expected exit %d (min score), got %d: %v", exitCodeFailOn, code, err)
+	}
+
+	f.minScore = 80
+	if err := runCheck(context.Background(), f); err != nil {
+		t.Errorf("score equal to --min-score should pass: %v", err)
+	}
+}
+
+func TestIntegration_RequireAnyEvidence(t *testing.T) {
+	const noEvidence = `{
+  "coverage": {
+    "spec": [
+      {"id":"SPEC-001","status":"IMPLEMENTED","spec_reference":{"line_start":4,"line_end":4},"evidence":[]},
+      {"id":"SPEC-002","status":"IMPLEMENTED","spec_reference":{"line_start":5,"line_end":5},"evidence":[]},
+      {"id":"SPEC-003","status":"IMPLEMENTED","spec_reference":{"line_start":6,"line_end":6},"evidence":[]}
+    ],
+    "plan": []
+  },
+  "drift": [],
+  "violations": []
+}`
+	injectMock(t, []string{noEvidence})
+	f := baseFlags(t, "aligned")
+
+	// Without the guard the response is accepted.
+	if err := runCheck(context.Background(), f); err != nil {
+		t.Fatalf("without --require-any-evidence: %v", err)
+	}
+
+	f.requireAnyEvidence = true
+	f.out = tempOut(t)
+	err := runCheck(context.Background(), f)
+	var ee *exitError
+	if !errors.As(err, &ee) || ee.code != exitCodeBadOutput || ee.reason != reasonBadOutput {
+		t.Fatalf("expected exit %d (bad output), got %v", exitCodeBadOutput, err)
+	}
+	if out := readOutput(t, f.out); len(out) != 0 {
+		t.Errorf("no report should be written; got %d bytes", len(out))
+	}
+
+	injectMock(t, []string{alignedMockResponse})
+	if err := runCheck(context.Background(), f); err != nil {
+		t.Errorf("a response with evidence should pass: %v", err)
+	}
+}
+
+func TestIntegration_MaxSeverityCounts(t *testing.T) {
+	// The drift fixture has one CRITICAL drift finding and nothing else.
+	cases := []struct {
+		name    string
+		set     func(*checkFlags)
+		wantErr string
+	}{
+		{"critical over", func(f *checkFlags) { f.maxCritical = 0 }, "1 CRITICAL findings exceed --max-critical 0"},
+		{"critical at limit", func(f *checkFlags) { f.maxCritical = 1 }, ""},
+		{"warn and info at limit", func(f *checkFlags) { f.maxWarn, f.maxInfo = 0, 0 }, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			injectMock(t, []string{driftMockResponse})
+			f := baseFlags(t, "drift")
+			c.set(&f)
+			err := runCheck(context.Background(), f)
+			if c.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			var ee *exitError
+			if !errors.As(err, &ee) || ee.code != exitCodeFailOn || ee.msg != c.wantErr {
+				t.Errorf("err = %v, want exit %d with %q", err, exitCodeFailOn, c.wantErr)
+			}
+		})
+	}
+
+	f := baseFlags(t, "aligned")
+	f.maxWarn = -2
+	if code := exitCode(runCheck(context.Background(), f)); code != exitCodeBadInput {
+		t.Errorf("--max-warn -2: expected exit %d (bad input), got %d", exitCodeBadInput, code)
+	}
+}
+
+func TestIntegration_FailOnExitCode(t *testing.T) {
+	injectMock(t, []string{driftMockResponse, driftMockResponse})
+	f := baseFlags(t, "drift")
+	f.scoreWeights = verdict.DefaultScoreWeights
+	f.failOnExitCode = 17
+
+	f.failOn = "DRIFT_DETECTED"
+	if code := exitCode(runCheck(context.Background(), f)); code != 17 {
+		t.Errorf("--fail-on: expected exit 17, got %d", code)
+	}
+	f.failOn = ""
+	f.minScore = 85
+	if code := exitCode(runCheck(context.Background(), f)); code != 17 {
+		t.Errorf("--min-score: expected exit 17, got %d", code)
+	}
+
+	for _, n := range []int{0, 256} {
+		f := baseFlags(t, "aligned")
+		f.failOnExitCode = n
+		if code := exitCode(runCheck(context.Background(), f)); code != exitCodeBadInput {
+			t.Errorf("--fail-on-exit-code %d: expected exit %d (bad input), got %d", n, exitCodeBadInput, code)
+		}
+	}
+}
+
+func TestIntegration_PostURL(t *testing.T) {
+	injectMock(t, []string{driftMockResponse, driftMockResponse})
+	var posted []byte
+	status := http.StatusAccepted
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posted, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	f := baseFlags(t, "drift")
+	f.format = "md"
+	f.postURL = srv.URL
+	f.postRequired = true
+	f.failOn = "DRIFT_DETECTED"
+
+	// --out still gets the Markdown; the POST body is always the JSON report.
+	err := runCheck(context.Background(), f)
+	if code := exitCode(err); code != exitCodeFailOn {
+		t.Errorf("expected exit %d (failOn), got %d: %v", exitCodeFailOn, code, err)
+	}
+	if out := readOutput(t, f.out); !bytes.HasPrefix(out, []byte("#")) {
+		t.Errorf("--out should hold Markdown, got %.40q", out)
+	}
+	var report schema.Report
+	if parseErr := json.Unmarshal(posted, &report); parseErr != nil {
+		t.Fatalf("parse posted JSON: %v", parseErr)
+	}
+	if report.Summary.Verdict != schema.VerdictViolation {
+		t.Errorf("posted verdict = %q, want VIOLATION", report.Summary.Verdict)
+	}
+
+	// A failed POST under --post-required takes precedence over --fail-on.
+	status = http.StatusInternalServerError
+	var ee *exitError
+	if err := runCheck(context.Background(), f); !errors.As(err, &ee) || ee.code != exitCodeGeneral || ee.reason != reasonPost {
+		t.Errorf("err = %v, want exit %d with reason %s", err, exitCodeGeneral, reasonPost)
+	}
+}
+
+func TestIntegration_MinScoreOutOfRange_ExitsThree(t *testing.T) {
+	for _, n := range []int{-1, 101} {
+		f := baseFlags(t, "aligned")
+		f.minScore = n
+		err := runCheck(context.Background(), f)
+		if code := exitCode(err); code != exitCodeBadInput {
+			t.Errorf("--min-score %d: expected exit %d (bad input), got %d: %v", n, exitCodeBadInput, code, err)
+		}
+	}
+}
+
+func TestIntegration_NegativeConcurrency_ExitsThree(t *testing.T) {
+	f := baseFlags(t, "aligned")
+	f.concurrency = -1
+	if code := exitCode(runCheck(context.Background(), f)); code != exitCodeBadInput {
+		t.Errorf("--concurrency -1: expected exit %d (bad input), got %d", exitCodeBadInput, code)
+	}
+}
+
 func TestIntegration_MissingSpec_ExitsThree(t *testing.T) {
 	f := baseFlags(t, "aligned")
-	f.specFile = "" // missing required flag
+	f.specFiles = nil // missing required flag
 
 	err := runCheck(context.Background(), f)
 	if code := exitCode(err); code != exitCodeBadInput {
@@ -197,6 +396,24 @@ func TestIntegration_ProviderError_ExitsFour(t *testing.T) {
 	}
 }
 
+func TestIntegration_Timeout_ExitsFour(t *testing.T) {
+	orig := llm.NewProvider
+	llm.NewProvider = func(provider, model string) (llm.Provider, error) {
+		return &hangingProvider{}, nil
+	}
+	t.Cleanup(func() { llm.NewProvider = orig })
+	f := baseFlags(t, "aligned")
+	f.timeout = 50 * time.Millisecond
+
+	err := runCheck(context.Background(), f)
+	if code := exitCode(err); code != exitCodeAPIError {
+		t.Fatalf("expected exit %d (API error), got %d: %v", exitCodeAPIError, code, err)
+	}
+	if !strings.Contains(err.Error(), "50ms timeout") {
+		t.Errorf("error should name the limit: %v", err)
+	}
+}
+
 func TestIntegration_InvalidOutput_ExitsFive(t *testing.T) {
 	// Both initial and repair responses are invalid JSON → ErrInvalidModelOutput → exit 5.
 	injectMock(t, []string{"not json at all", "still not json"})
@@ -207,3 +424,129 @@ func TestIntegration_InvalidOutput_ExitsFive(t *testing.T) {
 		t.Errorf("expected exit %d (bad output), got %d: %v", exitCodeBadOutput, code, err)
 	}
 }
+
+func TestIntegration_MultipleSpecFiles(t *testing.T) {
+	injectMock(t, []string{alignedMockResponse})
+	f := baseFlags(t, "aligned")
+	f.specFiles = append(f.specFiles, "../../testdata/spec_fixture.md")
+
+	err := runCheck(context.Background(), f)
+	if code := exitCode(err); code != 0 {
+		t.Fatalf("expected exit 0, got %d: %v", code, err)
+	}
+
+	var report schema.Report
+	if parseErr := json.Unmarshal(readOutput(t, f.out), &report); parseErr != nil {
+		t.Fatalf("parse output JSON: %v", parseErr)
+	}
+	if len(report.Input.SpecFiles) != 2 {
+		t.Errorf("input.spec_files: got %v, want 2 entries", report.Input.SpecFiles)
+	}
+	if report.Input.SpecFile != f.specFiles[0] {
+		t.Errorf("input.spec_file: got %q, want %q", report.Input.SpecFile, f.specFiles[0])
+	}
+}
+
+// captureStdout runs fn with os.Stdout redirected and returns what it wrote.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	orig := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = orig }()
+	done := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		_, _ = buf.ReadFrom(r)
+		done <- buf.String()
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
+
+func TestIntegration_SortFindings(t *testing.T) {
+	shuffled := strings.Replace(driftMockResponse, `"drift": [`, `"drift": [
+    {"id":"DRIFT-002","severity":"INFO","description":"Extra logging","evidence":[{"path":"store.go","symbol":"Get","confidence":"HIGH"}],"why_unjustified":"Not in spec","impact":"None","recommendation":"Remove"},`, 1)
+	injectMock(t, []string{shuffled})
+	f := baseFlags(t, "drift")
+	f.sortFindings = true
+
+	if err := runCheck(context.Background(), f); err != nil {
+		t.Fatalf("runCheck: %v", err)
+	}
+	var report schema.Report
+	if parseErr := json.Unmarshal(readOutput(t, f.out), &report); parseErr != nil {
+		t.Fatalf("parse output JSON: %v", parseErr)
+	}
+	if len(report.Drift) != 2 || report.Drift[0].Severity != schema.SeverityCritical || report.Drift[1].Severity != schema.SeverityInfo {
+		t.Errorf("drift = %+v, want the CRITICAL finding first", report.Drift)
+	}
+}
+
+func TestIntegration_StdoutJSON(t *testing.T) {
+	injectMock(t, []string{alignedMockResponse})
+	f := baseFlags(t, "aligned")
+	f.out = ""
+
+	var err error
+	out := captureStdout(t, func() { err = runCheck(context.Background(), f) })
+	if err != nil {
+		t.Fatalf("runCheck: %v", err)
+	}
+	var report schema.Report
+	if parseErr := json.Unmarshal([]byte(out), &report); parseErr != nil {
+		t.Fatalf("parse stdout JSON: %v", parseErr)
+	}
+	if report.Summary.Verdict != schema.VerdictAligned {
+		t.Errorf("verdict: got %q, want ALIGNED", report.Summary.Verdict)
+	}
+	if !strings.HasPrefix(out, "{\n  \"") || !strings.HasSuffix(out, "}\n") {
+		t.Errorf("stdout should be indented JSON ending in a newline; got %q...", out[:min(len(out), 40)])
+	}
+}
+
+func TestIntegration_Quiet(t *testing.T) {
+	injectMock(t, []string{driftMockResponse, driftMockResponse})
+	f := baseFlags(t, "drift")
+	f.quiet = true
+	f.failOn = "DRIFT_DETECTED"
+
+	// With --out, the file gets the full report and stdout stays silent.
+	var err error
+	out := captureStdout(t, func() { err = runCheck(context.Background(), f) })
+	if code := exitCode(err); code != exitCodeFailOn {
+		t.Errorf("expected exit %d (failOn), got %d: %v", exitCodeFailOn, code, err)
+	}
+	if out != "" {
+		t.Errorf("stdout should be empty with --out, got %q", out)
+	}
+	var report schema.Report
+	if parseErr := json.Unmarshal(readOutput(t, f.out), &report); parseErr != nil {
+		t.Fatalf("parse output JSON: %v", parseErr)
+	}
+
+	// Without --out, stdout gets only the verdict line.
+	f.out = ""
+	out = captureStdout(t, func() { err = runCheck(context.Background(), f) })
+	if code := exitCode(err); code != exitCodeFailOn {
+		t.Errorf("expected exit %d (failOn), got %d: %v", exitCodeFailOn, code, err)
+	}
+	if want := fmt.Sprintf("%s %d\n", report.Summary.Verdict, report.Summary.Score); out != want {
+		t.Errorf("stdout = %q, want %q", out, want)
+	}
+}
+
+func TestIntegration_QuietVerbose_ExitsThree(t *testing.T) {
+	f := baseFlags(t, "aligned")
+	f.quiet = true
+	f.verbose = true
+
+	err := runCheck(context.Background(), f)
+	if code := exitCode(err); code != exitCodeBadInput {
+		t.Errorf("expected exit %d (bad input), got %d: %v", exitCodeBadInput, code, err)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dshills/realitycheck/internal/lint"
+	"github.com/dshills/realitycheck/internal/mdparse"
+	"github.com/dshills/realitycheck/internal/plan"
+	"github.com/dshills/realitycheck/internal/spec"
+)
+
+type lintFlags struct {
+	specFiles    []string
+	planFiles    []string
+	maxItemLines int
+}
+
+func newLintCmd() *cobra.Command {
+	var f lintFlags
+
+	cmd := &cobra.Command{
+		Use:          "lint",
+		Short:        "Check spec and plan files for authoring mistakes before running an analysis",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLint(f, os.Stdout)
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&f.specFiles, "spec", nil, "path to SPEC.md (repeatable)")
+	cmd.Flags().StringArrayVar(&f.planFiles, "plan", nil, "path to PLAN.md (repeatable)")
+	cmd.Flags().IntVar(&f.maxItemLines, "max-item-lines", lint.DefaultMaxItemLines, "report items with more than this many lines of text, outside code blocks, as likely mis-segmented")
+
+	return cmd
+}
+
+// runLint writes one line per problem to w and exits 2 when there are any.
+func runLint(f lintFlags, w io.Writer) error {
+	if len(f.specFiles) == 0 && len(f.planFiles) == 0 {
+		return &exitError{exitCodeBadInput, reasonBadInput, "error: lint needs --spec, --plan, or both"}
+	}
+	if f.maxItemLines < 1 {
+		return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: --max-item-lines must be at least 1; got %d", f.maxItemLines)}
+	}
+	opts := lint.Options{MaxItemLines: f.maxItemLines}
+
+	var issues []lint.Issue
+	for _, doc := range []struct {
+		seg   mdparse.Segmenter
+		paths []string
+	}{
+		{spec.Segmenter(), f.specFiles},
+		{plan.Segmenter(), f.planFiles},
+	} {
+		if len(doc.paths) == 0 {
+			continue
+		}
+		found, err := lint.Files(doc.seg, doc.paths, opts)
+		if err != nil {
+			return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: %v", err)}
+		}
+		issues = append(issues, found...)
+	}
+
+	for _, is := range issues {
+		if _, err := fmt.Fprintln(w, is); err != nil {
+			return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: write stdout: %v", err)}
+		}
+	}
+	if len(issues) > 0 {
+		return &exitError{exitCodeFailOn, reasonLint, fmt.Sprintf("lint: %d problem(s) found", len(issues))}
+	}
+	return nil
+}
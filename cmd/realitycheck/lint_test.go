@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunLint(t *testing.T) {
+	clean := lintFlags{
+		specFiles:    []string{"../../testdata/aligned/SPEC.md"},
+		planFiles:    []string{"../../testdata/aligned/PLAN.md"},
+		maxItemLines: 12,
+	}
+	var out bytes.Buffer
+	if err := runLint(clean, &out); err != nil || out.Len() != 0 {
+		t.Fatalf("clean files: err = %v, output %q", err, out.String())
+	}
+
+	plan := filepath.Join(t.TempDir(), "PLAN.md")
+	if err := os.WriteFile(plan, []byte("PLAN-001: Parse the files.\nPLAN-002: Render.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	bad := clean
+	bad.planFiles = []string{plan}
+	out.Reset()
+	err := runLint(bad, &out)
+	var ee *exitError
+	if !errors.As(err, &ee) || ee.code != exitCodeFailOn || ee.reason != reasonLint {
+		t.Fatalf("err = %v, want exit %d with reason %s", err, exitCodeFailOn, reasonLint)
+	}
+	if want := plan + ":2: merged-items: "; !strings.HasPrefix(out.String(), want) {
+		t.Errorf("output = %q, want it to start with %q", out.String(), want)
+	}
+}
+
+func TestRunLint_BadInput(t *testing.T) {
+	for _, f := range []lintFlags{
+		{maxItemLines: 12},
+		{specFiles: []string{"../../testdata/aligned/SPEC.md"}},
+		{specFiles: []string{"missing.md"}, maxItemLines: 12},
+	} {
+		var ee *exitError
+		if err := runLint(f, &bytes.Buffer{}); !errors.As(err, &ee) || ee.code != exitCodeBadInput {
+			t.Errorf("%+v: err = %v, want exit %d", f, err, exitCodeBadInput)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+// logLevels maps --log-level values to slog levels.
+var logLevels = map[string]slog.Level{
+	"error": slog.LevelError,
+	"warn":  slog.LevelWarn,
+	"info":  slog.LevelInfo,
+	"debug": slog.LevelDebug,
+}
+
+// validateLogFlags checks --log-level and --log-format.
+func validateLogFlags(f checkFlags) *exitError {
+	if _, ok := logLevels[strings.ToLower(f.logLevel)]; f.logLevel != "" && !ok {
+		return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: --log-level must be one of error, warn, info, debug; got %q", f.logLevel)}
+	}
+	switch strings.ToLower(f.logFormat) {
+	case "", "text", "json":
+		// valid
+	default:
+		return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: --log-format must be one of text, json; got %q", f.logFormat)}
+	}
+	return nil
+}
+
+// newLogger returns the logger selected by the check flags, writing to w, or
+// nil when logging is off. --log-level sets the level; without it, --verbose
+// means info and no logs are written otherwise. Text records that carry a
+// verdict are colored when w is a terminal and color is allowed.
+func newLogger(f checkFlags, w *os.File) *slog.Logger {
+	level, ok := logLevels[strings.ToLower(f.logLevel)]
+	if !ok {
+		if !f.verbose {
+			return nil
+		}
+		level = slog.LevelInfo
+	}
+	opts := &slog.HandlerOptions{Level: level}
+	if strings.ToLower(f.logFormat) == "json" {
+		return slog.New(slog.NewJSONHandler(w, opts))
+	}
+	var out io.Writer = w
+	if useColor(w, f.noColor) {
+		out = verdictColorWriter{w}
+	}
+	return slog.New(slog.NewTextHandler(out, opts))
+}
+
+// streamWriter returns where --stream echoes response text: w when logger
+// records info-level events in text format, and io.Discard otherwise, so raw
+// text never interleaves with JSON log records.
+func streamWriter(f checkFlags, logger *slog.Logger, w io.Writer) io.Writer {
+	if logger == nil || strings.ToLower(f.logFormat) == "json" || !logger.Enabled(context.Background(), slog.LevelInfo) {
+		return io.Discard
+	}
+	return w
+}
+
+// verdictAttrRe finds the verdict attribute in a text log record.
+var verdictAttrRe = regexp.MustCompile(`\bverdict=([A-Z_]+)`)
+
+// verdictColorWriter colors each text log record that has a verdict
+// attribute. slog's text handler writes one record per Write call.
+type verdictColorWriter struct {
+	w io.Writer
+}
+
+func (c verdictColorWriter) Write(p []byte) (int, error) {
+	m := verdictAttrRe.FindSubmatch(p)
+	if m == nil {
+		return c.w.Write(p)
+	}
+	line := strings.TrimSuffix(string(p), "\n")
+	if _, err := io.WriteString(c.w, colorVerdict(schema.Verdict(m[1]), line, true)+"\n"); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
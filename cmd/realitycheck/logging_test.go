@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateLogFlags(t *testing.T) {
+	ok := []checkFlags{{}, {logLevel: "DEBUG", logFormat: "json"}, {logLevel: "warn", logFormat: "text"}}
+	for _, f := range ok {
+		if err := validateLogFlags(f); err != nil {
+			t.Errorf("validateLogFlags(%+v) = %v, want nil", f, err)
+		}
+	}
+	bad := []checkFlags{{logLevel: "trace"}, {logFormat: "xml"}}
+	for _, f := range bad {
+		if err := validateLogFlags(f); err == nil || err.code != exitCodeBadInput {
+			t.Errorf("validateLogFlags(%+v) = %v, want a bad-input error", f, err)
+		}
+	}
+}
+
+func TestNewLogger(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if newLogger(checkFlags{}, file) != nil {
+		t.Error("logging should be off without --verbose or --log-level")
+	}
+	verbose := newLogger(checkFlags{verbose: true}, file)
+	if verbose == nil || !verbose.Enabled(t.Context(), slog.LevelInfo) || verbose.Enabled(t.Context(), slog.LevelDebug) {
+		t.Error("--verbose should log at info level")
+	}
+	debug := newLogger(checkFlags{verbose: true, logLevel: "debug"}, file)
+	if debug == nil || !debug.Enabled(t.Context(), slog.LevelDebug) {
+		t.Error("--log-level should override --verbose")
+	}
+
+	newLogger(checkFlags{logLevel: "info", logFormat: "json"}, file).Info("parsed spec", "items", 3)
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rec map[string]any
+	if err := json.Unmarshal(data, &rec); err != nil {
+		t.Fatalf("json log record: %v\n%s", err, data)
+	}
+	if rec["msg"] != "parsed spec" || rec["items"] != float64(3) {
+		t.Errorf("record = %v", rec)
+	}
+}
+
+func TestVerdictColorWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := verdictColorWriter{&buf}
+	if _, err := w.Write([]byte("level=INFO msg=done verdict=VIOLATION score=10\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("level=INFO msg=\"parsed spec\" items=3\n")); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	if lines[0] != ansiRed+"level=INFO msg=done verdict=VIOLATION score=10"+ansiReset {
+		t.Errorf("verdict record = %q, want it colored red", lines[0])
+	}
+	if strings.Contains(lines[1], "\x1b[") {
+		t.Errorf("record without a verdict should not be colored: %q", lines[1])
+	}
+}
+
+func TestStreamWriter(t *testing.T) {
+	var w bytes.Buffer
+	cases := []struct {
+		name string
+		f    checkFlags
+		want bool
+	}{
+		{"no logging", checkFlags{}, false},
+		{"verbose", checkFlags{verbose: true}, true},
+		{"debug", checkFlags{logLevel: "debug"}, true},
+		{"warn", checkFlags{logLevel: "warn"}, false},
+		{"json", checkFlags{verbose: true, logFormat: "json"}, false},
+	}
+	for _, c := range cases {
+		got := streamWriter(c.f, newLogger(c.f, os.Stderr), &w) == &w
+		if got != c.want {
+			t.Errorf("%s: writes to stderr = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
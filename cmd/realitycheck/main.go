@@ -2,87 +2,213 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/dshills/realitycheck"
 	"github.com/dshills/realitycheck/internal/codeindex"
-	"github.com/dshills/realitycheck/internal/drift"
-	"github.com/dshills/realitycheck/internal/llm"
-	"github.com/dshills/realitycheck/internal/plan"
-	"github.com/dshills/realitycheck/internal/profile"
 	"github.com/dshills/realitycheck/internal/render"
+	"github.com/dshills/realitycheck/internal/rules"
 	"github.com/dshills/realitycheck/internal/schema"
-	"github.com/dshills/realitycheck/internal/spec"
 	"github.com/dshills/realitycheck/internal/verdict"
 )
 
-const version = "0.1.0"
+const version = realitycheck.Version
 
 // Process exit codes as defined in SPEC §6 and PLAN Step 12.
 const (
 	exitCodeGeneral   = 1 // unexpected/internal error
-	exitCodeFailOn    = 2 // --fail-on threshold met
+	exitCodeFailOn    = 2 // --fail-on, --min-score, or --max-* threshold met; lint problems
 	exitCodeBadInput  = 3 // input validation error (missing flags, bad files)
 	exitCodeAPIError  = 4 // LLM provider / API error
 	exitCodeBadOutput = 5 // LLM produced unrecoverable invalid output
 )
 
-// exitError carries a desired process exit code alongside an error message.
-// main() inspects the returned error from root.Execute() and calls os.Exit
-// with the embedded code, keeping RunE free of direct os.Exit calls.
+// Machine-readable exit reasons reported with --error-format json. Each
+// exitError names its reason explicitly rather than having it inferred from
+// the exit code.
+const (
+	reasonInternal   = "internal"
+	reasonFailOn     = "fail_on"
+	reasonRegression = "regression"
+	reasonLint       = "lint"
+	reasonBadInput   = "bad_input"
+	reasonAPIError   = "api_error"
+	reasonBadOutput  = "bad_output"
+	reasonPost       = "post_failed"
+)
+
+// exitError carries a desired process exit code and reason alongside an error
+// message. main() inspects the returned error from root.Execute() and calls
+// os.Exit with the embedded code, keeping RunE free of direct os.Exit calls.
 // A pointer receiver is used so errors.As(&exitError{}) matching is unambiguous.
 type exitError struct {
-	code int
-	msg  string
+	code   int
+	reason string
+	msg    string
 }
 
 func (e *exitError) Error() string { return e.msg }
 
 func main() {
+	var errorFormat string
 	root := &cobra.Command{
 		Use:           "realitycheck",
 		Short:         "Intent enforcement for agentic coding systems",
+		Version:       version,
 		SilenceErrors: true,
 		SilenceUsage:  true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			switch errorFormat {
+			case "text", "json":
+				return nil
+			default:
+				return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: --error-format must be text or json; got %q", errorFormat)}
+			}
+		},
 	}
+	root.SetVersionTemplate(versionString() + "\n")
+	root.PersistentFlags().StringVar(&errorFormat, "error-format", "text", "format of the error printed to stderr on a non-zero exit: text or json")
 	root.AddCommand(newCheckCmd())
+	root.AddCommand(newDiffCmd())
+	root.AddCommand(newSpecDiffCmd())
+	root.AddCommand(newAggregateCmd())
+	root.AddCommand(newRenderCmd())
+	root.AddCommand(newViewCmd())
+	root.AddCommand(newBaselineCmd())
+	root.AddCommand(newLintCmd())
+	root.AddCommand(newValidateResponseCmd())
+	root.AddCommand(newVersionCmd())
 
 	if err := root.Execute(); err != nil {
 		var ee *exitError
-		if errors.As(err, &ee) {
-			if ee.msg != "" {
-				fmt.Fprintln(os.Stderr, ee.msg)
-			}
-			os.Exit(ee.code)
+		if !errors.As(err, &ee) {
+			// Errors from cobra itself (unknown flags, bad arguments).
+			ee = &exitError{exitCodeGeneral, reasonInternal, err.Error()}
 		}
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		writeExitError(os.Stderr, ee, errorFormat == "json")
+		os.Exit(ee.code)
 	}
 }
 
+// writeExitError reports ee on w, either as its plain message or as a single
+// JSON object: {"code":2,"reason":"fail_on","message":"..."}.
+func writeExitError(w io.Writer, ee *exitError, asJSON bool) {
+	if !asJSON {
+		if ee.msg != "" {
+			fmt.Fprintln(w, ee.msg)
+		}
+		return
+	}
+	b, err := json.Marshal(struct {
+		Code    int    `json:"code"`
+		Reason  string `json:"reason"`
+		Message string `json:"message"`
+	}{ee.code, ee.reason, ee.msg})
+	if err != nil {
+		fmt.Fprintln(w, ee.msg)
+		return
+	}
+	fmt.Fprintln(w, string(b))
+}
+
 type checkFlags struct {
-	specFile          string
-	planFile          string
-	codeRoot          string
-	format            string
-	out               string
-	profileName       string
-	provider          string
-	strict            bool
-	failOn            string
-	severityThreshold string
-	maxTokens         int
-	temperature       float64
-	model             string
-	offline           bool
-	verbose           bool
-	debug             bool
+	configFile         string
+	specFiles          []string
+	planFiles          []string
+	specGlobs          []string
+	planGlobs          []string
+	codeRoot           string
+	repo               string
+	ref                string
+	format             string
+	sarifGaps          bool
+	mdStyle            string
+	out                string
+	postURL            string
+	postContentType    string
+	postHeaders        []string
+	postRequired       bool
+	profileName        string
+	profileFile        string
+	provider           string
+	strict             bool
+	requireTests       bool
+	requireEvidence    bool
+	requireAnyEvidence bool
+	scanTodos          bool
+	checkPlanOrder     bool
+	partialAsWarn      bool
+	traceability       bool
+	sortFindings       bool
+	explain            bool
+	mode               string
+	failOn             string
+	minScore           int
+	maxCritical        int
+	maxWarn            int
+	maxInfo            int
+	failOnExitCode     int
+	severityThreshold  string
+	minConfidence      string
+	baseline           string
+	baselineIndex      string
+	saveIndex          string
+	maxTokens          int
+	temperature        float64
+	model              string
+	maxRetries         int
+	retryBaseDelay     time.Duration
+	timeout            time.Duration
+	scoreWeights       verdict.ScoreWeights
+	keywordWeights     bool
+	driftIDPattern     string
+	violationIDPattern string
+	cacheDir           string
+	chunkByDir         bool
+	chunkThreshold     int
+	maxIndexBytes      int
+	maxManifestBytes   int
+	maxPromptTokens    int
+	noCache            bool
+	offline            bool
+	record             string
+	replay             string
+	ignorePatterns     []string
+	include            []string
+	exclude            []string
+	langMap            []string
+	pathPrefix         string
+	since              string
+	useGitignore       bool
+	goAST              bool
+	concurrency        int
+	includeSnippets    bool
+	embedInventory     bool
+	watch              bool
+	dryRun             bool
+	verbose            bool
+	logLevel           string
+	logFormat          string
+	quiet              bool
+	noColor            bool
+	debug              bool
+	stream             bool
+	// rules come from the config file only; there is no flag for them.
+	rules []rules.Config
+	// driftIDRe and violationIDRe are compiled from driftIDPattern and
+	// violationIDPattern by compileIDPatterns.
+	driftIDRe     *regexp.Regexp
+	violationIDRe *regexp.Regexp
 }
 
 func newCheckCmd() *cobra.Command {
@@ -94,28 +220,126 @@ func newCheckCmd() *cobra.Command {
 		Args:         cobra.MaximumNArgs(1),
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) > 0 && f.codeRoot == "" {
+			// The path argument is as explicit as --code-root, so it must win
+			// over a code-root from the config file.
+			rootFlagSet := cmd.Flags().Changed("code-root")
+			ruleCfgs, err := applyConfig(cmd, f.configFile)
+			if err != nil {
+				return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: %v", err)}
+			}
+			if len(args) > 0 && !rootFlagSet {
 				f.codeRoot = args[0]
 			}
+			f.rules = ruleCfgs
+			if err := validateLogFlags(f); err != nil {
+				return err
+			}
+			if err := compileIDPatterns(&f); err != nil {
+				return err
+			}
+			if f.ref != "" && f.repo == "" {
+				return &exitError{exitCodeBadInput, reasonBadInput, "error: --ref requires --repo"}
+			}
+			if f.repo != "" {
+				cleanup, err := cloneRepo(cmd.Context(), &f)
+				defer cleanup()
+				if err != nil {
+					return err
+				}
+			}
+			if f.dryRun {
+				return runDryRun(f, os.Stdout)
+			}
+			if f.watch {
+				return runWatch(cmd.Context(), f)
+			}
 			return runCheck(cmd.Context(), f)
 		},
 	}
 
-	cmd.Flags().StringVar(&f.specFile, "spec", "", "path to SPEC.md (required)")
-	cmd.Flags().StringVar(&f.planFile, "plan", "", "path to PLAN.md (required)")
+	cmd.Flags().StringVar(&f.configFile, "config", "", "read default flag values from this YAML file (default: ./"+defaultConfigFile+" if present)")
+	cmd.Flags().StringArrayVar(&f.specFiles, "spec", nil, "path to SPEC.md (required; repeatable to merge several spec files)")
+	cmd.Flags().StringArrayVar(&f.planFiles, "plan", nil, "path to PLAN.md (required; repeatable to merge several plan files)")
+	cmd.Flags().StringArrayVar(&f.specGlobs, "spec-glob", nil, "add every file matching this glob as a spec file, e.g. 'docs/spec/**/*.md'; matches are merged in path order (repeatable)")
+	cmd.Flags().StringArrayVar(&f.planGlobs, "plan-glob", nil, "add every file matching this glob as a plan file, merged in path order (repeatable)")
 	cmd.Flags().StringVar(&f.codeRoot, "code-root", "", "root of the code to analyze (default: path arg or cwd)")
-	cmd.Flags().StringVar(&f.format, "format", "json", "output format: json or md")
+	cmd.Flags().StringVar(&f.repo, "repo", "", "shallow-clone this git URL to a temporary directory and analyze it; --spec and --plan are relative to the clone and default to SPEC.md/PLAN.md or docs/SPEC.md/docs/PLAN.md")
+	cmd.Flags().StringVar(&f.ref, "ref", "", "with --repo, the branch or tag to clone (default: the remote's default branch)")
+	cmd.Flags().StringVar(&f.format, "format", "json", "output format: json, yaml, md, html, sarif, junit, csv, or github")
+	cmd.Flags().BoolVar(&f.sarifGaps, "sarif-gaps", false, "with --format sarif, also emit a result for each NOT_IMPLEMENTED spec/plan item")
+	cmd.Flags().StringVar(&f.mdStyle, "md-style", render.MarkdownStyleTable, "with --format md, render spec and plan coverage as a table or a GitHub task-list checklist: table or checklist")
+	cmd.Flags().BoolVar(&f.sortFindings, "sort-findings", false, "sort drift and violations by severity, ID, and description, and coverage by ID, so repeated runs diff cleanly")
 	cmd.Flags().StringVar(&f.out, "out", "", "write output to this file instead of stdout")
+	cmd.Flags().StringVar(&f.postURL, "post-url", "", "after writing the output, POST the JSON report to this http or https URL")
+	cmd.Flags().StringVar(&f.postContentType, "post-content-type", "application/json", "Content-Type header for --post-url")
+	cmd.Flags().StringArrayVar(&f.postHeaders, "post-header", nil, "add a \"Name: value\" header to the --post-url request, e.g. for auth (repeatable)")
+	cmd.Flags().BoolVar(&f.postRequired, "post-required", false, "exit 1 when the --post-url request fails or gets a non-2xx response, instead of printing a warning")
 	cmd.Flags().StringVar(&f.profileName, "profile", "general", "enforcement profile name")
-	cmd.Flags().StringVar(&f.provider, "provider", "anthropic", "LLM provider: anthropic, openai, google")
+	cmd.Flags().StringVar(&f.profileFile, "profile-file", "", "load a user-defined profile from a YAML or TOML file (overrides --profile)")
+	cmd.Flags().StringVar(&f.provider, "provider", "anthropic", "LLM provider: anthropic, openai, azure, google; or a comma-separated fallback list tried in order, with optional :model per entry (e.g. anthropic,openai:gpt-4.1)")
 	cmd.Flags().BoolVar(&f.strict, "strict", false, "strict mode: escalate drift severities and treat unclear coverage as NOT_IMPLEMENTED")
+	cmd.Flags().BoolVar(&f.requireTests, "require-tests", false, "downgrade IMPLEMENTED spec items with no test evidence to PARTIAL (items saying \"no test required\" are exempt)")
+	cmd.Flags().BoolVar(&f.requireEvidence, "require-evidence", false, "downgrade IMPLEMENTED spec and plan items that cite no evidence to UNCLEAR")
+	cmd.Flags().BoolVar(&f.requireAnyEvidence, "require-any-evidence", false, "exit 5 if no coverage entry or finding in the report cites any evidence")
+	cmd.Flags().BoolVar(&f.scanTodos, "scan-todos", false, "mark spec and plan items named in TODO or FIXME comments (e.g. \"TODO: implement SPEC-003\") NOT_IMPLEMENTED")
+	cmd.Flags().BoolVar(&f.checkPlanOrder, "check-plan-order", false, "add WARN drift when a plan step is IMPLEMENTED but a step it \"depends on\" is NOT_IMPLEMENTED")
+	cmd.Flags().BoolVar(&f.partialAsWarn, "partial-as-warn", false, "add WARN drift for each PARTIAL or UNCLEAR spec item so it counts toward the score")
+	cmd.Flags().BoolVar(&f.traceability, "traceability", false, "ask the model which spec items each plan item serves and report a plan-to-spec matrix (uses more tokens)")
+	cmd.Flags().BoolVar(&f.explain, "explain", false, "ask the model for a plain-English rationale of the verdict, shown at the top of Markdown and HTML output (uses more tokens)")
 	cmd.Flags().StringVar(&f.failOn, "fail-on", "", "exit 2 if verdict >= this level (ALIGNED|PARTIALLY_ALIGNED|DRIFT_DETECTED|VIOLATION)")
+	cmd.Flags().IntVar(&f.minScore, "min-score", 0, "exit 2 if the score is below this value (0-100); combines with --fail-on")
+	cmd.Flags().IntVar(&f.maxCritical, "max-critical", -1, "exit 2 if there are more than this many CRITICAL findings (-1 disables); combines with --fail-on")
+	cmd.Flags().IntVar(&f.maxWarn, "max-warn", -1, "exit 2 if there are more than this many WARN findings (-1 disables); combines with --fail-on")
+	cmd.Flags().IntVar(&f.maxInfo, "max-info", -1, "exit 2 if there are more than this many INFO findings (-1 disables); combines with --fail-on")
+	cmd.Flags().IntVar(&f.failOnExitCode, "fail-on-exit-code", exitCodeFailOn, "exit code used when --fail-on, --min-score, or a --max-* limit is met (1-255)")
+	cmd.Flags().StringVar(&f.minConfidence, "min-confidence", "", "lower drift and violation severities one step when all evidence is below this confidence (HIGH|MEDIUM|LOW)")
+	cmd.Flags().StringVar(&f.baseline, "baseline", "", "suppress drift findings and violations listed in this baseline file (see realitycheck baseline write); they stay in the report but do not affect the score or verdict")
+	cmd.Flags().StringVar(&f.baselineIndex, "baseline-index", "", "add WARN drift for each exported Go symbol added since this code index (see --save-index) that no spec item cites as evidence")
+	cmd.Flags().StringVar(&f.saveIndex, "save-index", "", "write the code index built for this run to this file, for a later --baseline-index")
 	cmd.Flags().StringVar(&f.severityThreshold, "severity-threshold", "", "filter findings below this severity from output (INFO|WARN|CRITICAL); does not affect scoring")
-	cmd.Flags().IntVar(&f.maxTokens, "max-tokens", 4096, "maximum tokens for LLM response")
+	cmd.Flags().StringVar(&f.mode, "mode", "full", "analysis mode: full, or summary for a cheap pre-check that asks only for a rationale and the three most severe findings, with no coverage")
+	cmd.Flags().IntVar(&f.maxTokens, "max-tokens", 0, "maximum tokens for LLM response (default 4096, or 1024 with --mode summary)")
 	cmd.Flags().Float64Var(&f.temperature, "temperature", 0.2, "LLM temperature")
-	cmd.Flags().StringVar(&f.model, "model", "", "model ID (default varies by provider: claude-opus-4-6 / gpt-4o / gemini-2.0-flash)")
+	cmd.Flags().IntVar(&f.maxRetries, "max-retries", 2, "retries for transient provider errors (HTTP 429/500/502/503/529); 0 disables")
+	cmd.Flags().DurationVar(&f.retryBaseDelay, "retry-base-delay", time.Second, "delay before the first retry; doubles on each retry, with jitter")
+	cmd.Flags().DurationVar(&f.timeout, "timeout", 0, "overall limit for the LLM stage, including retries and the repair call (e.g. 5m); 0 means no limit")
+	cmd.Flags().IntVar(&f.scoreWeights.Critical, "score-critical", verdict.DefaultScoreWeights.Critical, "points subtracted from the score per CRITICAL finding")
+	cmd.Flags().IntVar(&f.scoreWeights.Warn, "score-warn", verdict.DefaultScoreWeights.Warn, "points subtracted from the score per WARN finding")
+	cmd.Flags().IntVar(&f.scoreWeights.Info, "score-info", verdict.DefaultScoreWeights.Info, "points subtracted from the score per INFO finding")
+	cmd.Flags().BoolVar(&f.keywordWeights, "keyword-weights", false, "weigh untagged spec items by their RFC 2119 keywords: 3 for MUST, SHALL, or REQUIRED, 2 for SHOULD or RECOMMENDED")
+	cmd.Flags().IntVar(&f.scoreWeights.Unmet, "score-unmet", verdict.DefaultScoreWeights.Unmet, "points subtracted from the score per unit of weight above 1 of each unmet spec item (half for PARTIAL)")
+	cmd.Flags().StringVar(&f.driftIDPattern, "drift-id-pattern", "", "regular expression that model drift IDs must match (default ^DRIFT-\\d+$); findings that do not match are renumbered")
+	cmd.Flags().StringVar(&f.violationIDPattern, "violation-id-pattern", "", "regular expression that model violation IDs must match (default ^VIOLATION-\\d+$); findings that do not match are renumbered")
+	cmd.Flags().StringVar(&f.model, "model", "", "model ID (default varies by provider: claude-opus-4-6 / gpt-4o / gemini-2.5-flash); for azure, the deployment name (required)")
+	cmd.Flags().StringVar(&f.cacheDir, "cache-dir", "", "reuse LLM responses stored in this directory when the prompt, model, and temperature are unchanged, and re-scan only changed files for the code index")
+	cmd.Flags().BoolVar(&f.noCache, "no-cache", false, "ignore --cache-dir (e.g. one set in the config file) and always call the provider")
+	cmd.Flags().BoolVar(&f.chunkByDir, "chunk-by-dir", false, "for large trees, analyze each top-level directory in a separate LLM call and merge the results")
+	cmd.Flags().IntVar(&f.chunkThreshold, "chunk-threshold", 2000, "with --chunk-by-dir, only chunk when the code index has more than this many symbols")
+	cmd.Flags().IntVar(&f.maxIndexBytes, "max-index-bytes", 0, "byte limit for the code inventory sent to the model (default: derived from --model)")
+	cmd.Flags().IntVar(&f.maxManifestBytes, "max-manifest-bytes", codeindex.DefaultManifestBytes, "byte limit for each dependency manifest in the code inventory; longer manifests are truncated with a notice")
+	cmd.Flags().IntVar(&f.maxPromptTokens, "max-prompt-tokens", 0, "exit 3 before calling the provider if a prompt is estimated at more than this many tokens (0 = no limit)")
 	cmd.Flags().BoolVar(&f.offline, "offline", false, "skip API key pre-flight check; use when operating with an injected mock provider or cached data")
-	cmd.Flags().BoolVar(&f.verbose, "verbose", false, "print execution trace to stderr")
+	cmd.Flags().StringVar(&f.record, "record", "", "write the model responses, with their prompts and metadata, to this JSON transcript for --replay")
+	cmd.Flags().StringVar(&f.replay, "replay", "", "answer provider calls from a transcript written by --record instead of calling a provider; implies --offline")
+	cmd.Flags().StringArrayVar(&f.ignorePatterns, "ignore", nil, "exclude from the code index: a directory name glob, or a path glob containing / such as internal/**/gen or **/*.pb.go (repeatable)")
+	cmd.Flags().StringArrayVar(&f.include, "include", nil, "index only paths matching this glob, e.g. internal/api/** (repeatable)")
+	cmd.Flags().StringArrayVar(&f.exclude, "exclude", nil, "drop paths matching this glob from the code index, e.g. **/*_gen.go (repeatable)")
+	cmd.Flags().StringArrayVar(&f.langMap, "lang-map", nil, "label files matching a glob with a language, as comma-separated glob=Language pairs, e.g. '*.h=C++,internal/dsl/*.md=MyDSL'; a language without an extractor is only a label (repeatable)")
+	cmd.Flags().StringVar(&f.pathPrefix, "path-prefix", "", "prepend this path to every indexed file, e.g. internal/api with --code-root internal/api, so evidence paths are relative to the repository root")
+	cmd.Flags().StringVar(&f.since, "since", "", "mark files changed since this git revision in the code index so new drift is reported first")
+	cmd.Flags().BoolVar(&f.useGitignore, "use-gitignore", false, "exclude files and directories matched by .gitignore files from the code index")
+	cmd.Flags().BoolVar(&f.goAST, "go-ast", false, "extract Go symbols with go/parser instead of regex (falls back to regex per file on parse errors)")
+	cmd.Flags().IntVar(&f.concurrency, "concurrency", 0, "number of files scanned at once while indexing the code (default: the number of CPUs)")
+	cmd.Flags().BoolVar(&f.includeSnippets, "include-snippets", false, "WARNING: sends source code to the provider; add the first lines of each symbol's declaration to the prompt so the model can verify behavior")
+	cmd.Flags().BoolVar(&f.embedInventory, "embed-inventory", false, "store the code inventory sent to the model, as truncated, in the report's meta.inventory for auditing; can be large")
+	cmd.Flags().BoolVar(&f.dryRun, "dry-run", false, "print the system and user prompts to stdout and exit without calling the provider (no API key needed)")
+	cmd.Flags().BoolVar(&f.watch, "watch", false, "after the first run, re-run whenever the code root, spec, or plan files change (Ctrl-C to exit)")
+	cmd.Flags().BoolVar(&f.verbose, "verbose", false, "log run events to stderr (same as --log-level info)")
+	cmd.Flags().StringVar(&f.logLevel, "log-level", "", "log run events to stderr at this level: error, warn, info, or debug (overrides --verbose)")
+	cmd.Flags().StringVar(&f.logFormat, "log-format", "text", "log record format: text or json")
+	cmd.Flags().BoolVar(&f.quiet, "quiet", false, "print only \"<verdict> <score>\" to stdout; with --out, print nothing and write the full report to the file")
+	cmd.Flags().BoolVar(&f.noColor, "no-color", false, "never color verdict log records (color is also off when NO_COLOR is set or stderr is not a terminal)")
+	cmd.Flags().BoolVar(&f.stream, "stream", false, "stream provider responses; with --verbose or --log-level info or debug, echo the response text to stderr as it arrives")
 	cmd.Flags().BoolVar(&f.debug, "debug", false, "dump assembled prompt to stderr")
 
 	return cmd
@@ -124,207 +348,331 @@ func newCheckCmd() *cobra.Command {
 func runCheck(ctx context.Context, f checkFlags) error {
 	start := time.Now()
 
-	// Step 1: Validate required flags and inputs.
-	if f.specFile == "" {
-		return &exitError{exitCodeBadInput, "error: --spec is required"}
-	}
-	if f.planFile == "" {
-		return &exitError{exitCodeBadInput, "error: --plan is required"}
-	}
-	if _, err := os.Stat(f.specFile); err != nil {
-		return &exitError{exitCodeBadInput, fmt.Sprintf("error: spec file %q not found: %v", f.specFile, err)}
-	}
-	if _, err := os.Stat(f.planFile); err != nil {
-		return &exitError{exitCodeBadInput, fmt.Sprintf("error: plan file %q not found: %v", f.planFile, err)}
-	}
-	if f.codeRoot == "" {
-		cwd, err := os.Getwd()
-		if err != nil {
-			return &exitError{exitCodeBadInput, fmt.Sprintf("error: cannot determine cwd: %v", err)}
-		}
-		f.codeRoot = cwd
-	}
-	if f.format != "json" && f.format != "md" {
-		return &exitError{exitCodeBadInput, fmt.Sprintf("error: --format must be \"json\" or \"md\", got %q", f.format)}
+	// Step 1: Validate output-only flags. Everything else is validated by
+	// realitycheck.Run.
+	if err := validateReportFormat(f.format, f.mdStyle); err != nil {
+		return err
 	}
 	// Normalize flag values to uppercase for case-insensitive matching.
 	f.failOn = strings.ToUpper(f.failOn)
-	f.severityThreshold = strings.ToUpper(f.severityThreshold)
-	// Validate provider.
-	switch strings.ToLower(f.provider) {
-	case "anthropic", "openai", "google":
-		// valid
-	default:
-		return &exitError{exitCodeBadInput, fmt.Sprintf("error: --provider value %q is not valid (anthropic|openai|google)", f.provider)}
-	}
-	// Apply default model for the selected provider if none was specified.
-	if f.model == "" {
-		f.model = defaultModelForProvider(f.provider)
-	}
 	if f.failOn != "" {
 		if verdict.VerdictOrdinal(schema.Verdict(f.failOn)) < 0 {
-			return &exitError{exitCodeBadInput, fmt.Sprintf("error: --fail-on value %q is not a valid verdict", f.failOn)}
+			return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: --fail-on value %q is not a valid verdict", f.failOn)}
 		}
 	}
-	if f.severityThreshold != "" {
-		switch schema.Severity(f.severityThreshold) {
-		case schema.SeverityInfo, schema.SeverityWarn, schema.SeverityCritical:
-			// valid
-		default:
-			return &exitError{exitCodeBadInput, fmt.Sprintf("error: --severity-threshold value %q is not valid (INFO|WARN|CRITICAL)", f.severityThreshold)}
+	if f.minScore < 0 || f.minScore > 100 {
+		return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: --min-score must be between 0 and 100; got %d", f.minScore)}
+	}
+	for _, l := range severityLimits(f, schema.Summary{}) {
+		if l.max < -1 {
+			return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: --%s must be -1 or more; got %d", l.flag, l.max)}
 		}
 	}
-	// Pre-flight API key check. When --offline is set the check is skipped
-	// (offline mode indicates a no-network or mock-provider environment).
-	// Per PLAN §7b: exit 4 if key is absent and --offline is false.
-	if !f.offline && os.Getenv(providerAPIKeyEnvVar(f.provider)) == "" {
-		envVar := providerAPIKeyEnvVar(f.provider)
-		return &exitError{exitCodeAPIError, fmt.Sprintf("error: %s is not set; set the environment variable or pass --offline to skip this check", envVar)}
+	if f.failOnExitCode < 1 || f.failOnExitCode > 255 {
+		return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: --fail-on-exit-code must be between 1 and 255; got %d", f.failOnExitCode)}
+	}
+	if f.concurrency < 0 {
+		return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: --concurrency must not be negative; got %d", f.concurrency)}
+	}
+	if f.quiet && f.verbose {
+		return &exitError{exitCodeBadInput, reasonBadInput, "error: --quiet and --verbose cannot be used together"}
+	}
+	if err := validatePostFlags(f); err != nil {
+		return err
 	}
 
-	logVerbose := func(msg string) {
-		if f.verbose {
-			fmt.Fprintf(os.Stderr, "[%.3fs] %s\n", time.Since(start).Seconds(), msg)
-		}
+	if err := expandInputGlobs(&f); err != nil {
+		return err
 	}
 
-	// Step 2: Parse SPEC.md.
-	logVerbose("parsing SPEC.md")
-	specItems, err := spec.Parse(f.specFile)
+	// Steps 2–14: Parse inputs, index code, call the LLM, score, and
+	// assemble the report.
+	cfg := runConfig(f)
+	report, err := realitycheck.Run(ctx, cfg)
 	if err != nil {
-		return &exitError{exitCodeBadInput, fmt.Sprintf("error: parse spec: %v", err)}
+		return runError(err)
+	}
+	// A report without a single citation cannot be verified; it usually
+	// means the model never saw the code index.
+	if f.requireAnyEvidence && !hasEvidence(report) {
+		return &exitError{exitCodeBadOutput, reasonBadOutput, "error: no coverage entry or finding cites any evidence (--require-any-evidence)"}
 	}
-	logVerbose(fmt.Sprintf("parsed %d spec items", len(specItems)))
 
-	// Step 3: Parse PLAN.md.
-	logVerbose("parsing PLAN.md")
-	planItems, err := plan.Parse(f.planFile)
-	if err != nil {
-		return &exitError{exitCodeBadInput, fmt.Sprintf("error: parse plan: %v", err)}
+	if f.sortFindings {
+		render.SortReport(report)
 	}
-	logVerbose(fmt.Sprintf("parsed %d plan items", len(planItems)))
 
-	// Step 4: Build code index.
-	logVerbose("building code index")
-	idx, err := codeindex.Build(f.codeRoot, nil)
-	if err != nil {
-		return &exitError{exitCodeBadInput, fmt.Sprintf("error: build code index: %v", err)}
+	// Step 15: Render output. JSON bound for stdout is not rendered here but
+	// encoded straight to it; --out needs the full bytes for its atomic write.
+	streamJSON := f.format == "json" && f.out == "" && !f.quiet
+	var output []byte
+	if !streamJSON {
+		output, err = renderReport(report, f.format, f.mdStyle, f.sarifGaps, &f.scoreWeights)
+		if err != nil {
+			return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: render: %v", err)}
+		}
 	}
-	logVerbose(fmt.Sprintf("indexed %d files", len(idx.Files)))
 
-	// Step 5: Load profile.
-	logVerbose("loading profile")
-	prof, err := profile.Load(f.profileName)
-	if err != nil {
-		return &exitError{exitCodeBadInput, fmt.Sprintf("error: %v", err)}
+	// Step 16: Write output. In quiet mode stdout gets only the verdict line,
+	// and nothing at all when the report goes to --out.
+	switch {
+	case f.out != "":
+		if writeErr := atomicWrite(f.out, output); writeErr != nil {
+			return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: write output: %v", writeErr)}
+		}
+	case f.quiet:
+		if _, writeErr := fmt.Fprintf(os.Stdout, "%s %d\n", report.Summary.Verdict, report.Summary.Score); writeErr != nil {
+			return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: write stdout: %v", writeErr)}
+		}
+	case streamJSON:
+		if writeErr := render.RenderJSONTo(os.Stdout, report); writeErr != nil {
+			return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: write stdout: %v", writeErr)}
+		}
+	default:
+		if _, writeErr := os.Stdout.Write(output); writeErr != nil {
+			return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: write stdout: %v", writeErr)}
+		}
 	}
 
-	// Step 6: Build LLM options (--debug causes prompt to be dumped to stderr inside llm.Analyze).
-	opts := llm.Options{
-		Provider:    f.provider,
-		Strict:      f.strict,
-		MaxTokens:   f.maxTokens,
-		Temperature: f.temperature,
-		Model:       f.model,
-		Debug:       f.debug,
+	// Step 17: POST the report to --post-url, after the normal output so both
+	// can be used together.
+	if f.postURL != "" {
+		if err := sendReport(ctx, f, report); err != nil {
+			return err
+		}
 	}
 
-	// Step 7: Call LLM.
-	logVerbose("calling LLM")
-	partial, err := llm.Analyze(ctx, specItems, planItems, idx, prof, opts)
-	if err != nil {
-		if errors.Is(err, llm.ErrInvalidModelOutput) {
-			return &exitError{exitCodeBadOutput, fmt.Sprintf("error: %v", err)}
+	if cfg.Logger != nil {
+		sum := report.Summary
+		cfg.Logger.Info("done",
+			"verdict", sum.Verdict,
+			"score", sum.Score,
+			"critical", sum.CriticalCount,
+			"warn", sum.WarnCount,
+			"info", sum.InfoCount,
+			"duration", time.Since(start))
+	}
+
+	// Step 18: Exit code based on --fail-on, --min-score, and the --max-*
+	// severity limits.
+	verd := report.Summary.Verdict
+	if f.failOn != "" {
+		threshold := schema.Verdict(f.failOn)
+		if verdict.VerdictOrdinal(verd) >= verdict.VerdictOrdinal(threshold) {
+			return &exitError{f.failOnExitCode, reasonFailOn, fmt.Sprintf("verdict %s meets or exceeds --fail-on threshold %s", verd, f.failOn)}
 		}
-		return &exitError{exitCodeAPIError, fmt.Sprintf("error: LLM: %v", err)}
 	}
-	logVerbose("LLM response received and validated")
+	if score := report.Summary.Score; score < f.minScore {
+		return &exitError{f.failOnExitCode, reasonFailOn, fmt.Sprintf("score %d is below --min-score %d", score, f.minScore)}
+	}
+	for _, l := range severityLimits(f, report.Summary) {
+		if l.max >= 0 && l.count > l.max {
+			return &exitError{f.failOnExitCode, reasonFailOn, fmt.Sprintf("%d %s findings exceed --%s %d", l.count, l.severity, l.flag, l.max)}
+		}
+	}
+	return nil
+}
+
+// severityLimit is one of the --max-critical, --max-warn, and --max-info
+// limits with the matching count from a report summary. A negative max
+// disables it.
+type severityLimit struct {
+	flag     string
+	severity schema.Severity
+	max      int
+	count    int
+}
+
+// severityLimits returns the --max-* limits in f, most severe first, with
+// the counts from sum.
+func severityLimits(f checkFlags, sum schema.Summary) []severityLimit {
+	return []severityLimit{
+		{"max-critical", schema.SeverityCritical, f.maxCritical, sum.CriticalCount},
+		{"max-warn", schema.SeverityWarn, f.maxWarn, sum.WarnCount},
+		{"max-info", schema.SeverityInfo, f.maxInfo, sum.InfoCount},
+	}
+}
 
-	// Step 8: Apply strict-mode severity escalation to drift findings.
-	if f.strict {
-		for i, d := range partial.Drift {
-			partial.Drift[i] = drift.EscalateSeverity(d, true)
+// hasEvidence reports whether any coverage entry, drift finding, or
+// violation in report cites evidence.
+func hasEvidence(report *schema.Report) bool {
+	for _, e := range report.Coverage.Spec {
+		if len(e.Evidence) > 0 {
+			return true
+		}
+	}
+	for _, e := range report.Coverage.Plan {
+		if len(e.Evidence) > 0 {
+			return true
 		}
 	}
+	for _, d := range report.Drift {
+		if len(d.Evidence) > 0 {
+			return true
+		}
+	}
+	for _, v := range report.Violations {
+		if len(v.Evidence) > 0 {
+			return true
+		}
+	}
+	return false
+}
 
-	// Steps 9–12: Count, score, and determine verdict on all findings.
-	// NOTE: severity filtering (Step 13) removes findings from OUTPUT only and
-	// does not affect these computed values, per PLAN Step 12 ("do not affect scoring").
-	crit, warn, info := verdict.CountSeverities(partial)
-	score := verdict.ComputeScore(crit, warn, info)
-	verd := verdict.DetermineVerdict(partial)
-	logVerbose(fmt.Sprintf("verdict=%s score=%d critical=%d warn=%d info=%d", verd, score, crit, warn, info))
-
-	// Step 13: Filter findings by severity threshold (output only; scoring is already done).
-	filteredDrift := partial.Drift
-	filteredViolations := partial.Violations
-	if f.severityThreshold != "" {
-		thresh := schema.Severity(f.severityThreshold)
-		filteredDrift = filterDrift(partial.Drift, thresh)
-		filteredViolations = filterViolations(partial.Violations, thresh)
-	}
-
-	// Step 14: Assemble final Report.
-	report := &schema.Report{
-		Tool:    "realitycheck",
-		Version: version,
-		Input: schema.Input{
-			SpecFile: f.specFile,
-			PlanFile: f.planFile,
-			CodeRoot: f.codeRoot,
-			Profile:  f.profileName,
-			Strict:   f.strict,
-		},
-		Summary: schema.Summary{
-			Verdict:       verd,
-			Score:         score,
-			CriticalCount: crit,
-			WarnCount:     warn,
-			InfoCount:     info,
-		},
-		Coverage:   partial.Coverage,
-		Drift:      filteredDrift,
-		Violations: filteredViolations,
-		Meta:       partial.Meta,
+// reportFormats lists the --format values accepted by check and render.
+const reportFormats = "json, yaml, md, html, sarif, junit, csv, github"
+
+// validateReportFormat checks --format and --md-style.
+func validateReportFormat(format, mdStyle string) *exitError {
+	switch format {
+	case "json", "yaml", "md", "html", "sarif", "junit", "csv", "github":
+		// valid
+	default:
+		return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: --format must be one of %s; got %q", reportFormats, format)}
+	}
+	switch mdStyle {
+	case "", render.MarkdownStyleTable, render.MarkdownStyleChecklist:
+		// valid
+	default:
+		return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: --md-style must be one of table, checklist; got %q", mdStyle)}
 	}
+	return nil
+}
 
-	// Step 15: Render output.
+// renderReport renders report in format, which must already be validated.
+// weights are the score weights shown in the HTML legend, or nil when they
+// are not known. The output always ends with a newline.
+func renderReport(report *schema.Report, format, mdStyle string, sarifGaps bool, weights *verdict.ScoreWeights) ([]byte, error) {
 	var output []byte
-	switch f.format {
+	var err error
+	switch format {
+	case "yaml":
+		output, err = render.RenderYAML(report)
 	case "md":
-		output = []byte(render.RenderMarkdown(report))
+		output = []byte(render.RenderMarkdownWithOptions(report, render.MarkdownOptions{Style: mdStyle}))
+	case "html":
+		output = []byte(render.RenderHTMLWithOptions(report, render.HTMLOptions{ScoreWeights: weights}))
+	case "sarif":
+		output, err = render.RenderSARIFWithOptions(report, render.SARIFOptions{IncludeGaps: sarifGaps})
+	case "junit":
+		output, err = render.RenderJUnit(report)
+	case "csv":
+		output, err = render.RenderCSV(report)
+	case "github":
+		output, err = render.RenderGitHub(report)
 	default:
 		output, err = render.RenderJSON(report)
-		if err != nil {
-			return &exitError{exitCodeGeneral, fmt.Sprintf("error: render: %v", err)}
-		}
 	}
-	// Ensure output ends with a newline.
+	if err != nil {
+		return nil, err
+	}
 	if len(output) > 0 && output[len(output)-1] != '\n' {
 		output = append(output, '\n')
 	}
+	return output, nil
+}
 
-	// Step 16: Write output.
-	if f.out != "" {
-		if writeErr := atomicWrite(f.out, output); writeErr != nil {
-			return &exitError{exitCodeGeneral, fmt.Sprintf("error: write output: %v", writeErr)}
+// compileIDPatterns compiles --drift-id-pattern and --violation-id-pattern
+// into f.driftIDRe and f.violationIDRe. An empty pattern leaves the default.
+func compileIDPatterns(f *checkFlags) *exitError {
+	for _, p := range []struct {
+		flag, pattern string
+		re            **regexp.Regexp
+	}{
+		{"drift-id-pattern", f.driftIDPattern, &f.driftIDRe},
+		{"violation-id-pattern", f.violationIDPattern, &f.violationIDRe},
+	} {
+		if p.pattern == "" {
+			continue
 		}
-	} else {
-		if _, writeErr := os.Stdout.Write(output); writeErr != nil {
-			return &exitError{exitCodeGeneral, fmt.Sprintf("error: write stdout: %v", writeErr)}
+		re, err := regexp.Compile(p.pattern)
+		if err != nil {
+			return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: --%s: %v", p.flag, err)}
 		}
+		*p.re = re
 	}
+	return nil
+}
 
-	logVerbose(fmt.Sprintf("done in %.3fs", time.Since(start).Seconds()))
+// runConfig maps the check flags onto a library RunConfig.
+func runConfig(f checkFlags) realitycheck.RunConfig {
+	cfg := realitycheck.RunConfig{
+		SpecFiles:          f.specFiles,
+		PlanFiles:          f.planFiles,
+		CodeRoot:           f.codeRoot,
+		ProfileName:        f.profileName,
+		ProfileFile:        f.profileFile,
+		Provider:           f.provider,
+		Model:              f.model,
+		Strict:             f.strict,
+		RequireTests:       f.requireTests,
+		RequireEvidence:    f.requireEvidence,
+		ScanTodos:          f.scanTodos,
+		CheckPlanOrder:     f.checkPlanOrder,
+		PartialAsWarn:      f.partialAsWarn,
+		Traceability:       f.traceability,
+		Explain:            f.explain,
+		Mode:               f.mode,
+		SeverityThreshold:  f.severityThreshold,
+		MinConfidence:      f.minConfidence,
+		ScoreWeights:       &f.scoreWeights,
+		KeywordWeights:     f.keywordWeights,
+		DriftIDPattern:     f.driftIDRe,
+		ViolationIDPattern: f.violationIDRe,
+		MaxTokens:          f.maxTokens,
+		Temperature:        f.temperature,
+		MaxRetries:         f.maxRetries,
+		RetryBaseDelay:     f.retryBaseDelay,
+		Timeout:            f.timeout,
+		IgnorePatterns:     f.ignorePatterns,
+		Include:            f.include,
+		Exclude:            f.exclude,
+		LangMap:            f.langMap,
+		PathPrefix:         f.pathPrefix,
+		Since:              f.since,
+		UseGitignore:       f.useGitignore,
+		GoAST:              f.goAST,
+		Concurrency:        f.concurrency,
+		IncludeSnippets:    f.includeSnippets,
+		EmbedInventory:     f.embedInventory,
+		ChunkByDir:         f.chunkByDir,
+		ChunkThreshold:     f.chunkThreshold,
+		MaxIndexBytes:      f.maxIndexBytes,
+		MaxManifestBytes:   f.maxManifestBytes,
+		MaxPromptTokens:    f.maxPromptTokens,
+		Offline:            f.offline,
+		Record:             f.record,
+		Replay:             f.replay,
+		Debug:              f.debug,
+		Rules:              f.rules,
+		Baseline:           f.baseline,
+		BaselineIndex:      f.baselineIndex,
+		SaveIndex:          f.saveIndex,
+	}
+	if !f.noCache {
+		cfg.CacheDir = f.cacheDir
+	}
+	cfg.Logger = newLogger(f, os.Stderr)
+	if f.stream {
+		cfg.Stream = streamWriter(f, cfg.Logger, os.Stderr)
+	}
+	return cfg
+}
 
-	// Step 17: Exit code based on --fail-on.
-	if f.failOn != "" {
-		threshold := schema.Verdict(f.failOn)
-		if verdict.VerdictOrdinal(verd) >= verdict.VerdictOrdinal(threshold) {
-			return &exitError{exitCodeFailOn, fmt.Sprintf("verdict %s meets or exceeds --fail-on threshold %s", verd, f.failOn)}
-		}
+// runError maps an error from the realitycheck library to an exitError.
+func runError(err error) *exitError {
+	switch {
+	case errors.Is(err, realitycheck.ErrInvalidInput):
+		return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: %v", err)}
+	case errors.Is(err, realitycheck.ErrMissingAPIKey):
+		return &exitError{exitCodeAPIError, reasonAPIError, fmt.Sprintf("error: %v; set the environment variable or pass --offline to skip this check", err)}
+	case errors.Is(err, realitycheck.ErrInvalidModelOutput):
+		return &exitError{exitCodeBadOutput, reasonBadOutput, fmt.Sprintf("error: %v", err)}
+	case errors.Is(err, realitycheck.ErrProvider):
+		return &exitError{exitCodeAPIError, reasonAPIError, fmt.Sprintf("error: %v", err)}
+	default:
+		return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: %v", err)}
 	}
-	return nil
 }
 
 // atomicWrite writes data to path via a temp file in the same directory, then renames.
@@ -359,65 +707,3 @@ func atomicWrite(path string, data []byte) error {
 	}
 	return nil
 }
-
-// severityOrdinal returns a numeric ordering for severity comparison.
-func severityOrdinal(s schema.Severity) int {
-	switch s {
-	case schema.SeverityInfo:
-		return 0
-	case schema.SeverityWarn:
-		return 1
-	case schema.SeverityCritical:
-		return 2
-	default:
-		return -1
-	}
-}
-
-// filterDrift returns a new slice containing only findings at or above threshold.
-func filterDrift(findings []schema.DriftFinding, threshold schema.Severity) []schema.DriftFinding {
-	thresh := severityOrdinal(threshold)
-	out := make([]schema.DriftFinding, 0, len(findings))
-	for _, d := range findings {
-		if severityOrdinal(d.Severity) >= thresh {
-			out = append(out, d)
-		}
-	}
-	return out
-}
-
-// filterViolations returns a new slice containing only violations at or above threshold.
-func filterViolations(violations []schema.Violation, threshold schema.Severity) []schema.Violation {
-	thresh := severityOrdinal(threshold)
-	out := make([]schema.Violation, 0, len(violations))
-	for _, v := range violations {
-		if severityOrdinal(v.Severity) >= thresh {
-			out = append(out, v)
-		}
-	}
-	return out
-}
-
-// providerAPIKeyEnvVar returns the environment variable name for the given provider's API key.
-func providerAPIKeyEnvVar(provider string) string {
-	switch strings.ToLower(provider) {
-	case "openai":
-		return "OPENAI_API_KEY"
-	case "google":
-		return "GOOGLE_API_KEY"
-	default:
-		return "ANTHROPIC_API_KEY"
-	}
-}
-
-// defaultModelForProvider returns the default model ID for the given provider.
-func defaultModelForProvider(provider string) string {
-	switch strings.ToLower(provider) {
-	case "openai":
-		return "gpt-4o"
-	case "google":
-		return "gemini-2.5-flash"
-	default:
-		return "claude-opus-4-6"
-	}
-}
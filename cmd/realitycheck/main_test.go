@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWriteExitError(t *testing.T) {
+	ee := &exitError{exitCodeFailOn, reasonFailOn, "verdict VIOLATION meets or exceeds --fail-on threshold DRIFT_DETECTED"}
+
+	var text bytes.Buffer
+	writeExitError(&text, ee, false)
+	if got, want := text.String(), ee.msg+"\n"; got != want {
+		t.Errorf("text output = %q, want %q", got, want)
+	}
+
+	var js bytes.Buffer
+	writeExitError(&js, ee, true)
+	var got struct {
+		Code    int    `json:"code"`
+		Reason  string `json:"reason"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(js.Bytes(), &got); err != nil {
+		t.Fatalf("json output %q does not parse: %v", js.String(), err)
+	}
+	if got.Code != 2 || got.Reason != "fail_on" || got.Message != ee.msg {
+		t.Errorf("json output = %+v", got)
+	}
+	if bytes.Count(js.Bytes(), []byte("\n")) != 1 {
+		t.Errorf("json output should be a single line, got %q", js.String())
+	}
+}
+
+func TestWriteExitError_EmptyMessage(t *testing.T) {
+	var text bytes.Buffer
+	writeExitError(&text, &exitError{exitCodeFailOn, reasonFailOn, ""}, false)
+	if text.Len() != 0 {
+		t.Errorf("empty message should print nothing in text mode, got %q", text.String())
+	}
+
+	var js bytes.Buffer
+	writeExitError(&js, &exitError{exitCodeFailOn, reasonFailOn, ""}, true)
+	if !bytes.Contains(js.Bytes(), []byte(`"reason":"fail_on"`)) {
+		t.Errorf("json mode should still report the reason, got %q", js.String())
+	}
+}
+
+func TestCompileIDPatterns(t *testing.T) {
+	f := checkFlags{driftIDPattern: `^DRIFT-\d+(-[a-z]+)?$`}
+	if err := compileIDPatterns(&f); err != nil {
+		t.Fatalf("compileIDPatterns: %v", err)
+	}
+	if f.driftIDRe == nil || !f.driftIDRe.MatchString("DRIFT-001-a") || f.violationIDRe != nil {
+		t.Errorf("driftIDRe = %v, violationIDRe = %v", f.driftIDRe, f.violationIDRe)
+	}
+	bad := checkFlags{violationIDPattern: `VIOLATION-(`}
+	if err := compileIDPatterns(&bad); err == nil || err.code != exitCodeBadInput || !strings.Contains(err.msg, "--violation-id-pattern") {
+		t.Errorf("invalid pattern: err = %v, want a bad-input error naming the flag", err)
+	}
+}
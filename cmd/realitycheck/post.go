@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dshills/realitycheck/internal/render"
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+// postTimeout bounds the --post-url request, on top of any deadline on the
+// run's context.
+const postTimeout = 30 * time.Second
+
+// postBodyExcerpt is how much of a non-2xx response body is quoted in the
+// error.
+const postBodyExcerpt = 512
+
+// validatePostFlags checks --post-url and the flags that go with it.
+func validatePostFlags(f checkFlags) *exitError {
+	if f.postURL == "" {
+		if len(f.postHeaders) > 0 || f.postRequired {
+			return &exitError{exitCodeBadInput, reasonBadInput, "error: --post-header and --post-required require --post-url"}
+		}
+		return nil
+	}
+	u, err := url.Parse(f.postURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: --post-url must be an http or https URL; got %q", f.postURL)}
+	}
+	if _, err := parsePostHeaders(f.postHeaders); err != nil {
+		return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: %v", err)}
+	}
+	return nil
+}
+
+// parsePostHeaders parses --post-header values of the form "Name: value".
+func parsePostHeaders(values []string) (http.Header, error) {
+	h := make(http.Header, len(values))
+	for _, v := range values {
+		name, value, ok := strings.Cut(v, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("--post-header must be \"Name: value\"; got %q", v)
+		}
+		h.Add(name, strings.TrimSpace(value))
+	}
+	return h, nil
+}
+
+// sendReport POSTs report as JSON to --post-url. A failure is printed as a
+// warning, or returned as an exitError under --post-required.
+func sendReport(ctx context.Context, f checkFlags, report *schema.Report) *exitError {
+	body, err := render.RenderJSON(report)
+	if err == nil {
+		err = postReport(ctx, f, body)
+	}
+	if err == nil {
+		return nil
+	}
+	if f.postRequired {
+		return &exitError{exitCodeGeneral, reasonPost, fmt.Sprintf("error: %v", err)}
+	}
+	fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	return nil
+}
+
+// postReport sends body to f.postURL with f.postContentType and the
+// --post-header headers. Transport errors and non-2xx responses are errors;
+// the URL in them has any password redacted.
+func postReport(ctx context.Context, f checkFlags, body []byte) error {
+	u, err := url.Parse(f.postURL)
+	if err != nil {
+		return fmt.Errorf("post report: %w", err)
+	}
+	header, err := parsePostHeaders(f.postHeaders)
+	if err != nil {
+		return fmt.Errorf("post report: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, postTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("post report to %s: %w", u.Redacted(), err)
+	}
+	req.Header = header
+	req.Header.Set("Content-Type", f.postContentType)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		// The client error already names the URL, without its password.
+		return fmt.Errorf("post report: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		excerpt, _ := io.ReadAll(io.LimitReader(resp.Body, postBodyExcerpt))
+		msg := fmt.Sprintf("post report to %s: %s", u.Redacted(), resp.Status)
+		if s := strings.TrimSpace(string(excerpt)); s != "" {
+			msg += ": " + s
+		}
+		return errors.New(msg)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body) // let the connection be reused
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+func TestValidatePostFlags(t *testing.T) {
+	cases := []struct {
+		name string
+		f    checkFlags
+		ok   bool
+	}{
+		{"none", checkFlags{}, true},
+		{"url and header", checkFlags{postURL: "https://example.com/in", postHeaders: []string{"Authorization: Bearer x"}}, true},
+		{"header without url", checkFlags{postHeaders: []string{"A: b"}}, false},
+		{"required without url", checkFlags{postRequired: true}, false},
+		{"not http", checkFlags{postURL: "ftp://example.com/in"}, false},
+		{"no host", checkFlags{postURL: "example.com/in"}, false},
+		{"header without colon", checkFlags{postURL: "https://example.com", postHeaders: []string{"Authorization"}}, false},
+		{"header name with space", checkFlags{postURL: "https://example.com", postHeaders: []string{"X Token: y"}}, false},
+	}
+	for _, c := range cases {
+		err := validatePostFlags(c.f)
+		if c.ok && err != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+		}
+		if !c.ok && (err == nil || err.code != exitCodeBadInput) {
+			t.Errorf("%s: err = %v, want exit %d", c.name, err, exitCodeBadInput)
+		}
+	}
+}
+
+func TestSendReport(t *testing.T) {
+	var gotBody, gotType, gotAuth string
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody, gotType, gotAuth = string(b), r.Header.Get("Content-Type"), r.Header.Get("Authorization")
+		w.WriteHeader(status)
+		io.WriteString(w, "ingest says no\n")
+	}))
+	defer srv.Close()
+
+	report := &schema.Report{Tool: "realitycheck", Summary: schema.Summary{Verdict: schema.VerdictAligned, Score: 100}}
+	f := checkFlags{
+		postURL:         srv.URL,
+		postContentType: "application/vnd.rc+json",
+		postHeaders:     []string{"Authorization: Bearer secret"},
+	}
+	if err := sendReport(context.Background(), f, report); err != nil {
+		t.Fatalf("sendReport: %v", err)
+	}
+	if !strings.Contains(gotBody, `"verdict": "ALIGNED"`) {
+		t.Errorf("body = %s, want the JSON report", gotBody)
+	}
+	if gotType != "application/vnd.rc+json" || gotAuth != "Bearer secret" {
+		t.Errorf("Content-Type = %q, Authorization = %q", gotType, gotAuth)
+	}
+
+	// A non-2xx response is only a warning unless --post-required is set.
+	status = http.StatusUnauthorized
+	if err := sendReport(context.Background(), f, report); err != nil {
+		t.Errorf("without --post-required: %v", err)
+	}
+	f.postRequired = true
+	err := sendReport(context.Background(), f, report)
+	if err == nil || err.code != exitCodeGeneral || err.reason != reasonPost {
+		t.Fatalf("err = %v, want exit %d with reason %s", err, exitCodeGeneral, reasonPost)
+	}
+	if !strings.Contains(err.msg, "401 Unauthorized: ingest says no") {
+		t.Errorf("msg = %q, want the status and response body", err.msg)
+	}
+}
+
+func TestPostReport_Canceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := postReport(ctx, checkFlags{postURL: srv.URL, postContentType: "application/json"}, []byte("{}"))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
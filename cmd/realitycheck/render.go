@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dshills/realitycheck/internal/coverage"
+	"github.com/dshills/realitycheck/internal/schema"
+	"github.com/dshills/realitycheck/internal/verdict"
+)
+
+type renderFlags struct {
+	format    string
+	mdStyle   string
+	sarifGaps bool
+	out       string
+}
+
+func newRenderCmd() *cobra.Command {
+	var f renderFlags
+
+	cmd := &cobra.Command{
+		Use:          "render <report.json>",
+		Short:        "Render a saved JSON report in another format without re-running the analysis",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRender(args[0], f)
+		},
+	}
+
+	cmd.Flags().StringVar(&f.format, "format", "md", "output format: json, yaml, md, html, sarif, junit, csv, or github")
+	cmd.Flags().StringVar(&f.mdStyle, "md-style", "", "coverage layout for --format md: table (default) or checklist")
+	cmd.Flags().BoolVar(&f.sarifGaps, "sarif-gaps", false, "with --format sarif, also report NOT_IMPLEMENTED spec and plan items")
+	cmd.Flags().StringVar(&f.out, "out", "", "write output to this file instead of stdout")
+
+	return cmd
+}
+
+func runRender(path string, f renderFlags) error {
+	if err := validateReportFormat(f.format, f.mdStyle); err != nil {
+		return err
+	}
+	report, err := loadReportStrict(path)
+	if err != nil {
+		return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: %v", err)}
+	}
+	output, err := renderReport(&report, f.format, f.mdStyle, f.sarifGaps, nil)
+	if err != nil {
+		return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: render: %v", err)}
+	}
+
+	if f.out != "" {
+		if writeErr := atomicWrite(f.out, output); writeErr != nil {
+			return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: write output: %v", writeErr)}
+		}
+	} else {
+		if _, writeErr := os.Stdout.Write(output); writeErr != nil {
+			return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: write stdout: %v", writeErr)}
+		}
+	}
+	return nil
+}
+
+// loadReportStrict is loadReport for reports that will be rendered: unknown
+// fields are rejected, and the verdict, coverage statuses, and severities
+// must be valid, so a file from another tool or version fails clearly rather
+// than rendering as an empty report.
+func loadReportStrict(path string) (schema.Report, error) {
+	var report schema.Report
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return report, fmt.Errorf("read report %q: %w", path, err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&report); err != nil {
+		return report, fmt.Errorf("parse report %q: %w", path, err)
+	}
+	if report.Tool != "realitycheck" {
+		return report, fmt.Errorf("%q is not a realitycheck JSON report", path)
+	}
+	if err := validateReport(report); err != nil {
+		return report, fmt.Errorf("report %q does not match the schema: %w", path, err)
+	}
+	return report, nil
+}
+
+// validateReport checks the enum fields of a decoded report and returns the
+// first invalid one.
+func validateReport(r schema.Report) error {
+	if verdict.VerdictOrdinal(r.Summary.Verdict) < 0 {
+		return fmt.Errorf("summary.verdict: %q is not a valid verdict", r.Summary.Verdict)
+	}
+	for i, e := range r.Coverage.Spec {
+		if _, err := coverage.ParseCoverageStatus(string(e.Status)); err != nil {
+			return fmt.Errorf("coverage.spec[%d].status: %w", i, err)
+		}
+	}
+	for i, e := range r.Coverage.Plan {
+		if _, err := coverage.ParseCoverageStatus(string(e.Status)); err != nil {
+			return fmt.Errorf("coverage.plan[%d].status: %w", i, err)
+		}
+	}
+	for i, d := range r.Drift {
+		if !validSeverity(d.Severity) {
+			return fmt.Errorf("drift[%d].severity: %q is not a valid severity", i, d.Severity)
+		}
+	}
+	for i, v := range r.Violations {
+		if !validSeverity(v.Severity) {
+			return fmt.Errorf("violations[%d].severity: %q is not a valid severity", i, v.Severity)
+		}
+	}
+	return nil
+}
+
+func validSeverity(s schema.Severity) bool {
+	switch s {
+	case schema.SeverityInfo, schema.SeverityWarn, schema.SeverityCritical:
+		return true
+	}
+	return false
+}
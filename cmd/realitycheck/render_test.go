@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+func TestRunRender_Formats(t *testing.T) {
+	dir := t.TempDir()
+	r := schema.Report{Tool: "realitycheck", Summary: schema.Summary{Verdict: schema.VerdictDriftDetected, Score: 93}}
+	r.Drift = []schema.DriftFinding{{ID: "DRIFT-001", Severity: schema.SeverityWarn, Description: "Undocumented retry loop"}}
+	in := writeReport(t, dir, "report.json", r)
+
+	cases := []struct {
+		format string
+		want   string
+	}{
+		{"md", "Undocumented retry loop"},
+		{"html", "<html"},
+		{"sarif", `"version": "2.1.0"`},
+		{"github", "::warning title=DRIFT-001::DRIFT-001: Undocumented retry loop"},
+		{"json", `"score": 93`},
+	}
+	for _, c := range cases {
+		out := filepath.Join(dir, "out."+c.format)
+		if err := runRender(in, renderFlags{format: c.format, out: out}); err != nil {
+			t.Fatalf("%s: %v", c.format, err)
+		}
+		b, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(b), c.want) {
+			t.Errorf("%s: output missing %q:\n%s", c.format, c.want, b)
+		}
+	}
+}
+
+func TestRunRender_BadInput(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	valid := writeReport(t, dir, "valid.json", schema.Report{Tool: "realitycheck", Summary: schema.Summary{Verdict: schema.VerdictAligned, Score: 100}})
+	cases := []struct {
+		name   string
+		path   string
+		format string
+	}{
+		{"missing file", filepath.Join(dir, "missing.json"), "md"},
+		{"not json", write("bad.json", "not json"), "md"},
+		{"not a report", write("other.json", `{"name":"x"}`), "md"},
+		{"unknown field", write("unknown.json", `{"tool":"realitycheck","extra":1,"summary":{"verdict":"ALIGNED"}}`), "md"},
+		{"bad verdict", write("verdict.json", `{"tool":"realitycheck","summary":{"verdict":"GREAT"}}`), "md"},
+		{"bad status", write("status.json", `{"tool":"realitycheck","summary":{"verdict":"ALIGNED"},"coverage":{"spec":[{"id":"SPEC-001","status":"DONE"}]}}`), "md"},
+		{"bad format", valid, "pdf"},
+	}
+	for _, c := range cases {
+		err := runRender(c.path, renderFlags{format: c.format, out: filepath.Join(dir, "out")})
+		var ee *exitError
+		if !errors.As(err, &ee) || ee.code != exitCodeBadInput {
+			t.Errorf("%s: expected exit code %d, got %v", c.name, exitCodeBadInput, err)
+		}
+	}
+}
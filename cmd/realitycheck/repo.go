@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Conventional spec and plan locations in a --repo clone, tried in order
+// when --spec or --plan is not given.
+var (
+	repoSpecPaths = []string{"SPEC.md", "docs/SPEC.md"}
+	repoPlanPaths = []string{"PLAN.md", "docs/PLAN.md"}
+)
+
+// cloneRepo makes a shallow clone of f.repo at f.ref in a temporary
+// directory and points f at it: the clone becomes the code root, relative
+// --spec and --plan paths are resolved inside it, and missing ones are
+// looked up at the conventional paths. Relative --spec-glob and --plan-glob
+// patterns are resolved inside the clone too. The returned function removes
+// the clone and must be called even when an error is returned.
+// Authentication is left to git's configured credential helper.
+func cloneRepo(ctx context.Context, f *checkFlags) (func(), error) {
+	cleanup := func() {}
+	if strings.HasPrefix(f.repo, "-") || strings.HasPrefix(f.ref, "-") {
+		return cleanup, &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: invalid --repo %q or --ref %q", f.repo, f.ref)}
+	}
+	if f.codeRoot != "" {
+		return cleanup, &exitError{exitCodeBadInput, reasonBadInput, "error: --repo cannot be combined with --code-root or a path argument"}
+	}
+	if f.watch {
+		return cleanup, &exitError{exitCodeBadInput, reasonBadInput, "error: --repo cannot be combined with --watch"}
+	}
+
+	dir, err := os.MkdirTemp("", "realitycheck-repo-*")
+	if err != nil {
+		return cleanup, &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: create clone directory: %v", err)}
+	}
+	cleanup = func() { _ = os.RemoveAll(dir) } // best-effort cleanup
+
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if f.ref != "" {
+		args = append(args, "--branch", f.ref)
+	}
+	args = append(args, "--", f.repo, dir)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return cleanup, &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: clone %s: %v", f.repo, err)}
+	}
+
+	f.codeRoot = dir
+	f.specGlobs = repoGlobs(dir, f.specGlobs)
+	f.planGlobs = repoGlobs(dir, f.planGlobs)
+	if f.specFiles, err = repoFiles(dir, f.specFiles, len(f.specGlobs) > 0, repoSpecPaths); err != nil {
+		return cleanup, &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: --repo %s: spec %v", f.repo, err)}
+	}
+	if f.planFiles, err = repoFiles(dir, f.planFiles, len(f.planGlobs) > 0, repoPlanPaths); err != nil {
+		return cleanup, &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: --repo %s: plan %v", f.repo, err)}
+	}
+	return cleanup, nil
+}
+
+// repoFiles resolves the relative paths in given against the clone at dir.
+// With no paths or globs given, it returns the first of conventional that
+// exists.
+func repoFiles(dir string, given []string, globbed bool, conventional []string) ([]string, error) {
+	if len(given) > 0 || globbed {
+		out := make([]string, len(given))
+		for i, p := range given {
+			if filepath.IsAbs(p) {
+				out[i] = p
+			} else {
+				out[i] = filepath.Join(dir, filepath.FromSlash(p))
+			}
+		}
+		return out, nil
+	}
+	for _, p := range conventional {
+		path := filepath.Join(dir, filepath.FromSlash(p))
+		if _, err := os.Stat(path); err == nil {
+			return []string{path}, nil
+		}
+	}
+	return nil, fmt.Errorf("not found at %s", strings.Join(conventional, " or "))
+}
+
+// repoGlobs resolves relative globs against the clone at dir.
+func repoGlobs(dir string, globs []string) []string {
+	out := make([]string, len(globs))
+	for i, g := range globs {
+		if filepath.IsAbs(g) {
+			out[i] = g
+		} else {
+			out[i] = filepath.ToSlash(filepath.Join(dir, g))
+		}
+	}
+	return out
+}
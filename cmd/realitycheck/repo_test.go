@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// gitRepo creates a git repository with files committed on branch main. It
+// returns the repository's file URL and a function that runs git in it.
+func gitRepo(t *testing.T, files map[string]string) (string, func(args ...string)) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q", "-b", "main")
+	for rel, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+	return "file://" + filepath.ToSlash(dir), git
+}
+
+func TestCloneRepo(t *testing.T) {
+	url, git := gitRepo(t, map[string]string{
+		"SPEC.md":  "1. Store values.\n",
+		"PLAN.md":  "1. Write the store.\n",
+		"store.go": "package store\n",
+	})
+	git("checkout", "-q", "-b", "release")
+	if err := os.Mkdir(filepath.Join(strings.TrimPrefix(url, "file://"), "docs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	git("mv", "PLAN.md", "docs/PLAN.md")
+	git("commit", "-q", "-m", "move plan")
+	git("checkout", "-q", "main")
+
+	f := checkFlags{repo: url}
+	cleanup, err := cloneRepo(context.Background(), &f)
+	if err != nil {
+		t.Fatalf("cloneRepo: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(f.codeRoot, "store.go")); err != nil {
+		t.Errorf("clone is missing store.go: %v", err)
+	}
+	if want := []string{filepath.Join(f.codeRoot, "SPEC.md")}; !reflect.DeepEqual(f.specFiles, want) {
+		t.Errorf("specFiles = %v, want %v", f.specFiles, want)
+	}
+	cleanup()
+	if _, err := os.Stat(f.codeRoot); !os.IsNotExist(err) {
+		t.Errorf("clone %s still exists after cleanup", f.codeRoot)
+	}
+
+	// --ref picks the branch; the plan is found at docs/PLAN.md.
+	f = checkFlags{repo: url, ref: "release", specFiles: []string{"SPEC.md"}}
+	cleanup, err = cloneRepo(context.Background(), &f)
+	defer cleanup()
+	if err != nil {
+		t.Fatalf("cloneRepo release: %v", err)
+	}
+	if want := []string{filepath.Join(f.codeRoot, "docs", "PLAN.md")}; !reflect.DeepEqual(f.planFiles, want) {
+		t.Errorf("planFiles = %v, want %v", f.planFiles, want)
+	}
+}
+
+func TestCloneRepo_Errors(t *testing.T) {
+	url, _ := gitRepo(t, map[string]string{"SPEC.md": "1. Store values.\n"})
+	cases := map[string]checkFlags{
+		"missing repo": {repo: "file://" + filepath.ToSlash(filepath.Join(t.TempDir(), "nope"))},
+		"unknown ref":  {repo: url, ref: "no-such-branch"},
+		"option ref":   {repo: url, ref: "--upload-pack=x"},
+		"code root":    {repo: url, codeRoot: "."},
+		"watch":        {repo: url, watch: true},
+		"no plan":      {repo: url, ref: "main"},
+	}
+	for name, f := range cases {
+		cleanup, err := cloneRepo(context.Background(), &f)
+		cleanup()
+		var ee *exitError
+		if !errors.As(err, &ee) || ee.code != exitCodeBadInput {
+			t.Errorf("%s: err = %v, want exit code %d", name, err, exitCodeBadInput)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dshills/realitycheck/internal/render"
+	"github.com/dshills/realitycheck/internal/spec"
+	"github.com/dshills/realitycheck/internal/specdiff"
+)
+
+type specDiffFlags struct {
+	format string
+	out    string
+}
+
+func newSpecDiffCmd() *cobra.Command {
+	var f specDiffFlags
+
+	cmd := &cobra.Command{
+		Use:          "spec-diff <old-SPEC.md> <new-SPEC.md>",
+		Short:        "Compare two versions of a spec and list added, removed, and changed items",
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSpecDiff(args[0], args[1], f)
+		},
+	}
+
+	cmd.Flags().StringVar(&f.format, "format", "json", "output format: json or md")
+	cmd.Flags().StringVar(&f.out, "out", "", "write output to this file instead of stdout")
+
+	return cmd
+}
+
+func runSpecDiff(oldPath, newPath string, f specDiffFlags) error {
+	switch f.format {
+	case "json", "md":
+		// valid
+	default:
+		return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: --format must be one of json, md; got %q", f.format)}
+	}
+
+	oldItems, err := spec.Parse(oldPath)
+	if err != nil {
+		return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: %v", err)}
+	}
+	newItems, err := spec.Parse(newPath)
+	if err != nil {
+		return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: %v", err)}
+	}
+
+	res := specdiff.Compare(oldItems, newItems)
+
+	var output []byte
+	if f.format == "md" {
+		output = []byte(render.RenderSpecDiffMarkdown(res))
+	} else {
+		output, err = render.RenderSpecDiffJSON(res)
+		if err != nil {
+			return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: render: %v", err)}
+		}
+	}
+	if len(output) > 0 && output[len(output)-1] != '\n' {
+		output = append(output, '\n')
+	}
+
+	if f.out != "" {
+		if writeErr := atomicWrite(f.out, output); writeErr != nil {
+			return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: write output: %v", writeErr)}
+		}
+	} else {
+		if _, writeErr := os.Stdout.Write(output); writeErr != nil {
+			return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: write stdout: %v", writeErr)}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/specdiff"
+)
+
+func TestRunSpecDiff(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	oldPath := write("old.md", "# Spec\n\n- Users can log in with email\n- Sessions expire after 30 minutes\n")
+	newPath := write("new.md", "# Spec\n\n- Users can log in with email\n- Admins can export audit logs as CSV\n- Sessions expire after 30 minutes\n")
+	out := filepath.Join(dir, "diff.json")
+
+	if err := runSpecDiff(oldPath, newPath, specDiffFlags{format: "json", out: out}); err != nil {
+		t.Fatalf("runSpecDiff: %v", err)
+	}
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res specdiff.Result
+	if err := json.Unmarshal(b, &res); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(res.Added) != 1 || res.Added[0].Text != "Admins can export audit logs as CSV" || res.Unchanged != 2 {
+		t.Errorf("result = %+v, want one added item and two unchanged", res)
+	}
+}
+
+func TestRunSpecDiff_BadInput(t *testing.T) {
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "SPEC.md")
+	if err := os.WriteFile(specPath, []byte("- Users can log in\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name     string
+		old, new string
+		format   string
+	}{
+		{"missing old", filepath.Join(dir, "missing.md"), specPath, "json"},
+		{"missing new", specPath, filepath.Join(dir, "missing.md"), "json"},
+		{"bad format", specPath, specPath, "sarif"},
+	}
+	for _, c := range cases {
+		err := runSpecDiff(c.old, c.new, specDiffFlags{format: c.format})
+		var ee *exitError
+		if !errors.As(err, &ee) || ee.code != exitCodeBadInput {
+			t.Errorf("%s: expected exit code %d, got %v", c.name, exitCodeBadInput, err)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dshills/realitycheck"
+)
+
+type validateFlags struct {
+	codeRoot       string
+	ignorePatterns []string
+	include        []string
+	exclude        []string
+	langMap        []string
+	pathPrefix     string
+	useGitignore   bool
+	goAST          bool
+}
+
+func newValidateResponseCmd() *cobra.Command {
+	var f validateFlags
+
+	cmd := &cobra.Command{
+		Use:   "validate-response <raw.json>",
+		Short: "Run the model output validation on a saved raw response, without a provider",
+		Long: `validate-response indexes the code root the way check does, validates a raw
+model response against it, and prints each validation error. The last line
+says whether check would accept the response or ask the model to repair it.
+It exits 5 when the response would need a repair.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runValidateResponse(args[0], f, os.Stdout)
+		},
+	}
+
+	cmd.Flags().StringVar(&f.codeRoot, "code-root", ".", "root of the code the response describes")
+	cmd.Flags().StringArrayVar(&f.ignorePatterns, "ignore", nil, "exclude from the code index: a directory name glob, or a path glob containing / (repeatable)")
+	cmd.Flags().StringArrayVar(&f.include, "include", nil, "index only paths matching this glob (repeatable)")
+	cmd.Flags().StringArrayVar(&f.exclude, "exclude", nil, "drop paths matching this glob from the code index (repeatable)")
+	cmd.Flags().StringArrayVar(&f.langMap, "lang-map", nil, "label files matching a glob with a language, as comma-separated glob=Language pairs (repeatable)")
+	cmd.Flags().StringVar(&f.pathPrefix, "path-prefix", "", "prepend this path to every indexed file, as check does")
+	cmd.Flags().BoolVar(&f.useGitignore, "use-gitignore", false, "exclude files and directories matched by .gitignore files from the code index")
+	cmd.Flags().BoolVar(&f.goAST, "go-ast", false, "extract Go symbols with go/parser instead of regex")
+
+	return cmd
+}
+
+// runValidateResponse validates the response at path against an index of
+// f.codeRoot and writes the validation errors and the outcome to w.
+func runValidateResponse(path string, f validateFlags, w io.Writer) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: %v", err)}
+	}
+	errs, repair, err := realitycheck.ValidateResponse(string(raw), realitycheck.RunConfig{
+		CodeRoot:       f.codeRoot,
+		IgnorePatterns: f.ignorePatterns,
+		Include:        f.include,
+		Exclude:        f.exclude,
+		LangMap:        f.langMap,
+		PathPrefix:     f.pathPrefix,
+		UseGitignore:   f.useGitignore,
+		GoAST:          f.goAST,
+	})
+	if err != nil {
+		return runError(err)
+	}
+	for _, e := range errs {
+		if _, err := fmt.Fprintf(w, "%s: %s\n", e.Field, e.Message); err != nil {
+			return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: write stdout: %v", err)}
+		}
+	}
+	if repair {
+		fmt.Fprintf(w, "result: repair needed (%d validation error(s))\n", len(errs))
+		return &exitError{exitCodeBadOutput, reasonBadOutput, "validate-response: the response would trigger a repair"}
+	}
+	fmt.Fprintf(w, "result: accepted (%d note(s))\n", len(errs))
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunValidateResponse(t *testing.T) {
+	const response = `{
+  "coverage": {
+    "spec": [{"id":"SPEC-001","status":"IMPLEMENTED","spec_reference":{"line_start":5,"line_end":5},"evidence":[{"path":"%s","symbol":"Get","confidence":"HIGH"}]}],
+    "plan": []
+  },
+  "drift": [],
+  "violations": []
+}`
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	f := validateFlags{codeRoot: "../../testdata/aligned"}
+
+	var out bytes.Buffer
+	if err := runValidateResponse(write("ok.json", strings.Replace(response, "%s", "store.go", 1)), f, &out); err != nil {
+		t.Fatalf("valid response: %v", err)
+	}
+	if out.String() != "result: accepted (0 note(s))\n" {
+		t.Errorf("valid response output = %q", out.String())
+	}
+
+	// A path given relative to the repository rather than the code root is
+	// resolved against --code-root, as check does.
+	out.Reset()
+	if err := runValidateResponse(write("rooted.json", strings.Replace(response, "%s", "testdata/aligned/store.go", 1)), f, &out); err != nil {
+		t.Fatalf("code-root-relative path: %v", err)
+	}
+	if !strings.Contains(out.String(), `normalized to "store.go"`) || !strings.HasSuffix(out.String(), "result: accepted (1 note(s))\n") {
+		t.Errorf("code-root-relative path output = %q", out.String())
+	}
+
+	out.Reset()
+	if err := runValidateResponse(write("fabricated.json", strings.Replace(response, "%s", "missing.go", 1)), f, &out); err != nil {
+		t.Fatalf("fabricated path: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "coverage.spec[0].evidence[0].path: ") || !strings.HasSuffix(out.String(), "result: accepted (1 note(s))\n") {
+		t.Errorf("fabricated path output = %q", out.String())
+	}
+
+	out.Reset()
+	err := runValidateResponse(write("bad.json", "not json"), f, &out)
+	var ee *exitError
+	if !errors.As(err, &ee) || ee.code != exitCodeBadOutput {
+		t.Fatalf("err = %v, want exit %d", err, exitCodeBadOutput)
+	}
+	if !strings.Contains(out.String(), "result: repair needed") {
+		t.Errorf("bad response output = %q", out.String())
+	}
+}
+
+func TestRunValidateResponse_BadInput(t *testing.T) {
+	var ee *exitError
+	if err := runValidateResponse("missing.json", validateFlags{codeRoot: "."}, &bytes.Buffer{}); !errors.As(err, &ee) || ee.code != exitCodeBadInput {
+		t.Errorf("missing file: err = %v, want exit %d", err, exitCodeBadInput)
+	}
+}
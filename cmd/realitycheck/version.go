@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Build metadata, set at link time:
+//
+//	go build -ldflags "-X main.commit=$(git rev-parse --short HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/realitycheck
+//
+// Either may be empty, and is then left out of the version line.
+var (
+	commit    string
+	buildDate string
+)
+
+// versionString returns the line printed by the version subcommand and
+// --version, e.g. "realitycheck 0.1.0 (commit 1a2b3c4, built 2026-01-02T03:04:05Z)".
+func versionString() string {
+	var meta []string
+	if commit != "" {
+		meta = append(meta, "commit "+commit)
+	}
+	if buildDate != "" {
+		meta = append(meta, "built "+buildDate)
+	}
+	s := "realitycheck " + version
+	if len(meta) > 0 {
+		s += " (" + strings.Join(meta, ", ") + ")"
+	}
+	return s
+}
+
+func newVersionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "version",
+		Short:        "Print the version and build metadata",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := fmt.Fprintln(cmd.OutOrStdout(), versionString()); err != nil {
+				return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: write stdout: %v", err)}
+			}
+			return nil
+		},
+	}
+}
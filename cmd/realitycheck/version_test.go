@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestVersionString(t *testing.T) {
+	origCommit, origDate := commit, buildDate
+	t.Cleanup(func() { commit, buildDate = origCommit, origDate })
+
+	cases := []struct {
+		commit, date string
+		want         string
+	}{
+		{"", "", "realitycheck " + version},
+		{"1a2b3c4", "", "realitycheck " + version + " (commit 1a2b3c4)"},
+		{"", "2026-01-02", "realitycheck " + version + " (built 2026-01-02)"},
+		{"1a2b3c4", "2026-01-02", "realitycheck " + version + " (commit 1a2b3c4, built 2026-01-02)"},
+	}
+	for _, c := range cases {
+		commit, buildDate = c.commit, c.date
+		if got := versionString(); got != c.want {
+			t.Errorf("commit %q, date %q: got %q, want %q", c.commit, c.date, got, c.want)
+		}
+	}
+}
+
+func TestVersionCmd(t *testing.T) {
+	cmd := newVersionCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs(nil)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), versionString()+"\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dshills/realitycheck/internal/tui"
+)
+
+func newViewCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "view <report.json>",
+		Short:        "Explore a saved JSON report in an interactive terminal viewer",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runView(args[0])
+		},
+	}
+}
+
+func runView(path string) error {
+	report, err := loadReportStrict(path)
+	if err != nil {
+		return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: %v", err)}
+	}
+	if !tui.IsTerminal(os.Stdin) || !tui.IsTerminal(os.Stdout) {
+		return &exitError{exitCodeBadInput, reasonBadInput, "error: view needs an interactive terminal; use render for piped output"}
+	}
+	if err := tui.Run(os.Stdin, os.Stdout, &report); err != nil {
+		if errors.Is(err, tui.ErrUnsupported) {
+			return &exitError{exitCodeBadInput, reasonBadInput, fmt.Sprintf("error: %v", err)}
+		}
+		return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: view: %v", err)}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+func TestRunView_Errors(t *testing.T) {
+	dir := t.TempDir()
+	in := writeReport(t, dir, "report.json", schema.Report{Tool: "realitycheck", Summary: schema.Summary{Verdict: schema.VerdictAligned}})
+
+	cases := []struct {
+		path string
+		want string
+	}{
+		{filepath.Join(dir, "missing.json"), "read report"},
+		// Test binaries do not run on a terminal.
+		{in, "interactive terminal"},
+	}
+	for _, c := range cases {
+		err := runView(c.path)
+		var ee *exitError
+		if !errors.As(err, &ee) || ee.code != exitCodeBadInput || !strings.Contains(ee.msg, c.want) {
+			t.Errorf("runView(%s) = %v, want exit %d mentioning %q", c.path, err, exitCodeBadInput, c.want)
+		}
+	}
+}
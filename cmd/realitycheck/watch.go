@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/dshills/realitycheck/internal/codeindex"
+)
+
+// watchDebounce is how long the watcher waits after the last change before
+// re-running, so that a save touching several files triggers one run.
+const watchDebounce = 500 * time.Millisecond
+
+// runWatch runs the check once, then again after every change to the code
+// root, spec, or plan files until interrupted. Errors from individual runs
+// are reported on stderr and do not stop the loop; only an input error on
+// the first run does, since it would repeat on every change.
+func runWatch(ctx context.Context, f checkFlags) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Without a cache every save would cost a provider call, even when the
+	// prompt is unchanged (e.g. a whitespace edit outside the inventory). Use
+	// a cache for the session unless one is configured or caching is off.
+	if f.cacheDir == "" && !f.noCache {
+		dir, err := os.MkdirTemp("", "realitycheck-watch-*")
+		if err != nil {
+			return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: create watch cache: %v", err)}
+		}
+		defer os.RemoveAll(dir)
+		f.cacheDir = dir
+	}
+
+	codeRoot := f.codeRoot
+	if codeRoot == "" {
+		codeRoot = "."
+	}
+	w, err := newWatcher(codeRoot, append(append([]string{}, f.specFiles...), f.planFiles...), watchExcluded(f))
+	if err != nil {
+		return &exitError{exitCodeGeneral, reasonInternal, fmt.Sprintf("error: watch: %v", err)}
+	}
+	defer w.Close()
+
+	run := func(ctx context.Context) error { return runCheck(ctx, f) }
+	if err := run(ctx); err != nil {
+		var ee *exitError
+		if errors.As(err, &ee) && ee.reason == reasonBadInput {
+			return err
+		}
+		reportWatchError(os.Stderr, err)
+	}
+	fmt.Fprintln(os.Stderr, "[watch] waiting for changes (Ctrl-C to exit)")
+	return w.loop(ctx, watchDebounce, run, os.Stderr)
+}
+
+// watchExcluded returns the paths each run writes to: the report, the
+// response cache, the --record transcript, and the --save-index file.
+// Changes there must not trigger another run, or the watch would never
+// settle.
+func watchExcluded(f checkFlags) []string {
+	return []string{f.out, f.cacheDir, f.record, f.saveIndex}
+}
+
+// reportWatchError prints a run error without ending the watch loop.
+func reportWatchError(w io.Writer, err error) {
+	if ee := (*exitError)(nil); errors.As(err, &ee) {
+		fmt.Fprintf(w, "[watch] %s\n", ee.msg)
+		return
+	}
+	fmt.Fprintf(w, "[watch] error: %v\n", err)
+}
+
+// watcher tracks the directories under a code root and the spec and plan
+// files, and decides which events warrant a re-run.
+type watcher struct {
+	fs       *fsnotify.Watcher
+	root     string
+	inputs   map[string]bool // absolute spec and plan paths
+	excluded []string        // absolute paths whose changes are ignored
+}
+
+// newWatcher watches every directory under root that codeindex.Build would
+// visit, plus the directory of each input file. Watching the directory
+// rather than the file itself survives editors that save by renaming a new
+// file into place. Changes at or under an excluded path (the report being
+// written, the response cache) are ignored so a run cannot trigger itself.
+func newWatcher(root string, inputs, excluded []string) (*watcher, error) {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		fw.Close()
+		return nil, err
+	}
+	w := &watcher{fs: fw, root: absRoot, inputs: make(map[string]bool)}
+	for _, p := range excluded {
+		if p == "" {
+			continue
+		}
+		if abs, err := filepath.Abs(p); err == nil {
+			w.excluded = append(w.excluded, abs)
+		}
+	}
+	if err := w.addTree(absRoot); err != nil {
+		fw.Close()
+		return nil, err
+	}
+	for _, p := range inputs {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			fw.Close()
+			return nil, err
+		}
+		w.inputs[abs] = true
+		if err := fw.Add(filepath.Dir(abs)); err != nil {
+			fw.Close()
+			return nil, fmt.Errorf("watch %q: %w", p, err)
+		}
+	}
+	return w, nil
+}
+
+// Close stops watching.
+func (w *watcher) Close() error { return w.fs.Close() }
+
+// addTree watches dir and every subdirectory not skipped by codeindex.Build.
+func (w *watcher) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != dir && (codeindex.IsDefaultIgnoredDir(d.Name()) || w.isExcluded(path)) {
+			return fs.SkipDir
+		}
+		return w.fs.Add(path)
+	})
+}
+
+// isExcluded reports whether path is at or under an excluded path, or is
+// the temp file codeindex.WriteCache renames into place over one
+// ("<path>.<random>.tmp").
+func (w *watcher) isExcluded(path string) bool {
+	for _, ex := range w.excluded {
+		if path == ex || strings.HasPrefix(path, ex+string(filepath.Separator)) {
+			return true
+		}
+		if strings.HasPrefix(path, ex+".") && strings.HasSuffix(path, ".tmp") {
+			return true
+		}
+	}
+	return false
+}
+
+// relevant reports whether a change to path should trigger a re-run.
+func (w *watcher) relevant(path string) bool {
+	if w.inputs[path] {
+		return true
+	}
+	if w.isExcluded(path) {
+		return false
+	}
+	rel, err := filepath.Rel(w.root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	// atomicWrite's temp files appear next to --out before the rename.
+	if strings.HasPrefix(filepath.Base(path), ".realitycheck-") {
+		return false
+	}
+	for _, part := range strings.Split(filepath.ToSlash(rel), "/") {
+		if codeindex.IsDefaultIgnoredDir(part) {
+			return false
+		}
+	}
+	return true
+}
+
+// loop calls run after each burst of relevant events, once debounce has
+// passed without another one, until ctx is cancelled.
+func (w *watcher) loop(ctx context.Context, debounce time.Duration, run func(context.Context) error, log io.Writer) error {
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	var changed string
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-w.fs.Events:
+			if !ok {
+				return nil
+			}
+			if !w.relevant(ev.Name) {
+				continue
+			}
+			// New directories must be watched explicitly.
+			if ev.Has(fsnotify.Create) {
+				if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+					if err := w.addTree(ev.Name); err != nil {
+						fmt.Fprintf(log, "[watch] cannot watch %s: %v\n", ev.Name, err)
+					}
+				}
+			}
+			changed = ev.Name
+			timer.Reset(debounce)
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintf(log, "[watch] error: %v\n", err)
+		case <-timer.C:
+			fmt.Fprintf(log, "[watch] %s changed; re-running\n", changed)
+			if err := run(ctx); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				reportWatchError(log, err)
+			}
+		}
+	}
+}
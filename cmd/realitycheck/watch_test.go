@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher_Relevant(t *testing.T) {
+	root := t.TempDir()
+	specDir := t.TempDir()
+	spec := filepath.Join(specDir, "SPEC.md")
+	out := filepath.Join(root, "report.json")
+	for _, d := range []string{"pkg", "node_modules/dep"} {
+		if err := os.MkdirAll(filepath.Join(root, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w, err := newWatcher(root, []string{spec}, []string{out})
+	if err != nil {
+		t.Fatalf("newWatcher: %v", err)
+	}
+	defer w.Close()
+
+	cases := []struct {
+		path string
+		want bool
+	}{
+		{filepath.Join(root, "main.go"), true},
+		{filepath.Join(root, "pkg", "a.go"), true},
+		{spec, true},
+		{filepath.Join(specDir, "NOTES.md"), false},
+		{filepath.Join(root, "node_modules", "dep", "index.js"), false},
+		{filepath.Join(root, ".git", "index"), false},
+		{out, false},
+		{filepath.Join(root, ".realitycheck-123.tmp"), false},
+	}
+	for _, c := range cases {
+		if got := w.relevant(c.path); got != c.want {
+			t.Errorf("relevant(%s) = %v, want %v", c.path, got, c.want)
+		}
+	}
+}
+
+func TestWatcher_LoopDebouncesAndExits(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "node_modules"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	w, err := newWatcher(root, nil, nil)
+	if err != nil {
+		t.Fatalf("newWatcher: %v", err)
+	}
+	defer w.Close()
+
+	runs := make(chan struct{}, 10)
+	run := func(context.Context) error {
+		runs <- struct{}{}
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.loop(ctx, 100*time.Millisecond, run, io.Discard) }()
+
+	// Changes under an ignored directory never trigger a run.
+	if err := os.WriteFile(filepath.Join(root, "node_modules", "x.js"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-runs:
+		t.Fatal("change under node_modules triggered a run")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	// A burst of writes triggers exactly one run.
+	for i := 0; i < 3; i++ {
+		if err := os.WriteFile(filepath.Join(root, "main.go"), []byte{byte('a' + i)}, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	select {
+	case <-runs:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no run after a change to main.go")
+	}
+	select {
+	case <-runs:
+		t.Fatal("burst of writes triggered more than one run")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("loop returned %v on cancel, want nil", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("loop did not exit on cancel")
+	}
+}
+
+func TestWatcher_ExcludedWriteDoesNotRerun(t *testing.T) {
+	root := t.TempDir()
+	f := checkFlags{record: filepath.Join(root, "transcript.json")}
+	w, err := newWatcher(root, nil, watchExcluded(f))
+	if err != nil {
+		t.Fatalf("newWatcher: %v", err)
+	}
+	defer w.Close()
+
+	runs := make(chan struct{}, 10)
+	run := func(context.Context) error {
+		runs <- struct{}{}
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.loop(ctx, 100*time.Millisecond, run, io.Discard)
+
+	if err := os.WriteFile(f.record, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-runs:
+		t.Fatal("writing the --record transcript triggered a run")
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestWatcher_SaveIndexExcluded(t *testing.T) {
+	root := t.TempDir()
+	f := checkFlags{saveIndex: filepath.Join(root, "index.json")}
+	w, err := newWatcher(root, nil, watchExcluded(f))
+	if err != nil {
+		t.Fatalf("newWatcher: %v", err)
+	}
+	defer w.Close()
+
+	for _, p := range []string{f.saveIndex, f.saveIndex + ".123456.tmp"} {
+		if w.relevant(p) {
+			t.Errorf("relevant(%s) = true, want false", p)
+		}
+	}
+	if !w.relevant(filepath.Join(root, "index.go")) {
+		t.Error("a source file next to the saved index should still be relevant")
+	}
+}
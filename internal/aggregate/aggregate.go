@@ -0,0 +1,44 @@
+// Package aggregate rolls several RealityCheck reports up into one summary,
+// such as one report per service in an organization.
+package aggregate
+
+import (
+	"math"
+
+	"github.com/dshills/realitycheck/internal/schema"
+	"github.com/dshills/realitycheck/internal/verdict"
+)
+
+// Combine summarizes reports, which were read from the files in paths
+// (paths[i] names reports[i]). Entries keep the order given. With no
+// reports the verdict is ALIGNED and the average score is 0.
+func Combine(paths []string, reports []schema.Report) schema.AggregateReport {
+	agg := schema.AggregateReport{
+		Verdict: schema.VerdictAligned,
+		Reports: make([]schema.AggregateEntry, 0, len(reports)),
+	}
+	total := 0
+	for i, r := range reports {
+		s := r.Summary
+		agg.Reports = append(agg.Reports, schema.AggregateEntry{
+			Path:          paths[i],
+			CodeRoot:      r.Input.CodeRoot,
+			Verdict:       s.Verdict,
+			Score:         s.Score,
+			CriticalCount: s.CriticalCount,
+			WarnCount:     s.WarnCount,
+			InfoCount:     s.InfoCount,
+		})
+		if verdict.VerdictOrdinal(s.Verdict) > verdict.VerdictOrdinal(agg.Verdict) {
+			agg.Verdict = s.Verdict
+		}
+		agg.CriticalCount += s.CriticalCount
+		agg.WarnCount += s.WarnCount
+		agg.InfoCount += s.InfoCount
+		total += s.Score
+	}
+	if len(reports) > 0 {
+		agg.AverageScore = math.Round(float64(total)/float64(len(reports))*10) / 10
+	}
+	return agg
+}
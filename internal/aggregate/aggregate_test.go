@@ -0,0 +1,62 @@
+package aggregate
+
+import (
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+func report(root string, v schema.Verdict, score, crit, warn, info int) schema.Report {
+	return schema.Report{
+		Input:   schema.Input{CodeRoot: root},
+		Summary: schema.Summary{Verdict: v, Score: score, CriticalCount: crit, WarnCount: warn, InfoCount: info},
+	}
+}
+
+func TestCombine(t *testing.T) {
+	agg := Combine(
+		[]string{"billing.json", "auth.json", "search.json"},
+		[]schema.Report{
+			report("billing", schema.VerdictAligned, 100, 0, 0, 0),
+			report("auth", schema.VerdictDriftDetected, 86, 0, 2, 0),
+			report("search", schema.VerdictPartiallyAligned, 96, 0, 0, 2),
+		},
+	)
+	if agg.Verdict != schema.VerdictDriftDetected {
+		t.Errorf("verdict = %s, want the worst, DRIFT_DETECTED", agg.Verdict)
+	}
+	if agg.AverageScore != 94 {
+		t.Errorf("average score = %v, want 94", agg.AverageScore)
+	}
+	if agg.CriticalCount != 0 || agg.WarnCount != 2 || agg.InfoCount != 2 {
+		t.Errorf("counts = %d/%d/%d, want 0/2/2", agg.CriticalCount, agg.WarnCount, agg.InfoCount)
+	}
+	if len(agg.Reports) != 3 || agg.Reports[1].Path != "auth.json" || agg.Reports[1].CodeRoot != "auth" || agg.Reports[1].Score != 86 {
+		t.Errorf("reports = %+v", agg.Reports)
+	}
+}
+
+func TestCombine_RoundsAverage(t *testing.T) {
+	agg := Combine([]string{"a", "b", "c"}, []schema.Report{
+		report("", schema.VerdictAligned, 100, 0, 0, 0),
+		report("", schema.VerdictAligned, 100, 0, 0, 0),
+		report("", schema.VerdictViolation, 79, 1, 0, 0),
+	})
+	if agg.AverageScore != 93 || agg.Verdict != schema.VerdictViolation {
+		t.Errorf("average = %v, verdict = %s; want 93 and VIOLATION", agg.AverageScore, agg.Verdict)
+	}
+	agg = Combine([]string{"a", "b"}, []schema.Report{
+		report("", schema.VerdictAligned, 100, 0, 0, 0),
+		report("", schema.VerdictAligned, 93, 0, 1, 0),
+	})
+	if agg.AverageScore != 96.5 {
+		t.Errorf("average = %v, want 96.5", agg.AverageScore)
+	}
+}
+
+func TestCombine_Empty(t *testing.T) {
+	agg := Combine(nil, nil)
+	if agg.Verdict != schema.VerdictAligned || agg.AverageScore != 0 || agg.Reports == nil {
+		t.Errorf("empty aggregate = %+v", agg)
+	}
+}
@@ -0,0 +1,87 @@
+// Package apisurface flags exported symbols added since a previous code
+// index that no spec item accounts for.
+package apisurface
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/dshills/realitycheck/internal/codeindex"
+	"github.com/dshills/realitycheck/internal/drift"
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+// Added returns the exported symbols in cur that were not exported symbols
+// in prev, in the order of cur. A symbol is identified by its path and
+// name, so a symbol that moved to another file counts as added. Methods of
+// unexported types are not exported (see codeindex.SymbolEntry), since no
+// caller outside the package can reach them.
+func Added(prev, cur codeindex.Index) []codeindex.SymbolEntry {
+	old := make(map[string]bool)
+	for _, s := range prev.Symbols {
+		if s.Exported {
+			old[key(s.Path, s.Symbol)] = true
+		}
+	}
+	var out []codeindex.SymbolEntry
+	for _, s := range cur.Symbols {
+		if s.Exported && !old[key(s.Path, s.Symbol)] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// key identifies a symbol across indexes built on different platforms.
+func key(path, symbol string) string {
+	return filepath.ToSlash(path) + "\x00" + symbol
+}
+
+// Check returns a WARN drift finding for each symbol in added that no spec
+// coverage entry cites as evidence. Evidence cites a symbol when it names
+// the same path and either the same symbol or a qualified form of it, such
+// as "Client.Get" for Get. Findings follow the order of added; their IDs
+// are left empty for the caller to assign.
+func Check(added []codeindex.SymbolEntry, specCov []schema.SpecCoverageEntry) []schema.DriftFinding {
+	cited := make(map[string]bool)
+	for _, e := range specCov {
+		for _, ev := range e.Evidence {
+			sym := ev.Symbol
+			if i := strings.LastIndex(sym, "."); i >= 0 {
+				sym = sym[i+1:]
+			}
+			cited[key(ev.Path, sym)] = true
+		}
+	}
+
+	var out []schema.DriftFinding
+	for _, s := range added {
+		path := filepath.ToSlash(s.Path)
+		if cited[key(path, s.Symbol)] {
+			continue
+		}
+		out = append(out, schema.DriftFinding{
+			Severity:       schema.SeverityWarn,
+			Description:    fmt.Sprintf("New exported symbol %s in %s has no spec backing.", s.Symbol, path),
+			Evidence:       []schema.Evidence{{Path: path, Symbol: s.Symbol, Confidence: schema.ConfidenceHigh}},
+			WhyUnjustified: fmt.Sprintf("%s was added to the public API since the baseline index, and no spec item cites it as evidence.", s.Symbol),
+			Impact:         "The public API grew beyond what the spec describes; callers may come to depend on it.",
+			Recommendation: fmt.Sprintf("Add a spec item covering %s, or unexport it.", s.Symbol),
+		})
+	}
+	return out
+}
+
+// Apply runs Check on the symbols added between prev and cur and appends
+// the findings to report.Drift, numbered by drift.NextID. It returns the
+// number of findings added.
+func Apply(report *schema.PartialReport, prev, cur codeindex.Index) int {
+	nextID := drift.NextID(report.Drift)
+	found := Check(Added(prev, cur), report.Coverage.Spec)
+	for _, d := range found {
+		d.ID = nextID()
+		report.Drift = append(report.Drift, d)
+	}
+	return len(found)
+}
@@ -0,0 +1,71 @@
+package apisurface
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/codeindex"
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+func sym(path, name string, exported bool) codeindex.SymbolEntry {
+	return codeindex.SymbolEntry{Path: path, Symbol: name, Exported: exported}
+}
+
+func TestAdded(t *testing.T) {
+	prev := codeindex.Index{Symbols: []codeindex.SymbolEntry{
+		sym("store.go", "Get", true),
+		sym("store.go", "helper", false),
+	}}
+	cur := codeindex.Index{Symbols: []codeindex.SymbolEntry{
+		sym("store.go", "Get", true),
+		sym("store.go", "Set", true),
+		sym("store.go", "helper", false),
+		sym("store.go", "other", false),
+		sym("cache.go", "Get", true), // same name, new file
+	}}
+	got := Added(prev, cur)
+	var names []string
+	for _, s := range got {
+		names = append(names, s.Path+":"+s.Symbol)
+	}
+	if want := "store.go:Set cache.go:Get"; strings.Join(names, " ") != want {
+		t.Errorf("Added = %v, want %s", names, want)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	added := []codeindex.SymbolEntry{
+		sym("store.go", "Get", true),
+		sym("store.go", "Set", true),
+		sym("cache.go", "Evict", true),
+	}
+	cov := []schema.SpecCoverageEntry{
+		{ID: "SPEC-001", Evidence: []schema.Evidence{{Path: "store.go", Symbol: "Store.Get"}}},
+		{ID: "SPEC-002", Evidence: []schema.Evidence{{Path: "store.go", Symbol: "Evict"}}}, // wrong file
+	}
+	got := Check(added, cov)
+	if len(got) != 2 {
+		t.Fatalf("Check returned %d findings, want 2: %+v", len(got), got)
+	}
+	for i, want := range []string{"Set", "Evict"} {
+		d := got[i]
+		if d.ID != "" || d.Severity != schema.SeverityWarn || d.Evidence[0].Symbol != want {
+			t.Errorf("finding %d = %+v, want WARN for %s with no ID", i, d, want)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	report := &schema.PartialReport{Drift: []schema.DriftFinding{{ID: "DRIFT-002"}}}
+	cur := codeindex.Index{Symbols: []codeindex.SymbolEntry{sym("store.go", "Set", true)}}
+	if n := Apply(report, codeindex.Index{}, cur); n != 1 {
+		t.Fatalf("Apply added %d findings, want 1", n)
+	}
+	if got := report.Drift[1].ID; got != "DRIFT-003" {
+		t.Errorf("new finding ID = %q, want DRIFT-003", got)
+	}
+	if n := Apply(report, cur, cur); n != 0 {
+		t.Errorf("Apply with an unchanged index added %d findings", n)
+	}
+}
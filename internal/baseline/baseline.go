@@ -0,0 +1,147 @@
+// Package baseline records accepted drift findings and violations so later
+// runs can suppress them.
+//
+// Finding IDs are regenerated on every run, so findings are keyed by a
+// fingerprint of their severity, normalized description, and sorted evidence
+// paths. A suppressed finding stays in the report but does not count toward
+// the score or the verdict.
+package baseline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+// FormatVersion is the version written to new baseline files.
+const FormatVersion = 1
+
+// File is the on-disk baseline document.
+type File struct {
+	Version  int     `json:"version"`
+	Findings []Entry `json:"findings"`
+}
+
+// Entry is one accepted finding. Only Fingerprint is used for matching; the
+// other fields make the file reviewable.
+type Entry struct {
+	Fingerprint string          `json:"fingerprint"`
+	Kind        string          `json:"kind"` // "drift" or "violation"
+	Severity    schema.Severity `json:"severity"`
+	Description string          `json:"description"`
+	Paths       []string        `json:"paths,omitempty"`
+}
+
+// Fingerprint returns the matching key for a finding: a hash of its
+// severity, its description lowercased with whitespace collapsed, and its
+// distinct evidence paths in sorted order.
+func Fingerprint(sev schema.Severity, description string, evidence []schema.Evidence) string {
+	h := sha256.New()
+	h.Write([]byte(sev))
+	h.Write([]byte{0})
+	h.Write([]byte(normalize(description)))
+	for _, p := range paths(evidence) {
+		h.Write([]byte{0})
+		h.Write([]byte(p))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// normalize lowercases s and collapses runs of whitespace to single spaces.
+func normalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// paths returns the distinct evidence paths, sorted.
+func paths(evidence []schema.Evidence) []string {
+	seen := make(map[string]bool, len(evidence))
+	var out []string
+	for _, ev := range evidence {
+		if ev.Path == "" || seen[ev.Path] {
+			continue
+		}
+		seen[ev.Path] = true
+		out = append(out, ev.Path)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// FromReport returns a baseline accepting every drift finding and violation
+// in r, including ones already suppressed. Duplicate fingerprints are
+// written once.
+func FromReport(r schema.Report) File {
+	f := File{Version: FormatVersion, Findings: []Entry{}}
+	seen := map[string]bool{}
+	add := func(kind string, sev schema.Severity, description string, evidence []schema.Evidence) {
+		fp := Fingerprint(sev, description, evidence)
+		if seen[fp] {
+			return
+		}
+		seen[fp] = true
+		f.Findings = append(f.Findings, Entry{
+			Fingerprint: fp,
+			Kind:        kind,
+			Severity:    sev,
+			Description: description,
+			Paths:       paths(evidence),
+		})
+	}
+	for _, d := range r.Drift {
+		add("drift", d.Severity, d.Description, d.Evidence)
+	}
+	for _, v := range r.Violations {
+		add("violation", v.Severity, v.Description, v.Evidence)
+	}
+	return f
+}
+
+// Load reads a baseline file written by Marshal.
+func Load(path string) (File, error) {
+	var f File
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return f, fmt.Errorf("baseline: read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return f, fmt.Errorf("baseline: parse %s: %w", path, err)
+	}
+	if f.Version != FormatVersion {
+		return f, fmt.Errorf("baseline: %s has version %d; want %d", path, f.Version, FormatVersion)
+	}
+	return f, nil
+}
+
+// Marshal returns f as indented JSON.
+func (f File) Marshal() ([]byte, error) {
+	return json.MarshalIndent(f, "", "  ")
+}
+
+// Apply marks every drift finding and violation in report whose fingerprint
+// is in f as suppressed, and returns how many it marked.
+func (f File) Apply(report *schema.PartialReport) int {
+	accepted := make(map[string]bool, len(f.Findings))
+	for _, e := range f.Findings {
+		accepted[e.Fingerprint] = true
+	}
+	n := 0
+	for i, d := range report.Drift {
+		if accepted[Fingerprint(d.Severity, d.Description, d.Evidence)] {
+			report.Drift[i].Suppressed = true
+			n++
+		}
+	}
+	for i, v := range report.Violations {
+		if accepted[Fingerprint(v.Severity, v.Description, v.Evidence)] {
+			report.Violations[i].Suppressed = true
+			n++
+		}
+	}
+	return n
+}
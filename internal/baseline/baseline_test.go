@@ -0,0 +1,95 @@
+package baseline
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+func TestFingerprint(t *testing.T) {
+	ev := []schema.Evidence{{Path: "b.go"}, {Path: "a.go", Symbol: "Get"}, {Path: "b.go"}}
+	fp := Fingerprint(schema.SeverityWarn, "Undocumented  TTL\neviction", ev)
+
+	same := Fingerprint(schema.SeverityWarn, "undocumented TTL eviction", []schema.Evidence{{Path: "a.go"}, {Path: "b.go"}})
+	if fp != same {
+		t.Error("fingerprint should ignore case, whitespace, path order, duplicate paths, and symbols")
+	}
+	for name, other := range map[string]string{
+		"severity":    Fingerprint(schema.SeverityCritical, "undocumented TTL eviction", ev),
+		"description": Fingerprint(schema.SeverityWarn, "undocumented LRU eviction", ev),
+		"paths":       Fingerprint(schema.SeverityWarn, "undocumented TTL eviction", []schema.Evidence{{Path: "a.go"}}),
+	} {
+		if other == fp {
+			t.Errorf("fingerprint should depend on %s", name)
+		}
+	}
+}
+
+func TestFromReportApply(t *testing.T) {
+	report := schema.Report{
+		Drift: []schema.DriftFinding{
+			{ID: "DRIFT-001", Severity: schema.SeverityWarn, Description: "Undocumented TTL eviction", Evidence: []schema.Evidence{{Path: "store.go"}}},
+			{ID: "DRIFT-002", Severity: schema.SeverityWarn, Description: "Undocumented TTL eviction", Evidence: []schema.Evidence{{Path: "store.go"}}},
+		},
+		Violations: []schema.Violation{
+			{ID: "VIOLATION-001", Severity: schema.SeverityCritical, Description: "Writes outside the data dir"},
+		},
+	}
+	f := FromReport(report)
+	if len(f.Findings) != 2 {
+		t.Fatalf("findings = %d, want 2 (duplicates written once)", len(f.Findings))
+	}
+	if f.Findings[0].Kind != "drift" || f.Findings[1].Kind != "violation" {
+		t.Errorf("kinds = %q, %q", f.Findings[0].Kind, f.Findings[1].Kind)
+	}
+
+	next := &schema.PartialReport{
+		Drift: []schema.DriftFinding{
+			{ID: "DRIFT-001", Severity: schema.SeverityWarn, Description: "undocumented TTL eviction", Evidence: []schema.Evidence{{Path: "store.go"}}},
+			{ID: "DRIFT-002", Severity: schema.SeverityWarn, Description: "New retry loop", Evidence: []schema.Evidence{{Path: "client.go"}}},
+		},
+		Violations: []schema.Violation{
+			{ID: "VIOLATION-001", Severity: schema.SeverityCritical, Description: "Writes outside the data dir"},
+		},
+	}
+	if n := f.Apply(next); n != 2 {
+		t.Errorf("Apply = %d, want 2", n)
+	}
+	if !next.Drift[0].Suppressed || next.Drift[1].Suppressed || !next.Violations[0].Suppressed {
+		t.Errorf("suppressed = %v, %v, %v; want true, false, true",
+			next.Drift[0].Suppressed, next.Drift[1].Suppressed, next.Violations[0].Suppressed)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "baseline.json")
+	b, err := FromReport(schema.Report{}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if f.Version != FormatVersion || len(f.Findings) != 0 {
+		t.Errorf("loaded %+v", f)
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte(`{"findings":[]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(bad); err == nil || !strings.Contains(err.Error(), "version") {
+		t.Errorf("Load without a version: err = %v", err)
+	}
+	if _, err := Load(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("Load of a missing file should fail")
+	}
+}
@@ -0,0 +1,80 @@
+package codeindex
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// cacheFormat is bumped whenever the Index layout or what is extracted from
+// a file changes, independently of the tool version.
+const cacheFormat = "4"
+
+// CacheFile returns the path under dir of the cached index for the code
+// tree at root. The name is derived from the absolute root and version, so
+// different trees never share an entry and an upgrade (new extractors)
+// starts afresh.
+func CacheFile(dir, root, version string) string {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	h := sha256.New()
+	for _, part := range []string{cacheFormat, version, root} {
+		fmt.Fprintf(h, "%d:%s\n", len(part), part)
+	}
+	return filepath.Join(dir, hex.EncodeToString(h.Sum(nil))+".index")
+}
+
+// ReadCache returns the index stored at path by WriteCache. A missing file
+// is reported as ok == false with a nil error.
+func ReadCache(path string) (idx Index, ok bool, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Index{}, false, nil
+		}
+		return Index{}, false, fmt.Errorf("codeindex: read cache: %w", err)
+	}
+	if err := json.Unmarshal(b, &idx); err != nil {
+		return Index{}, false, fmt.Errorf("codeindex: read cache %s: %w", path, err)
+	}
+	return idx, true, nil
+}
+
+// WriteCache stores idx at path for a later BuildIncremental, creating the
+// directory if needed. The file is written to a temp file and renamed so
+// concurrent runs never observe a partial index.
+func WriteCache(path string, idx Index) error {
+	b, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("codeindex: write cache: %w", err)
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("codeindex: create cache dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("codeindex: write cache: %w", err)
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return fmt.Errorf("codeindex: write cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("codeindex: write cache: %w", err)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("codeindex: write cache: %w", err)
+	}
+	return nil
+}
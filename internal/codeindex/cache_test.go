@@ -0,0 +1,108 @@
+package codeindex
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func symbolNames(idx Index) []string {
+	var names []string
+	for _, s := range idx.Symbols {
+		names = append(names, s.Path+":"+s.Symbol)
+	}
+	return names
+}
+
+func TestBuildIncremental(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "a.go"), "package x\n\nfunc A() {}\n")
+	writeFile(t, filepath.Join(root, "b.go"), "package x\n\nfunc B() {}\n")
+	writeFile(t, filepath.Join(root, "c.go"), "package x\n\nfunc C() {}\n")
+
+	prev, err := BuildWithOptions(root, BuildOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prev.Stamps) != 3 {
+		t.Fatalf("stamps = %v, want one per file", prev.Stamps)
+	}
+	// Tamper with a.go's cached symbol to tell a reused result from a
+	// re-scan.
+	for i := range prev.Symbols {
+		if prev.Symbols[i].Symbol == "A" {
+			prev.Symbols[i].Symbol = "CachedA"
+		}
+	}
+
+	writeFile(t, filepath.Join(root, "b.go"), "package x\n\nfunc B2() {}\n")
+	if err := os.Remove(filepath.Join(root, "c.go")); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(root, "d.go"), "package x\n\nfunc D() {}\n")
+
+	idx, err := BuildIncremental(root, BuildOptions{}, prev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"a.go:CachedA", "b.go:B2", "d.go:D"}
+	if got := symbolNames(idx); !reflect.DeepEqual(got, want) {
+		t.Errorf("symbols = %v, want %v", got, want)
+	}
+	if _, ok := idx.Stamps["c.go"]; ok {
+		t.Error("removed file should have no stamp")
+	}
+
+	// Different extraction options re-scan every file.
+	idx, err = BuildIncremental(root, BuildOptions{SnippetLines: 2}, prev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := symbolNames(idx); got[0] != "a.go:A" {
+		t.Errorf("symbols = %v, want a.go re-scanned", got)
+	}
+}
+
+func TestCacheRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	path := CacheFile(dir, fixtureDir, "v1")
+	if path == CacheFile(dir, fixtureDir, "v2") || path == CacheFile(dir, ".", "v1") {
+		t.Error("cache file should depend on the root and version")
+	}
+
+	if _, ok, err := ReadCache(path); ok || err != nil {
+		t.Fatalf("missing cache: ok = %v, err = %v", ok, err)
+	}
+	idx, err := Build(fixtureDir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteCache(path, idx); err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err := ReadCache(path)
+	if !ok || err != nil {
+		t.Fatalf("ReadCache: ok = %v, err = %v", ok, err)
+	}
+	if !reflect.DeepEqual(got, idx) {
+		t.Error("cached index differs from the one written")
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := ReadCache(path); ok || err == nil {
+		t.Errorf("corrupt cache: ok = %v, err = %v, want an error", ok, err)
+	}
+}
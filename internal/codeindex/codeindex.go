@@ -1,27 +1,57 @@
 // Package codeindex builds a lightweight code inventory from a directory tree.
 // It extracts file lists, symbols, test function names, dependency manifests,
-// and config file names without performing full AST parsing.
+// and config file names. Extraction is regex-based by default; Go files can
+// optionally be parsed with go/parser (see BuildOptions.UseAST).
 package codeindex
 
 import (
 	"fmt"
+	"go/token"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/dshills/realitycheck/internal/mdparse"
 )
 
 // FileEntry describes a single file in the inventory.
 type FileEntry struct {
 	Path     string // relative to the code root
 	Language string // classified by file extension
+	Changed  bool   // modified since Index.ChangedSince; see MarkChanged
 }
 
 // SymbolEntry is a named symbol (function, type, class, etc.) extracted from a file.
 type SymbolEntry struct {
-	Path   string // relative file path
-	Symbol string // extracted symbol name
+	Path     string // relative file path
+	Symbol   string // extracted symbol name
+	Exported bool   // symbol is exported, and for a method so is its type; determined for Go files only
+	// Signature is the callable signature including the name, e.g.
+	// "Get(key string) (string, bool)". Empty for non-callable symbols and
+	// for languages without a signature extractor.
+	Signature string
+	// Line is the 1-based line of the declaration, or 0 if unknown. It is
+	// set by the Go AST extractor and, with BuildOptions.SnippetLines, for
+	// every symbol.
+	Line int
+	// Snippet is source text starting at Line; set only with
+	// BuildOptions.SnippetLines.
+	Snippet string
+}
+
+// summaryLine renders s for Summary(): the signature when known, otherwise
+// the bare symbol name.
+func (s SymbolEntry) summaryLine() string {
+	if s.Signature != "" {
+		return fmt.Sprintf("  %s: %s\n", s.Path, s.Signature)
+	}
+	return fmt.Sprintf("  %s: %s\n", s.Path, s.Symbol)
 }
 
 // TestEntry is a named test function extracted from a test file.
@@ -41,12 +71,64 @@ type Index struct {
 	Files               []FileEntry
 	Symbols             []SymbolEntry
 	Tests               []TestEntry
+	Todos               []TodoEntry // only with BuildOptions.ScanTodos
 	DependencyManifests []ManifestEntry
 	ConfigFiles         []string // relative paths only; content not included
+	// ChangedSince is the git revision passed to MarkChanged, or empty.
+	ChangedSince string
+	// ManifestBytes caps the text of each dependency manifest in Summary,
+	// so that one large manifest cannot crowd out the symbol list. A longer
+	// manifest is cut and followed by a notice giving its full size. Zero
+	// means DefaultManifestBytes.
+	ManifestBytes int
+	// PathPrefix is the BuildOptions.PathPrefix the index was built with,
+	// slash-separated, or empty. Every path in the index except the Stamps
+	// keys starts with it.
+	PathPrefix string
+	// Stamps records the size and modification time of each source file
+	// when it was indexed, keyed by its path relative to the code root,
+	// without PathPrefix. BuildIncremental compares them to decide which
+	// files to re-scan.
+	Stamps map[string]FileStamp
+	// Extraction identifies the BuildOptions that change what is extracted
+	// from a file. BuildIncremental reuses nothing from an index built with
+	// different ones.
+	Extraction string
 }
 
-// maxSummaryBytes is the maximum byte length of Summary() output before truncation.
-const maxSummaryBytes = 40_000
+// FileStamp is a file's size and modification time (Unix nanoseconds).
+type FileStamp struct {
+	Size    int64
+	ModTime int64
+}
+
+// MarkChanged sets Changed on each file whose path is in changed
+// (slash-separated, relative to the code root, without PathPrefix) and
+// records since as the revision they changed from. It returns the number of
+// files marked.
+func (idx *Index) MarkChanged(since string, changed []string) int {
+	set := make(map[string]bool, len(changed))
+	for _, p := range changed {
+		set[p] = true
+	}
+	idx.ChangedSince = since
+	n := 0
+	for i := range idx.Files {
+		if set[filepath.ToSlash(idx.unprefixed(idx.Files[i].Path))] {
+			idx.Files[i].Changed = true
+			n++
+		}
+	}
+	return n
+}
+
+// DefaultSummaryBytes is the byte limit Summary applies before truncating
+// the symbol list.
+const DefaultSummaryBytes = 40_000
+
+// DefaultManifestBytes is the per-manifest byte limit Summary applies when
+// Index.ManifestBytes is zero.
+const DefaultManifestBytes = 8 << 10
 
 // maxFileSize is the maximum file size to read for symbol extraction.
 const maxFileSize = 1 << 20 // 1 MB
@@ -57,25 +139,70 @@ type ExtractorFunc func(content string) []string
 // symbolExtractors maps file extensions to their symbol extractors.
 // Designed for extension: add new entries to support additional languages.
 var symbolExtractors = map[string]ExtractorFunc{
-	".go":  extractGoSymbols,
-	".ts":  extractJSSymbols,
-	".tsx": extractJSSymbols,
-	".js":  extractJSSymbols,
-	".jsx": extractJSSymbols,
-	".py":  extractPythonSymbols,
-	".rs":  extractRustSymbols,
+	".go":     extractGoSymbols,
+	".ts":     extractJSSymbols,
+	".tsx":    extractJSSymbols,
+	".js":     extractJSSymbols,
+	".jsx":    extractJSSymbols,
+	".mjs":    extractJSSymbols,
+	".cjs":    extractJSSymbols,
+	".vue":    extractSFCSymbols,
+	".svelte": extractSFCSymbols,
+	".py":     extractPythonSymbols,
+	".rs":     extractRustSymbols,
+	".cs":     extractCSharpSymbols,
+	".kt":     extractKotlinSymbols,
+	".swift":  extractSwiftSymbols,
+	".php":    extractPHPSymbols,
+	".rb":     extractRubySymbols,
+	".sh":     extractShellSymbols,
+	".bash":   extractShellSymbols,
+}
+
+// SignatureFunc extracts callable signatures from a file's content, keyed by
+// symbol name. Each signature includes the name, e.g. "Get(key string) error".
+type SignatureFunc func(content string) map[string]string
+
+// signatureExtractors maps file extensions to signature extractors. Languages
+// without an entry produce symbols with an empty Signature.
+var signatureExtractors = map[string]SignatureFunc{
+	".go":     extractGoSignatures,
+	".ts":     extractJSSignatures,
+	".tsx":    extractJSSignatures,
+	".js":     extractJSSignatures,
+	".jsx":    extractJSSignatures,
+	".mjs":    extractJSSignatures,
+	".cjs":    extractJSSignatures,
+	".vue":    extractSFCSignatures,
+	".svelte": extractSFCSignatures,
+	".py":     extractPythonSignatures,
+	".rs":     extractRustSignatures,
+	".cs":     extractCSharpSignatures,
+	".kt":     extractKotlinSignatures,
+	".swift":  extractSwiftSignatures,
+	".php":    extractPHPSignatures,
+	".rb":     extractRubySignatures,
 }
 
 // testExtractors maps file extensions to test-function extractors.
 var testExtractors = map[string]ExtractorFunc{
-	".go":  extractGoTestFunctions,
-	".ts":  extractJSTestFunctions,
-	".tsx": extractJSTestFunctions,
-	".js":  extractJSTestFunctions,
-	".py":  extractPythonTestFunctions,
+	".go":    extractGoTestFunctions,
+	".ts":    extractJSTestFunctions,
+	".tsx":   extractJSTestFunctions,
+	".js":    extractJSTestFunctions,
+	".mjs":   extractJSTestFunctions,
+	".cjs":   extractJSTestFunctions,
+	".py":    extractPythonTestFunctions,
+	".cs":    extractCSharpTestFunctions,
+	".kt":    extractKotlinTestFunctions,
+	".swift": extractSwiftTestFunctions,
+	".php":   extractPHPTestFunctions,
+	".rb":    extractRubyTestFunctions,
 }
 
 // isTestFile returns true for files that follow test-file naming conventions.
+// name is the file's path relative to the code root; most conventions look
+// only at its base name.
 func isTestFile(name string) bool {
 	base := filepath.Base(name)
 	ext := filepath.Ext(base)
@@ -88,12 +215,37 @@ func isTestFile(name string) bool {
 		strings.HasSuffix(base, ".test.tsx"),
 		strings.HasSuffix(base, ".spec.tsx"),
 		strings.HasSuffix(base, ".test.js"),
-		strings.HasSuffix(base, ".spec.js"):
+		strings.HasSuffix(base, ".spec.js"),
+		strings.HasSuffix(base, ".test.mjs"),
+		strings.HasSuffix(base, ".spec.mjs"),
+		strings.HasSuffix(base, ".test.cjs"),
+		strings.HasSuffix(base, ".spec.cjs"):
 		return true
 	case strings.HasPrefix(base, "test_") && ext == ".py":
 		return true
 	case strings.HasSuffix(stem, "_test") && ext == ".py":
 		return true
+	case (strings.HasSuffix(stem, "Test") || strings.HasSuffix(stem, "Tests")) &&
+		(ext == ".cs" || ext == ".kt" || ext == ".swift"):
+		return true
+	case strings.HasSuffix(stem, "Test") && ext == ".php":
+		return true
+	case (strings.HasSuffix(stem, "_test") || strings.HasSuffix(stem, "_spec") || strings.HasPrefix(base, "test_")) && ext == ".rb":
+		return true
+	case (ext == ".php" || ext == ".rb") && inTestDir(name):
+		return true
+	}
+	return false
+}
+
+// inTestDir reports whether any directory in the path name is a test, tests,
+// or spec directory, where PHPUnit, Minitest, and RSpec suites live.
+func inTestDir(name string) bool {
+	dirs := strings.Split(filepath.ToSlash(filepath.Dir(name)), "/")
+	for _, d := range dirs {
+		if d == "test" || d == "tests" || d == "spec" {
+			return true
+		}
 	}
 	return false
 }
@@ -103,7 +255,8 @@ func isManifest(name string) bool {
 	base := filepath.Base(name)
 	switch base {
 	case "go.mod", "package.json", "requirements.txt",
-		"Cargo.toml", "pyproject.toml", "pom.xml":
+		"Cargo.toml", "pyproject.toml", "pom.xml",
+		"Gemfile", "build.gradle", "build.gradle.kts", "composer.json":
 		return true
 	}
 	return false
@@ -129,7 +282,8 @@ func isConfig(name string) bool {
 
 // defaultIgnore is the default set of directory names to skip.
 // Note: ignore matching is against directory base names only, not full paths.
-// To ignore a specific subdirectory by path, use ignorePatterns in Build().
+// To ignore a specific subdirectory by path, pass a pattern containing "/"
+// to Build().
 var defaultIgnore = map[string]bool{
 	".git":         true,
 	"vendor":       true,
@@ -140,6 +294,12 @@ var defaultIgnore = map[string]bool{
 	"build":        true,
 }
 
+// IsDefaultIgnoredDir reports whether Build skips directories with this base
+// name by default.
+func IsDefaultIgnoredDir(name string) bool {
+	return defaultIgnore[name]
+}
+
 // classifyLanguage returns a language label for a file extension.
 func classifyLanguage(ext string) string {
 	switch ext {
@@ -147,8 +307,12 @@ func classifyLanguage(ext string) string {
 		return "Go"
 	case ".ts", ".tsx":
 		return "TypeScript"
-	case ".js", ".jsx":
+	case ".js", ".jsx", ".mjs", ".cjs":
 		return "JavaScript"
+	case ".vue":
+		return "Vue"
+	case ".svelte":
+		return "Svelte"
 	case ".py":
 		return "Python"
 	case ".rs":
@@ -159,8 +323,16 @@ func classifyLanguage(ext string) string {
 		return "C"
 	case ".cpp", ".hpp", ".cc":
 		return "C++"
+	case ".cs":
+		return "C#"
+	case ".kt":
+		return "Kotlin"
+	case ".swift":
+		return "Swift"
 	case ".rb":
 		return "Ruby"
+	case ".php":
+		return "PHP"
 	case ".sh", ".bash":
 		return "Shell"
 	case ".md":
@@ -170,22 +342,180 @@ func classifyLanguage(ext string) string {
 	}
 }
 
+// BuildOptions configures BuildWithOptions.
+type BuildOptions struct {
+	// IgnorePatterns supplements the default ignore list. A pattern without
+	// "/" is a path.Match glob matched against directory base names, so "gen"
+	// skips every directory named gen. A pattern containing "/" is matched
+	// against the full slash-separated path relative to root, for both files
+	// and directories (see CompileGlob), e.g. "internal/**/gen" or
+	// "**/*.pb.go".
+	IgnorePatterns []string
+	// Include, if non-empty, restricts the index to files whose path
+	// relative to root matches one of these globs (see CompileGlob). A glob
+	// naming a directory also covers everything beneath it.
+	Include []string
+	// Exclude drops files and directories whose relative path matches one of
+	// these globs. Include and Exclude apply after the default ignore list,
+	// IgnorePatterns, and the root's IgnoreFileName; if they leave no source
+	// files, Build fails.
+	Exclude []string
+	// UseAST extracts Go symbols with go/parser instead of regular
+	// expressions. Files that fail to parse fall back to the regex extractor.
+	UseAST bool
+	// RespectGitignore applies the patterns from every .gitignore file found
+	// during the walk to both files and directories. A nested .gitignore only
+	// affects its own subtree, matching git semantics.
+	RespectGitignore bool
+	// SnippetLines, if positive, records up to this many lines of source
+	// for each symbol, starting at its declaration, in SymbolEntry.Snippet.
+	// This puts code content in the index, so it is off by default.
+	SnippetLines int
+	// ScanTodos records the TODO and FIXME comments in source files that
+	// name spec or plan IDs in Index.Todos.
+	ScanTodos bool
+	// Concurrency is the number of files read and scanned at once. Zero
+	// uses runtime.GOMAXPROCS(0). The index is the same for any value.
+	Concurrency int
+	// PathPrefix, if set, is prepended to every path in the index, so that
+	// paths are relative to a directory above root, such as the repository
+	// root when root is a subdirectory of it. It must be a relative path
+	// without leading "..". IgnorePatterns, Include, Exclude, and
+	// LanguageOverrides still match paths relative to root.
+	PathPrefix string
+	// LanguageOverrides label matching files with a language of their own,
+	// consulted in order before the extension default and the shebang sniff;
+	// the first match wins. A built-in language also gets that language's
+	// extractors, so "*.mjs=JavaScript" indexes .mjs symbols. Any other
+	// name, such as "MyDSL", is only a label: the files are listed under it
+	// but no symbols or tests are extracted.
+	LanguageOverrides []LanguageOverride
+}
+
+// DefaultSnippetLines is the snippet length used by --include-snippets.
+const DefaultSnippetLines = 8
+
 // Build walks the directory at root and builds an inventory.
-// ignorePatterns supplements the default ignore list; entries are matched
-// against directory base names (not full paths).
+// ignorePatterns supplements the default ignore list; see
+// BuildOptions.IgnorePatterns. The patterns in root's IgnoreFileName, if it
+// exists, are applied as well.
 func Build(root string, ignorePatterns []string) (Index, error) {
-	extraIgnore := make(map[string]bool, len(ignorePatterns))
-	for _, p := range ignorePatterns {
-		extraIgnore[p] = true
+	return BuildWithOptions(root, BuildOptions{IgnorePatterns: ignorePatterns})
+}
+
+// BuildWithOptions walks the directory at root and builds an inventory
+// according to opts.
+func BuildWithOptions(root string, opts BuildOptions) (Index, error) {
+	return build(root, opts, nil)
+}
+
+// BuildIncremental is BuildWithOptions, reusing the symbols and tests prev
+// extracted from files whose size and modification time are unchanged; only
+// new and changed files are read. The whole tree is still walked, so added,
+// removed, and newly ignored files are handled as in a full build, and
+// manifests are always re-read. If prev was built with different extraction
+// options (UseAST, SnippetLines, ScanTodos, LanguageOverrides), every file is
+// re-scanned.
+func BuildIncremental(root string, opts BuildOptions, prev Index) (Index, error) {
+	if prev.Extraction != extraction(opts) || len(prev.Stamps) == 0 {
+		return build(root, opts, nil)
+	}
+	return build(root, opts, newReuse(prev))
+}
+
+// extraction returns the Index.Extraction value for opts.
+func extraction(opts BuildOptions) string {
+	s := fmt.Sprintf("ast=%t snippets=%d todos=%t", opts.UseAST, opts.SnippetLines, opts.ScanTodos)
+	if len(opts.LanguageOverrides) > 0 {
+		parts := make([]string, len(opts.LanguageOverrides))
+		for i, o := range opts.LanguageOverrides {
+			parts[i] = o.Glob + "=" + o.Language
+		}
+		s += " langs=" + strings.Join(parts, ",")
+	}
+	return s
+}
+
+// reuse holds the per-file results of a previous build, grouped by path.
+type reuse struct {
+	stamps  map[string]FileStamp
+	symbols map[string][]SymbolEntry
+	tests   map[string][]TestEntry
+	todos   map[string][]TodoEntry
+}
+
+func newReuse(prev Index) *reuse {
+	r := &reuse{
+		stamps:  prev.Stamps,
+		symbols: make(map[string][]SymbolEntry),
+		tests:   make(map[string][]TestEntry),
+		todos:   make(map[string][]TodoEntry),
+	}
+	// Entries are stored relative to the code root, like the stamps; the
+	// new build applies its own PathPrefix.
+	for _, s := range prev.Symbols {
+		s.Path = prev.unprefixed(s.Path)
+		r.symbols[s.Path] = append(r.symbols[s.Path], s)
+	}
+	for _, t := range prev.Tests {
+		t.Path = prev.unprefixed(t.Path)
+		r.tests[t.Path] = append(r.tests[t.Path], t)
 	}
+	for _, t := range prev.Todos {
+		t.Path = prev.unprefixed(t.Path)
+		r.todos[t.Path] = append(r.todos[t.Path], t)
+	}
+	return r
+}
 
-	shouldIgnoreDir := func(name string) bool {
-		return defaultIgnore[name] || extraIgnore[name]
+// unchanged reports whether the file at rel had stamp when r was built.
+func (r *reuse) unchanged(rel string, stamp FileStamp) bool {
+	if r == nil {
+		return false
 	}
+	old, ok := r.stamps[rel]
+	return ok && old == stamp
+}
 
-	var idx Index
+// build is BuildWithOptions; a non-nil prev supplies the results for
+// unchanged files.
+func build(root string, opts BuildOptions, prev *reuse) (Index, error) {
+	ign, err := compileIgnorePatterns(opts.IgnorePatterns)
+	if err != nil {
+		return Index{}, err
+	}
+	fileIgn, err := loadIgnoreFile(root)
+	if err != nil {
+		return Index{}, err
+	}
+	ign.add(fileIgn)
+	filter, err := compilePathFilter(opts.Include, opts.Exclude)
+	if err != nil {
+		return Index{}, err
+	}
+	langs, err := compileLanguageMap(opts.LanguageOverrides)
+	if err != nil {
+		return Index{}, err
+	}
+	prefix, err := cleanPathPrefix(opts.PathPrefix)
+	if err != nil {
+		return Index{}, err
+	}
+
+	var gi *gitignore
+	if opts.RespectGitignore {
+		gi = newGitignore()
+	}
 
-	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+	idx := Index{Stamps: make(map[string]FileStamp), Extraction: extraction(opts)}
+	// The walk only lists the files to scan; the scanning happens afterwards
+	// on opts.Concurrency goroutines. results holds one entry per source
+	// file in walk order, so the index does not depend on which worker
+	// finishes first.
+	var results []fileResult
+	var jobs []extractJob
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -195,9 +525,27 @@ func Build(root string, ignorePatterns []string) (Index, error) {
 		}
 
 		if d.IsDir() {
-			if shouldIgnoreDir(d.Name()) && path != root {
+			if path == root {
+				if gi != nil {
+					gi.load(path, ".")
+				}
+				return nil
+			}
+			if defaultIgnore[d.Name()] || ign.dir(d.Name(), rel) || filter.excludedDir(rel) {
 				return fs.SkipDir
 			}
+			if gi != nil {
+				if gi.ignored(rel, true) {
+					return fs.SkipDir
+				}
+				gi.load(path, rel)
+			}
+			return nil
+		}
+		if ign.file(rel) || !filter.keep(rel) {
+			return nil
+		}
+		if gi != nil && gi.ignored(rel, false) {
 			return nil
 		}
 
@@ -221,51 +569,207 @@ func Build(root string, ignorePatterns []string) (Index, error) {
 			return nil
 		}
 
-		lang := classifyLanguage(ext)
+		info, infoErr := d.Info()
+		lang, overrideExt, overridden := langs.lookup(rel)
+		if overridden {
+			ext = overrideExt
+		} else {
+			if infoErr == nil && classifyLanguage(ext) == "Other" && isExecutable(info) {
+				if sniffed := shebangExt(path); sniffed != "" {
+					ext = sniffed
+				}
+			}
+			lang = classifyLanguage(ext)
+		}
 		idx.Files = append(idx.Files, FileEntry{Path: rel, Language: lang})
 
-		// Skip files that are too large to read for symbol extraction.
-		info, infoErr := d.Info()
-		if infoErr != nil || info.Size() > maxFileSize {
+		if infoErr != nil {
 			return nil
 		}
-
-		data, readErr := os.ReadFile(path)
-		if readErr != nil {
-			// Skip unreadable files silently.
+		stamp := FileStamp{Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+		idx.Stamps[rel] = stamp
+		if prev.unchanged(rel, stamp) {
+			results = append(results, fileResult{symbols: prev.symbols[rel], tests: prev.tests[rel], todos: prev.todos[rel]})
 			return nil
 		}
-		content := string(data)
+		results = append(results, fileResult{})
 
-		if isTestFile(d.Name()) {
-			if extractor, ok := testExtractors[ext]; ok {
-				for _, fn := range extractor(content) {
-					idx.Tests = append(idx.Tests, TestEntry{Path: rel, Function: fn})
-				}
-			}
-		} else {
-			if extractor, ok := symbolExtractors[ext]; ok {
-				for _, sym := range extractor(content) {
-					idx.Symbols = append(idx.Symbols, SymbolEntry{Path: rel, Symbol: sym})
-				}
-			}
+		// Skip files that are too large to read for symbol extraction.
+		if info.Size() > maxFileSize {
+			return nil
 		}
-
+		jobs = append(jobs, extractJob{
+			slot: len(results) - 1,
+			path: path,
+			rel:  rel,
+			ext:  ext,
+			test: isTestFile(rel),
+		})
 		return nil
 	})
 	if err != nil {
 		return Index{}, fmt.Errorf("codeindex: walk %s: %w", root, err)
 	}
+	if filter.active() && len(idx.Files) == 0 {
+		return Index{}, fmt.Errorf("codeindex: no source files under %s match the include/exclude filters", root)
+	}
+
+	extractAll(jobs, results, opts)
+	for _, r := range results {
+		idx.Symbols = append(idx.Symbols, r.symbols...)
+		idx.Tests = append(idx.Tests, r.tests...)
+		idx.Todos = append(idx.Todos, r.todos...)
+	}
+	idx.applyPrefix(prefix)
 
 	return idx, nil
 }
 
+// extractJob is a source file to read and scan; slot is its entry in the
+// build's results.
+type extractJob struct {
+	slot      int
+	path, rel string
+	ext       string
+	test      bool
+}
+
+// fileResult is what one source file contributes to the index.
+type fileResult struct {
+	symbols []SymbolEntry
+	tests   []TestEntry
+	todos   []TodoEntry
+}
+
+// extractAll runs every job, storing each file's result in its slot of
+// results. opts.Concurrency workers share the jobs; each writes only its
+// own slots, so no locking is needed.
+func extractAll(jobs []extractJob, results []fileResult, opts BuildOptions) {
+	n := opts.Concurrency
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	n = min(n, len(jobs))
+	ch := make(chan extractJob)
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range ch {
+				results[j.slot] = extractFile(j, opts)
+			}
+		}()
+	}
+	for _, j := range jobs {
+		ch <- j
+	}
+	close(ch)
+	wg.Wait()
+}
+
+// extractFile reads the file for j and extracts its test functions, or its
+// symbols for a non-test file, and with opts.ScanTodos the TODO comments of
+// a source file in a supported language. An unreadable file contributes
+// nothing.
+func extractFile(j extractJob, opts BuildOptions) fileResult {
+	var r fileResult
+	data, err := os.ReadFile(j.path)
+	if err != nil {
+		// Skip unreadable files silently.
+		return r
+	}
+	content := string(data)
+
+	if opts.ScanTodos && isCode(j.ext) {
+		r.todos = scanTodos(content, j.rel)
+	}
+
+	if j.test {
+		if extractor, ok := testExtractors[j.ext]; ok {
+			for _, fn := range extractor(content) {
+				r.tests = append(r.tests, TestEntry{Path: j.rel, Function: fn})
+			}
+		}
+		return r
+	}
+
+	r.symbols = extractSymbols(content, j.rel, j.ext, opts.UseAST)
+	if opts.SnippetLines > 0 {
+		attachSnippets(r.symbols, content, opts.SnippetLines)
+	}
+	return r
+}
+
+// isCode reports whether ext has a symbol or test extractor. TODO comments
+// are only scanned in such files, so that prose, such as a SPEC.md under the
+// code root, is never mistaken for code.
+func isCode(ext string) bool {
+	_, sym := symbolExtractors[ext]
+	_, test := testExtractors[ext]
+	return sym || test
+}
+
+// extractSymbols returns the symbols declared in content, the text of the
+// file at rel. With useAST, Go files are parsed with go/parser; files that
+// fail to parse fall back to the regex extractor.
+func extractSymbols(content, rel, ext string, useAST bool) []SymbolEntry {
+	var out []SymbolEntry
+	if useAST && ext == ".go" {
+		if syms, err := extractGoSymbolsAST(content); err == nil {
+			for _, sym := range syms {
+				sym.Path = rel
+				out = append(out, sym)
+			}
+			return out
+		}
+	}
+	extractor, ok := symbolExtractors[ext]
+	if !ok {
+		return nil
+	}
+	var sigs map[string]string
+	if sigExtractor, ok := signatureExtractors[ext]; ok {
+		sigs = sigExtractor(content)
+	}
+	var hidden map[string]bool
+	if ext == ".go" {
+		hidden = goHiddenMethods(content)
+	}
+	for _, sym := range extractor(content) {
+		out = append(out, SymbolEntry{
+			Path:      rel,
+			Symbol:    sym,
+			Exported:  ext == ".go" && token.IsExported(sym) && !hidden[sym],
+			Signature: sigs[sym],
+		})
+	}
+	return out
+}
+
 // writeNonSymbolSections appends all non-symbol sections (file tree, tests,
 // manifests, config) to sb. Called by both Summary and truncatedSummary.
 func writeNonSymbolSections(sb *strings.Builder, idx Index) {
 	sb.WriteString("=== File Tree ===\n")
+	var changed []string
 	for _, f := range idx.Files {
-		fmt.Fprintf(sb, "  %s (%s)\n", f.Path, f.Language)
+		if f.Changed {
+			changed = append(changed, f.Path)
+			fmt.Fprintf(sb, "  %s (%s) [changed]\n", f.Path, f.Language)
+		} else {
+			fmt.Fprintf(sb, "  %s (%s)\n", f.Path, f.Language)
+		}
+	}
+	if idx.ChangedSince != "" {
+		fmt.Fprintf(sb, "\n=== RECENTLY CHANGED (since %s) ===\n", idx.ChangedSince)
+		if len(changed) == 0 {
+			sb.WriteString("  (no indexed files changed)\n")
+		} else {
+			sb.WriteString("  Drift found in these files was introduced by the change under review; report it first.\n")
+		}
+		for _, p := range changed {
+			fmt.Fprintf(sb, "  %s\n", p)
+		}
 	}
 	if len(idx.Tests) > 0 {
 		sb.WriteString("\n=== Tests ===\n")
@@ -273,10 +777,17 @@ func writeNonSymbolSections(sb *strings.Builder, idx Index) {
 			fmt.Fprintf(sb, "  %s: %s\n", t.Path, t.Function)
 		}
 	}
+	if len(idx.Todos) > 0 {
+		sb.WriteString("\n=== TODOs Naming Spec/Plan Items ===\n")
+		sb.WriteString("  These comments acknowledge that the items they name are not implemented yet.\n")
+		for _, t := range idx.Todos {
+			fmt.Fprintf(sb, "  %s:%d: %s\n", t.Path, t.Line, t.Text)
+		}
+	}
 	if len(idx.DependencyManifests) > 0 {
 		sb.WriteString("\n=== Dependency Manifests ===\n")
 		for _, m := range idx.DependencyManifests {
-			fmt.Fprintf(sb, "--- %s ---\n%s\n", m.Path, m.Content)
+			fmt.Fprintf(sb, "--- %s ---\n%s\n", m.Path, manifestText(m.Content, idx.ManifestBytes))
 		}
 	}
 	if len(idx.ConfigFiles) > 0 {
@@ -287,68 +798,170 @@ func writeNonSymbolSections(sb *strings.Builder, idx Index) {
 	}
 }
 
-// Summary produces a human-readable text block for LLM consumption.
-// If the output exceeds maxSummaryBytes, the symbol list is truncated and a
-// notice is appended. A warning is emitted to stderr when truncation occurs.
+// manifestText returns content, or when it is longer than limit bytes (<= 0
+// means DefaultManifestBytes), its first lines within limit followed by a
+// truncation notice giving the full size.
+func manifestText(content string, limit int) string {
+	if limit <= 0 {
+		limit = DefaultManifestBytes
+	}
+	if len(content) <= limit {
+		return content
+	}
+	cut := content[:limit]
+	if i := strings.LastIndexByte(cut, '\n'); i > 0 {
+		cut = cut[:i]
+	} else {
+		// A single long line: cut on a rune boundary.
+		for len(cut) > 0 && !utf8.RuneStart(content[len(cut)]) {
+			cut = cut[:len(cut)-1]
+		}
+	}
+	return fmt.Sprintf("%s\n[TRUNCATED: manifest is %d bytes; only the first %d are shown]\n", cut, len(content), len(cut))
+}
+
+// Summary produces a human-readable text block for LLM consumption, limited
+// to DefaultSummaryBytes. See SummaryWithLimit.
 func (idx Index) Summary() string {
-	var sb strings.Builder
+	return idx.SummaryWithLimit(DefaultSummaryBytes)
+}
 
-	writeNonSymbolSections(&sb, idx)
+// SummaryWithLimit is Summary with a byte limit; limit <= 0 means
+// DefaultSummaryBytes. If the output would exceed limit, the symbol list is
+// truncated and a notice is appended. A warning is emitted to stderr when
+// truncation occurs.
+func (idx Index) SummaryWithLimit(limit int) string {
+	return idx.summary(limit, nil)
+}
 
-	sb.WriteString("\n=== Symbols ===\n")
-	for _, s := range idx.Symbols {
-		fmt.Fprintf(&sb, "  %s: %s\n", s.Path, s.Symbol)
-	}
+// SummaryForSpec is SummaryWithLimit, except that when the symbol list must
+// be truncated, symbols whose names or paths match words in the spec and plan
+// items are kept in preference to the rest. See relevanceScore.
+func (idx Index) SummaryForSpec(specItems, planItems []mdparse.Item, limit int) string {
+	return idx.summary(limit, specKeywords(specItems, planItems))
+}
 
-	result := sb.String()
-	if len(result) <= maxSummaryBytes {
+// summary implements SummaryWithLimit and SummaryForSpec. keywords may be nil.
+func (idx Index) summary(limit int, keywords map[string]bool) string {
+	if limit <= 0 {
+		limit = DefaultSummaryBytes
+	}
+	result := idx.fullSummary()
+	if len(result) <= limit {
 		return result
 	}
 
-	return truncatedSummary(idx, len(result))
+	return truncatedSummary(idx, len(result), limit, keywords)
+}
+
+// fullSummary is the summary with no byte limit.
+func (idx Index) fullSummary() string {
+	var sb strings.Builder
+	writeNonSymbolSections(&sb, idx)
+	sb.WriteString(symbolSectionHeader)
+	for _, s := range idx.Symbols {
+		sb.WriteString(s.summaryLine())
+	}
+	return sb.String()
+}
+
+// Stats describes an index and the size of its summary.
+type Stats struct {
+	Files           int
+	FilesByLanguage map[string]int // keyed by FileEntry.Language
+	Symbols         int
+	Tests           int
+	// SummaryBytes is the size of the untruncated summary, and Truncated
+	// reports whether it exceeds the limit passed to Stats.
+	SummaryBytes int
+	Truncated    bool
+}
+
+// Stats counts the files, per-language files, symbols, and tests in idx,
+// and measures its summary against limit (<= 0 means DefaultSummaryBytes).
+// Unlike SummaryWithLimit, it never prints a truncation warning.
+func (idx Index) Stats(limit int) Stats {
+	if limit <= 0 {
+		limit = DefaultSummaryBytes
+	}
+	st := Stats{
+		Files:           len(idx.Files),
+		FilesByLanguage: make(map[string]int),
+		Symbols:         len(idx.Symbols),
+		Tests:           len(idx.Tests),
+		SummaryBytes:    len(idx.fullSummary()),
+	}
+	for _, f := range idx.Files {
+		st.FilesByLanguage[f.Language]++
+	}
+	st.Truncated = st.SummaryBytes > limit
+	return st
 }
 
 // symbolSectionHeader is included in the budget so the final output stays
-// within maxSummaryBytes.
+// within the limit.
 const symbolSectionHeader = "\n=== Symbols ===\n"
 
+// truncationNotice ends a truncated symbol list.
+const truncationNotice = "[TRUNCATED: %d symbols omitted to fit context limit]\n"
+
 // truncatedSummary rebuilds Summary() with the symbol list pruned to fit within
-// maxSummaryBytes. It emits a warning to stderr.
+// limit. It emits a warning to stderr.
 // File Tree, Tests, Manifests, and Config sections are rendered only once and
 // reused in the final output.
-func truncatedSummary(idx Index, fullLen int) string {
+// Symbols are kept in walk order unless keywords is non-empty, in which case
+// the highest-scoring symbols are kept; either way, kept symbols are listed
+// in walk order.
+func truncatedSummary(idx Index, fullLen, limit int, keywords map[string]bool) string {
 	// Render non-symbol sections once; reuse the result.
 	var nonSym strings.Builder
 	writeNonSymbolSections(&nonSym, idx)
 	nonSymStr := nonSym.String()
 
-	// Reserve space for the section header, truncation notice, and a margin.
-	const truncationNotice = "[TRUNCATED: %d symbols omitted to fit context limit]\n"
-	reservedForOverhead := len(symbolSectionHeader) + 80 // 80 bytes covers the formatted notice
-	budget := maxSummaryBytes - len(nonSymStr) - reservedForOverhead
+	// Reserve space for the section header and the notice. The notice is
+	// sized for the largest possible count, since omitted <= len(Symbols).
+	reservedForOverhead := len(symbolSectionHeader) + len(fmt.Sprintf(truncationNotice, len(idx.Symbols)))
+	budget := limit - len(nonSymStr) - reservedForOverhead
 
-	// Determine how many symbols to keep within budget.
+	order := make([]int, len(idx.Symbols))
+	for i := range order {
+		order[i] = i
+	}
+	if len(keywords) > 0 {
+		scores := make([]int, len(idx.Symbols))
+		for i, s := range idx.Symbols {
+			scores[i] = relevanceScore(s, keywords)
+		}
+		sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+	}
+
+	// Determine which symbols to keep within budget.
+	keep := make([]bool, len(idx.Symbols))
 	kept := 0
 	used := 0
-	for _, s := range idx.Symbols {
-		line := fmt.Sprintf("  %s: %s\n", s.Path, s.Symbol)
+	for _, i := range order {
+		line := idx.Symbols[i].summaryLine()
 		if used+len(line) > budget {
 			break
 		}
 		used += len(line)
+		keep[i] = true
 		kept++
 	}
 
 	omitted := len(idx.Symbols) - kept
 	fmt.Fprintf(os.Stderr,
-		"codeindex: WARNING: summary truncated: %d symbols omitted (total %d chars > %d limit)\n",
-		omitted, fullLen, maxSummaryBytes)
+		"codeindex: WARNING: summary truncated: %d of %d symbols omitted (%d bytes > %d byte limit); "+
+			"raise the limit with --max-index-bytes to include them\n",
+		omitted, len(idx.Symbols), fullLen, limit)
 
 	var sb strings.Builder
 	sb.WriteString(nonSymStr)
 	sb.WriteString(symbolSectionHeader)
-	for _, s := range idx.Symbols[:kept] {
-		fmt.Fprintf(&sb, "  %s: %s\n", s.Path, s.Symbol)
+	for i, s := range idx.Symbols {
+		if keep[i] {
+			sb.WriteString(s.summaryLine())
+		}
 	}
 	fmt.Fprintf(&sb, truncationNotice, omitted)
 
@@ -362,8 +975,35 @@ var (
 	goMethodRe = regexp.MustCompile(`(?m)^func\s+\([^)]+\)\s+(\w+)\s*\(`)
 	goTypeRe   = regexp.MustCompile(`(?m)^type\s+(\w+)\s+(?:struct|interface)`)
 	goTestRe   = regexp.MustCompile(`(?m)^func\s+(Test\w+)\s*\(`)
+	// goRecvMethodRe captures the receiver type and the name of a method,
+	// as "List" and "Push" in "func (l *List[T]) Push(".
+	goRecvMethodRe = regexp.MustCompile(`(?m)^func\s+\(\s*(?:\w+\s+)?\*?\s*(\w+)[^)]*\)\s+(\w+)\s*[\[(]`)
 )
 
+// goHiddenMethods returns the names content declares only as methods of
+// unexported types. Such methods cannot be called from outside the package,
+// so they are not exported even when the name is capitalized.
+func goHiddenMethods(content string) map[string]bool {
+	hidden := make(map[string]bool)
+	reachable := make(map[string]bool)
+	for _, m := range goRecvMethodRe.FindAllStringSubmatch(content, -1) {
+		if token.IsExported(m[1]) {
+			reachable[m[2]] = true
+		} else {
+			hidden[m[2]] = true
+		}
+	}
+	for _, re := range []*regexp.Regexp{goFuncRe, goTypeRe} {
+		for _, m := range re.FindAllStringSubmatch(content, -1) {
+			reachable[m[1]] = true
+		}
+	}
+	for name := range reachable {
+		delete(hidden, name)
+	}
+	return hidden
+}
+
 func extractGoSymbols(content string) []string {
 	seen := make(map[string]bool)
 	var out []string
@@ -378,6 +1018,15 @@ func extractGoSymbols(content string) []string {
 	return out
 }
 
+// goSigRe captures a func or method name and the remainder of its declaration
+// line. Multi-line signatures yield only their first line; use the AST
+// extractor for full fidelity.
+var goSigRe = regexp.MustCompile(`(?m)^func\s+(?:\([^)]*\)\s+)?(\w+)(\s*[\[(].*?)\s*\{?\s*$`)
+
+func extractGoSignatures(content string) map[string]string {
+	return extractSignatures(content, goSigRe)
+}
+
 func extractGoTestFunctions(content string) []string {
 	var out []string
 	for _, m := range goTestRe.FindAllStringSubmatch(content, -1) {
@@ -409,6 +1058,12 @@ func extractJSSymbols(content string) []string {
 	return out
 }
 
+var jsSigRe = regexp.MustCompile(`(?m)\bfunction\s+(\w+)\s*(\([^)]*\))`)
+
+func extractJSSignatures(content string) map[string]string {
+	return extractSignatures(content, jsSigRe)
+}
+
 func extractJSTestFunctions(content string) []string {
 	var out []string
 	for _, m := range jsTestRe.FindAllStringSubmatch(content, -1) {
@@ -417,6 +1072,30 @@ func extractJSTestFunctions(content string) []string {
 	return out
 }
 
+// ── Vue / Svelte single-file components ───────────────────────────────────────
+
+// sfcScriptRe matches a <script> block, including <script setup> and
+// <script lang="ts">, and captures its body.
+var sfcScriptRe = regexp.MustCompile(`(?is)<script\b[^>]*>(.*?)</script\s*>`)
+
+// sfcScript returns the bodies of the <script> blocks in a .vue or .svelte
+// file, joined by newlines. Markup and styles are dropped.
+func sfcScript(content string) string {
+	var parts []string
+	for _, m := range sfcScriptRe.FindAllStringSubmatch(content, -1) {
+		parts = append(parts, m[1])
+	}
+	return strings.Join(parts, "\n")
+}
+
+func extractSFCSymbols(content string) []string {
+	return extractJSSymbols(sfcScript(content))
+}
+
+func extractSFCSignatures(content string) map[string]string {
+	return extractJSSignatures(sfcScript(content))
+}
+
 // ── Python ────────────────────────────────────────────────────────────────────
 
 var (
@@ -439,6 +1118,12 @@ func extractPythonSymbols(content string) []string {
 	return out
 }
 
+var pySigRe = regexp.MustCompile(`(?m)^def\s+(\w+)\s*(\([^)]*\)(?:\s*->\s*[^:]+)?)\s*:`)
+
+func extractPythonSignatures(content string) map[string]string {
+	return extractSignatures(content, pySigRe)
+}
+
 func extractPythonTestFunctions(content string) []string {
 	var out []string
 	for _, m := range pyTestRe.FindAllStringSubmatch(content, -1) {
@@ -470,3 +1155,247 @@ func extractRustSymbols(content string) []string {
 	}
 	return out
 }
+
+var rustSigRe = regexp.MustCompile(`(?m)\bfn\s+(\w+)\s*((?:<[^>]*>)?\([^)]*\)(?:\s*->\s*[^{;\n]+)?)`)
+
+func extractRustSignatures(content string) map[string]string {
+	return extractSignatures(content, rustSigRe)
+}
+
+// ── C# ────────────────────────────────────────────────────────────────────────
+
+var (
+	csTypeRe = regexp.MustCompile(`(?m)\b(?:class|interface|struct|record|enum)\s+(\w+)`)
+	// csMethodRe requires at least one modifier before the return type so
+	// that calls and control statements (if (...), foo(...)) are not matched.
+	// Constructors have no return type and are covered by their class.
+	csMethodRe = regexp.MustCompile(`(?m)^\s*(?:(?:public|private|protected|internal|static|virtual|override|abstract|async|sealed|partial|extern|unsafe|new)\s+)+[\w<>\[\],.?]+\s+(\w+)\s*(?:<[^>]*>)?\s*\(`)
+	// csTestRe matches xUnit ([Fact], [Theory]), NUnit ([Test], [TestCase])
+	// and MSTest ([TestMethod]) attributes followed by the method name.
+	csTestRe = regexp.MustCompile(`(?m)\[(?:Fact|Theory|Test|TestCase|TestMethod)\b[^\]]*\]\s*(?:\[[^\]]*\]\s*)*(?:(?:public|private|protected|internal|static|async|virtual|override)\s+)*[\w<>\[\],.?]+\s+(\w+)\s*\(`)
+)
+
+func extractCSharpSymbols(content string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, re := range []*regexp.Regexp{csTypeRe, csMethodRe} {
+		for _, m := range re.FindAllStringSubmatch(content, -1) {
+			if name := m[1]; !seen[name] {
+				seen[name] = true
+				out = append(out, name)
+			}
+		}
+	}
+	return out
+}
+
+var csSigRe = regexp.MustCompile(`(?m)^\s*(?:(?:public|private|protected|internal|static|virtual|override|abstract|async|sealed|partial|extern|unsafe|new)\s+)+[\w<>\[\],.?]+\s+(\w+)\s*((?:<[^>]*>)?\s*\([^)]*\))`)
+
+func extractCSharpSignatures(content string) map[string]string {
+	return extractSignatures(content, csSigRe)
+}
+
+func extractCSharpTestFunctions(content string) []string {
+	var out []string
+	for _, m := range csTestRe.FindAllStringSubmatch(content, -1) {
+		out = append(out, m[1])
+	}
+	return out
+}
+
+// ── Kotlin ────────────────────────────────────────────────────────────────────
+
+var (
+	// ktFunRe skips optional type parameters and an extension receiver
+	// (fun <T> List<T>.name) to capture the function name.
+	ktFunRe   = regexp.MustCompile(`(?m)\bfun\s+(?:<[^>]+>\s*)?(?:[\w.<>?]+\.)?(\w+)\s*\(`)
+	ktClassRe = regexp.MustCompile(`(?m)\b(?:class|interface|object)\s+(\w+)`)
+	// ktTestRe matches JUnit @Test functions, including backtick-quoted names.
+	ktTestRe = regexp.MustCompile("(?m)@Test\\b\\s+(?:@\\w+(?:\\([^)]*\\))?\\s+)*(?:(?:public|internal|private|open|override|suspend)\\s+)*fun\\s+(`[^`]+`|\\w+)\\s*\\(")
+)
+
+func extractKotlinSymbols(content string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, re := range []*regexp.Regexp{ktFunRe, ktClassRe} {
+		for _, m := range re.FindAllStringSubmatch(content, -1) {
+			if name := m[1]; !seen[name] {
+				seen[name] = true
+				out = append(out, name)
+			}
+		}
+	}
+	return out
+}
+
+var ktSigRe = regexp.MustCompile(`(?m)\bfun\s+(?:<[^>]+>\s*)?(?:[\w.<>?]+\.)?(\w+)\s*(\([^)]*\)(?:\s*:\s*[^={\n]+)?)`)
+
+func extractKotlinSignatures(content string) map[string]string {
+	return extractSignatures(content, ktSigRe)
+}
+
+func extractKotlinTestFunctions(content string) []string {
+	var out []string
+	for _, m := range ktTestRe.FindAllStringSubmatch(content, -1) {
+		out = append(out, strings.Trim(m[1], "`"))
+	}
+	return out
+}
+
+// ── Swift ─────────────────────────────────────────────────────────────────────
+
+var (
+	swiftFuncRe = regexp.MustCompile(`(?m)\bfunc\s+(\w+)\s*(?:<[^>]*>)?\s*\(`)
+	swiftTypeRe = regexp.MustCompile(`(?m)\b(?:class|struct|protocol|enum|actor)\s+(\w+)`)
+	// swiftTestRe matches XCTest methods, which must be named test*.
+	swiftTestRe = regexp.MustCompile(`(?m)\bfunc\s+(test\w*)\s*\(`)
+)
+
+// swiftTypeKeywords are words that can follow "class" in a declaration
+// (class func, class var) and so are not type names.
+var swiftTypeKeywords = map[string]bool{"func": true, "var": true, "let": true, "subscript": true}
+
+func extractSwiftSymbols(content string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, re := range []*regexp.Regexp{swiftFuncRe, swiftTypeRe} {
+		for _, m := range re.FindAllStringSubmatch(content, -1) {
+			if name := m[1]; !seen[name] && !swiftTypeKeywords[name] {
+				seen[name] = true
+				out = append(out, name)
+			}
+		}
+	}
+	return out
+}
+
+var swiftSigRe = regexp.MustCompile(`(?m)\bfunc\s+(\w+)\s*((?:<[^>]*>)?\([^)]*\)[^{\n]*)`)
+
+func extractSwiftSignatures(content string) map[string]string {
+	return extractSignatures(content, swiftSigRe)
+}
+
+func extractSwiftTestFunctions(content string) []string {
+	var out []string
+	for _, m := range swiftTestRe.FindAllStringSubmatch(content, -1) {
+		out = append(out, m[1])
+	}
+	return out
+}
+
+// ── PHP ───────────────────────────────────────────────────────────────────────
+
+var (
+	// phpFuncRe matches named functions and methods; closures have no name.
+	phpFuncRe = regexp.MustCompile(`(?m)\bfunction\s+&?(\w+)\s*\(`)
+	phpTypeRe = regexp.MustCompile(`(?m)^\s*(?:(?:abstract|final|readonly)\s+)*(?:class|interface|trait|enum)\s+(\w+)`)
+	// phpTestRe matches PHPUnit test methods: those named test*, and those
+	// marked with an @test annotation or a #[Test] attribute.
+	phpTestRe = regexp.MustCompile(`(?m)(?:\bfunction\s+(test\w*)\s*\(|(?:@test\b[^/]*\*/|#\[Test\])\s*(?:(?:public|protected|private|static|final)\s+)*function\s+(\w+)\s*\()`)
+)
+
+func extractPHPSymbols(content string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, re := range []*regexp.Regexp{phpTypeRe, phpFuncRe} {
+		for _, m := range re.FindAllStringSubmatch(content, -1) {
+			if name := m[1]; !seen[name] {
+				seen[name] = true
+				out = append(out, name)
+			}
+		}
+	}
+	return out
+}
+
+var phpSigRe = regexp.MustCompile(`(?m)\bfunction\s+&?(\w+)\s*(\([^)]*\)(?:\s*:\s*\??[\w\\|]+)?)`)
+
+func extractPHPSignatures(content string) map[string]string {
+	return extractSignatures(content, phpSigRe)
+}
+
+func extractPHPTestFunctions(content string) []string {
+	var out []string
+	for _, m := range phpTestRe.FindAllStringSubmatch(content, -1) {
+		out = append(out, m[1]+m[2])
+	}
+	return out
+}
+
+// ── Ruby ──────────────────────────────────────────────────────────────────────
+
+var (
+	// rubyDefRe matches instance and singleton (self.) methods, including
+	// predicate, bang, and setter names.
+	rubyDefRe = regexp.MustCompile(`(?m)^\s*def\s+(?:self\.)?([A-Za-z_]\w*[?!=]?)`)
+	// rubyTypeRe captures the last segment of a namespaced name such as
+	// Admin::User; "class << self" does not match.
+	rubyTypeRe = regexp.MustCompile(`(?m)^\s*(?:class|module)\s+(?:[A-Z]\w*::)*([A-Z]\w*)`)
+	// rubyTestRe matches Minitest methods (def test_*), and RSpec examples
+	// and Rails tests given as strings (it "...", test "...").
+	rubyTestRe = regexp.MustCompile(`(?m)^\s*(?:def\s+(test_\w+)|(?:it|specify|test)\s*\(?\s*["']([^"']+)["'])`)
+)
+
+func extractRubySymbols(content string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, re := range []*regexp.Regexp{rubyTypeRe, rubyDefRe} {
+		for _, m := range re.FindAllStringSubmatch(content, -1) {
+			if name := m[1]; !seen[name] {
+				seen[name] = true
+				out = append(out, name)
+			}
+		}
+	}
+	return out
+}
+
+var rubySigRe = regexp.MustCompile(`(?m)^\s*def\s+(?:self\.)?([A-Za-z_]\w*[?!=]?)(\([^)]*\))`)
+
+func extractRubySignatures(content string) map[string]string {
+	return extractSignatures(content, rubySigRe)
+}
+
+func extractRubyTestFunctions(content string) []string {
+	var out []string
+	for _, m := range rubyTestRe.FindAllStringSubmatch(content, -1) {
+		out = append(out, m[1]+m[2])
+	}
+	return out
+}
+
+// ── Shell ─────────────────────────────────────────────────────────────────────
+
+// shellFuncRe matches "name() {", "function name {", and
+// "function name() {" definitions.
+var shellFuncRe = regexp.MustCompile(`(?m)^\s*(?:function\s+([A-Za-z_][\w-]*)\s*(?:\(\s*\))?|([A-Za-z_][\w-]*)\s*\(\s*\))\s*\{`)
+
+func extractShellSymbols(content string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, m := range shellFuncRe.FindAllStringSubmatch(content, -1) {
+		if name := m[1] + m[2]; !seen[name] {
+			seen[name] = true
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// ── Signatures ────────────────────────────────────────────────────────────────
+
+// extractSignatures applies re, whose first group is the symbol name and
+// second group the parameter/result text, and returns name → signature.
+// Internal whitespace is collapsed so signatures render on one line. The
+// first declaration of a name wins.
+func extractSignatures(content string, re *regexp.Regexp) map[string]string {
+	out := make(map[string]string)
+	for _, m := range re.FindAllStringSubmatch(content, -1) {
+		name := m[1]
+		if _, ok := out[name]; ok {
+			continue
+		}
+		out[name] = name + strings.Join(strings.Fields(m[2]), " ")
+	}
+	return out
+}
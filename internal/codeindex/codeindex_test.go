@@ -1,6 +1,9 @@
 package codeindex
 
 import (
+	"fmt"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -84,6 +87,70 @@ func TestBuild_DependencyManifest(t *testing.T) {
 	}
 }
 
+func TestBuild_OtherManifests(t *testing.T) {
+	idx, err := Build(fixtureDir, nil)
+	if err != nil {
+		t.Fatalf("Build error: %v", err)
+	}
+	manifests := make(map[string]string)
+	for _, m := range idx.DependencyManifests {
+		manifests[filepath.Base(m.Path)] = m.Content
+	}
+	for name, want := range map[string]string{"Gemfile": `gem "sinatra"`, "build.gradle": "com.google.guava:guava"} {
+		if !strings.Contains(manifests[name], want) {
+			t.Errorf("%s content = %q, want it in DependencyManifests with %q", name, manifests[name], want)
+		}
+	}
+	for _, c := range idx.ConfigFiles {
+		t.Errorf("unexpected config file %q", c)
+	}
+	for _, f := range idx.Files {
+		if base := filepath.Base(f.Path); base == "Gemfile" || base == "build.gradle" {
+			t.Errorf("manifest %q also listed as a source file", f.Path)
+		}
+	}
+	for _, name := range []string{"build.gradle.kts", "composer.json"} {
+		if !isManifest(name) || isConfig(name) {
+			t.Errorf("%s: isManifest = %v, isConfig = %v; want a manifest only", name, isManifest(name), isConfig(name))
+		}
+	}
+}
+
+func TestSummary_ManifestCap(t *testing.T) {
+	big := strings.Repeat("left-pad==1.0.0\n", 100) // 1600 bytes
+	idx := Index{
+		DependencyManifests: []ManifestEntry{
+			{Path: "requirements.txt", Content: big},
+			{Path: "go.mod", Content: "module example.com/small\n"},
+		},
+		Symbols:       []SymbolEntry{{Path: "main.go", Symbol: "Run"}},
+		ManifestBytes: 100,
+	}
+	summary := idx.Summary()
+	if !strings.Contains(summary, "[TRUNCATED: manifest is 1600 bytes; only the first 95 are shown]") {
+		t.Errorf("large manifest should be cut at a line break with its size noted:\n%s", summary)
+	}
+	if !strings.Contains(summary, "module example.com/small\n") || strings.Count(summary, "[TRUNCATED") != 1 {
+		t.Errorf("the cap applies per manifest; the small one should be whole:\n%s", summary)
+	}
+	if !strings.Contains(summary, "main.go: Run") {
+		t.Errorf("symbol list missing:\n%s", summary)
+	}
+
+	// The default cap leaves small manifests alone.
+	idx.ManifestBytes = 0
+	if strings.Contains(idx.Summary(), "[TRUNCATED") {
+		t.Error("a 1600-byte manifest should fit the default cap")
+	}
+}
+
+func TestManifestText_LongLine(t *testing.T) {
+	got := manifestText(strings.Repeat("é", 10), 5) // 20 bytes; byte 5 is mid-rune
+	if want := "éé\n[TRUNCATED: manifest is 20 bytes; only the first 4 are shown]\n"; got != want {
+		t.Errorf("manifestText = %q, want %q", got, want)
+	}
+}
+
 func TestBuild_IgnorePatterns(t *testing.T) {
 	idx, err := Build(fixtureDir, []string{"scripts"})
 	if err != nil {
@@ -133,8 +200,469 @@ func TestSummary_Truncation(t *testing.T) {
 	if !strings.Contains(summary, "[TRUNCATED:") {
 		t.Error("large index should trigger truncation notice")
 	}
-	// Allow a small margin for the truncation notice and section header.
-	if len(summary) > maxSummaryBytes+100 {
-		t.Errorf("truncated summary is too long: %d bytes (limit %d)", len(summary), maxSummaryBytes)
+	if len(summary) > DefaultSummaryBytes {
+		t.Errorf("truncated summary is too long: %d bytes (limit %d)", len(summary), DefaultSummaryBytes)
+	}
+}
+
+func TestSummaryWithLimit(t *testing.T) {
+	var symbols []SymbolEntry
+	for i := 0; i < 200; i++ {
+		symbols = append(symbols, SymbolEntry{Path: "internal/big/big.go", Symbol: "VeryLongFunctionNameThatTakesUpSpace"})
+	}
+	idx := Index{
+		Files:   []FileEntry{{Path: "internal/big/big.go", Language: "Go"}},
+		Symbols: symbols,
+	}
+	full := idx.Summary()
+	if strings.Contains(full, "[TRUNCATED:") {
+		t.Fatal("default limit should not truncate a small index")
+	}
+	for _, limit := range []int{len(full) / 2, len(full) / 4} {
+		got := idx.SummaryWithLimit(limit)
+		if len(got) > limit {
+			t.Errorf("limit %d: summary is %d bytes", limit, len(got))
+		}
+		kept := strings.Count(got, "VeryLongFunctionNameThatTakesUpSpace")
+		want := fmt.Sprintf("[TRUNCATED: %d symbols omitted", len(symbols)-kept)
+		if !strings.Contains(got, want) {
+			t.Errorf("limit %d: notice should report %d omitted symbols", limit, len(symbols)-kept)
+		}
+	}
+	if got := idx.SummaryWithLimit(0); got != full {
+		t.Error("limit 0 should use DefaultSummaryBytes")
+	}
+}
+
+func TestStats(t *testing.T) {
+	idx := Index{
+		Files: []FileEntry{
+			{Path: "a.go", Language: "Go"},
+			{Path: "a_test.go", Language: "Go"},
+			{Path: "b.py", Language: "Python"},
+		},
+		Symbols: []SymbolEntry{{Path: "a.go", Symbol: "A"}, {Path: "b.py", Symbol: "B"}},
+		Tests:   []TestEntry{{Path: "a_test.go", Function: "TestA"}},
+	}
+	st := idx.Stats(0)
+	if st.Files != 3 || st.Symbols != 2 || st.Tests != 1 {
+		t.Errorf("files/symbols/tests = %d/%d/%d, want 3/2/1", st.Files, st.Symbols, st.Tests)
+	}
+	if st.FilesByLanguage["Go"] != 2 || st.FilesByLanguage["Python"] != 1 {
+		t.Errorf("files by language = %v", st.FilesByLanguage)
+	}
+	if st.SummaryBytes != len(idx.Summary()) || st.Truncated {
+		t.Errorf("summary bytes = %d, truncated = %v; want %d, false", st.SummaryBytes, st.Truncated, len(idx.Summary()))
+	}
+	if !idx.Stats(st.SummaryBytes - 1).Truncated {
+		t.Error("a limit below the summary size should report truncation")
+	}
+}
+
+func TestBuild_GoSignatures(t *testing.T) {
+	idx, err := Build(fixtureDir, nil)
+	if err != nil {
+		t.Fatalf("Build error: %v", err)
+	}
+	sigs := make(map[string]string)
+	for _, s := range idx.Symbols {
+		sigs[s.Symbol] = s.Signature
+	}
+	if sigs["Store"] != "" {
+		t.Errorf("type Store should have no signature, got %q", sigs["Store"])
+	}
+	if !strings.HasPrefix(sigs["Get"], "Get(") {
+		t.Errorf("Get signature = %q, want it to start with %q", sigs["Get"], "Get(")
+	}
+	if !strings.Contains(idx.Summary(), sigs["Get"]) {
+		t.Errorf("Summary() should render signature %q", sigs["Get"])
+	}
+}
+
+func TestExtractSignatures(t *testing.T) {
+	cases := []struct {
+		name    string
+		fn      SignatureFunc
+		content string
+		symbol  string
+		want    string
+	}{
+		{"go", extractGoSignatures, "func (s *Store) Get(key string) (string, bool) {\n", "Get", "Get(key string) (string, bool)"},
+		{"go generic", extractGoSignatures, "func Map[T any](xs []T) []T {\n", "Map", "Map[T any](xs []T) []T"},
+		{"python", extractPythonSignatures, "def process(data: list) -> dict:\n", "process", "process(data: list) -> dict"},
+		{"js", extractJSSignatures, "export function handle(req, res) {\n", "handle", "handle(req, res)"},
+		{"rust", extractRustSignatures, "pub fn get(&self, key: &str) -> Option<String> {\n", "get", "get(&self, key: &str) -> Option<String>"},
+	}
+	for _, c := range cases {
+		if got := c.fn(c.content)[c.symbol]; got != c.want {
+			t.Errorf("%s: signature = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestSummary_TruncationCountsSignatures(t *testing.T) {
+	var symbols []SymbolEntry
+	for i := 0; i < 1000; i++ {
+		symbols = append(symbols, SymbolEntry{
+			Path:      "internal/big/big.go",
+			Symbol:    "F",
+			Signature: "F(" + strings.Repeat("arg string, ", 5) + ") error",
+		})
+	}
+	large := Index{
+		Files:   []FileEntry{{Path: "internal/big/big.go", Language: "Go"}},
+		Symbols: symbols,
+	}
+	summary := large.Summary()
+	if !strings.Contains(summary, "[TRUNCATED:") {
+		t.Error("signatures should count toward the truncation budget")
+	}
+	if len(summary) > DefaultSummaryBytes {
+		t.Errorf("truncated summary is too long: %d bytes (limit %d)", len(summary), DefaultSummaryBytes)
+	}
+}
+
+func TestExtract_CSharp(t *testing.T) {
+	src := `namespace Shop.Orders
+{
+    public interface IOrderStore { }
+
+    public sealed class OrderService
+    {
+        public OrderService(IOrderStore store) { }
+
+        public async Task<Order> PlaceAsync(Cart cart, CancellationToken ct)
+        {
+            if (cart.IsEmpty) { throw new InvalidOperationException(); }
+            return await Save(cart);
+        }
+
+        private static int Total(IEnumerable<Line> lines) => 0;
+    }
+}
+`
+	assertSymbols(t, extractCSharpSymbols(src), []string{"IOrderStore", "OrderService", "PlaceAsync", "Total"}, []string{"if", "Save", "InvalidOperationException"})
+	if got, want := extractCSharpSignatures(src)["PlaceAsync"], "PlaceAsync(Cart cart, CancellationToken ct)"; got != want {
+		t.Errorf("PlaceAsync signature = %q, want %q", got, want)
+	}
+
+	tests := `public class OrderServiceTests
+{
+    [Fact]
+    public async Task PlaceAsync_RejectsEmptyCart() { }
+
+    [Theory]
+    [InlineData(1)]
+    public void Total_SumsLines(int n) { }
+
+    [TestMethod]
+    public void LegacyCheck() { }
+
+    public void Helper() { }
+}
+`
+	assertSymbols(t, extractCSharpTestFunctions(tests), []string{"PlaceAsync_RejectsEmptyCart", "Total_SumsLines", "LegacyCheck"}, []string{"Helper"})
+}
+
+func TestExtract_Kotlin(t *testing.T) {
+	src := `package shop.orders
+
+data class Order(val id: String)
+
+interface OrderStore
+
+object Defaults
+
+class OrderService(private val store: OrderStore) {
+    companion object {
+        fun create(): OrderService = OrderService(InMemoryStore())
+    }
+
+    suspend fun place(cart: Cart): Order {
+        return Order(cart.id)
+    }
+}
+
+fun <T> List<T>.second(): T = this[1]
+`
+	assertSymbols(t, extractKotlinSymbols(src), []string{"Order", "OrderStore", "Defaults", "OrderService", "create", "place", "second"}, nil)
+	if got, want := extractKotlinSignatures(src)["place"], "place(cart: Cart): Order"; got != want {
+		t.Errorf("place signature = %q, want %q", got, want)
+	}
+
+	tests := "class OrderServiceTest {\n" +
+		"    @Test\n    fun placesOrder() { }\n\n" +
+		"    @Test\n    @DisplayName(\"x\")\n    fun `rejects empty cart`() { }\n\n" +
+		"    fun helper() { }\n}\n"
+	assertSymbols(t, extractKotlinTestFunctions(tests), []string{"placesOrder", "rejects empty cart"}, []string{"helper"})
+}
+
+func TestExtract_Swift(t *testing.T) {
+	src := `protocol OrderStore {
+    func save(_ order: Order) throws
+}
+
+struct Order { let id: String }
+
+final class OrderService {
+    class func shared() -> OrderService { OrderService() }
+
+    func place<T: Cart>(_ cart: T) async throws -> Order {
+        return Order(id: cart.id)
+    }
+}
+`
+	assertSymbols(t, extractSwiftSymbols(src), []string{"OrderStore", "save", "Order", "OrderService", "shared", "place"}, []string{"func"})
+	if got, want := extractSwiftSignatures(src)["place"], "place<T: Cart>(_ cart: T) async throws -> Order"; got != want {
+		t.Errorf("place signature = %q, want %q", got, want)
+	}
+
+	tests := `final class OrderServiceTests: XCTestCase {
+    func testPlaceOrder() throws { }
+    func testEmptyCartFails() { }
+    func makeService() -> OrderService { OrderService() }
+}
+`
+	assertSymbols(t, extractSwiftTestFunctions(tests), []string{"testPlaceOrder", "testEmptyCartFails"}, []string{"makeService"})
+}
+
+func TestExtract_PHP(t *testing.T) {
+	src := `<?php
+namespace App\\Orders;
+
+interface OrderStore {}
+
+trait Audits {}
+
+final class OrderService
+{
+    public function __construct(private OrderStore $store) {}
+
+    public static function place(Cart $cart, ?int $qty = null): ?Order
+    {
+        $total = array_map(function ($l) { return $l->price; }, $cart->lines);
+        return new Order(OrderService::class);
+    }
+}
+
+function &registry(): array { static $r = []; return $r; }
+`
+	assertSymbols(t, extractPHPSymbols(src), []string{"OrderStore", "Audits", "OrderService", "__construct", "place", "registry"}, []string{"array_map", "Order"})
+	if got, want := extractPHPSignatures(src)["place"], "place(Cart $cart, ?int $qty = null): ?Order"; got != want {
+		t.Errorf("place signature = %q, want %q", got, want)
+	}
+
+	tests := `<?php
+class OrderServiceTest extends TestCase
+{
+    public function testPlacesOrder(): void {}
+
+    /** @test */
+    public function rejects_empty_cart(): void {}
+
+    #[Test]
+    public function totalsLines(): void {}
+
+    private function makeService(): OrderService {}
+}
+`
+	assertSymbols(t, extractPHPTestFunctions(tests), []string{"testPlacesOrder", "rejects_empty_cart", "totalsLines"}, []string{"makeService"})
+}
+
+func TestExtract_Ruby(t *testing.T) {
+	src := `module Shop
+  class Admin::OrderService < Base
+    class << self
+      def build(store)
+        new(store)
+      end
+    end
+
+    def place(cart, qty: 1)
+      Order.new(cart.id)
+    end
+
+    def valid?
+      true
+    end
+  end
+end
+`
+	assertSymbols(t, extractRubySymbols(src), []string{"Shop", "OrderService", "build", "place", "valid?"}, []string{"Admin", "self", "Order"})
+	if got, want := extractRubySignatures(src)["place"], "place(cart, qty: 1)"; got != want {
+		t.Errorf("place signature = %q, want %q", got, want)
+	}
+
+	tests := `class OrderServiceTest < Minitest::Test
+  def test_places_order
+  end
+
+  test "rejects an empty cart" do
+  end
+
+  def helper
+  end
+end
+
+RSpec.describe OrderService do
+  it "totals the lines" do
+  end
+end
+`
+	assertSymbols(t, extractRubyTestFunctions(tests), []string{"test_places_order", "rejects an empty cart", "totals the lines"}, []string{"helper"})
+}
+
+func TestIsTestFile_PHPAndRuby(t *testing.T) {
+	cases := map[string]bool{
+		"OrderServiceTest.php":      true,
+		"tests/Unit/bootstrap.php":  true,
+		"src/OrderService.php":      false,
+		"src/Latest.php":            false, // suffix match is case-sensitive
+		"order_service_test.rb":     true,
+		"spec/models/order_spec.rb": true,
+		"test/test_order.rb":        true,
+		"spec/support/helpers.rb":   true,
+		"app/models/order.rb":       false,
+		"lib/contest.rb":            false,
+		"tests/helpers.py":          false, // directory rule is PHP and Ruby only
+	}
+	for name, want := range cases {
+		if got := isTestFile(name); got != want {
+			t.Errorf("isTestFile(%q) = %v, want %v", name, got, want)
+		}
+	}
+	if got := classifyLanguage(".php"); got != "PHP" {
+		t.Errorf("classifyLanguage(.php) = %q, want PHP", got)
+	}
+}
+
+func TestIsTestFile_JVMAndApple(t *testing.T) {
+	cases := map[string]bool{
+		"OrderServiceTests.cs":    true,
+		"OrderServiceTest.kt":     true,
+		"OrderServiceTests.swift": true,
+		"OrderService.cs":         false,
+		"Contest.kt":              false, // suffix match is case-sensitive
+		"Latest.swift":            false,
+		"Testing.swift":           false,
+	}
+	for name, want := range cases {
+		if got := isTestFile(name); got != want {
+			t.Errorf("isTestFile(%q) = %v, want %v", name, got, want)
+		}
+	}
+	for ext, want := range map[string]string{".cs": "C#", ".kt": "Kotlin", ".swift": "Swift"} {
+		if got := classifyLanguage(ext); got != want {
+			t.Errorf("classifyLanguage(%q) = %q, want %q", ext, got, want)
+		}
+	}
+}
+
+func TestExtract_SingleFileComponents(t *testing.T) {
+	src := `<template>
+  <button @click="submit">function notCode() {}</button>
+</template>
+
+<script setup lang="ts">
+import { ref } from 'vue'
+function submit(order) {
+  return save(order)
+}
+</script>
+
+<script>
+export default class CartView {}
+</script>
+
+<style>
+.cart { color: red; }
+</style>
+`
+	assertSymbols(t, extractSFCSymbols(src), []string{"submit", "CartView"}, []string{"notCode"})
+	if got, want := extractSFCSignatures(src)["submit"], "submit(order)"; got != want {
+		t.Errorf("submit signature = %q, want %q", got, want)
+	}
+	if got := extractSFCSymbols("<template><p>function x() {}</p></template>"); len(got) != 0 {
+		t.Errorf("component without a script block: got %v", got)
+	}
+}
+
+func TestIsTestFile_ESModules(t *testing.T) {
+	cases := map[string]bool{
+		"cart.test.mjs": true,
+		"cart.spec.cjs": true,
+		"cart.mjs":      false,
+		"Cart.vue":      false,
+	}
+	for name, want := range cases {
+		if got := isTestFile(name); got != want {
+			t.Errorf("isTestFile(%q) = %v, want %v", name, got, want)
+		}
+	}
+	for ext, want := range map[string]string{".mjs": "JavaScript", ".cjs": "JavaScript", ".vue": "Vue", ".svelte": "Svelte"} {
+		if got := classifyLanguage(ext); got != want {
+			t.Errorf("classifyLanguage(%q) = %q, want %q", ext, got, want)
+		}
+	}
+}
+
+// assertSymbols checks that every name in want is present in got and that
+// no name in absent is.
+func assertSymbols(t *testing.T, got, want, absent []string) {
+	t.Helper()
+	set := make(map[string]bool, len(got))
+	for _, s := range got {
+		set[s] = true
+	}
+	for _, s := range want {
+		if !set[s] {
+			t.Errorf("expected %q in %v", s, got)
+		}
+	}
+	for _, s := range absent {
+		if set[s] {
+			t.Errorf("did not expect %q in %v", s, got)
+		}
+	}
+}
+
+func TestMarkChanged(t *testing.T) {
+	idx := Index{Files: []FileEntry{
+		{Path: "internal/a.go", Language: "Go"},
+		{Path: "internal/b.go", Language: "Go"},
+	}}
+	if n := idx.MarkChanged("main", []string{"internal/b.go", "deleted.go"}); n != 1 {
+		t.Errorf("MarkChanged = %d, want 1", n)
+	}
+	if idx.Files[0].Changed || !idx.Files[1].Changed {
+		t.Errorf("Changed flags = %v, %v; want false, true", idx.Files[0].Changed, idx.Files[1].Changed)
+	}
+	summary := idx.Summary()
+	if !strings.Contains(summary, "internal/b.go (Go) [changed]") {
+		t.Error("file tree should tag changed files")
+	}
+	if !strings.Contains(summary, "=== RECENTLY CHANGED (since main) ===") {
+		t.Error("summary should have a RECENTLY CHANGED section")
+	}
+	if strings.Contains(Index{Files: idx.Files[:1]}.Summary(), "RECENTLY CHANGED") {
+		t.Error("an index without ChangedSince should have no RECENTLY CHANGED section")
+	}
+}
+
+func TestBuildWithOptions_ConcurrencyMatchesSerial(t *testing.T) {
+	for _, opts := range []BuildOptions{{}, {UseAST: true, SnippetLines: DefaultSnippetLines}} {
+		opts.Concurrency = 1
+		serial, err := BuildWithOptions(fixtureDir, opts)
+		if err != nil {
+			t.Fatalf("serial build: %v", err)
+		}
+		opts.Concurrency = 8
+		parallel, err := BuildWithOptions(fixtureDir, opts)
+		if err != nil {
+			t.Fatalf("parallel build: %v", err)
+		}
+		if !reflect.DeepEqual(serial, parallel) {
+			t.Errorf("opts %+v: parallel index differs from serial", opts)
+		}
 	}
 }
@@ -0,0 +1,170 @@
+package codeindex
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// gitignoreRule is a single compiled pattern from a .gitignore file.
+type gitignoreRule struct {
+	re      *regexp.Regexp
+	negate  bool // pattern began with "!": re-include a previously ignored path
+	dirOnly bool // pattern ended with "/": matches directories only
+}
+
+// gitignore holds the rules from every .gitignore file encountered during a
+// walk, keyed by the slash-separated directory (relative to the code root)
+// that contains the file. "." is the root.
+type gitignore struct {
+	rules map[string][]gitignoreRule
+}
+
+func newGitignore() *gitignore {
+	return &gitignore{rules: make(map[string][]gitignoreRule)}
+}
+
+// load reads the .gitignore in dir (absolute path), if any, and records its
+// rules under relDir. A missing or unreadable file is silently ignored.
+func (g *gitignore) load(dir, relDir string) {
+	f, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	var rules []gitignoreRule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if r, ok := parseGitignoreLine(scanner.Text()); ok {
+			rules = append(rules, r)
+		}
+	}
+	if len(rules) > 0 {
+		g.rules[filepath.ToSlash(relDir)] = rules
+	}
+}
+
+// ignored reports whether rel (slash-separated, relative to the code root)
+// is excluded. Rules from each ancestor directory's .gitignore are applied
+// from the root downward, so a nested .gitignore only affects its own subtree
+// and can override its parents; within and across files the last match wins.
+func (g *gitignore) ignored(rel string, isDir bool) bool {
+	if len(g.rules) == 0 {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+
+	// Ancestor directories of rel, root first.
+	dirs := []string{"."}
+	if parent := path.Dir(rel); parent != "." {
+		parts := strings.Split(parent, "/")
+		for i := range parts {
+			dirs = append(dirs, strings.Join(parts[:i+1], "/"))
+		}
+	}
+
+	ignored := false
+	for _, dir := range dirs {
+		sub := rel
+		if dir != "." {
+			sub = strings.TrimPrefix(rel, dir+"/")
+		}
+		for _, r := range g.rules[dir] {
+			if r.dirOnly && !isDir {
+				continue
+			}
+			if r.re.MatchString(sub) {
+				ignored = !r.negate
+			}
+		}
+	}
+	return ignored
+}
+
+// parseGitignoreLine compiles one .gitignore line. ok is false for blank
+// lines and comments.
+func parseGitignoreLine(line string) (r gitignoreRule, ok bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return gitignoreRule{}, false
+	}
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		line = line[1:] // escaped leading "#" or "!"
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return gitignoreRule{}, false
+	}
+
+	// A pattern containing a slash (other than a trailing one) is anchored to
+	// the .gitignore's directory; otherwise it matches a name at any depth.
+	var prefix string
+	if strings.Contains(line, "/") {
+		line = strings.TrimPrefix(line, "/")
+		prefix = "^"
+	} else {
+		prefix = "^(?:.*/)?"
+	}
+	re, err := regexp.Compile(prefix + globToRegexp(line) + "$")
+	if err != nil {
+		return gitignoreRule{}, false
+	}
+	r.re = re
+	return r, true
+}
+
+// CompileGlob compiles a glob for matching slash-separated paths relative to
+// the code root. The whole path must match; "*" and "?" do not cross "/",
+// "**" matches any number of directories, and a leading "/" is ignored.
+func CompileGlob(glob string) (*regexp.Regexp, error) {
+	return regexp.Compile("^" + globToRegexp(strings.TrimPrefix(glob, "/")) + "$")
+}
+
+// globToRegexp converts a gitignore glob to a regular expression fragment.
+// "*" and "?" do not cross "/"; "**" does.
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob):
+			sb.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
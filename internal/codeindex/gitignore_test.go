@@ -0,0 +1,110 @@
+package codeindex
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTree creates files under root from a map of slash-separated relative
+// paths to contents.
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", rel, err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", rel, err)
+		}
+	}
+}
+
+func TestBuildWithOptions_RespectGitignore(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".gitignore":          "gen/\n*.out\n!keep.out\n",
+		"main.go":             "package main\nfunc Main() {}\n",
+		"gen/generated.go":    "package gen\nfunc Generated() {}\n",
+		"coverage.out":        "mode: set\n",
+		"keep.out":            "kept\n",
+		"sub/.gitignore":      "local.go\n",
+		"sub/local.go":        "package sub\nfunc Local() {}\n",
+		"sub/other.go":        "package sub\nfunc Other() {}\n",
+		"local.go":            "package main\nfunc RootLocal() {}\n",
+		"sub/deep/nested.out": "x\n",
+	})
+
+	idx, err := BuildWithOptions(root, BuildOptions{RespectGitignore: true})
+	if err != nil {
+		t.Fatalf("BuildWithOptions error: %v", err)
+	}
+	got := make(map[string]bool)
+	for _, f := range idx.Files {
+		got[filepath.ToSlash(f.Path)] = true
+	}
+
+	for _, want := range []string{"main.go", "keep.out", "sub/other.go", "local.go"} {
+		if !got[want] {
+			t.Errorf("expected %q in index", want)
+		}
+	}
+	// local.go is ignored only inside sub/, where its .gitignore applies.
+	for _, unwanted := range []string{"gen/generated.go", "coverage.out", "sub/local.go", "sub/deep/nested.out"} {
+		if got[unwanted] {
+			t.Errorf("expected %q to be excluded by .gitignore", unwanted)
+		}
+	}
+}
+
+func TestBuildWithOptions_GitignoreDisabledByDefault(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".gitignore":       "gen/\n",
+		"gen/generated.go": "package gen\n",
+	})
+	idx, err := Build(root, nil)
+	if err != nil {
+		t.Fatalf("Build error: %v", err)
+	}
+	found := false
+	for _, f := range idx.Files {
+		if filepath.ToSlash(f.Path) == "gen/generated.go" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Build without RespectGitignore should index gen/generated.go")
+	}
+}
+
+func TestParseGitignoreLine(t *testing.T) {
+	cases := []struct {
+		pattern string
+		path    string
+		isDir   bool
+		want    bool
+	}{
+		{"*.log", "a/b/debug.log", false, true},
+		{"/build", "build", true, true},
+		{"/build", "x/build", true, false},
+		{"docs/*.md", "docs/a.md", false, true},
+		{"docs/*.md", "docs/sub/a.md", false, false},
+		{"**/fixtures", "a/b/fixtures", true, true},
+		{"logs/**", "logs/a/b.txt", false, true},
+		{"tmp/", "tmp", false, false},
+		{"file[0-9].txt", "file7.txt", false, true},
+	}
+	for _, c := range cases {
+		g := newGitignore()
+		r, ok := parseGitignoreLine(c.pattern)
+		if !ok {
+			t.Fatalf("parseGitignoreLine(%q) not ok", c.pattern)
+		}
+		g.rules["."] = []gitignoreRule{r}
+		if got := g.ignored(c.path, c.isDir); got != c.want {
+			t.Errorf("pattern %q on %q (dir=%v) = %v, want %v", c.pattern, c.path, c.isDir, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package codeindex
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"strings"
+)
+
+// extractGoSymbolsAST parses Go source with go/parser and returns the
+// top-level functions, methods (including methods on generic types), type
+// declarations, and interface methods it declares. Unlike the regex
+// extractor it is not fooled by "func" inside comments or strings and
+// handles multi-line signatures. A method, including an interface method,
+// counts as exported only when its type is exported too. An error is
+// returned when the file does not parse; callers should fall back to
+// extractGoSymbols.
+func extractGoSymbolsAST(content string) ([]SymbolEntry, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", content, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]int) // name -> index in out
+	var out []SymbolEntry
+	add := func(ident *ast.Ident, fn *ast.FuncType, owner string) {
+		name := ident.Name
+		if name == "_" {
+			return
+		}
+		exported := token.IsExported(name) && (owner == "" || token.IsExported(owner))
+		if i, ok := seen[name]; ok {
+			// A name declared twice, say as methods of two types, is
+			// exported if either declaration is.
+			out[i].Exported = out[i].Exported || exported
+			return
+		}
+		seen[name] = len(out)
+		out = append(out, SymbolEntry{
+			Symbol:    name,
+			Exported:  exported,
+			Signature: goFuncSignature(name, fn),
+			Line:      fset.Position(ident.Pos()).Line,
+		})
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			add(d.Name, d.Type, receiverType(d.Recv))
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				ts, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				add(ts.Name, nil, "")
+				if it, ok := ts.Type.(*ast.InterfaceType); ok {
+					for _, m := range it.Methods.List {
+						// Embedded interfaces and type constraints have no names.
+						fn, isFunc := m.Type.(*ast.FuncType)
+						if !isFunc {
+							continue
+						}
+						for _, n := range m.Names {
+							add(n, fn, ts.Name.Name)
+						}
+					}
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+// receiverType returns the name of the type a method is declared on, as
+// "List" for "(l *List[T])", or "" for a function without a receiver.
+func receiverType(recv *ast.FieldList) string {
+	if recv == nil || len(recv.List) == 0 {
+		return ""
+	}
+	t := recv.List[0].Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+	switch x := t.(type) {
+	case *ast.IndexExpr:
+		t = x.X
+	case *ast.IndexListExpr:
+		t = x.X
+	}
+	if id, ok := t.(*ast.Ident); ok {
+		return id.Name
+	}
+	return ""
+}
+
+// goFuncSignature renders name followed by fn's type parameters, parameters,
+// and results on a single line, e.g. "Get(key string) (string, bool)".
+// It returns "" when fn is nil or cannot be printed. An empty FileSet is used
+// so the printer ignores source positions and does not reproduce the original
+// line breaks of multi-line signatures.
+func goFuncSignature(name string, fn *ast.FuncType) string {
+	if fn == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, token.NewFileSet(), fn); err != nil {
+		return ""
+	}
+	sig := strings.TrimPrefix(buf.String(), "func")
+	return name + strings.Join(strings.Fields(sig), " ")
+}
@@ -0,0 +1,129 @@
+package codeindex
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+const goASTSource = `package store
+
+// func Commented() is not a real function.
+var doc = "func InString() {}"
+
+type List[T any] struct{ items []T }
+
+func (l *List[T]) Push(
+	item T,
+) {
+	l.items = append(l.items, item)
+}
+
+type Reader interface {
+	Read(key string) (string, error)
+	fmt.Stringer
+}
+
+func helper() {}
+
+type store struct{}
+
+func (s *store) Flush() {}
+
+type cache interface {
+	Load(key string) string
+}
+`
+
+func TestExtractGoSymbolsAST(t *testing.T) {
+	syms, err := extractGoSymbolsAST(goASTSource)
+	if err != nil {
+		t.Fatalf("extractGoSymbolsAST error: %v", err)
+	}
+	got := make(map[string]bool)
+	for _, s := range syms {
+		got[s.Symbol] = s.Exported
+	}
+
+	want := map[string]bool{"List": true, "Push": true, "Reader": true, "Read": true, "helper": false,
+		"store": false, "Flush": false, "cache": false, "Load": false}
+	for name, exported := range want {
+		e, ok := got[name]
+		if !ok {
+			t.Errorf("expected symbol %q", name)
+			continue
+		}
+		if e != exported {
+			t.Errorf("symbol %q Exported = %v, want %v", name, e, exported)
+		}
+	}
+	for _, name := range []string{"Commented", "InString"} {
+		if _, ok := got[name]; ok {
+			t.Errorf("symbol %q from a comment or string should not be extracted", name)
+		}
+	}
+}
+
+func TestExtractGoSymbolsAST_ParseError(t *testing.T) {
+	if _, err := extractGoSymbolsAST("package broken\nfunc {"); err == nil {
+		t.Fatal("expected parse error")
+	}
+}
+
+func TestBuildWithOptions_UseASTFallsBack(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"good.go":   goASTSource,
+		"broken.go": "package broken\nfunc Broken() {\n",
+	})
+	idx, err := BuildWithOptions(root, BuildOptions{UseAST: true})
+	if err != nil {
+		t.Fatalf("BuildWithOptions error: %v", err)
+	}
+	got := make(map[string]string)
+	for _, s := range idx.Symbols {
+		got[s.Symbol] = filepath.ToSlash(s.Path)
+	}
+	if got["Push"] != "good.go" {
+		t.Errorf("expected AST symbol Push from good.go, got %q", got["Push"])
+	}
+	if got["Broken"] != "broken.go" {
+		t.Errorf("expected regex fallback symbol Broken from broken.go, got %q", got["Broken"])
+	}
+}
+
+func TestExtractGoSymbolsAST_Signatures(t *testing.T) {
+	syms, err := extractGoSymbolsAST(goASTSource)
+	if err != nil {
+		t.Fatalf("extractGoSymbolsAST error: %v", err)
+	}
+	got := make(map[string]string)
+	for _, s := range syms {
+		got[s.Symbol] = s.Signature
+	}
+	want := map[string]string{
+		"Push":   "Push(item T)",
+		"Read":   "Read(key string) (string, error)",
+		"helper": "helper()",
+		"List":   "",
+	}
+	for name, sig := range want {
+		if got[name] != sig {
+			t.Errorf("signature of %q = %q, want %q", name, got[name], sig)
+		}
+	}
+}
+
+func TestExtractSymbols_GoHiddenMethods(t *testing.T) {
+	src := "package store\n\ntype store struct{}\n\nfunc (s *store) Flush() {}\n\nfunc (s store) Close() {}\n\ntype Client struct{}\n\nfunc (c *Client) Close() {}\n\nfunc New() *store { return nil }\n"
+	got := make(map[string]bool)
+	for _, s := range extractSymbols(src, "store.go", ".go", false) {
+		got[s.Symbol] = s.Exported
+	}
+	// Close is also a method of the exported Client, so it stays exported.
+	want := map[string]bool{"New": true, "Flush": false, "Close": true, "Client": true, "store": false}
+	for name, exported := range want {
+		if e, ok := got[name]; !ok || e != exported {
+			t.Errorf("symbol %q Exported = %v (found %v), want %v", name, e, ok, exported)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package codeindex
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ignoreSet holds the compiled BuildOptions.IgnorePatterns.
+type ignoreSet struct {
+	names []string         // path.Match globs for directory base names
+	paths []*regexp.Regexp // globs for full relative paths
+}
+
+// compileIgnorePatterns splits patterns into base-name and full-path globs
+// and validates them.
+func compileIgnorePatterns(patterns []string) (ignoreSet, error) {
+	var s ignoreSet
+	for _, p := range patterns {
+		if p == "" {
+			continue
+		}
+		if !strings.Contains(strings.TrimSuffix(p, "/"), "/") {
+			if _, err := path.Match(p, ""); err != nil {
+				return ignoreSet{}, fmt.Errorf("codeindex: ignore pattern %q: %w", p, err)
+			}
+			s.names = append(s.names, strings.TrimSuffix(p, "/"))
+			continue
+		}
+		re, err := CompileGlob(strings.TrimSuffix(p, "/"))
+		if err != nil {
+			return ignoreSet{}, fmt.Errorf("codeindex: ignore pattern %q: %w", p, err)
+		}
+		s.paths = append(s.paths, re)
+	}
+	return s, nil
+}
+
+// IgnoreFileName is the per-repo ignore file that Build reads from the code
+// root. Each line is a pattern with the same meaning as an entry in
+// BuildOptions.IgnorePatterns; blank lines and lines starting with "#" are
+// skipped.
+const IgnoreFileName = ".realitycheckignore"
+
+// loadIgnoreFile reads and compiles IgnoreFileName in root. A missing file
+// yields an empty set. Negated patterns ("!path") are rejected rather than
+// silently treated as plain globs.
+func loadIgnoreFile(root string) (ignoreSet, error) {
+	f, err := os.Open(filepath.Join(root, IgnoreFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return ignoreSet{}, nil
+	}
+	if err != nil {
+		return ignoreSet{}, fmt.Errorf("codeindex: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var s ignoreSet
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "!") {
+			return ignoreSet{}, fmt.Errorf("codeindex: %s line %d: negated pattern %q is not supported", IgnoreFileName, n, line)
+		}
+		ls, err := compileIgnorePatterns([]string{line})
+		if err != nil {
+			return ignoreSet{}, fmt.Errorf("%s line %d: %w", IgnoreFileName, n, err)
+		}
+		s.add(ls)
+	}
+	if err := sc.Err(); err != nil {
+		return ignoreSet{}, fmt.Errorf("codeindex: read %s: %w", IgnoreFileName, err)
+	}
+	return s, nil
+}
+
+// add appends the patterns of o to s.
+func (s *ignoreSet) add(o ignoreSet) {
+	s.names = append(s.names, o.names...)
+	s.paths = append(s.paths, o.paths...)
+}
+
+// dir reports whether the directory with base name name at rel (relative to
+// the root, OS separators) is ignored.
+func (s ignoreSet) dir(name, rel string) bool {
+	for _, p := range s.names {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return s.file(rel)
+}
+
+// file reports whether rel matches a full-path pattern.
+func (s ignoreSet) file(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	for _, re := range s.paths {
+		if re.MatchString(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathFilter holds the compiled BuildOptions.Include and Exclude globs.
+type pathFilter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+func compilePathFilter(include, exclude []string) (pathFilter, error) {
+	var f pathFilter
+	var err error
+	if f.include, err = compilePathGlobs("include", include); err != nil {
+		return pathFilter{}, err
+	}
+	if f.exclude, err = compilePathGlobs("exclude", exclude); err != nil {
+		return pathFilter{}, err
+	}
+	return f, nil
+}
+
+func compilePathGlobs(kind string, globs []string) ([]*regexp.Regexp, error) {
+	var out []*regexp.Regexp
+	for _, g := range globs {
+		if g == "" {
+			continue
+		}
+		re, err := CompileGlob(strings.TrimSuffix(g, "/"))
+		if err != nil {
+			return nil, fmt.Errorf("codeindex: %s pattern %q: %w", kind, g, err)
+		}
+		out = append(out, re)
+	}
+	return out, nil
+}
+
+func (f pathFilter) active() bool { return len(f.include) > 0 || len(f.exclude) > 0 }
+
+// excludedDir reports whether the directory at rel matches an exclude glob,
+// so the walk can skip it.
+func (f pathFilter) excludedDir(rel string) bool {
+	return matchAny(f.exclude, filepath.ToSlash(rel))
+}
+
+// keep reports whether the file at rel passes the filter: it must match an
+// include glob, if any are set, and no exclude glob. A glob also matches the
+// files under a matching directory, so "internal/api" is "internal/api/**".
+func (f pathFilter) keep(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	if len(f.include) > 0 && !matchAnyParent(f.include, rel) {
+		return false
+	}
+	return !matchAnyParent(f.exclude, rel)
+}
+
+func matchAny(res []*regexp.Regexp, p string) bool {
+	for _, re := range res {
+		if re.MatchString(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAnyParent reports whether p, or one of its parent directories,
+// matches any of res.
+func matchAnyParent(res []*regexp.Regexp, p string) bool {
+	for p != "." && p != "/" && p != "" {
+		if matchAny(res, p) {
+			return true
+		}
+		p = path.Dir(p)
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package codeindex
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// goTree writes a stub Go file at each slash-separated path under a new
+// temporary directory and returns the directory.
+func goTree(t *testing.T, files ...string) string {
+	t.Helper()
+	root := t.TempDir()
+	m := make(map[string]string, len(files))
+	for _, f := range files {
+		m[f] = "package x\n"
+	}
+	writeTree(t, root, m)
+	return root
+}
+
+// filePaths returns the sorted slash-separated paths of idx.Files.
+func filePaths(idx Index) []string {
+	var got []string
+	for _, f := range idx.Files {
+		got = append(got, filepath.ToSlash(f.Path))
+	}
+	sort.Strings(got)
+	return got
+}
+
+func TestBuild_IgnorePathGlobs(t *testing.T) {
+	root := goTree(t,
+		"internal/gen/a.go",
+		"internal/x/gen/b.go",
+		"gen/c.go",
+		"api/api.go",
+		"api/api.pb.go",
+		"tools/gen.go",
+	)
+
+	cases := []struct {
+		patterns []string
+		want     []string
+	}{
+		// A base-name pattern skips every directory of that name, but not files.
+		{[]string{"gen"}, []string{"api/api.go", "api/api.pb.go", "tools/gen.go"}},
+		{[]string{"ge?"}, []string{"api/api.go", "api/api.pb.go", "tools/gen.go"}},
+		// A path pattern only matches the full relative path.
+		{[]string{"internal/**/gen"}, []string{"api/api.go", "api/api.pb.go", "gen/c.go", "tools/gen.go"}},
+		{[]string{"**/*.pb.go", "/gen/"}, []string{"api/api.go", "internal/gen/a.go", "internal/x/gen/b.go", "tools/gen.go"}},
+	}
+	for _, c := range cases {
+		idx, err := Build(root, c.patterns)
+		if err != nil {
+			t.Fatalf("%v: Build error: %v", c.patterns, err)
+		}
+		if got := filePaths(idx); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%v: files = %v, want %v", c.patterns, got, c.want)
+		}
+	}
+
+	if _, err := Build(root, []string{"[gen"}); err == nil {
+		t.Error("malformed pattern should be rejected")
+	}
+}
+
+func TestBuild_IncludeExclude(t *testing.T) {
+	root := goTree(t,
+		"go.mod",
+		"main.go",
+		"internal/api/api.go",
+		"internal/api/v2/api.go",
+		"internal/api/api_gen.go",
+		"internal/store/store.go",
+		"vendor/lib/api.go",
+	)
+	cases := []struct {
+		include, exclude []string
+		want             []string
+	}{
+		{[]string{"internal/api/**"}, nil, []string{"internal/api/api.go", "internal/api/api_gen.go", "internal/api/v2/api.go"}},
+		// A directory glob covers its subtree.
+		{[]string{"internal/api"}, []string{"**/*_gen.go", "internal/api/v2"}, []string{"internal/api/api.go"}},
+		// The default ignore list still applies.
+		{[]string{"**/api.go"}, nil, []string{"internal/api/api.go", "internal/api/v2/api.go"}},
+		{nil, []string{"internal"}, []string{"main.go"}},
+	}
+	for _, c := range cases {
+		idx, err := BuildWithOptions(root, BuildOptions{Include: c.include, Exclude: c.exclude})
+		if err != nil {
+			t.Fatalf("include %v exclude %v: %v", c.include, c.exclude, err)
+		}
+		if got := filePaths(idx); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("include %v exclude %v: files = %v, want %v", c.include, c.exclude, got, c.want)
+		}
+	}
+
+	_, err := BuildWithOptions(root, BuildOptions{Include: []string{"cmd/**"}})
+	if err == nil || !strings.Contains(err.Error(), "no source files") {
+		t.Errorf("empty filtered index: err = %v", err)
+	}
+}
+
+func TestBuild_IgnoreFile(t *testing.T) {
+	root := goTree(t,
+		"examples/vendored/a.go",
+		"internal/gen/b.go",
+		"api/api.go",
+		"api/api.pb.go",
+	)
+	writeTree(t, root, map[string]string{
+		IgnoreFileName: "# tracked in git, irrelevant to the spec\nexamples/vendored/\n\ngen\n**/*.pb.go\n",
+	})
+
+	idx, err := Build(root, nil)
+	if err != nil {
+		t.Fatalf("Build error: %v", err)
+	}
+	want := []string{IgnoreFileName, "api/api.go"}
+	sort.Strings(want)
+	if got := filePaths(idx); !reflect.DeepEqual(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+
+	// Include and Exclude cannot bring back a path the ignore file drops.
+	idx, err = BuildWithOptions(root, BuildOptions{Include: []string{"api"}})
+	if err != nil {
+		t.Fatalf("BuildWithOptions error: %v", err)
+	}
+	if got := filePaths(idx); !reflect.DeepEqual(got, []string{"api/api.go"}) {
+		t.Errorf("with include, files = %v, want [api/api.go]", got)
+	}
+}
+
+func TestBuild_IgnoreFileErrors(t *testing.T) {
+	for _, content := range []string{"!keep.go\n", "gen[\n"} {
+		root := goTree(t, "a.go")
+		writeTree(t, root, map[string]string{IgnoreFileName: content})
+		_, err := Build(root, nil)
+		if err == nil || !strings.Contains(err.Error(), IgnoreFileName+" line 1") {
+			t.Errorf("%q: err = %v, want an error naming the line", content, err)
+		}
+	}
+}
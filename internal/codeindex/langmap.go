@@ -0,0 +1,121 @@
+package codeindex
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// LanguageOverride labels the files matching Glob as Language, in place of
+// the language their extension implies. A Glob without "/" is a path.Match
+// pattern matched against the file's base name, such as "*.h"; one with "/"
+// is matched against the slash-separated path relative to the root (see
+// CompileGlob), such as "internal/dsl/*.md".
+type LanguageOverride struct {
+	Glob     string
+	Language string
+}
+
+// languageExts maps each language label classifyLanguage returns to the
+// extension whose extractors apply to it. An empty extension means the
+// language has no extractors.
+var languageExts = map[string]string{
+	"Go":         ".go",
+	"TypeScript": ".ts",
+	"JavaScript": ".js",
+	"Vue":        ".vue",
+	"Svelte":     ".svelte",
+	"Python":     ".py",
+	"Rust":       ".rs",
+	"Java":       "",
+	"C":          "",
+	"C++":        "",
+	"C#":         ".cs",
+	"Kotlin":     ".kt",
+	"Swift":      ".swift",
+	"Ruby":       ".rb",
+	"PHP":        ".php",
+	"Shell":      ".sh",
+	"Markdown":   "",
+}
+
+// ParseLanguageOverrides parses entries of the form "glob=Language". An
+// entry may hold several, separated by commas, as in
+// "*.h=C++,internal/dsl/*.md=MyDSL". A language that matches a built-in
+// label case-insensitively is written as that label, so "c++" becomes
+// "C++"; any other name is kept as given.
+func ParseLanguageOverrides(entries []string) ([]LanguageOverride, error) {
+	var out []LanguageOverride
+	for _, entry := range entries {
+		for _, pair := range strings.Split(entry, ",") {
+			pair = strings.TrimSpace(pair)
+			if pair == "" {
+				continue
+			}
+			glob, lang, ok := strings.Cut(pair, "=")
+			glob, lang = strings.TrimSpace(glob), strings.TrimSpace(lang)
+			if !ok || glob == "" || lang == "" {
+				return nil, fmt.Errorf("codeindex: language override %q: want glob=Language", pair)
+			}
+			for label := range languageExts {
+				if strings.EqualFold(label, lang) {
+					lang = label
+					break
+				}
+			}
+			out = append(out, LanguageOverride{Glob: glob, Language: lang})
+		}
+	}
+	return out, nil
+}
+
+// languageMap is a compiled list of LanguageOverride.
+type languageMap []languageRule
+
+type languageRule struct {
+	name     string         // base-name pattern, or ""
+	path     *regexp.Regexp // relative-path pattern when name is ""
+	language string
+}
+
+// compileLanguageMap compiles overrides, keeping their order.
+func compileLanguageMap(overrides []LanguageOverride) (languageMap, error) {
+	var m languageMap
+	for _, o := range overrides {
+		if !strings.Contains(o.Glob, "/") {
+			if _, err := path.Match(o.Glob, ""); err != nil {
+				return nil, fmt.Errorf("codeindex: language override %q: %w", o.Glob, err)
+			}
+			m = append(m, languageRule{name: o.Glob, language: o.Language})
+			continue
+		}
+		re, err := CompileGlob(o.Glob)
+		if err != nil {
+			return nil, fmt.Errorf("codeindex: language override %q: %w", o.Glob, err)
+		}
+		m = append(m, languageRule{path: re, language: o.Language})
+	}
+	return m, nil
+}
+
+// lookup returns the language of the first override matching the file at
+// rel (relative to the root, OS separators), and the extension whose
+// extractors apply to it, or "" if there are none. ok is false when no
+// override matches.
+func (m languageMap) lookup(rel string) (lang, ext string, ok bool) {
+	slash := filepath.ToSlash(rel)
+	for _, r := range m {
+		var match bool
+		if r.path != nil {
+			match = r.path.MatchString(slash)
+		} else {
+			match, _ = path.Match(r.name, path.Base(slash))
+		}
+		if match {
+			return r.language, languageExts[r.language], true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,94 @@
+package codeindex
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseLanguageOverrides(t *testing.T) {
+	got, err := ParseLanguageOverrides([]string{"*.h=c++, internal/dsl/*.md=MyDSL", "*.mjs=JavaScript"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []LanguageOverride{
+		{Glob: "*.h", Language: "C++"},
+		{Glob: "internal/dsl/*.md", Language: "MyDSL"},
+		{Glob: "*.mjs", Language: "JavaScript"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	for _, bad := range []string{"*.h", "=C++", "*.h="} {
+		if _, err := ParseLanguageOverrides([]string{bad}); err == nil {
+			t.Errorf("ParseLanguageOverrides(%q): expected error", bad)
+		}
+	}
+}
+
+func TestBuild_LanguageOverrides(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.go":              "package main\n\nfunc Run() {}\n",
+		"lib/util.h":           "int util(void);\n",
+		"internal/dsl/rule.md": "# rule\n",
+		"docs/rule.md":         "# doc\n",
+		"scripts/build.tmpl":   "#!/bin/bash\ndeploy() {\n  :\n}\n",
+	}
+	for rel, content := range files {
+		p := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := BuildOptions{LanguageOverrides: []LanguageOverride{
+		{Glob: "*.h", Language: "C++"},
+		{Glob: "internal/dsl/*.md", Language: "MyDSL"},
+		{Glob: "*.tmpl", Language: "Shell"},
+	}}
+	idx, err := BuildWithOptions(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	langs := make(map[string]string)
+	for _, f := range idx.Files {
+		langs[filepath.ToSlash(f.Path)] = f.Language
+	}
+	want := map[string]string{
+		"main.go":              "Go",
+		"lib/util.h":           "C++",
+		"internal/dsl/rule.md": "MyDSL",
+		"docs/rule.md":         "Markdown",
+		"scripts/build.tmpl":   "Shell",
+	}
+	if !reflect.DeepEqual(langs, want) {
+		t.Errorf("languages = %v, want %v", langs, want)
+	}
+
+	// An override to a built-in language uses its extractors.
+	var syms []string
+	for _, s := range idx.Symbols {
+		syms = append(syms, filepath.ToSlash(s.Path)+":"+s.Symbol)
+	}
+	if !reflect.DeepEqual(syms, []string{"main.go:Run", "scripts/build.tmpl:deploy"}) {
+		t.Errorf("symbols = %v", syms)
+	}
+
+	if idx.Extraction == extraction(BuildOptions{}) {
+		t.Error("overrides should change Index.Extraction")
+	}
+}
+
+func TestBuild_LanguageOverrideBadGlob(t *testing.T) {
+	opts := BuildOptions{LanguageOverrides: []LanguageOverride{{Glob: "[", Language: "C"}}}
+	if _, err := BuildWithOptions(t.TempDir(), opts); err == nil {
+		t.Error("expected error for malformed glob")
+	}
+}
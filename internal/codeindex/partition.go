@@ -0,0 +1,74 @@
+package codeindex
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// RootPartition is the Dir of the partition holding files that sit directly
+// in the code root rather than in a subdirectory.
+const RootPartition = "."
+
+// Partition is the slice of an Index under one top-level directory.
+type Partition struct {
+	Dir   string // top-level directory name, or RootPartition
+	Index Index
+}
+
+// topDir returns the first path element of rel, or RootPartition for a file
+// in the code root.
+func topDir(rel string) string {
+	rel = filepath.ToSlash(rel)
+	if i := strings.IndexByte(rel, '/'); i >= 0 {
+		return rel[:i]
+	}
+	return RootPartition
+}
+
+// SplitByTopDir partitions idx by top-level directory of the code root,
+// ignoring idx.PathPrefix, sorted by Dir. Files,
+// symbols, tests, and TODOs go to the partition of their path. Dependency manifests
+// and config file names describe the whole project, so every partition
+// carries all of them.
+func (idx Index) SplitByTopDir() []Partition {
+	parts := make(map[string]*Index)
+	get := func(rel string) *Index {
+		dir := topDir(idx.unprefixed(rel))
+		p, ok := parts[dir]
+		if !ok {
+			p = &Index{
+				DependencyManifests: idx.DependencyManifests,
+				ConfigFiles:         idx.ConfigFiles,
+				ChangedSince:        idx.ChangedSince,
+				ManifestBytes:       idx.ManifestBytes,
+				PathPrefix:          idx.PathPrefix,
+			}
+			parts[dir] = p
+		}
+		return p
+	}
+	for _, f := range idx.Files {
+		p := get(f.Path)
+		p.Files = append(p.Files, f)
+	}
+	for _, s := range idx.Symbols {
+		p := get(s.Path)
+		p.Symbols = append(p.Symbols, s)
+	}
+	for _, t := range idx.Tests {
+		p := get(t.Path)
+		p.Tests = append(p.Tests, t)
+	}
+	for _, t := range idx.Todos {
+		p := get(t.Path)
+		p.Todos = append(p.Todos, t)
+	}
+
+	out := make([]Partition, 0, len(parts))
+	for dir, p := range parts {
+		out = append(out, Partition{Dir: dir, Index: *p})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Dir < out[j].Dir })
+	return out
+}
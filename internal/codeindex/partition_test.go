@@ -0,0 +1,52 @@
+package codeindex
+
+import "testing"
+
+func TestSplitByTopDir(t *testing.T) {
+	idx := Index{
+		Files: []FileEntry{
+			{Path: "main.go", Language: "Go"},
+			{Path: "api/handler.go", Language: "Go"},
+			{Path: "api/v2/handler.go", Language: "Go"},
+			{Path: "store/store.go", Language: "Go"},
+		},
+		Symbols: []SymbolEntry{
+			{Path: "main.go", Symbol: "main"},
+			{Path: "api/handler.go", Symbol: "Serve"},
+			{Path: "api/v2/handler.go", Symbol: "ServeV2"},
+			{Path: "store/store.go", Symbol: "Get"},
+		},
+		Tests:               []TestEntry{{Path: "store/store_test.go", Function: "TestGet"}},
+		DependencyManifests: []ManifestEntry{{Path: "go.mod", Content: "module x"}},
+		ConfigFiles:         []string{"config.yaml"},
+	}
+
+	parts := idx.SplitByTopDir()
+	var dirs []string
+	for _, p := range parts {
+		dirs = append(dirs, p.Dir)
+	}
+	want := []string{RootPartition, "api", "store"}
+	if len(dirs) != len(want) {
+		t.Fatalf("partitions = %v, want %v", dirs, want)
+	}
+	for i := range want {
+		if dirs[i] != want[i] {
+			t.Fatalf("partitions = %v, want %v (sorted)", dirs, want)
+		}
+	}
+
+	api := parts[1].Index
+	if len(api.Files) != 2 || len(api.Symbols) != 2 {
+		t.Errorf("api partition should hold nested files: %+v", api)
+	}
+	store := parts[2].Index
+	if len(store.Tests) != 1 || store.Tests[0].Function != "TestGet" {
+		t.Errorf("store partition tests = %+v", store.Tests)
+	}
+	for _, p := range parts {
+		if len(p.Index.DependencyManifests) != 1 || len(p.Index.ConfigFiles) != 1 {
+			t.Errorf("partition %q should carry all manifests and config files", p.Dir)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package codeindex
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// cleanPathPrefix validates a BuildOptions.PathPrefix and returns it
+// slash-separated and cleaned, or "" for an empty or "." prefix. The prefix
+// must be relative and must not climb out of the directory it names paths
+// from.
+func cleanPathPrefix(prefix string) (string, error) {
+	if prefix == "" {
+		return "", nil
+	}
+	p := path.Clean(filepath.ToSlash(prefix))
+	switch {
+	case path.IsAbs(p) || filepath.IsAbs(prefix):
+		return "", fmt.Errorf("codeindex: path prefix %q must be relative", prefix)
+	case p == "..", strings.HasPrefix(p, "../"):
+		return "", fmt.Errorf("codeindex: path prefix %q must not start with ..", prefix)
+	case p == ".":
+		return "", nil
+	}
+	return p, nil
+}
+
+// applyPrefix prepends prefix (as returned by cleanPathPrefix) to every path
+// in idx except the Stamps keys, and records it in idx.PathPrefix.
+func (idx *Index) applyPrefix(prefix string) {
+	idx.PathPrefix = prefix
+	if prefix == "" {
+		return
+	}
+	join := func(rel string) string { return filepath.Join(filepath.FromSlash(prefix), rel) }
+	for i := range idx.Files {
+		idx.Files[i].Path = join(idx.Files[i].Path)
+	}
+	for i := range idx.Symbols {
+		idx.Symbols[i].Path = join(idx.Symbols[i].Path)
+	}
+	for i := range idx.Tests {
+		idx.Tests[i].Path = join(idx.Tests[i].Path)
+	}
+	for i := range idx.Todos {
+		idx.Todos[i].Path = join(idx.Todos[i].Path)
+	}
+	for i := range idx.DependencyManifests {
+		idx.DependencyManifests[i].Path = join(idx.DependencyManifests[i].Path)
+	}
+	for i := range idx.ConfigFiles {
+		idx.ConfigFiles[i] = join(idx.ConfigFiles[i])
+	}
+}
+
+// unprefixed returns p, a path from idx, relative to the code root again:
+// idx.PathPrefix and the separator after it are removed.
+func (idx Index) unprefixed(p string) string {
+	if idx.PathPrefix == "" {
+		return p
+	}
+	return strings.TrimPrefix(p, filepath.FromSlash(idx.PathPrefix)+string(filepath.Separator))
+}
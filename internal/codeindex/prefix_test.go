@@ -0,0 +1,84 @@
+package codeindex
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBuild_PathPrefix(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"store.go":          "package api\n\nfunc Get() {}\n",
+		"store_test.go":     "package api\n\nfunc TestGet(t *testing.T) {}\n",
+		"handlers/users.go": "package handlers\n\nfunc List() {}\n",
+		"go.mod":            "module example.com/api\n",
+	})
+
+	idx, err := BuildWithOptions(root, BuildOptions{PathPrefix: "./internal/api/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idx.PathPrefix != "internal/api" {
+		t.Errorf("PathPrefix = %q, want the cleaned prefix", idx.PathPrefix)
+	}
+	want := []string{"internal/api/handlers/users.go", "internal/api/store.go", "internal/api/store_test.go"}
+	if got := filePaths(idx); !reflect.DeepEqual(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+	for _, s := range idx.Symbols {
+		if filepath.ToSlash(s.Path) != "internal/api/store.go" && filepath.ToSlash(s.Path) != "internal/api/handlers/users.go" {
+			t.Errorf("symbol path %q is not prefixed", s.Path)
+		}
+	}
+	if len(idx.Tests) != 1 || filepath.ToSlash(idx.Tests[0].Path) != "internal/api/store_test.go" {
+		t.Errorf("tests = %+v, want the prefixed test file", idx.Tests)
+	}
+	if len(idx.DependencyManifests) != 1 || filepath.ToSlash(idx.DependencyManifests[0].Path) != "internal/api/go.mod" {
+		t.Errorf("manifests = %+v, want the prefixed go.mod", idx.DependencyManifests)
+	}
+	if _, ok := idx.Stamps["store.go"]; !ok {
+		t.Errorf("stamps = %v, want keys relative to the code root", idx.Stamps)
+	}
+
+	// git reports paths relative to the code root.
+	if n := idx.MarkChanged("main", []string{"store.go"}); n != 1 {
+		t.Errorf("MarkChanged = %d, want 1", n)
+	}
+
+	// Partitions follow the code root's own top-level directories.
+	var dirs []string
+	for _, p := range idx.SplitByTopDir() {
+		dirs = append(dirs, p.Dir)
+	}
+	if !reflect.DeepEqual(dirs, []string{RootPartition, "handlers"}) {
+		t.Errorf("partitions = %v, want [. handlers]", dirs)
+	}
+
+	// An incremental build reuses prefixed results and applies the new prefix.
+	next, err := BuildIncremental(root, BuildOptions{PathPrefix: "svc"}, idx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSyms := []string{"svc/handlers/users.go:List", "svc/store.go:Get"}
+	var gotSyms []string
+	for _, s := range symbolNames(next) {
+		gotSyms = append(gotSyms, filepath.ToSlash(s))
+	}
+	if !reflect.DeepEqual(gotSyms, wantSyms) {
+		t.Errorf("incremental symbols = %v, want %v", gotSyms, wantSyms)
+	}
+}
+
+func TestBuild_PathPrefixInvalid(t *testing.T) {
+	root := goTree(t, "a.go")
+	for _, prefix := range []string{"/abs", "..", "../up"} {
+		if _, err := BuildWithOptions(root, BuildOptions{PathPrefix: prefix}); err == nil {
+			t.Errorf("PathPrefix %q: expected an error", prefix)
+		}
+	}
+	idx, err := BuildWithOptions(root, BuildOptions{PathPrefix: "."})
+	if err != nil || idx.PathPrefix != "" || filePaths(idx)[0] != "a.go" {
+		t.Errorf(`PathPrefix ".": idx = %+v, err = %v; want no prefix`, idx, err)
+	}
+}
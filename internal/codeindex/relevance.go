@@ -0,0 +1,98 @@
+package codeindex
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/dshills/realitycheck/internal/mdparse"
+)
+
+// stopwords are common words in spec and plan prose that say nothing about
+// which code is relevant. Words shorter than minKeywordLen are dropped too.
+var stopwords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true,
+	"this": true, "are": true, "from": true, "when": true, "will": true,
+	"each": true, "all": true, "not": true, "can": true, "its": true,
+	"into": true, "has": true, "have": true, "been": true, "must": true,
+	"should": true, "shall": true, "may": true, "any": true, "than": true,
+	"then": true, "use": true, "uses": true, "used": true, "also": true,
+	"only": true, "one": true, "new": true, "step": true, "spec": true,
+	"plan": true, "which": true, "there": true, "their": true, "they": true,
+	"does": true, "was": true, "were": true, "per": true, "via": true,
+}
+
+const minKeywordLen = 3
+
+// specKeywords returns the lower-cased words of every item's text, less
+// stopwords. Identifiers are also split into their parts, so "GetUser"
+// yields "getuser", "get", and "user".
+func specKeywords(itemSets ...[]mdparse.Item) map[string]bool {
+	kw := map[string]bool{}
+	for _, items := range itemSets {
+		for _, it := range items {
+			for _, w := range words(it.Text) {
+				addKeyword(kw, w)
+				for _, part := range identParts(w) {
+					addKeyword(kw, part)
+				}
+			}
+		}
+	}
+	return kw
+}
+
+func addKeyword(kw map[string]bool, w string) {
+	w = strings.ToLower(w)
+	if len(w) >= minKeywordLen && !stopwords[w] {
+		kw[w] = true
+	}
+}
+
+// relevanceScore rates how strongly s is referenced by keywords: 3 if the
+// whole symbol name is a keyword, plus 2 per matching name part and 1 per
+// matching path segment.
+func relevanceScore(s SymbolEntry, keywords map[string]bool) int {
+	score := 0
+	if keywords[strings.ToLower(s.Symbol)] {
+		score += 3
+	}
+	for _, part := range identParts(s.Symbol) {
+		if keywords[strings.ToLower(part)] {
+			score += 2
+		}
+	}
+	for _, w := range words(s.Path) {
+		for _, part := range identParts(w) {
+			if keywords[strings.ToLower(part)] {
+				score++
+			}
+		}
+	}
+	return score
+}
+
+// words splits s into runs of letters and digits.
+func words(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// identParts splits an identifier at camelCase boundaries, e.g. "parseHTTPRequest"
+// becomes "parse", "HTTP", "Request". Snake and kebab case are handled by words.
+func identParts(w string) []string {
+	runes := []rune(w)
+	var parts []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		lowerToUpper := unicode.IsLower(prev) && unicode.IsUpper(cur)
+		acronymEnd := unicode.IsUpper(prev) && unicode.IsUpper(cur) &&
+			i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if lowerToUpper || acronymEnd {
+			parts = append(parts, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(parts, string(runes[start:]))
+}
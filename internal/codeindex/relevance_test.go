@@ -0,0 +1,68 @@
+package codeindex
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/mdparse"
+)
+
+func TestIdentParts(t *testing.T) {
+	cases := map[string][]string{
+		"GetUser":          {"Get", "User"},
+		"parseHTTPRequest": {"parse", "HTTP", "Request"},
+		"URL":              {"URL"},
+		"lower":            {"lower"},
+	}
+	for in, want := range cases {
+		if got := identParts(in); !reflect.DeepEqual(got, want) {
+			t.Errorf("identParts(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRelevanceScore(t *testing.T) {
+	kw := specKeywords([]mdparse.Item{{Text: "The store must support GetUser lookups."}}, nil)
+	if kw["the"] || kw["must"] {
+		t.Errorf("stopwords should be dropped: %v", kw)
+	}
+	exact := relevanceScore(SymbolEntry{Path: "internal/store/store.go", Symbol: "GetUser"}, kw)
+	partial := relevanceScore(SymbolEntry{Path: "internal/api/api.go", Symbol: "DeleteUser"}, kw)
+	none := relevanceScore(SymbolEntry{Path: "internal/api/api.go", Symbol: "Render"}, kw)
+	if !(exact > partial && partial > none && none == 0) {
+		t.Errorf("scores exact=%d partial=%d none=%d, want exact > partial > none = 0", exact, partial, none)
+	}
+}
+
+func TestSummaryForSpec_KeepsReferencedSymbols(t *testing.T) {
+	var symbols []SymbolEntry
+	for i := 0; i < 500; i++ {
+		symbols = append(symbols, SymbolEntry{Path: "internal/misc/misc.go", Symbol: fmt.Sprintf("Helper%03d", i)})
+	}
+	// The referenced symbol comes last in walk order, so plain truncation
+	// drops it.
+	symbols = append(symbols, SymbolEntry{Path: "internal/billing/invoice.go", Symbol: "ComputeInvoiceTotal"})
+	idx := Index{
+		Files:   []FileEntry{{Path: "internal/misc/misc.go", Language: "Go"}},
+		Symbols: symbols,
+	}
+	items := []mdparse.Item{{ID: "SPEC-001", Text: "Invoices show a computed total."}}
+	limit := len(idx.Summary()) / 4
+
+	if strings.Contains(idx.SummaryWithLimit(limit), "ComputeInvoiceTotal") {
+		t.Fatal("test setup: plain truncation should drop the last symbol")
+	}
+	got := idx.SummaryForSpec(items, nil, limit)
+	if !strings.Contains(got, "ComputeInvoiceTotal") {
+		t.Error("SummaryForSpec should keep the symbol the spec references")
+	}
+	if !strings.Contains(got, "[TRUNCATED:") || len(got) > limit {
+		t.Errorf("summary should be truncated within %d bytes, got %d", limit, len(got))
+	}
+	// Kept symbols stay in walk order.
+	if strings.Index(got, "Helper000") > strings.Index(got, "ComputeInvoiceTotal") {
+		t.Error("kept symbols should be listed in walk order")
+	}
+}
@@ -0,0 +1,77 @@
+package codeindex
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxShebangBytes bounds how much of a file is read when looking for a
+// shebang line.
+const maxShebangBytes = 256
+
+// isExecutable reports whether info describes a file with an execute bit
+// set. Only such files are sniffed for a shebang, so extensionless data
+// and binaries without one are never opened.
+func isExecutable(info fs.FileInfo) bool {
+	return info.Mode().IsRegular() && info.Mode()&0o111 != 0 && info.Size() > 2
+}
+
+// shebangExt returns the extension whose extractors apply to the script at
+// path, judging by the interpreter on its "#!" line: ".py" for python, ".js"
+// for node, ".rb" for ruby, ".php" for php, and ".sh" for sh, bash, dash,
+// ksh, and zsh. It returns "" when the first line is not a shebang or names
+// another interpreter. Only the first line is read.
+func shebangExt(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	line, err := bufio.NewReaderSize(f, maxShebangBytes).ReadSlice('\n')
+	if err != nil && len(line) == 0 {
+		return ""
+	}
+	return interpreterExt(string(line))
+}
+
+// interpreterExt implements shebangExt for the first line of a file.
+func interpreterExt(line string) string {
+	rest, ok := strings.CutPrefix(line, "#!")
+	if !ok {
+		return ""
+	}
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return ""
+	}
+	interp := filepath.Base(fields[0])
+	if interp == "env" {
+		// "#!/usr/bin/env python3" or "#!/usr/bin/env -S node --flag":
+		// the interpreter is the first argument that is not an option.
+		interp = ""
+		for _, arg := range fields[1:] {
+			if !strings.HasPrefix(arg, "-") && !strings.Contains(arg, "=") {
+				interp = filepath.Base(arg)
+				break
+			}
+		}
+	}
+	// Drop a version suffix, as in python3 or python3.12.
+	interp = strings.TrimRight(interp, "0123456789.")
+	switch interp {
+	case "python":
+		return ".py"
+	case "node", "nodejs":
+		return ".js"
+	case "ruby":
+		return ".rb"
+	case "php":
+		return ".php"
+	case "sh", "bash", "dash", "ksh", "zsh":
+		return ".sh"
+	}
+	return ""
+}
@@ -0,0 +1,80 @@
+package codeindex
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInterpreterExt(t *testing.T) {
+	cases := map[string]string{
+		"#!/usr/bin/env python3\n":             ".py",
+		"#!/usr/bin/python3.12 -u\n":           ".py",
+		"#!/usr/bin/env -S node --no-warnings": ".js",
+		"#!/bin/bash\n":                        ".sh",
+		"#! /bin/sh -e\n":                      ".sh",
+		"#!/usr/bin/env FOO=1 zsh\n":           ".sh",
+		"#!/usr/bin/env ruby\n":                ".rb",
+		"#!/usr/bin/php -q\n":                  ".php",
+		"#!/usr/bin/env perl\n":                "",
+		"#!\n":                                 "",
+		"print('no shebang')\n":                "",
+	}
+	for line, want := range cases {
+		if got := interpreterExt(line); got != want {
+			t.Errorf("interpreterExt(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestExtract_Shell(t *testing.T) {
+	src := `#!/bin/bash
+deploy() {
+  echo "deploying"
+}
+
+function rollback {
+  :
+}
+
+function check_health() {
+  if (true); then :; fi
+}
+`
+	assertSymbols(t, extractShellSymbols(src), []string{"deploy", "rollback", "check_health"}, []string{"if", "echo"})
+}
+
+func TestBuild_Shebang(t *testing.T) {
+	root := t.TempDir()
+	script := func(name, content string, mode os.FileMode) {
+		t.Helper()
+		path := filepath.Join(root, "bin", name)
+		writeFile(t, path, content)
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	script("deploy", "#!/usr/bin/env python3\n\ndef main():\n    pass\n", 0o755)
+	script("serve", "#!/usr/bin/env node\nfunction start() {}\n", 0o755)
+	script("ci", "#!/bin/sh\nrun_tests() {\n  go test ./...\n}\n", 0o755)
+	// Not executable: never opened, so it stays Other with no symbols.
+	script("notes", "#!/usr/bin/env python3\n\ndef hidden():\n    pass\n", 0o644)
+
+	idx, err := BuildWithOptions(root, BuildOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	langs := map[string]string{}
+	for _, f := range idx.Files {
+		langs[filepath.Base(f.Path)] = f.Language
+	}
+	want := map[string]string{"deploy": "Python", "serve": "JavaScript", "ci": "Shell", "notes": "Other"}
+	for name, lang := range want {
+		if langs[name] != lang {
+			t.Errorf("%s: language = %q, want %q", name, langs[name], lang)
+		}
+	}
+	assertSymbols(t, symbolNames(idx),
+		[]string{"bin/deploy:main", "bin/serve:start", "bin/ci:run_tests"},
+		[]string{"bin/notes:hidden"})
+}
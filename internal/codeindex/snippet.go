@@ -0,0 +1,112 @@
+package codeindex
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dshills/realitycheck/internal/mdparse"
+)
+
+// declKeywordRe matches a line that declares something in one of the
+// supported languages. It is used to prefer a declaration over an earlier
+// mention, such as a call in a Vue template.
+var declKeywordRe = regexp.MustCompile(`\b(?:func|function|def|fn|class|struct|interface|type|enum|trait|object|protocol|fun|impl|record)\b`)
+
+// attachSnippets sets Line, when not already known, and Snippet on each of
+// syms, all extracted from content.
+func attachSnippets(syms []SymbolEntry, content string, n int) {
+	lines := strings.Split(content, "\n")
+	for i := range syms {
+		if syms[i].Line == 0 {
+			syms[i].Line = declLine(lines, syms[i].Symbol)
+		}
+		if syms[i].Line == 0 {
+			continue
+		}
+		end := min(syms[i].Line-1+n, len(lines))
+		syms[i].Snippet = strings.TrimRight(strings.Join(lines[syms[i].Line-1:end], "\n"), " \t\r\n")
+	}
+}
+
+// declLine returns the 1-based line declaring name: the first line that
+// mentions it as a word after a declaration keyword, else the first line
+// that mentions it at all, else 0. Regex extractors do not record positions,
+// so this is a heuristic.
+func declLine(lines []string, name string) int {
+	word := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`)
+	fallback := 0
+	for i, l := range lines {
+		loc := word.FindStringIndex(l)
+		if loc == nil {
+			continue
+		}
+		if declKeywordRe.MatchString(l[:loc[0]]) {
+			return i + 1
+		}
+		if fallback == 0 {
+			fallback = i + 1
+		}
+	}
+	return fallback
+}
+
+// snippetSectionHeader starts the snippet section of the prompt.
+const snippetSectionHeader = "=== Snippets ===\n"
+
+// snippetNotice ends a snippet section that did not fit its limit.
+const snippetNotice = "[TRUNCATED: %d snippets omitted to fit context limit]\n"
+
+// snippetBlock renders s for SnippetsForSpec.
+func (s SymbolEntry) snippetBlock() string {
+	return fmt.Sprintf("--- %s:%d %s\n%s\n", s.Path, s.Line, s.Symbol, s.Snippet)
+}
+
+// SnippetsForSpec renders the symbol snippets recorded with
+// BuildOptions.SnippetLines in at most limit bytes. When they do not all
+// fit, the snippets of symbols that best match the spec and plan items are
+// kept (see relevanceScore) and a notice gives the number omitted. Kept
+// snippets are listed in walk order. It returns "" when there are no
+// snippets or limit is not positive.
+func (idx Index) SnippetsForSpec(specItems, planItems []mdparse.Item, limit int) string {
+	var have []int
+	for i, s := range idx.Symbols {
+		if s.Snippet != "" {
+			have = append(have, i)
+		}
+	}
+	if len(have) == 0 || limit <= 0 {
+		return ""
+	}
+
+	order := append([]int(nil), have...)
+	keywords := specKeywords(specItems, planItems)
+	sort.SliceStable(order, func(a, b int) bool {
+		return relevanceScore(idx.Symbols[order[a]], keywords) > relevanceScore(idx.Symbols[order[b]], keywords)
+	})
+
+	budget := limit - len(snippetSectionHeader) - len(fmt.Sprintf(snippetNotice, len(have)))
+	keep := make(map[int]bool, len(have))
+	used := 0
+	for _, i := range order {
+		n := len(idx.Symbols[i].snippetBlock())
+		if used+n > budget {
+			continue
+		}
+		used += n
+		keep[i] = true
+	}
+
+	var sb strings.Builder
+	sb.WriteString(snippetSectionHeader)
+	for _, i := range have {
+		if keep[i] {
+			sb.WriteString(idx.Symbols[i].snippetBlock())
+		}
+	}
+	if omitted := len(have) - len(keep); omitted > 0 {
+		fmt.Fprintf(&sb, snippetNotice, omitted)
+	}
+	return sb.String()
+}
@@ -0,0 +1,86 @@
+package codeindex
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/mdparse"
+)
+
+func TestBuild_SnippetLines(t *testing.T) {
+	idx, err := BuildWithOptions(fixtureDir, BuildOptions{})
+	if err != nil {
+		t.Fatalf("Build error: %v", err)
+	}
+	for _, s := range idx.Symbols {
+		if s.Snippet != "" {
+			t.Fatalf("%s has a snippet without SnippetLines", s.Symbol)
+		}
+	}
+
+	for _, useAST := range []bool{false, true} {
+		idx, err := BuildWithOptions(fixtureDir, BuildOptions{SnippetLines: 3, UseAST: useAST})
+		if err != nil {
+			t.Fatalf("Build error: %v", err)
+		}
+		for _, s := range idx.Symbols {
+			if s.Line == 0 || s.Snippet == "" {
+				t.Errorf("ast=%v: %s:%s has line %d and snippet %q", useAST, s.Path, s.Symbol, s.Line, s.Snippet)
+				continue
+			}
+			if n := strings.Count(s.Snippet, "\n") + 1; n > 3 {
+				t.Errorf("ast=%v: %s snippet has %d lines, want at most 3", useAST, s.Symbol, n)
+			}
+			if !strings.Contains(strings.SplitN(s.Snippet, "\n", 2)[0], s.Symbol) {
+				t.Errorf("ast=%v: %s snippet does not start at its declaration: %q", useAST, s.Symbol, s.Snippet)
+			}
+		}
+	}
+}
+
+func TestDeclLine(t *testing.T) {
+	lines := strings.Split(`<template>
+  <button @click="submit">Go</button>
+</template>
+<script>
+function submit() {}
+</script>`, "\n")
+	if got := declLine(lines, "submit"); got != 5 {
+		t.Errorf("declLine(submit) = %d, want 5 (the declaration, not the template)", got)
+	}
+	if got := declLine(lines, "button"); got != 2 {
+		t.Errorf("declLine(button) = %d, want the first mention 2", got)
+	}
+	if got := declLine(lines, "missing"); got != 0 {
+		t.Errorf("declLine(missing) = %d, want 0", got)
+	}
+}
+
+func TestSnippetsForSpec(t *testing.T) {
+	idx := Index{Symbols: []SymbolEntry{
+		{Path: "a.go", Symbol: "Alpha", Line: 3, Snippet: "func Alpha() {\n\treturn\n}"},
+		{Path: "b.go", Symbol: "Evict", Line: 9, Snippet: "func Evict() {\n\treturn\n}"},
+		{Path: "c.go", Symbol: "NoSnippet"},
+	}}
+	full := idx.SnippetsForSpec(nil, nil, 10_000)
+	if !strings.HasPrefix(full, "=== Snippets ===\n") || !strings.Contains(full, "--- a.go:3 Alpha\nfunc Alpha() {") {
+		t.Errorf("unexpected snippet section:\n%s", full)
+	}
+	if strings.Contains(full, "NoSnippet") || strings.Contains(full, "TRUNCATED") {
+		t.Errorf("unexpected snippet section:\n%s", full)
+	}
+
+	// With room for one snippet, the one the spec mentions is kept.
+	spec := []mdparse.Item{{ID: "SPEC-001", Text: "Evict expired entries."}}
+	limit := len(snippetSectionHeader) + len(fmt.Sprintf(snippetNotice, 2)) + len(idx.Symbols[1].snippetBlock())
+	got := idx.SnippetsForSpec(spec, nil, limit)
+	if len(got) > limit || strings.Contains(got, "Alpha") || !strings.Contains(got, "Evict") ||
+		!strings.Contains(got, "[TRUNCATED: 1 snippets omitted") {
+		t.Errorf("limit %d: unexpected snippet section:\n%s", limit, got)
+	}
+
+	if got := idx.SnippetsForSpec(nil, nil, 0); got != "" {
+		t.Errorf("limit 0: got %q, want empty", got)
+	}
+}
@@ -0,0 +1,42 @@
+package codeindex
+
+import (
+	"regexp"
+	"strings"
+)
+
+// TodoEntry is a TODO or FIXME comment that names spec or plan items, such
+// as "// TODO: implement SPEC-003". It acknowledges that those items are
+// not done yet.
+type TodoEntry struct {
+	Path string   // relative file path
+	Line int      // 1-based line of the comment
+	IDs  []string // spec and plan IDs named after the marker, in order
+	Text string   // the comment from the marker on
+}
+
+var (
+	// todoMarkerRe matches an upper-case TODO or FIXME marker.
+	todoMarkerRe = regexp.MustCompile(`\b(?:TODO|FIXME)\b`)
+	// todoIDRe matches a spec or plan item ID.
+	todoIDRe = regexp.MustCompile(`\b(?:SPEC|PLAN)-\d+\b`)
+)
+
+// scanTodos returns the TODO and FIXME comments in content, the text of the
+// file at rel, that name at least one spec or plan ID after the marker.
+func scanTodos(content, rel string) []TodoEntry {
+	var out []TodoEntry
+	for i, line := range strings.Split(content, "\n") {
+		loc := todoMarkerRe.FindStringIndex(line)
+		if loc == nil {
+			continue
+		}
+		text := strings.TrimSpace(line[loc[0]:])
+		ids := todoIDRe.FindAllString(text, -1)
+		if len(ids) == 0 {
+			continue
+		}
+		out = append(out, TodoEntry{Path: rel, Line: i + 1, IDs: ids, Text: text})
+	}
+	return out
+}
@@ -0,0 +1,50 @@
+package codeindex
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScanTodos(t *testing.T) {
+	content := "package x\n" +
+		"// TODO: implement SPEC-003 and PLAN-002\n" +
+		"// TODO: tidy up\n" +
+		"func A() {} // FIXME SPEC-010 returns early\n" +
+		"// todo: SPEC-004 (lower case is not a marker)\n" +
+		"// See SPEC-005. TODO: later\n"
+	want := []TodoEntry{
+		{Path: "x.go", Line: 2, IDs: []string{"SPEC-003", "PLAN-002"}, Text: "TODO: implement SPEC-003 and PLAN-002"},
+		{Path: "x.go", Line: 4, IDs: []string{"SPEC-010"}, Text: "FIXME SPEC-010 returns early"},
+	}
+	if got := scanTodos(content, "x.go"); !reflect.DeepEqual(got, want) {
+		t.Errorf("scanTodos = %+v, want %+v", got, want)
+	}
+}
+
+func TestBuild_ScanTodos(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "a.go"), "package x\n\n// TODO: implement SPEC-002\nfunc A() {}\n")
+	writeFile(t, filepath.Join(root, "a_test.go"), "package x\n\n// FIXME: cover PLAN-001\n")
+	writeFile(t, filepath.Join(root, "SPEC.md"), "- SPEC-002: TODO decide on storage\n")
+
+	idx, err := BuildWithOptions(root, BuildOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(idx.Todos) != 0 {
+		t.Errorf("todos = %+v, want none without ScanTodos", idx.Todos)
+	}
+
+	idx, err = BuildWithOptions(root, BuildOptions{ScanTodos: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(idx.Todos) != 2 || idx.Todos[0].Path != "a.go" || idx.Todos[1].Path != "a_test.go" {
+		t.Fatalf("todos = %+v, want one per Go file and none from SPEC.md", idx.Todos)
+	}
+	if s := idx.Summary(); !strings.Contains(s, "=== TODOs Naming Spec/Plan Items ===") || !strings.Contains(s, "a.go:3: TODO: implement SPEC-002") {
+		t.Errorf("summary missing the TODO section:\n%s", s)
+	}
+}
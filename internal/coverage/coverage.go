@@ -3,7 +3,12 @@ package coverage
 
 import (
 	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
 
+	"github.com/dshills/realitycheck/internal/mdparse"
 	"github.com/dshills/realitycheck/internal/schema"
 )
 
@@ -80,3 +85,282 @@ func SummarizeSpecCoverage(entries []schema.SpecCoverageEntry) (implemented, par
 	}
 	return
 }
+
+// SummarizePlanCoverage counts entries by status.
+func SummarizePlanCoverage(entries []schema.PlanCoverageEntry) (implemented, partial, missing, unclear int) {
+	for _, e := range entries {
+		switch e.Status {
+		case schema.StatusImplemented:
+			implemented++
+		case schema.StatusPartial:
+			partial++
+		case schema.StatusNotImplemented:
+			missing++
+		case schema.StatusUnclear:
+			unclear++
+		}
+	}
+	return
+}
+
+// Percent returns the percentage of entries that are implemented, counting
+// a PARTIAL entry as half, rounded to one decimal place. It takes the counts
+// returned by SummarizeSpecCoverage or SummarizePlanCoverage, and returns
+// nil when they are all zero.
+func Percent(implemented, partial, missing, unclear int) *float64 {
+	total := implemented + partial + missing + unclear
+	if total == 0 {
+		return nil
+	}
+	pct := math.Round((float64(implemented)+float64(partial)/2)/float64(total)*1000) / 10
+	return &pct
+}
+
+// unmet reports whether status counts as an unmet requirement.
+func unmet(status schema.CoverageStatus) bool {
+	return status == schema.StatusNotImplemented || status == schema.StatusPartial
+}
+
+// UnmetSpecIDs returns the sorted IDs of spec entries that are
+// NOT_IMPLEMENTED or PARTIAL. The result is never nil.
+func UnmetSpecIDs(entries []schema.SpecCoverageEntry) []string {
+	ids := []string{}
+	for _, e := range entries {
+		if unmet(e.Status) {
+			ids = append(ids, e.ID)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// UnmetPlanIDs returns the sorted IDs of plan entries that are
+// NOT_IMPLEMENTED or PARTIAL. The result is never nil.
+func UnmetPlanIDs(entries []schema.PlanCoverageEntry) []string {
+	ids := []string{}
+	for _, e := range entries {
+		if unmet(e.Status) {
+			ids = append(ids, e.ID)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// FindIntentGaps returns the sorted IDs of NOT_IMPLEMENTED spec entries
+// that no plan entry serves: requirements nobody planned, as opposed to
+// planned work not yet done. A spec item is served when a traceability link
+// from a plan entry in plan names it. Without links nothing is known to be
+// served, but nothing is known to be unplanned either, so the result is nil
+// unless plan is empty, in which case every NOT_IMPLEMENTED item is a gap.
+func FindIntentGaps(spec []schema.SpecCoverageEntry, plan []schema.PlanCoverageEntry, links []schema.TraceLink) []string {
+	if links == nil && len(plan) > 0 {
+		return nil
+	}
+	planned := make(map[string]bool, len(plan))
+	for _, e := range plan {
+		planned[e.ID] = true
+	}
+	served := make(map[string]bool)
+	for _, l := range links {
+		if !planned[l.PlanID] {
+			continue
+		}
+		for _, id := range l.SpecIDs {
+			served[id] = true
+		}
+	}
+	var ids []string
+	for _, e := range spec {
+		if e.Status == schema.StatusNotImplemented && !served[e.ID] {
+			ids = append(ids, e.ID)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// noTestRequiredRe matches spec text that explicitly waives the test
+// requirement, e.g. "No test required" or "no tests are required".
+var noTestRequiredRe = regexp.MustCompile(`(?i)\bno\s+tests?\s+(?:is\s+|are\s+)?required\b`)
+
+// ExemptFromTests reports whether a spec item's text waives the test
+// requirement.
+func ExemptFromTests(text string) bool {
+	return noTestRequiredRe.MatchString(text)
+}
+
+// Adjustments recorded on coverage entries and evidence by the functions in
+// this package.
+const (
+	AdjustNoTests    = "status downgraded: no test evidence under --require-tests"
+	AdjustNoEvidence = "status downgraded: no evidence under --require-evidence"
+	AdjustTodo       = "status set to NOT_IMPLEMENTED: acknowledged by a TODO comment"
+	AdjustTodoCited  = "added: TODO comment naming the item"
+	AdjustNotEval    = "added: item not evaluated by model"
+)
+
+// RequireTests downgrades IMPLEMENTED spec entries to PARTIAL when none of
+// their evidence cites a file in testFiles. Entries whose ID is in exempt are
+// left alone. Entries are modified in place and record AdjustNoTests; the
+// IDs of downgraded entries are returned in order.
+func RequireTests(entries []schema.SpecCoverageEntry, testFiles, exempt map[string]bool) []string {
+	var downgraded []string
+	for i, e := range entries {
+		if e.Status != schema.StatusImplemented || exempt[e.ID] {
+			continue
+		}
+		tested := false
+		for _, ev := range e.Evidence {
+			if testFiles[ev.Path] {
+				tested = true
+				break
+			}
+		}
+		if tested {
+			continue
+		}
+		entries[i].Status = schema.StatusPartial
+		note := "Downgraded from IMPLEMENTED: no test evidence found."
+		if e.Notes != "" {
+			note = e.Notes + " " + note
+		}
+		entries[i].Notes = note
+		entries[i].Adjustments = append(entries[i].Adjustments, AdjustNoTests)
+		downgraded = append(downgraded, e.ID)
+	}
+	return downgraded
+}
+
+// noEvidenceNote is appended to the notes of entries downgraded by
+// RequireEvidence.
+const noEvidenceNote = "Downgraded from IMPLEMENTED: no evidence cited."
+
+// RequireEvidence downgrades IMPLEMENTED spec and plan entries that cite no
+// evidence to UNCLEAR, since the claim cannot be verified. Entries are
+// modified in place and record AdjustNoEvidence; the IDs of downgraded entries are returned in order,
+// spec entries first.
+func RequireEvidence(cov *schema.Coverage) []string {
+	var downgraded []string
+	downgrade := func(id string, status *schema.CoverageStatus, notes *string, adjustments *[]string) {
+		*status = schema.StatusUnclear
+		*adjustments = append(*adjustments, AdjustNoEvidence)
+		if *notes != "" {
+			*notes += " " + noEvidenceNote
+		} else {
+			*notes = noEvidenceNote
+		}
+		downgraded = append(downgraded, id)
+	}
+	for i, e := range cov.Spec {
+		if e.Status == schema.StatusImplemented && len(e.Evidence) == 0 {
+			downgrade(e.ID, &cov.Spec[i].Status, &cov.Spec[i].Notes, &cov.Spec[i].Adjustments)
+		}
+	}
+	for i, e := range cov.Plan {
+		if e.Status == schema.StatusImplemented && len(e.Evidence) == 0 {
+			downgrade(e.ID, &cov.Plan[i].Status, &cov.Plan[i].Notes, &cov.Plan[i].Adjustments)
+		}
+	}
+	return downgraded
+}
+
+// Todo is the location of a TODO or FIXME comment that names an item.
+type Todo struct {
+	Path string
+	Line int
+}
+
+// AcknowledgeTodos marks spec and plan entries named by a TODO or FIXME
+// comment as NOT_IMPLEMENTED, whatever the model reported: the comment is
+// the code's own statement that the item is not done. todos maps item IDs
+// to the comments naming them. Each comment's file is added as HIGH
+// confidence evidence and the comment locations are noted. Changed entries
+// record AdjustTodo and the added evidence AdjustTodoCited. Entries are
+// modified in place; the IDs of changed entries are returned in order, spec
+// entries first. Entries already NOT_IMPLEMENTED are left alone.
+func AcknowledgeTodos(cov *schema.Coverage, todos map[string][]Todo) []string {
+	var changed []string
+	mark := func(id string, status *schema.CoverageStatus, evidence *[]schema.Evidence, notes *string, adjustments *[]string) {
+		found := todos[id]
+		if len(found) == 0 || *status == schema.StatusNotImplemented {
+			return
+		}
+		*status = schema.StatusNotImplemented
+		*adjustments = append(*adjustments, AdjustTodo)
+		locs := make([]string, len(found))
+		for i, t := range found {
+			locs[i] = fmt.Sprintf("%s:%d", t.Path, t.Line)
+			*evidence = append(*evidence, schema.Evidence{
+				Path:        t.Path,
+				Confidence:  schema.ConfidenceHigh,
+				Adjustments: []string{AdjustTodoCited},
+			})
+		}
+		note := fmt.Sprintf("Marked NOT_IMPLEMENTED: acknowledged by a TODO at %s.", strings.Join(locs, ", "))
+		if *notes != "" {
+			note = *notes + " " + note
+		}
+		*notes = note
+		changed = append(changed, id)
+	}
+	for i := range cov.Spec {
+		e := &cov.Spec[i]
+		mark(e.ID, &e.Status, &e.Evidence, &e.Notes, &e.Adjustments)
+	}
+	for i := range cov.Plan {
+		e := &cov.Plan[i]
+		mark(e.ID, &e.Status, &e.Evidence, &e.Notes, &e.Adjustments)
+	}
+	return changed
+}
+
+// NotEvaluatedNote is the note on coverage entries added by FillMissing.
+const NotEvaluatedNote = "Item not evaluated by model."
+
+// FillMissing appends an UNCLEAR entry to cov for every spec and plan item
+// the model left out, so that every parsed item appears in the report. The
+// entry's reference is the item's line range, its note is NotEvaluatedNote
+// and it records AdjustNotEval. The IDs of the added entries are returned in item order.
+func FillMissing(cov *schema.Coverage, specItems, planItems []mdparse.Item) []string {
+	var added []string
+	seen := make(map[string]bool, len(cov.Spec))
+	for _, e := range cov.Spec {
+		seen[e.ID] = true
+	}
+	for _, item := range specItems {
+		if seen[item.ID] {
+			continue
+		}
+		seen[item.ID] = true
+		cov.Spec = append(cov.Spec, schema.SpecCoverageEntry{
+			ID:            item.ID,
+			Status:        schema.StatusUnclear,
+			SpecReference: schema.Reference{LineStart: item.LineStart, LineEnd: item.LineEnd},
+			Evidence:      []schema.Evidence{},
+			Notes:         NotEvaluatedNote,
+			Adjustments:   []string{AdjustNotEval},
+		})
+		added = append(added, item.ID)
+	}
+	seen = make(map[string]bool, len(cov.Plan))
+	for _, e := range cov.Plan {
+		seen[e.ID] = true
+	}
+	for _, item := range planItems {
+		if seen[item.ID] {
+			continue
+		}
+		seen[item.ID] = true
+		cov.Plan = append(cov.Plan, schema.PlanCoverageEntry{
+			ID:            item.ID,
+			Status:        schema.StatusUnclear,
+			PlanReference: schema.Reference{LineStart: item.LineStart, LineEnd: item.LineEnd},
+			Evidence:      []schema.Evidence{},
+			Notes:         NotEvaluatedNote,
+			Adjustments:   []string{AdjustNotEval},
+		})
+		added = append(added, item.ID)
+	}
+	return added
+}
@@ -1,8 +1,11 @@
 package coverage
 
 import (
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/dshills/realitycheck/internal/mdparse"
 	"github.com/dshills/realitycheck/internal/schema"
 )
 
@@ -137,3 +140,227 @@ func TestSummarizeSpecCoverage(t *testing.T) {
 		t.Errorf("unclear = %d, want 1", unclear)
 	}
 }
+
+func TestSummarizePlanCoverage(t *testing.T) {
+	entries := []schema.PlanCoverageEntry{
+		{Status: schema.StatusImplemented},
+		{Status: schema.StatusPartial},
+		{Status: schema.StatusPartial},
+		{Status: schema.StatusNotImplemented},
+	}
+	imp, part, miss, unclear := SummarizePlanCoverage(entries)
+	if imp != 1 || part != 2 || miss != 1 || unclear != 0 {
+		t.Errorf("counts = %d, %d, %d, %d, want 1, 2, 1, 0", imp, part, miss, unclear)
+	}
+}
+
+func TestPercent(t *testing.T) {
+	cases := []struct {
+		imp, part, miss, unclear int
+		want                     float64
+	}{
+		{2, 1, 1, 1, 50},
+		{1, 0, 2, 0, 33.3},
+		{0, 1, 0, 0, 50},
+		{3, 0, 0, 0, 100},
+		{0, 0, 1, 1, 0},
+	}
+	for _, c := range cases {
+		got := Percent(c.imp, c.part, c.miss, c.unclear)
+		if got == nil || *got != c.want {
+			t.Errorf("Percent(%d, %d, %d, %d) = %v, want %v", c.imp, c.part, c.miss, c.unclear, got, c.want)
+		}
+	}
+	if got := Percent(0, 0, 0, 0); got != nil {
+		t.Errorf("Percent with no entries = %v, want nil", *got)
+	}
+}
+
+func TestExemptFromTests(t *testing.T) {
+	cases := map[string]bool{
+		"Log startup time. No test required.":     true,
+		"Print a banner (no tests are required)":  true,
+		"NO TEST REQUIRED for the debug endpoint": true,
+		"The store must support Get(key).":        false,
+		"A test is required for every handler.":   false,
+	}
+	for text, want := range cases {
+		if got := ExemptFromTests(text); got != want {
+			t.Errorf("ExemptFromTests(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
+
+func TestRequireTests(t *testing.T) {
+	entries := []schema.SpecCoverageEntry{
+		{ID: "SPEC-001", Status: schema.StatusImplemented, Evidence: []schema.Evidence{{Path: "store.go"}, {Path: "store_test.go", Symbol: "TestGet"}}},
+		{ID: "SPEC-002", Status: schema.StatusImplemented, Evidence: []schema.Evidence{{Path: "store.go"}}, Notes: "Set is in store.go."},
+		{ID: "SPEC-003", Status: schema.StatusImplemented, Evidence: []schema.Evidence{{Path: "banner.go"}}},
+		{ID: "SPEC-004", Status: schema.StatusNotImplemented},
+	}
+	testFiles := map[string]bool{"store_test.go": true}
+	exempt := map[string]bool{"SPEC-003": true}
+
+	got := RequireTests(entries, testFiles, exempt)
+	if len(got) != 1 || got[0] != "SPEC-002" {
+		t.Fatalf("downgraded = %v, want [SPEC-002]", got)
+	}
+	if entries[1].Status != schema.StatusPartial {
+		t.Errorf("SPEC-002 status = %s, want PARTIAL", entries[1].Status)
+	}
+	if !strings.HasPrefix(entries[1].Notes, "Set is in store.go. ") || !strings.Contains(entries[1].Notes, "no test evidence") {
+		t.Errorf("SPEC-002 notes = %q, want original notes plus the downgrade reason", entries[1].Notes)
+	}
+	if !slices.Equal(entries[1].Adjustments, []string{AdjustNoTests}) || entries[0].Adjustments != nil {
+		t.Errorf("adjustments = %q, %q; want only SPEC-002 adjusted", entries[1].Adjustments, entries[0].Adjustments)
+	}
+	for _, i := range []int{0, 2} {
+		if entries[i].Status != schema.StatusImplemented {
+			t.Errorf("%s status = %s, want IMPLEMENTED", entries[i].ID, entries[i].Status)
+		}
+	}
+	if entries[3].Status != schema.StatusNotImplemented {
+		t.Errorf("SPEC-004 status = %s, want unchanged", entries[3].Status)
+	}
+}
+
+func TestRequireEvidence(t *testing.T) {
+	cov := schema.Coverage{
+		Spec: []schema.SpecCoverageEntry{
+			{ID: "SPEC-001", Status: schema.StatusImplemented, Evidence: []schema.Evidence{{Path: "store.go"}}},
+			{ID: "SPEC-002", Status: schema.StatusImplemented, Evidence: []schema.Evidence{}, Notes: "Looks done."},
+			{ID: "SPEC-003", Status: schema.StatusNotImplemented},
+		},
+		Plan: []schema.PlanCoverageEntry{
+			{ID: "PLAN-001", Status: schema.StatusImplemented},
+		},
+	}
+
+	got := RequireEvidence(&cov)
+	if len(got) != 2 || got[0] != "SPEC-002" || got[1] != "PLAN-001" {
+		t.Fatalf("downgraded = %v, want [SPEC-002 PLAN-001]", got)
+	}
+	if cov.Spec[1].Status != schema.StatusUnclear || cov.Spec[1].Notes != "Looks done. Downgraded from IMPLEMENTED: no evidence cited." {
+		t.Errorf("SPEC-002 = %+v, want UNCLEAR with the downgrade noted", cov.Spec[1])
+	}
+	if cov.Plan[0].Status != schema.StatusUnclear || !strings.Contains(cov.Plan[0].Notes, "no evidence") {
+		t.Errorf("PLAN-001 = %+v, want UNCLEAR with the downgrade noted", cov.Plan[0])
+	}
+	if !slices.Equal(cov.Spec[1].Adjustments, []string{AdjustNoEvidence}) || !slices.Equal(cov.Plan[0].Adjustments, []string{AdjustNoEvidence}) {
+		t.Errorf("adjustments = %q, %q; want %q", cov.Spec[1].Adjustments, cov.Plan[0].Adjustments, AdjustNoEvidence)
+	}
+	if cov.Spec[0].Status != schema.StatusImplemented || cov.Spec[2].Status != schema.StatusNotImplemented {
+		t.Errorf("other entries changed: %+v", cov.Spec)
+	}
+}
+
+func TestAcknowledgeTodos(t *testing.T) {
+	cov := schema.Coverage{
+		Spec: []schema.SpecCoverageEntry{
+			{ID: "SPEC-001", Status: schema.StatusImplemented, Evidence: []schema.Evidence{{Path: "store.go"}}, Notes: "Looks done."},
+			{ID: "SPEC-002", Status: schema.StatusImplemented},
+			{ID: "SPEC-003", Status: schema.StatusNotImplemented},
+		},
+		Plan: []schema.PlanCoverageEntry{
+			{ID: "PLAN-001", Status: schema.StatusPartial},
+		},
+	}
+	todos := map[string][]Todo{
+		"SPEC-001": {{Path: "store.go", Line: 12}, {Path: "cache.go", Line: 3}},
+		"SPEC-003": {{Path: "store.go", Line: 40}},
+		"PLAN-001": {{Path: "main.go", Line: 7}},
+		"SPEC-009": {{Path: "main.go", Line: 8}},
+	}
+
+	got := AcknowledgeTodos(&cov, todos)
+	if !slices.Equal(got, []string{"SPEC-001", "PLAN-001"}) {
+		t.Fatalf("changed = %v, want [SPEC-001 PLAN-001]", got)
+	}
+	e := cov.Spec[0]
+	if e.Status != schema.StatusNotImplemented ||
+		e.Notes != "Looks done. Marked NOT_IMPLEMENTED: acknowledged by a TODO at store.go:12, cache.go:3." {
+		t.Errorf("SPEC-001 = %+v, want NOT_IMPLEMENTED with the TODOs noted", e)
+	}
+	if len(e.Evidence) != 3 || e.Evidence[2].Path != "cache.go" || e.Evidence[2].Confidence != schema.ConfidenceHigh ||
+		!slices.Equal(e.Evidence[2].Adjustments, []string{AdjustTodoCited}) || e.Evidence[0].Adjustments != nil {
+		t.Errorf("SPEC-001 evidence = %+v, want the TODO files added with HIGH confidence", e.Evidence)
+	}
+	if !slices.Equal(e.Adjustments, []string{AdjustTodo}) {
+		t.Errorf("SPEC-001 adjustments = %q, want [%q]", e.Adjustments, AdjustTodo)
+	}
+	if cov.Plan[0].Status != schema.StatusNotImplemented {
+		t.Errorf("PLAN-001 status = %s, want NOT_IMPLEMENTED", cov.Plan[0].Status)
+	}
+	if cov.Spec[1].Status != schema.StatusImplemented || cov.Spec[2].Notes != "" {
+		t.Errorf("other entries changed: %+v", cov.Spec)
+	}
+}
+
+func TestUnmetIDs(t *testing.T) {
+	spec := []schema.SpecCoverageEntry{
+		{ID: "SPEC-003", Status: schema.StatusPartial},
+		{ID: "SPEC-001", Status: schema.StatusNotImplemented},
+		{ID: "SPEC-002", Status: schema.StatusImplemented},
+		{ID: "SPEC-004", Status: schema.StatusUnclear},
+	}
+	if got := UnmetSpecIDs(spec); !slices.Equal(got, []string{"SPEC-001", "SPEC-003"}) {
+		t.Errorf("UnmetSpecIDs = %v, want [SPEC-001 SPEC-003]", got)
+	}
+	plan := []schema.PlanCoverageEntry{{ID: "PLAN-001", Status: schema.StatusImplemented}}
+	if got := UnmetPlanIDs(plan); got == nil || len(got) != 0 {
+		t.Errorf("UnmetPlanIDs = %#v, want an empty non-nil slice", got)
+	}
+}
+
+func TestFindIntentGaps(t *testing.T) {
+	spec := []schema.SpecCoverageEntry{
+		{ID: "SPEC-003", Status: schema.StatusNotImplemented},
+		{ID: "SPEC-001", Status: schema.StatusNotImplemented},
+		{ID: "SPEC-002", Status: schema.StatusNotImplemented},
+		{ID: "SPEC-004", Status: schema.StatusPartial},
+	}
+	plan := []schema.PlanCoverageEntry{{ID: "PLAN-001", Status: schema.StatusNotImplemented}}
+	links := []schema.TraceLink{
+		{PlanID: "PLAN-001", SpecIDs: []string{"SPEC-002"}},
+		{PlanID: "PLAN-009", SpecIDs: []string{"SPEC-003"}}, // not a plan entry
+	}
+
+	if got := FindIntentGaps(spec, plan, links); !slices.Equal(got, []string{"SPEC-001", "SPEC-003"}) {
+		t.Errorf("with links = %v, want [SPEC-001 SPEC-003]", got)
+	}
+	if got := FindIntentGaps(spec, plan, nil); got != nil {
+		t.Errorf("without links = %v, want nil", got)
+	}
+	if got := FindIntentGaps(spec, nil, nil); !slices.Equal(got, []string{"SPEC-001", "SPEC-002", "SPEC-003"}) {
+		t.Errorf("without a plan = %v, want every NOT_IMPLEMENTED item", got)
+	}
+}
+
+func TestFillMissing(t *testing.T) {
+	cov := schema.Coverage{
+		Spec: []schema.SpecCoverageEntry{{ID: "SPEC-001", Status: schema.StatusImplemented}},
+		Plan: []schema.PlanCoverageEntry{},
+	}
+	specItems := []mdparse.Item{{ID: "SPEC-001"}, {ID: "SPEC-002", LineStart: 7, LineEnd: 8}}
+	planItems := []mdparse.Item{{ID: "PLAN-001", LineStart: 3, LineEnd: 3}}
+
+	added := FillMissing(&cov, specItems, planItems)
+	if !slices.Equal(added, []string{"SPEC-002", "PLAN-001"}) {
+		t.Errorf("added = %v, want [SPEC-002 PLAN-001]", added)
+	}
+	if len(cov.Spec) != 2 || cov.Spec[0].Status != schema.StatusImplemented {
+		t.Fatalf("spec coverage = %+v", cov.Spec)
+	}
+	e := cov.Spec[1]
+	if e.ID != "SPEC-002" || e.Status != schema.StatusUnclear || e.Notes != NotEvaluatedNote ||
+		e.SpecReference.LineStart != 7 || e.SpecReference.LineEnd != 8 || e.Evidence == nil ||
+		!slices.Equal(e.Adjustments, []string{AdjustNotEval}) {
+		t.Errorf("added spec entry = %+v", e)
+	}
+	if len(cov.Plan) != 1 || cov.Plan[0].ID != "PLAN-001" || cov.Plan[0].Status != schema.StatusUnclear {
+		t.Errorf("plan coverage = %+v", cov.Plan)
+	}
+	if again := FillMissing(&cov, specItems, planItems); len(again) != 0 {
+		t.Errorf("second FillMissing added %v", again)
+	}
+}
@@ -23,6 +23,46 @@ func EscalateSeverity(d schema.DriftFinding, strict bool) schema.DriftFinding {
 	return d
 }
 
+// DowngradeSeverity lowers a severity one step: CRITICAL → WARN,
+// WARN → INFO; INFO is unchanged.
+func DowngradeSeverity(s schema.Severity) schema.Severity {
+	switch s {
+	case schema.SeverityCritical:
+		return schema.SeverityWarn
+	case schema.SeverityWarn:
+		return schema.SeverityInfo
+	}
+	return s
+}
+
+// confidenceRank orders confidences from LOW to HIGH. An empty confidence
+// counts as LOW.
+func confidenceRank(c schema.Confidence) int {
+	switch c {
+	case schema.ConfidenceHigh:
+		return 2
+	case schema.ConfidenceMedium:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// WeakEvidence reports whether every evidence entry is below min. A finding
+// with no evidence counts as LOW confidence, so it is weak for any min above
+// LOW.
+func WeakEvidence(evidence []schema.Evidence, min schema.Confidence) bool {
+	if len(evidence) == 0 {
+		return confidenceRank(schema.ConfidenceLow) < confidenceRank(min)
+	}
+	for _, ev := range evidence {
+		if confidenceRank(ev.Confidence) >= confidenceRank(min) {
+			return false
+		}
+	}
+	return true
+}
+
 // ValidateDriftFinding returns field-level error messages for a drift finding.
 func ValidateDriftFinding(d schema.DriftFinding) []string {
 	var errs []string
@@ -59,3 +99,39 @@ func CountBySeverity(findings []schema.DriftFinding) (critical, warn, info int)
 	}
 	return
 }
+
+// NextID returns a function that yields IDs for drift findings appended to
+// findings: DRIFT-001 style, numbered after the highest existing DRIFT ID,
+// one higher on each call.
+func NextID(findings []schema.DriftFinding) func() string {
+	ids := make([]string, len(findings))
+	for i, d := range findings {
+		ids[i] = d.ID
+	}
+	return nextID("DRIFT", ids)
+}
+
+// NextViolationID is NextID for violations: it yields VIOLATION-001 style
+// IDs numbered after the highest existing VIOLATION ID.
+func NextViolationID(violations []schema.Violation) func() string {
+	ids := make([]string, len(violations))
+	for i, v := range violations {
+		ids[i] = v.ID
+	}
+	return nextID("VIOLATION", ids)
+}
+
+func nextID(prefix string, ids []string) func() string {
+	next := 1
+	for _, id := range ids {
+		var n int
+		if _, err := fmt.Sscanf(id, prefix+"-%d", &n); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+	return func() string {
+		id := fmt.Sprintf("%s-%03d", prefix, next)
+		next++
+		return id
+	}
+}
@@ -75,3 +75,54 @@ func TestCountBySeverity(t *testing.T) {
 		t.Errorf("info = %d, want 1", info)
 	}
 }
+
+func TestDowngradeSeverity(t *testing.T) {
+	cases := []struct {
+		input schema.Severity
+		want  schema.Severity
+	}{
+		{schema.SeverityCritical, schema.SeverityWarn},
+		{schema.SeverityWarn, schema.SeverityInfo},
+		{schema.SeverityInfo, schema.SeverityInfo},
+	}
+	for _, c := range cases {
+		if got := DowngradeSeverity(c.input); got != c.want {
+			t.Errorf("DowngradeSeverity(%q) = %q, want %q", c.input, got, c.want)
+		}
+	}
+}
+
+func TestWeakEvidence(t *testing.T) {
+	low := schema.Evidence{Path: "a.go", Confidence: schema.ConfidenceLow}
+	medium := schema.Evidence{Path: "b.go", Confidence: schema.ConfidenceMedium}
+	high := schema.Evidence{Path: "c.go", Confidence: schema.ConfidenceHigh}
+	unset := schema.Evidence{Path: "d.go"}
+	cases := []struct {
+		name     string
+		evidence []schema.Evidence
+		min      schema.Confidence
+		want     bool
+	}{
+		{"no evidence below MEDIUM", nil, schema.ConfidenceMedium, true},
+		{"no evidence at LOW", nil, schema.ConfidenceLow, false},
+		{"all low", []schema.Evidence{low, unset}, schema.ConfidenceMedium, true},
+		{"one meets min", []schema.Evidence{low, medium}, schema.ConfidenceMedium, false},
+		{"medium below HIGH", []schema.Evidence{medium}, schema.ConfidenceHigh, true},
+		{"high meets HIGH", []schema.Evidence{low, high}, schema.ConfidenceHigh, false},
+	}
+	for _, c := range cases {
+		if got := WeakEvidence(c.evidence, c.min); got != c.want {
+			t.Errorf("%s: WeakEvidence = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestNextID(t *testing.T) {
+	next := NextID([]schema.DriftFinding{{ID: "DRIFT-002"}, {ID: "DRIFT-007"}, {ID: "custom"}})
+	if a, b := next(), next(); a != "DRIFT-008" || b != "DRIFT-009" {
+		t.Errorf("IDs = %s, %s; want DRIFT-008, DRIFT-009", a, b)
+	}
+	if id := NextID(nil)(); id != "DRIFT-001" {
+		t.Errorf("first ID = %s, want DRIFT-001", id)
+	}
+}
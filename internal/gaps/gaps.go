@@ -0,0 +1,60 @@
+// Package gaps turns incomplete spec coverage into drift findings, so that
+// partly implemented items count toward the severity counts and score.
+package gaps
+
+import (
+	"fmt"
+
+	"github.com/dshills/realitycheck/internal/drift"
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+// Check returns a WARN drift finding for each PARTIAL or UNCLEAR spec entry.
+// The description carries the entry's notes and, when locations has one,
+// the location of the spec item, such as "SPEC.md:12-14". Findings follow
+// the order of specCov and cite the entry's evidence; their IDs are left
+// empty for the caller to assign.
+func Check(specCov []schema.SpecCoverageEntry, locations map[string]string) []schema.DriftFinding {
+	var out []schema.DriftFinding
+	for _, e := range specCov {
+		var why string
+		switch e.Status {
+		case schema.StatusPartial:
+			why = "is only partly implemented"
+		case schema.StatusUnclear:
+			why = "could not be confirmed as implemented"
+		default:
+			continue
+		}
+		subject := e.ID
+		if loc := locations[e.ID]; loc != "" {
+			subject = fmt.Sprintf("%s (%s)", e.ID, loc)
+		}
+		desc := fmt.Sprintf("%s is %s.", subject, e.Status)
+		if e.Notes != "" {
+			desc = fmt.Sprintf("%s is %s: %s", subject, e.Status, e.Notes)
+		}
+		out = append(out, schema.DriftFinding{
+			Severity:       schema.SeverityWarn,
+			Description:    desc,
+			Evidence:       e.Evidence,
+			WhyUnjustified: fmt.Sprintf("%s %s, and incomplete spec items are counted as findings.", e.ID, why),
+			Impact:         fmt.Sprintf("Behavior required by %s may be missing.", e.ID),
+			Recommendation: fmt.Sprintf("Complete %s and cite its evidence, or narrow the spec item to what is implemented.", e.ID),
+		})
+	}
+	return out
+}
+
+// Apply runs Check on report's spec coverage and appends the findings to
+// report.Drift, numbered by drift.NextID. It returns the number of
+// findings added.
+func Apply(report *schema.PartialReport, locations map[string]string) int {
+	nextID := drift.NextID(report.Drift)
+	found := Check(report.Coverage.Spec, locations)
+	for _, d := range found {
+		d.ID = nextID()
+		report.Drift = append(report.Drift, d)
+	}
+	return len(found)
+}
@@ -0,0 +1,39 @@
+package gaps
+
+import (
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+func TestCheck(t *testing.T) {
+	cov := []schema.SpecCoverageEntry{
+		{ID: "SPEC-001", Status: schema.StatusImplemented},
+		{ID: "SPEC-002", Status: schema.StatusPartial, Notes: "No expiry.", Evidence: []schema.Evidence{{Path: "session.go"}}},
+		{ID: "SPEC-003", Status: schema.StatusNotImplemented},
+		{ID: "SPEC-004", Status: schema.StatusUnclear},
+	}
+	got := Check(cov, map[string]string{"SPEC-002": "SPEC.md:7-9"})
+	if len(got) != 2 {
+		t.Fatalf("Check returned %d findings, want 2: %+v", len(got), got)
+	}
+	if d := got[0]; d.ID != "" || d.Severity != schema.SeverityWarn || d.Description != "SPEC-002 (SPEC.md:7-9) is PARTIAL: No expiry." || len(d.Evidence) != 1 {
+		t.Errorf("PARTIAL finding = %+v", d)
+	}
+	if d := got[1]; d.Description != "SPEC-004 is UNCLEAR." {
+		t.Errorf("UNCLEAR finding description = %q", d.Description)
+	}
+}
+
+func TestApply(t *testing.T) {
+	report := &schema.PartialReport{
+		Coverage: schema.Coverage{Spec: []schema.SpecCoverageEntry{{ID: "SPEC-001", Status: schema.StatusPartial}}},
+		Drift:    []schema.DriftFinding{{ID: "DRIFT-002"}},
+	}
+	if n := Apply(report, nil); n != 1 {
+		t.Fatalf("Apply added %d findings, want 1", n)
+	}
+	if got := report.Drift[1].ID; got != "DRIFT-003" {
+		t.Errorf("new finding ID = %q, want DRIFT-003", got)
+	}
+}
@@ -0,0 +1,65 @@
+// Package gitdiff lists the files changed in a git work tree since a given
+// revision, by running the git command.
+package gitdiff
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// ErrNotRepository reports that a directory is not inside a git work tree,
+// or that git is not installed.
+var ErrNotRepository = errors.New("gitdiff: not a git repository")
+
+// ChangedFiles returns the files under dir that differ from ref: files
+// changed in commits since ref, uncommitted changes to tracked files, and
+// untracked files not ignored by git. Paths are slash-separated, relative to
+// dir, and sorted. Files deleted since ref are included.
+func ChangedFiles(ctx context.Context, dir, ref string) ([]string, error) {
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return nil, fmt.Errorf("gitdiff: invalid revision %q", ref)
+	}
+	if _, err := git(ctx, dir, "rev-parse", "--is-inside-work-tree"); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrNotRepository, dir, err)
+	}
+	diff, err := git(ctx, dir, "diff", "--name-only", "--relative", ref, "--", ".")
+	if err != nil {
+		return nil, fmt.Errorf("gitdiff: diff %s: %w", ref, err)
+	}
+	untracked, err := git(ctx, dir, "ls-files", "--others", "--exclude-standard", "--", ".")
+	if err != nil {
+		return nil, fmt.Errorf("gitdiff: list untracked files: %w", err)
+	}
+
+	seen := map[string]bool{}
+	var out []string
+	for _, line := range strings.Split(diff+"\n"+untracked, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !seen[line] {
+			seen[line] = true
+			out = append(out, line)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// git runs git with args in dir and returns its standard output. On failure
+// the error includes git's standard error.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir, "-c", "core.quotepath=off"}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
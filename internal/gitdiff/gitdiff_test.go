@@ -0,0 +1,80 @@
+package gitdiff
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// run runs git in dir, failing the test on error.
+func run(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func write(t *testing.T, dir, rel, content string) {
+	t.Helper()
+	p := filepath.Join(dir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChangedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	run(t, repo, "init", "-q")
+	write(t, repo, ".gitignore", "*.log\n")
+	write(t, repo, "app/a.go", "package app\n")
+	write(t, repo, "app/b.go", "package app\n")
+	write(t, repo, "other/c.go", "package other\n")
+	run(t, repo, "add", ".")
+	run(t, repo, "commit", "-q", "-m", "base")
+	run(t, repo, "tag", "base")
+
+	write(t, repo, "app/b.go", "package app\n\nfunc B() {}\n") // committed change
+	run(t, repo, "commit", "-q", "-am", "change b")
+	write(t, repo, "app/a.go", "package app\n\nfunc A() {}\n") // uncommitted change
+	write(t, repo, "app/new.go", "package app\n")              // untracked
+	write(t, repo, "app/debug.log", "ignored\n")               // ignored
+	write(t, repo, "other/c.go", "package other\n\nfunc C() {}\n")
+
+	got, err := ChangedFiles(context.Background(), filepath.Join(repo, "app"), "base")
+	if err != nil {
+		t.Fatalf("ChangedFiles: %v", err)
+	}
+	// Paths are relative to dir, and changes outside it are left out.
+	if want := []string{"a.go", "b.go", "new.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ChangedFiles = %v, want %v", got, want)
+	}
+
+	if _, err := ChangedFiles(context.Background(), repo, "no-such-ref"); err == nil || errors.Is(err, ErrNotRepository) {
+		t.Errorf("unknown ref: err = %v, want a non-ErrNotRepository error", err)
+	}
+	if _, err := ChangedFiles(context.Background(), repo, "--output=x"); err == nil {
+		t.Error("a revision starting with - should be rejected")
+	}
+}
+
+func TestChangedFiles_NotRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(dir))
+	if _, err := ChangedFiles(context.Background(), dir, "HEAD"); !errors.Is(err, ErrNotRepository) {
+		t.Errorf("err = %v, want ErrNotRepository", err)
+	}
+}
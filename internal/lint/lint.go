@@ -0,0 +1,141 @@
+// Package lint checks SPEC.md and PLAN.md files for authoring mistakes that
+// the parsers accept silently but that skew an analysis, such as a missing
+// blank line that merges two requirements into one item.
+package lint
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dshills/realitycheck/internal/mdparse"
+)
+
+// Rules reported in Issue.Rule.
+const (
+	RuleEmptyItem     = "empty-item"
+	RuleUnclosedFence = "unclosed-fence"
+	RuleDuplicateID   = "duplicate-id"
+	RuleMergedItems   = "merged-items"
+	RuleLongItem      = "long-item"
+)
+
+// DefaultMaxItemLines is the MaxItemLines used when Options leaves it zero.
+const DefaultMaxItemLines = 12
+
+// Issue is one problem found in a file.
+type Issue struct {
+	Path    string
+	Line    int // 1-based
+	Rule    string
+	Message string
+}
+
+// String formats the issue as "path:line: rule: message".
+func (i Issue) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", i.Path, i.Line, i.Rule, i.Message)
+}
+
+// Options tunes the heuristics.
+type Options struct {
+	// MaxItemLines is the number of text lines, not counting code blocks
+	// and blank lines, above which an item is reported as likely
+	// mis-segmented. Zero means DefaultMaxItemLines.
+	MaxItemLines int
+}
+
+// Files lints the Markdown files at paths, read in order as one document
+// segmented by s. s.IDPrefix names the explicit IDs ("SPEC" or "PLAN").
+// Issues are ordered by file, then line.
+func Files(s mdparse.Segmenter, paths []string, opts Options) ([]Issue, error) {
+	if opts.MaxItemLines <= 0 {
+		opts.MaxItemLines = DefaultMaxItemLines
+	}
+	// Explicit IDs are resolved here rather than by the segmenter, which
+	// rejects duplicates outright and strips the labels from the text.
+	s.HonorExplicitIDs = false
+	isNum := s.IsNumberedItem
+	if isNum == nil {
+		isNum = mdparse.DefaultIsNumberedItem
+	}
+	labelRe := regexp.MustCompile(`^(` + regexp.QuoteMeta(s.IDPrefix) + `-\d+):\s*`)
+
+	var out []Issue
+	seen := map[string]string{} // explicit ID → location of its first use
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("lint: %w", err)
+		}
+		items, err := s.ParseFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("lint: %w", err)
+		}
+		lines := strings.Split(strings.ReplaceAll(string(b), "\r\n", "\n"), "\n")
+		fenced, open := mdparse.FencedLines(lines)
+
+		var issues []Issue
+		add := func(line int, rule, format string, args ...any) {
+			issues = append(issues, Issue{path, line, rule, fmt.Sprintf(format, args...)})
+		}
+		if open > 0 {
+			add(open, RuleUnclosedFence, "code fence is never closed; the rest of the file is read as one item")
+		}
+
+		covered := make([]bool, len(lines))
+		for _, it := range items {
+			for n := it.LineStart; n <= it.LineEnd && n <= len(lines); n++ {
+				covered[n-1] = true
+			}
+
+			if m := labelRe.FindStringSubmatch(it.Text); m != nil {
+				id := m[1]
+				if len(it.Text) == len(m[0]) {
+					add(it.LineStart, RuleEmptyItem, "%s has no text after its ID", id)
+				}
+				loc := fmt.Sprintf("%s:%d", path, it.LineStart)
+				if first, dup := seen[id]; dup {
+					add(it.LineStart, RuleDuplicateID, "%s is already used at %s", id, first)
+				} else {
+					seen[id] = loc
+				}
+			}
+
+			text := 0
+			for n := it.LineStart; n <= it.LineEnd && n <= len(lines); n++ {
+				line := lines[n-1]
+				if fenced[n-1] || strings.TrimSpace(line) == "" {
+					continue
+				}
+				text++
+				if n == it.LineStart {
+					continue
+				}
+				if m := labelRe.FindStringSubmatch(mdparse.StripListPrefix(line)); m != nil {
+					add(n, RuleMergedItems, "%s starts inside the item at line %d; add a blank line before it", m[1], it.LineStart)
+				}
+			}
+			if text > opts.MaxItemLines {
+				add(it.LineStart, RuleLongItem, "item spans %d lines of text (limit %d); a missing blank line may have merged several items", text, opts.MaxItemLines)
+			}
+		}
+
+		// A list item whose text is empty once its prefix is stripped, such
+		// as a bare "Step 3:", is dropped by the segmenter and never becomes
+		// an item.
+		for i, line := range lines {
+			if covered[i] || fenced[i] || mdparse.IsIndented(line) {
+				continue
+			}
+			if isNum(line) || mdparse.IsBullet(line) {
+				add(i+1, RuleEmptyItem, "list item has no text and is ignored")
+			}
+		}
+
+		sort.SliceStable(issues, func(a, b int) bool { return issues[a].Line < issues[b].Line })
+		out = append(out, issues...)
+	}
+	return out, nil
+}
@@ -0,0 +1,102 @@
+package lint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/plan"
+	"github.com/dshills/realitycheck/internal/spec"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// rules returns "file:line:rule" for each issue.
+func rules(issues []Issue) []string {
+	var out []string
+	for _, is := range issues {
+		out = append(out, fmt.Sprintf("%s:%d:%s", filepath.Base(is.Path), is.Line, is.Rule))
+	}
+	return out
+}
+
+func TestFiles_Spec(t *testing.T) {
+	dir := t.TempDir()
+	a := writeFile(t, dir, "a.md", `# Spec
+
+- SPEC-001: Users can log in.
+- SPEC-002:
+
+Passwords are hashed.
+SPEC-003: Sessions expire after an hour.
+
+`+"```go"+`
+func example() {}
+`)
+	b := writeFile(t, dir, "b.md", `- SPEC-001: Users can log out.
+`)
+
+	issues, err := Files(spec.Segmenter(), []string{a, b}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"a.md:4:empty-item",
+		"a.md:7:merged-items",
+		"a.md:9:unclosed-fence",
+		"b.md:1:duplicate-id",
+	}
+	if got := rules(issues); !reflect.DeepEqual(got, want) {
+		t.Errorf("issues = %v, want %v", got, want)
+	}
+	if msg := issues[3].Message; !strings.Contains(msg, a+":3") {
+		t.Errorf("duplicate message = %q, want the first use", msg)
+	}
+}
+
+func TestFiles_Plan(t *testing.T) {
+	dir := t.TempDir()
+	long := "Step 2: Build the parser.\n" + strings.Repeat("  More detail.\n", 4)
+	p := writeFile(t, dir, "PLAN.md", "# Plan\n\nStep 1:\n\n"+long+"\n```\nnot\ncounted\n```\n")
+
+	issues, err := Files(plan.Segmenter(), []string{p}, Options{MaxItemLines: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"PLAN.md:3:empty-item", "PLAN.md:5:long-item"}
+	if got := rules(issues); !reflect.DeepEqual(got, want) {
+		t.Errorf("issues = %v, want %v", got, want)
+	}
+
+	// With the default limit, only the empty step is reported.
+	issues, err = Files(plan.Segmenter(), []string{p}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 {
+		t.Errorf("issues = %v, want only the empty step", rules(issues))
+	}
+}
+
+func TestFiles_Clean(t *testing.T) {
+	issues, err := Files(spec.Segmenter(), []string{"../../testdata/aligned/SPEC.md"}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("issues = %v, want none", issues)
+	}
+	if _, err := Files(spec.Segmenter(), []string{"missing.md"}, Options{}); err == nil {
+		t.Error("missing file should be an error")
+	}
+}
@@ -0,0 +1,82 @@
+package llm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// cacheFormat is bumped whenever the cache entry layout or key derivation
+// changes, independently of the tool version.
+const cacheFormat = "1"
+
+// cacheKey returns the hex SHA-256 of everything that determines the model's
+// response: the prompts, provider, model, and temperature, plus the tool
+// version so an upgrade (new prompts, new validation rules) invalidates old
+// entries. Fields are length-prefixed so no two inputs share a key.
+func cacheKey(sysPrompt, userPrompt string, opts Options) string {
+	h := sha256.New()
+	for _, part := range []string{
+		cacheFormat,
+		opts.CacheVersion,
+		opts.Provider,
+		opts.Model,
+		strconv.FormatFloat(opts.Temperature, 'g', -1, 64),
+		sysPrompt,
+		userPrompt,
+	} {
+		fmt.Fprintf(h, "%d:%s\n", len(part), part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cachePath returns the file holding the raw response for key.
+func cachePath(dir, key string) string {
+	return filepath.Join(dir, key+".response")
+}
+
+// readCache returns the cached raw response for key. A missing entry is
+// reported as ok == false with a nil error.
+func readCache(dir, key string) (raw string, ok bool, err error) {
+	b, err := os.ReadFile(cachePath(dir, key))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("llm: read cache: %w", err)
+	}
+	return string(b), true, nil
+}
+
+// writeCache stores raw under key, creating dir if needed. The entry is
+// written to a temp file and renamed so concurrent runs never observe a
+// partial response.
+func writeCache(dir, key, raw string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("llm: create cache dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("llm: write cache: %w", err)
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.WriteString(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return fmt.Errorf("llm: write cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("llm: write cache: %w", err)
+	}
+	if err := os.Rename(tmpName, cachePath(dir, key)); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("llm: write cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/codeindex"
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+func TestCacheKey_Inputs(t *testing.T) {
+	base := Options{Provider: "anthropic", Model: "m", Temperature: 0.2, CacheVersion: "0.1.0"}
+	k := cacheKey("sys", "user", base)
+
+	variants := map[string]func() string{
+		"system prompt": func() string { return cacheKey("sys2", "user", base) },
+		"user prompt":   func() string { return cacheKey("sys", "user2", base) },
+		"model":         func() string { o := base; o.Model = "m2"; return cacheKey("sys", "user", o) },
+		"temperature":   func() string { o := base; o.Temperature = 0.3; return cacheKey("sys", "user", o) },
+		"provider":      func() string { o := base; o.Provider = "openai"; return cacheKey("sys", "user", o) },
+		"version":       func() string { o := base; o.CacheVersion = "0.2.0"; return cacheKey("sys", "user", o) },
+		// Moving text between fields must not collide.
+		"prompt boundary": func() string { return cacheKey("sysu", "ser", base) },
+	}
+	for name, fn := range variants {
+		if fn() == k {
+			t.Errorf("changing the %s should change the cache key", name)
+		}
+	}
+	if cacheKey("sys", "user", base) != k {
+		t.Error("cache key should be deterministic")
+	}
+	// Settings that do not affect the response are not part of the key.
+	o := base
+	o.MaxRetries = 5
+	o.Debug = true
+	if cacheKey("sys", "user", o) != k {
+		t.Error("retry and debug settings should not change the cache key")
+	}
+}
+
+func TestAnalyze_CacheHitSkipsProvider(t *testing.T) {
+	dir := t.TempDir()
+	opts := Options{Model: "test-model", CacheDir: dir, CacheVersion: "test"}
+
+	mp := &mockProvider{responses: []string{responseWithPath("internal/store/store.go")}, usage: Usage{PromptTokens: 10, CompletionTokens: 5}}
+	installMock(t, mp)
+	first, err := Analyze(context.Background(), nil, nil, testIndex(), loadGeneralProfile(t), opts)
+	if err != nil {
+		t.Fatalf("first Analyze: %v", err)
+	}
+	if mp.callCount != 1 {
+		t.Fatalf("first run: expected 1 provider call, got %d", mp.callCount)
+	}
+
+	second, err := Analyze(context.Background(), nil, nil, testIndex(), loadGeneralProfile(t), opts)
+	if err != nil {
+		t.Fatalf("second Analyze: %v", err)
+	}
+	if mp.callCount != 1 {
+		t.Errorf("cache hit should not call the provider, got %d calls", mp.callCount)
+	}
+	if len(second.Coverage.Spec) != len(first.Coverage.Spec) {
+		t.Errorf("cached report differs: %+v vs %+v", second.Coverage, first.Coverage)
+	}
+	if second.Meta.TotalTokens != 0 {
+		t.Errorf("cache hit should report zero tokens, got %d", second.Meta.TotalTokens)
+	}
+
+	// A different tool version misses.
+	opts.CacheVersion = "other"
+	if _, err := Analyze(context.Background(), nil, nil, testIndex(), loadGeneralProfile(t), opts); err != nil {
+		t.Fatalf("third Analyze: %v", err)
+	}
+	if mp.callCount != 2 {
+		t.Errorf("version change should miss the cache, got %d calls", mp.callCount)
+	}
+}
+
+func TestAnalyze_CacheHitZeroesUsage(t *testing.T) {
+	dir := t.TempDir()
+	opts := Options{Model: "test-model", CacheDir: dir, CacheVersion: "test"}
+
+	// The model reports token counts of its own in meta; a hit must not
+	// carry them over.
+	var r schema.PartialReport
+	if err := json.Unmarshal([]byte(responseWithPath("internal/store/store.go")), &r); err != nil {
+		t.Fatal(err)
+	}
+	r.Meta.PromptTokens, r.Meta.CompletionTokens, r.Meta.TotalTokens = 900, 90, 990
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mp := &mockProvider{responses: []string{string(b)}, usage: Usage{PromptTokens: 10, CompletionTokens: 5}}
+	installMock(t, mp)
+	if _, err := Analyze(context.Background(), nil, nil, testIndex(), loadGeneralProfile(t), opts); err != nil {
+		t.Fatalf("first Analyze: %v", err)
+	}
+
+	hit, err := Analyze(context.Background(), nil, nil, testIndex(), loadGeneralProfile(t), opts)
+	if err != nil {
+		t.Fatalf("second Analyze: %v", err)
+	}
+	if mp.callCount != 1 {
+		t.Fatalf("expected a cache hit, got %d provider calls", mp.callCount)
+	}
+	if m := hit.Meta; m.PromptTokens != 0 || m.CompletionTokens != 0 || m.TotalTokens != 0 {
+		t.Errorf("cache hit usage = %d/%d/%d, want zero", m.PromptTokens, m.CompletionTokens, m.TotalTokens)
+	}
+}
+
+func TestAnalyze_CacheStoresRepairedResponse(t *testing.T) {
+	dir := t.TempDir()
+	opts := Options{CacheDir: dir}
+	mp := &mockProvider{responses: []string{"bad json", minimalValidResponse()}}
+	installMock(t, mp)
+
+	if _, err := Analyze(context.Background(), nil, nil, codeindex.Index{}, loadGeneralProfile(t), opts); err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	entries, _ := filepath.Glob(filepath.Join(dir, "*.response"))
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 cache entry, got %d", len(entries))
+	}
+	b, err := os.ReadFile(entries[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != minimalValidResponse() {
+		t.Errorf("cache should hold the repaired response, got %q", b)
+	}
+}
+
+func TestAnalyze_InvalidResponseNotCached(t *testing.T) {
+	dir := t.TempDir()
+	mp := &mockProvider{responses: []string{"bad json"}}
+	installMock(t, mp)
+
+	_, err := Analyze(context.Background(), nil, nil, codeindex.Index{}, loadGeneralProfile(t), Options{CacheDir: dir})
+	if !errors.Is(err, ErrInvalidModelOutput) {
+		t.Fatalf("expected ErrInvalidModelOutput, got %v", err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("invalid responses must not be cached, found %d entries", len(entries))
+	}
+}
@@ -0,0 +1,248 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/dshills/realitycheck/internal/codeindex"
+	"github.com/dshills/realitycheck/internal/plan"
+	"github.com/dshills/realitycheck/internal/profile"
+	"github.com/dshills/realitycheck/internal/schema"
+	"github.com/dshills/realitycheck/internal/spec"
+)
+
+// analyzeChunked runs one analysis per top-level directory of index, each
+// against the full spec and plan, and merges the results. Partitions are
+// analyzed sequentially to stay within provider rate limits.
+func analyzeChunked(
+	ctx context.Context,
+	specItems []spec.Item,
+	planItems []plan.Item,
+	index codeindex.Index,
+	prof profile.Profile,
+	opts Options,
+) (*schema.PartialReport, error) {
+	// Create the provider once rather than once per partition. With
+	// fallbacks, a primary that cannot be created is left to analyzeOne,
+	// which moves on to the next provider.
+	if opts.Client == nil {
+		p, err := NewProvider(opts.Provider, opts.Model)
+		switch {
+		case err == nil:
+			opts.Client = p
+		case len(opts.Fallbacks) == 0:
+			return nil, fmt.Errorf("llm: create provider: %w", err)
+		}
+	}
+
+	parts := index.SplitByTopDir()
+	fragments := make([]*schema.PartialReport, 0, len(parts))
+	for _, part := range parts {
+		if opts.Inventory != nil {
+			fmt.Fprintf(opts.Inventory, "=== partition: %s ===\n", part.Dir)
+		}
+		r, err := analyzeOne(ctx, specItems, planItems, part.Index, prof, opts, scopeNote(part.Dir))
+		if err != nil {
+			return nil, fmt.Errorf("llm: partition %q: %w", part.Dir, err)
+		}
+		fragments = append(fragments, r)
+	}
+	return mergeReports(fragments, opts), nil
+}
+
+// scopeNote tells the model that the inventory is one partition, so that
+// missing evidence is not reported as a gap in the whole project.
+func scopeNote(dir string) string {
+	where := fmt.Sprintf("the top-level directory %q", dir)
+	if dir == codeindex.RootPartition {
+		where = "files in the repository root"
+	}
+	return "SCOPE: The CODE INVENTORY below covers only " + where + " of a larger repository; " +
+		"other directories are analyzed in separate requests and the results are merged. " +
+		"Cite evidence from this inventory only, mark spec and plan items with no evidence here as NOT_IMPLEMENTED, " +
+		"and report drift and violations only for code in this inventory.\n\n"
+}
+
+// statusRank orders coverage statuses from least to most implemented. When
+// partitions disagree, the most implemented status wins: an item built in
+// one directory is implemented even if other directories lack it.
+func statusRank(s schema.CoverageStatus) int {
+	switch s {
+	case schema.StatusImplemented:
+		return 3
+	case schema.StatusPartial:
+		return 2
+	case schema.StatusUnclear:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// severityRank orders severities so duplicate findings keep the highest.
+func severityRank(s schema.Severity) int {
+	switch s {
+	case schema.SeverityCritical:
+		return 2
+	case schema.SeverityWarn:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// mergeReports combines per-partition reports into one:
+//   - coverage entries with the same ID take the most implemented status
+//     (with that entry's notes and reference) and the union of evidence;
+//   - drift findings and violations with the same normalized description
+//     are merged, keeping the highest severity and the union of evidence;
+//   - traceability links for the same plan item take the union of spec IDs;
+//   - DRIFT and VIOLATION IDs are renumbered in merged order, except under
+//     a custom ID pattern in opts, where matching IDs are kept and only an
+//     ID already used by an earlier partition, or one that does not match,
+//     is reassigned (see reassignFindingIDs);
+//   - token counts are summed.
+func mergeReports(fragments []*schema.PartialReport, opts Options) *schema.PartialReport {
+	out := &schema.PartialReport{
+		Coverage: schema.Coverage{
+			Spec: []schema.SpecCoverageEntry{},
+			Plan: []schema.PlanCoverageEntry{},
+		},
+		Drift:      []schema.DriftFinding{},
+		Violations: []schema.Violation{},
+	}
+
+	specIdx := make(map[string]int)
+	planIdx := make(map[string]int)
+	driftIdx := make(map[string]int)
+	violIdx := make(map[string]int)
+	traceIdx := make(map[string]int)
+
+	for fi, f := range fragments {
+		if fi == 0 {
+			out.Meta.Model = f.Meta.Model
+			out.Meta.Temperature = f.Meta.Temperature
+		}
+		out.Meta.PromptTokens += f.Meta.PromptTokens
+		out.Meta.CompletionTokens += f.Meta.CompletionTokens
+		out.Meta.TotalTokens += f.Meta.TotalTokens
+		if f.Rationale != "" {
+			if out.Rationale != "" {
+				out.Rationale += " "
+			}
+			out.Rationale += f.Rationale
+		}
+
+		for _, e := range f.Coverage.Spec {
+			i, ok := specIdx[e.ID]
+			if !ok {
+				specIdx[e.ID] = len(out.Coverage.Spec)
+				e.Evidence = mergeEvidence(nil, e.Evidence)
+				out.Coverage.Spec = append(out.Coverage.Spec, e)
+				continue
+			}
+			cur := &out.Coverage.Spec[i]
+			evidence := mergeEvidence(cur.Evidence, e.Evidence)
+			if statusRank(e.Status) > statusRank(cur.Status) {
+				*cur = e
+			}
+			cur.Evidence = evidence
+		}
+		for _, e := range f.Coverage.Plan {
+			i, ok := planIdx[e.ID]
+			if !ok {
+				planIdx[e.ID] = len(out.Coverage.Plan)
+				e.Evidence = mergeEvidence(nil, e.Evidence)
+				out.Coverage.Plan = append(out.Coverage.Plan, e)
+				continue
+			}
+			cur := &out.Coverage.Plan[i]
+			evidence := mergeEvidence(cur.Evidence, e.Evidence)
+			if statusRank(e.Status) > statusRank(cur.Status) {
+				*cur = e
+			}
+			cur.Evidence = evidence
+		}
+
+		for _, d := range f.Drift {
+			key := normalizeDescription(d.Description)
+			i, ok := driftIdx[key]
+			if !ok {
+				driftIdx[key] = len(out.Drift)
+				d.Evidence = mergeEvidence(nil, d.Evidence)
+				out.Drift = append(out.Drift, d)
+				continue
+			}
+			cur := &out.Drift[i]
+			cur.Evidence = mergeEvidence(cur.Evidence, d.Evidence)
+			if severityRank(d.Severity) > severityRank(cur.Severity) {
+				cur.Severity = d.Severity
+			}
+		}
+		for _, v := range f.Violations {
+			key := normalizeDescription(v.Description)
+			i, ok := violIdx[key]
+			if !ok {
+				violIdx[key] = len(out.Violations)
+				v.Evidence = mergeEvidence(nil, v.Evidence)
+				out.Violations = append(out.Violations, v)
+				continue
+			}
+			cur := &out.Violations[i]
+			cur.Evidence = mergeEvidence(cur.Evidence, v.Evidence)
+			if severityRank(v.Severity) > severityRank(cur.Severity) {
+				cur.Severity = v.Severity
+			}
+			cur.Blocking = cur.Blocking || v.Blocking
+		}
+
+		for _, l := range f.Traceability {
+			i, ok := traceIdx[l.PlanID]
+			if !ok {
+				i = len(out.Traceability)
+				traceIdx[l.PlanID] = i
+				out.Traceability = append(out.Traceability, schema.TraceLink{PlanID: l.PlanID, SpecIDs: []string{}})
+			}
+			cur := &out.Traceability[i]
+			for _, id := range l.SpecIDs {
+				if !slices.Contains(cur.SpecIDs, id) {
+					cur.SpecIDs = append(cur.SpecIDs, id)
+				}
+			}
+		}
+	}
+
+	if opts.DriftIDPattern == nil {
+		renumberDrift(out.Drift)
+	}
+	if opts.ViolationIDPattern == nil {
+		renumberViolations(out.Violations)
+	}
+	reassignFindingIDs(out, opts)
+	return out
+}
+
+// mergeEvidence appends the entries of add not already in base (by path and
+// symbol) and returns a new slice; base is not modified.
+func mergeEvidence(base, add []schema.Evidence) []schema.Evidence {
+	out := make([]schema.Evidence, 0, len(base)+len(add))
+	seen := make(map[[2]string]bool, len(base)+len(add))
+	for _, list := range [][]schema.Evidence{base, add} {
+		for _, ev := range list {
+			k := [2]string{ev.Path, ev.Symbol}
+			if !seen[k] {
+				seen[k] = true
+				out = append(out, ev)
+			}
+		}
+	}
+	return out
+}
+
+// normalizeDescription is the dedup key for findings: lower-cased with
+// whitespace collapsed and trailing punctuation removed.
+func normalizeDescription(s string) string {
+	return strings.TrimRight(strings.Join(strings.Fields(strings.ToLower(s)), " "), ".!")
+}
@@ -0,0 +1,224 @@
+package llm
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/codeindex"
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+func TestMergeReports(t *testing.T) {
+	a := &schema.PartialReport{
+		Coverage: schema.Coverage{
+			Spec: []schema.SpecCoverageEntry{
+				{ID: "SPEC-001", Status: schema.StatusNotImplemented, Notes: "not in api"},
+				{ID: "SPEC-002", Status: schema.StatusPartial, Evidence: []schema.Evidence{{Path: "api/a.go", Symbol: "A"}}},
+			},
+			Plan: []schema.PlanCoverageEntry{{ID: "PLAN-001", Status: schema.StatusUnclear}},
+		},
+		Drift: []schema.DriftFinding{
+			{ID: "DRIFT-001", Severity: schema.SeverityInfo, Description: "Undocumented retry loop.", Evidence: []schema.Evidence{{Path: "api/a.go"}}},
+		},
+		Violations: []schema.Violation{
+			{ID: "VIOLATION-001", Severity: schema.SeverityWarn, Description: "Plaintext secrets", Evidence: []schema.Evidence{{Path: "api/a.go"}}},
+		},
+		Traceability: []schema.TraceLink{{PlanID: "PLAN-001", SpecIDs: []string{"SPEC-001"}}},
+		Meta:         schema.Meta{Model: "m", PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12},
+	}
+	b := &schema.PartialReport{
+		Coverage: schema.Coverage{
+			Spec: []schema.SpecCoverageEntry{
+				{ID: "SPEC-001", Status: schema.StatusImplemented, Notes: "store.Get", Evidence: []schema.Evidence{{Path: "store/s.go", Symbol: "Get"}}},
+				{ID: "SPEC-002", Status: schema.StatusPartial, Evidence: []schema.Evidence{{Path: "api/a.go", Symbol: "A"}, {Path: "store/s.go", Symbol: "Set"}}},
+			},
+			Plan: []schema.PlanCoverageEntry{{ID: "PLAN-001", Status: schema.StatusNotImplemented}},
+		},
+		Drift: []schema.DriftFinding{
+			{ID: "DRIFT-001", Severity: schema.SeverityWarn, Description: "undocumented  retry loop", Evidence: []schema.Evidence{{Path: "store/s.go"}}},
+			{ID: "DRIFT-002", Severity: schema.SeverityInfo, Description: "Extra cache", Evidence: []schema.Evidence{{Path: "store/s.go"}}},
+		},
+		Violations: []schema.Violation{
+			{ID: "VIOLATION-001", Severity: schema.SeverityCritical, Description: "SQL built by concatenation", Blocking: true},
+		},
+		Traceability: []schema.TraceLink{{PlanID: "PLAN-001", SpecIDs: []string{"SPEC-002", "SPEC-001"}}},
+		Meta:         schema.Meta{Model: "m", PromptTokens: 20, CompletionTokens: 3, TotalTokens: 23},
+	}
+
+	got := mergeReports([]*schema.PartialReport{a, b}, Options{})
+
+	if len(got.Coverage.Spec) != 2 {
+		t.Fatalf("spec coverage = %+v, want 2 entries", got.Coverage.Spec)
+	}
+	s1 := got.Coverage.Spec[0]
+	if s1.Status != schema.StatusImplemented || s1.Notes != "store.Get" || len(s1.Evidence) != 1 {
+		t.Errorf("SPEC-001 should take the implemented entry: %+v", s1)
+	}
+	if s2 := got.Coverage.Spec[1]; len(s2.Evidence) != 2 {
+		t.Errorf("SPEC-002 evidence should be the deduplicated union, got %+v", s2.Evidence)
+	}
+	if p1 := got.Coverage.Plan[0]; p1.Status != schema.StatusUnclear {
+		t.Errorf("PLAN-001 status = %s, want UNCLEAR over NOT_IMPLEMENTED", p1.Status)
+	}
+
+	if len(got.Drift) != 2 {
+		t.Fatalf("drift = %+v, want 2 after dedupe", got.Drift)
+	}
+	d1 := got.Drift[0]
+	if d1.ID != "DRIFT-001" || d1.Severity != schema.SeverityWarn || len(d1.Evidence) != 2 {
+		t.Errorf("merged retry-loop drift = %+v", d1)
+	}
+	if got.Drift[1].ID != "DRIFT-002" || got.Drift[1].Description != "Extra cache" {
+		t.Errorf("second drift = %+v", got.Drift[1])
+	}
+
+	if len(got.Violations) != 2 || got.Violations[1].ID != "VIOLATION-002" || !got.Violations[1].Blocking {
+		t.Errorf("violations should be renumbered in order: %+v", got.Violations)
+	}
+
+	if len(got.Traceability) != 1 || len(got.Traceability[0].SpecIDs) != 2 {
+		t.Errorf("traceability = %+v, want PLAN-001 linked to the union of spec IDs", got.Traceability)
+	}
+
+	if got.Meta.TotalTokens != 35 || got.Meta.PromptTokens != 30 || got.Meta.Model != "m" {
+		t.Errorf("meta = %+v, want summed tokens", got.Meta)
+	}
+}
+
+// promptRecorder records user prompts and returns a fixed response.
+type promptRecorder struct {
+	response string
+	prompts  []string
+}
+
+func (p *promptRecorder) Complete(_ context.Context, _, user string, _ int, _ float64) (CompletionResult, error) {
+	p.prompts = append(p.prompts, user)
+	return CompletionResult{Text: p.response}, nil
+}
+
+func TestAnalyze_ChunkByDir(t *testing.T) {
+	idx := codeindex.Index{
+		Files: []codeindex.FileEntry{{Path: "api/a.go"}, {Path: "store/s.go"}},
+		Symbols: []codeindex.SymbolEntry{
+			{Path: "api/a.go", Symbol: "Serve"},
+			{Path: "store/s.go", Symbol: "Get"},
+		},
+	}
+	rec := &promptRecorder{response: minimalValidResponse()}
+	opts := Options{Client: rec, ChunkByDir: true, ChunkThreshold: 1}
+
+	if _, err := Analyze(context.Background(), nil, nil, idx, loadGeneralProfile(t), opts); err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if len(rec.prompts) != 2 {
+		t.Fatalf("expected one call per top-level directory, got %d", len(rec.prompts))
+	}
+	if !strings.Contains(rec.prompts[0], `"api"`) || strings.Contains(rec.prompts[0], "store/s.go") {
+		t.Errorf("first prompt should be scoped to api only:\n%s", rec.prompts[0])
+	}
+
+	// At or below the threshold, a single unscoped call is made.
+	rec.prompts = nil
+	opts.ChunkThreshold = 2
+	if _, err := Analyze(context.Background(), nil, nil, idx, loadGeneralProfile(t), opts); err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if len(rec.prompts) != 1 || strings.Contains(rec.prompts[0], "SCOPE:") {
+		t.Errorf("expected one unscoped call below the threshold, got %d", len(rec.prompts))
+	}
+}
+
+func TestAnalyze_Inventory(t *testing.T) {
+	idx := codeindex.Index{
+		Files: []codeindex.FileEntry{{Path: "api/a.go"}, {Path: "store/s.go"}},
+		Symbols: []codeindex.SymbolEntry{
+			{Path: "api/a.go", Symbol: "Serve"},
+			{Path: "store/s.go", Symbol: "Get"},
+		},
+	}
+	rec := &promptRecorder{response: minimalValidResponse()}
+	var inv strings.Builder
+	opts := Options{Client: rec, Inventory: &inv}
+	if _, err := Analyze(context.Background(), nil, nil, idx, loadGeneralProfile(t), opts); err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if want := idx.SummaryWithLimit(0); inv.String() != want || !strings.Contains(rec.prompts[0], want) {
+		t.Errorf("inventory = %q, want the prompt's %q", inv.String(), want)
+	}
+
+	// A chunked analysis records each partition's inventory under its name.
+	inv.Reset()
+	opts.ChunkByDir, opts.ChunkThreshold = true, 1
+	if _, err := Analyze(context.Background(), nil, nil, idx, loadGeneralProfile(t), opts); err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	got := inv.String()
+	api, store := strings.Index(got, "=== partition: api ===\n"), strings.Index(got, "=== partition: store ===\n")
+	if api != 0 || store < 0 || strings.Contains(got[:store], "store/s.go") || !strings.Contains(got[store:], "store/s.go") {
+		t.Errorf("chunked inventory should list each partition under its own header:\n%s", got)
+	}
+}
+
+func TestBuildPrompts(t *testing.T) {
+	idx := codeindex.Index{
+		Files: []codeindex.FileEntry{{Path: "api/a.go"}, {Path: "store/s.go"}},
+		Symbols: []codeindex.SymbolEntry{
+			{Path: "api/a.go", Symbol: "Serve"},
+			{Path: "store/s.go", Symbol: "Get"},
+		},
+	}
+	prof := loadGeneralProfile(t)
+
+	single := BuildPrompts(nil, nil, idx, prof, Options{})
+	if len(single) != 1 || single[0].Partition != "" {
+		t.Fatalf("expected one unpartitioned prompt, got %+v", single)
+	}
+	if !strings.Contains(single[0].User, "api/a.go") || !strings.Contains(single[0].User, "store/s.go") {
+		t.Error("single prompt should include the whole inventory")
+	}
+
+	chunked := BuildPrompts(nil, nil, idx, prof, Options{ChunkByDir: true, ChunkThreshold: 1})
+	if len(chunked) != 2 || chunked[0].Partition != "api" || chunked[1].Partition != "store" {
+		t.Fatalf("expected api and store partitions, got %+v", chunked)
+	}
+	if !strings.HasPrefix(chunked[1].User, "SCOPE:") || strings.Contains(chunked[1].User, "api/a.go") {
+		t.Errorf("store prompt should be scoped to store only:\n%s", chunked[1].User)
+	}
+	if chunked[0].System != single[0].System {
+		t.Error("system prompt should not depend on chunking")
+	}
+}
+
+func TestMergeReports_KeepsIDsMatchingCustomPattern(t *testing.T) {
+	opts := Options{
+		DriftIDPattern:     regexp.MustCompile(`^DRIFT-\d+(-[a-z]+)?$`),
+		ViolationIDPattern: regexp.MustCompile(`^VIOLATION-\d+(-[a-z]+)?$`),
+	}
+	a := &schema.PartialReport{
+		Drift:      []schema.DriftFinding{{ID: "DRIFT-001-a", Description: "Extra cache"}},
+		Violations: []schema.Violation{{ID: "VIOLATION-002-x", Description: "Plaintext secrets"}},
+	}
+	b := &schema.PartialReport{
+		Drift: []schema.DriftFinding{
+			{ID: "DRIFT-001-a", Description: "Retry loop"},
+			{ID: "DRIFT-007", Description: "Metrics endpoint"},
+		},
+		Violations: []schema.Violation{{ID: "", Description: "SQL built by concatenation"}},
+	}
+
+	got := mergeReports([]*schema.PartialReport{a, b}, opts)
+
+	var driftIDs []string
+	for _, d := range got.Drift {
+		driftIDs = append(driftIDs, d.ID)
+	}
+	if want := []string{"DRIFT-001-a", "DRIFT-008", "DRIFT-007"}; !slices.Equal(driftIDs, want) {
+		t.Errorf("drift IDs = %v, want %v", driftIDs, want)
+	}
+	if len(got.Violations) != 2 || got.Violations[0].ID != "VIOLATION-002-x" || got.Violations[1].ID != "VIOLATION-003" {
+		t.Errorf("violations = %+v, want VIOLATION-002-x kept and the missing ID assigned VIOLATION-003", got.Violations)
+	}
+}
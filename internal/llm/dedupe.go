@@ -0,0 +1,146 @@
+package llm
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dshills/realitycheck/internal/drift"
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+// findingKey identifies equivalent findings: the normalized description and
+// the sorted, de-duplicated evidence paths.
+func findingKey(description string, evidence []schema.Evidence) string {
+	paths := make([]string, 0, len(evidence))
+	seen := make(map[string]bool, len(evidence))
+	for _, ev := range evidence {
+		if !seen[ev.Path] {
+			seen[ev.Path] = true
+			paths = append(paths, ev.Path)
+		}
+	}
+	sort.Strings(paths)
+	return normalizeDescription(description) + "\x00" + strings.Join(paths, "\x00")
+}
+
+// dedupeFindings collapses drift findings, and separately violations, that
+// share a findingKey. The first occurrence is kept with the highest severity
+// of the group and the union of evidence symbols; a violation is blocking if
+// any duplicate was. When anything was collapsed and the kind's ID pattern
+// is the default, its IDs are re-sequenced from DRIFT-001 / VIOLATION-001 so
+// there are no gaps; under a custom pattern kept IDs are left alone unless
+// they repeat an earlier finding's ID (see reassignFindingIDs). It reports
+// whether the report changed.
+func dedupeFindings(r *schema.PartialReport, opts Options) bool {
+	changed := false
+
+	driftIdx := make(map[string]int, len(r.Drift))
+	drifts := r.Drift[:0:0]
+	for _, d := range r.Drift {
+		key := findingKey(d.Description, d.Evidence)
+		i, ok := driftIdx[key]
+		if !ok {
+			driftIdx[key] = len(drifts)
+			drifts = append(drifts, d)
+			continue
+		}
+		cur := &drifts[i]
+		cur.Evidence = mergeEvidence(cur.Evidence, d.Evidence)
+		if severityRank(d.Severity) > severityRank(cur.Severity) {
+			cur.Severity = d.Severity
+		}
+	}
+	if len(drifts) < len(r.Drift) {
+		if opts.DriftIDPattern == nil {
+			renumberDrift(drifts)
+		}
+		r.Drift = drifts
+		changed = true
+	}
+
+	violIdx := make(map[string]int, len(r.Violations))
+	viols := r.Violations[:0:0]
+	for _, v := range r.Violations {
+		key := findingKey(v.Description, v.Evidence)
+		i, ok := violIdx[key]
+		if !ok {
+			violIdx[key] = len(viols)
+			viols = append(viols, v)
+			continue
+		}
+		cur := &viols[i]
+		cur.Evidence = mergeEvidence(cur.Evidence, v.Evidence)
+		if severityRank(v.Severity) > severityRank(cur.Severity) {
+			cur.Severity = v.Severity
+		}
+		cur.Blocking = cur.Blocking || v.Blocking
+	}
+	if len(viols) < len(r.Violations) {
+		if opts.ViolationIDPattern == nil {
+			renumberViolations(viols)
+		}
+		r.Violations = viols
+		changed = true
+	}
+
+	if reassignFindingIDs(r, opts) {
+		changed = true
+	}
+	return changed
+}
+
+// renumberDrift re-sequences the IDs of findings from DRIFT-001.
+func renumberDrift(findings []schema.DriftFinding) {
+	next := drift.NextID(nil)
+	for i := range findings {
+		findings[i].ID = next()
+	}
+}
+
+// renumberViolations re-sequences the IDs of violations from VIOLATION-001.
+func renumberViolations(violations []schema.Violation) {
+	next := drift.NextViolationID(nil)
+	for i := range violations {
+		violations[i].ID = next()
+	}
+}
+
+// reassignFindingIDs keeps each drift and violation ID that matches the
+// patterns in opts and is not already used by an earlier finding of the
+// same kind. Any other ID, including an empty one, is replaced by the next
+// free ID from drift.NextID or drift.NextViolationID. It reports whether
+// any ID changed.
+func reassignFindingIDs(r *schema.PartialReport, opts Options) bool {
+	driftRe, violationRe := opts.idPatterns()
+
+	driftIDs := make([]*string, len(r.Drift))
+	for i := range r.Drift {
+		driftIDs[i] = &r.Drift[i].ID
+	}
+	changed := reassignIDs(driftIDs, driftRe, drift.NextID(r.Drift))
+
+	violIDs := make([]*string, len(r.Violations))
+	for i := range r.Violations {
+		violIDs[i] = &r.Violations[i].ID
+	}
+	if reassignIDs(violIDs, violationRe, drift.NextViolationID(r.Violations)) {
+		changed = true
+	}
+	return changed
+}
+
+// reassignIDs replaces, in order, each ID that does not match re or repeats
+// an earlier one with next(). It reports whether any ID was replaced.
+func reassignIDs(ids []*string, re *regexp.Regexp, next func() string) bool {
+	changed := false
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if *id == "" || !re.MatchString(*id) || seen[*id] {
+			*id = next()
+			changed = true
+		}
+		seen[*id] = true
+	}
+	return changed
+}
@@ -0,0 +1,132 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/plan"
+	"github.com/dshills/realitycheck/internal/schema"
+	"github.com/dshills/realitycheck/internal/spec"
+)
+
+func TestAnalyze_DeduplicatesFindings(t *testing.T) {
+	ev := func(sym string) []schema.Evidence {
+		return []schema.Evidence{{Path: "internal/store/store.go", Symbol: sym, Confidence: schema.ConfidenceHigh}}
+	}
+	r := schema.PartialReport{
+		Coverage: schema.Coverage{Spec: []schema.SpecCoverageEntry{}, Plan: []schema.PlanCoverageEntry{}},
+		Drift: []schema.DriftFinding{
+			{ID: "DRIFT-001", Severity: schema.SeverityWarn, Description: "Undocumented retry loop", Evidence: ev("retry"), WhyUnjustified: "not in spec"},
+			{ID: "DRIFT-002", Severity: schema.SeverityInfo, Description: "Extra metrics endpoint", Evidence: ev("metrics"), WhyUnjustified: "not in spec"},
+			{ID: "DRIFT-003", Severity: schema.SeverityCritical, Description: "undocumented retry  loop.", Evidence: ev("backoff"), WhyUnjustified: "not in spec"},
+		},
+		Violations: []schema.Violation{
+			{ID: "VIOLATION-001", Severity: schema.SeverityWarn, Description: "Plaintext secrets", Evidence: ev("")},
+			{ID: "VIOLATION-002", Severity: schema.SeverityWarn, Description: "Plaintext secrets", Evidence: ev(""), Blocking: true},
+		},
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mp := &mockProvider{responses: []string{string(b)}}
+	installMock(t, mp)
+
+	report, err := Analyze(context.Background(), []spec.Item{}, []plan.Item{}, testIndex(), loadGeneralProfile(t), Options{})
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+
+	if len(report.Drift) != 2 {
+		t.Fatalf("drift = %+v, want 2 after dedupe", report.Drift)
+	}
+	d := report.Drift[0]
+	if d.ID != "DRIFT-001" || d.Severity != schema.SeverityCritical || len(d.Evidence) != 2 {
+		t.Errorf("merged drift = %+v, want DRIFT-001, CRITICAL, two evidence symbols", d)
+	}
+	if report.Drift[1].ID != "DRIFT-002" || report.Drift[1].Description != "Extra metrics endpoint" {
+		t.Errorf("second drift = %+v", report.Drift[1])
+	}
+
+	if len(report.Violations) != 1 {
+		t.Fatalf("violations = %+v, want 1 after dedupe", report.Violations)
+	}
+	if v := report.Violations[0]; v.ID != "VIOLATION-001" || !v.Blocking {
+		t.Errorf("merged violation = %+v, want VIOLATION-001 and blocking", v)
+	}
+}
+
+func TestDedupeFindings_DifferentEvidenceKept(t *testing.T) {
+	r := &schema.PartialReport{
+		Drift: []schema.DriftFinding{
+			{ID: "DRIFT-001", Description: "Extra cache", Evidence: []schema.Evidence{{Path: "a.go"}}},
+			{ID: "DRIFT-005", Description: "Extra cache", Evidence: []schema.Evidence{{Path: "b.go"}}},
+		},
+	}
+	if dedupeFindings(r, Options{}) {
+		t.Error("findings with different evidence paths should not be merged")
+	}
+	if r.Drift[1].ID != "DRIFT-005" {
+		t.Errorf("IDs should be left alone when nothing is merged, got %s", r.Drift[1].ID)
+	}
+}
+
+func TestDedupeFindings_RenumbersWithoutGaps(t *testing.T) {
+	ev := []schema.Evidence{{Path: "a.go"}}
+	r := &schema.PartialReport{
+		Drift: []schema.DriftFinding{
+			{ID: "DRIFT-001", Description: "Extra cache", Evidence: ev},
+			{ID: "DRIFT-002", Description: "extra cache.", Evidence: ev},
+			{ID: "DRIFT-003", Description: "Retry loop", Evidence: ev},
+			{ID: "DRIFT-004", Description: "Metrics endpoint", Evidence: ev},
+		},
+		Violations: []schema.Violation{
+			{ID: "VIOLATION-001", Description: "Plaintext secrets", Evidence: ev},
+			{ID: "VIOLATION-002", Description: "Plaintext secrets", Evidence: ev},
+			{ID: "VIOLATION-003", Description: "SQL built by concatenation", Evidence: ev},
+		},
+	}
+	if !dedupeFindings(r, Options{}) {
+		t.Fatal("duplicate findings should be collapsed")
+	}
+	var got []string
+	for _, d := range r.Drift {
+		got = append(got, d.ID)
+	}
+	for _, v := range r.Violations {
+		got = append(got, v.ID)
+	}
+	want := []string{"DRIFT-001", "DRIFT-002", "DRIFT-003", "VIOLATION-001", "VIOLATION-002"}
+	if !slices.Equal(got, want) {
+		t.Errorf("IDs = %v, want %v with no gaps", got, want)
+	}
+	if r.Drift[1].Description != "Retry loop" {
+		t.Errorf("DRIFT-002 = %+v, want the former DRIFT-003", r.Drift[1])
+	}
+}
+
+func TestDedupeFindings_KeepsIDsMatchingCustomPattern(t *testing.T) {
+	opts := Options{DriftIDPattern: regexp.MustCompile(`^DRIFT-\d+(-[a-z]+)?$`)}
+	r := &schema.PartialReport{
+		Drift: []schema.DriftFinding{
+			{ID: "DRIFT-001-a", Description: "Extra cache", Evidence: []schema.Evidence{{Path: "a.go"}}},
+			{ID: "DRIFT-002", Description: "extra cache.", Evidence: []schema.Evidence{{Path: "a.go"}}},
+			{ID: "DRIFT-003-b", Description: "Retry loop", Evidence: []schema.Evidence{{Path: "b.go"}}},
+			{ID: "DRIFT-003-b", Description: "Metrics endpoint", Evidence: []schema.Evidence{{Path: "c.go"}}},
+		},
+	}
+	if !dedupeFindings(r, opts) {
+		t.Fatal("duplicate findings should be collapsed")
+	}
+	var got []string
+	for _, d := range r.Drift {
+		got = append(got, d.ID)
+	}
+	want := []string{"DRIFT-001-a", "DRIFT-003-b", "DRIFT-004"}
+	if !slices.Equal(got, want) {
+		t.Errorf("IDs = %v, want %v: matching IDs kept, only the repeat reassigned", got, want)
+	}
+}
@@ -77,8 +77,8 @@ type singleResponseProvider struct {
 	response string
 }
 
-func (p *singleResponseProvider) Complete(ctx context.Context, system, user string, maxTokens int, temp float64) (string, error) {
-	return p.response, nil
+func (p *singleResponseProvider) Complete(ctx context.Context, system, user string, maxTokens int, temp float64) (CompletionResult, error) {
+	return CompletionResult{Text: p.response}, nil
 }
 
 func runGolden(t *testing.T, dir, response string) (*schema.PartialReport, error) {
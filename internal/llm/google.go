@@ -32,10 +32,10 @@ func (p *googleProvider) Complete(
 	systemPrompt, userPrompt string,
 	maxTokens int,
 	temperature float64,
-) (string, error) {
+) (CompletionResult, error) {
 	client, err := genai.NewClient(ctx, googleoption.WithAPIKey(p.apiKey))
 	if err != nil {
-		return "", fmt.Errorf("google: genai client: %w", err)
+		return CompletionResult{}, fmt.Errorf("google: genai client: %w", err)
 	}
 	defer client.Close()
 
@@ -53,7 +53,7 @@ func (p *googleProvider) Complete(
 
 	resp, err := m.GenerateContent(ctx, genai.Text(userPrompt))
 	if err != nil {
-		return "", fmt.Errorf("google: generate content: %w", err)
+		return CompletionResult{}, fmt.Errorf("google: generate content: %w", err)
 	}
 
 	var parts []string
@@ -68,7 +68,14 @@ func (p *googleProvider) Complete(
 		}
 	}
 	if len(parts) == 0 {
-		return "", fmt.Errorf("google: response contained no text content")
+		return CompletionResult{}, fmt.Errorf("google: response contained no text content")
 	}
-	return strings.Join(parts, ""), nil
+	var usage Usage
+	if md := resp.UsageMetadata; md != nil {
+		usage = Usage{
+			PromptTokens:     int(md.PromptTokenCount),
+			CompletionTokens: int(md.CandidatesTokenCount),
+		}
+	}
+	return CompletionResult{Text: strings.Join(parts, ""), Usage: usage}, nil
 }
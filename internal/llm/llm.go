@@ -7,14 +7,19 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"regexp"
 	"strings"
+	"time"
 
 	anthropic "github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
 
 	"github.com/dshills/realitycheck/internal/codeindex"
+	"github.com/dshills/realitycheck/internal/drift"
+	"github.com/dshills/realitycheck/internal/mdparse"
 	"github.com/dshills/realitycheck/internal/plan"
 	"github.com/dshills/realitycheck/internal/profile"
 	"github.com/dshills/realitycheck/internal/schema"
@@ -27,7 +32,57 @@ var ErrInvalidModelOutput = errors.New("llm: invalid model output after repair a
 
 // Provider is the interface for LLM backends.
 type Provider interface {
-	Complete(ctx context.Context, systemPrompt, userPrompt string, maxTokens int, temperature float64) (string, error)
+	Complete(ctx context.Context, systemPrompt, userPrompt string, maxTokens int, temperature float64) (CompletionResult, error)
+}
+
+// StreamingProvider is a Provider that can also stream its response.
+// CompleteStream writes the response text to w as it arrives and returns
+// the same result Complete would, so validation is unaffected. Providers
+// that do not implement it are called through Complete; see CompleteStream.
+type StreamingProvider interface {
+	Provider
+	CompleteStream(ctx context.Context, systemPrompt, userPrompt string, maxTokens int, temperature float64, w io.Writer) (CompletionResult, error)
+}
+
+// CompleteStream calls p.CompleteStream when p is a StreamingProvider.
+// Otherwise it calls p.Complete and writes the whole text to w at once.
+func CompleteStream(ctx context.Context, p Provider, systemPrompt, userPrompt string, maxTokens int, temperature float64, w io.Writer) (CompletionResult, error) {
+	if sp, ok := p.(StreamingProvider); ok {
+		return sp.CompleteStream(ctx, systemPrompt, userPrompt, maxTokens, temperature, w)
+	}
+	res, err := p.Complete(ctx, systemPrompt, userPrompt, maxTokens, temperature)
+	if err != nil {
+		return res, err
+	}
+	_, _ = io.WriteString(w, res.Text) // progress output only
+	return res, nil
+}
+
+// Usage records the token counts a provider reported for one completion.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// add returns the element-wise sum of u and o.
+func (u Usage) add(o Usage) Usage {
+	return Usage{
+		PromptTokens:     u.PromptTokens + o.PromptTokens,
+		CompletionTokens: u.CompletionTokens + o.CompletionTokens,
+	}
+}
+
+// CompletionResult is the output of a single Provider.Complete call.
+type CompletionResult struct {
+	Text  string
+	Usage Usage
+	// Model is the model that served the request as reported by the API,
+	// such as the dated snapshot behind an alias. Empty when the API does
+	// not say.
+	Model string
+	// Temperature is the sampling temperature the API reports it used, or
+	// nil when it does not report one.
+	Temperature *float64
 }
 
 // NewProvider is the factory for creating LLM providers. It is a package-level
@@ -35,6 +90,15 @@ type Provider interface {
 // Tests must restore the original value; use t.Cleanup to do so safely.
 var NewProvider func(providerName, model string) (Provider, error) = defaultNewProvider
 
+// ProviderConfig names a provider and the model to use with it.
+type ProviderConfig struct {
+	Name  string
+	Model string
+}
+
+// String returns c as "name:model".
+func (c ProviderConfig) String() string { return c.Name + ":" + c.Model }
+
 // Options configures an Analyze call.
 type Options struct {
 	Provider    string
@@ -43,6 +107,79 @@ type Options struct {
 	Temperature float64
 	Model       string
 	Debug       bool
+	// MaxRetries is the number of additional attempts made after a transient
+	// provider error (HTTP 429/500/502/503/529, or an empty response; see
+	// ErrEmptyResponse). Zero disables retries.
+	MaxRetries int
+	// RetryBaseDelay is the delay before the first retry; it doubles on each
+	// subsequent retry and has random jitter added.
+	RetryBaseDelay time.Duration
+	// CacheDir, if non-empty, is a directory of raw responses keyed by a
+	// hash of the prompts and model settings. A hit skips the provider call;
+	// the cached response is still validated.
+	CacheDir string
+	// CacheVersion is the tool version, mixed into cache keys so entries
+	// written by another version are never reused.
+	CacheVersion string
+	// Client, if non-nil, is used instead of calling NewProvider. Provider
+	// and Model are still recorded in cache keys.
+	Client Provider
+	// Fallbacks are tried in order when Provider fails: a provider error
+	// that survives retries, or output still invalid after repair. Only the
+	// last failure is returned. When fallbacks are set, Meta.Model records
+	// the provider and model that served the report as "name:model".
+	Fallbacks []ProviderConfig
+	// ChunkByDir analyzes the index one top-level directory at a time when
+	// it holds more than ChunkThreshold symbols, so large trees are not
+	// truncated by codeindex.Index.Summary.
+	ChunkByDir     bool
+	ChunkThreshold int
+	// RequireTests asks the model to cite the tests exercising each spec
+	// item as evidence, so that untested items can be detected afterwards.
+	RequireTests bool
+	// Traceability asks the model to map each plan item to the spec items
+	// it serves, returned in PartialReport.Traceability.
+	Traceability bool
+	// MaxIndexBytes is the byte limit for the code inventory in the user
+	// prompt. Zero uses codeindex.DefaultSummaryBytes.
+	MaxIndexBytes int
+	// SnippetBytes is the byte limit for the source snippets appended to the
+	// code inventory when the index has them (see
+	// codeindex.BuildOptions.SnippetLines). Zero sends no snippets. Snippets
+	// are source code, so setting this sends code to the provider.
+	SnippetBytes int
+	// SummaryOnly asks for a reduced report: a rationale and the three most
+	// severe findings, with no coverage. RequireTests and Traceability do
+	// not apply.
+	SummaryOnly bool
+	// Explain asks the model for a top-level rationale: a few plain-English
+	// sentences on why the verdict was reached. A response without one is
+	// repaired. Summary mode always asks for a rationale.
+	Explain bool
+	// Stream, if non-nil, receives the response text as it arrives, from
+	// every call including retries and the repair call. Each response is
+	// followed by a newline. See CompleteStream.
+	Stream io.Writer
+	// DriftIDPattern and ViolationIDPattern, if non-nil, replace the
+	// patterns that drift and violation IDs in a response must match,
+	// ^DRIFT-\d+$ and ^VIOLATION-\d+$. A relaxed pattern such as
+	// ^DRIFT-\d+(-[a-z]+)?$ accepts IDs from merged or renamed reports. A
+	// finding whose ID does not match is renumbered; see validateIDs.
+	DriftIDPattern     *regexp.Regexp
+	ViolationIDPattern *regexp.Regexp
+	// CodeRoot is the directory the index was built from. When set, an
+	// evidence path that is not indexed but starts with the root, or with
+	// its trailing directories, is resolved relative to it. See
+	// normalizeEvidencePath.
+	CodeRoot string
+	// Record, if non-nil, receives every response in the order received,
+	// with the prompts and settings that produced it, including responses
+	// served from the cache. See Transcript.
+	Record *Transcript
+	// Inventory, if non-nil, receives the code inventory of each prompt
+	// exactly as sent, truncation notice included. A chunked analysis
+	// writes a "=== partition: dir ===" line before each partition's.
+	Inventory *strings.Builder
 }
 
 // ValidationError records a single validation failure on an LLM response.
@@ -57,6 +194,14 @@ func (e ValidationError) Error() string {
 
 // Analyze builds a prompt, calls the LLM, validates the response, and performs
 // one repair attempt if validation fails. Returns a PartialReport or an error.
+//
+// Drift findings and violations the model repeated (same description and
+// evidence paths) are collapsed into one; see dedupeFindings.
+//
+// With Options.ChunkByDir, an index with more than ChunkThreshold symbols is
+// split by top-level directory and analyzed one partition per call; the
+// partial reports are then merged (see mergeReports), which also collapses
+// duplicates across partitions.
 func Analyze(
 	ctx context.Context,
 	specItems []spec.Item,
@@ -65,55 +210,245 @@ func Analyze(
 	prof profile.Profile,
 	opts Options,
 ) (*schema.PartialReport, error) {
-	provider, err := NewProvider(opts.Provider, opts.Model)
+	var report *schema.PartialReport
+	var err error
+	if opts.ChunkByDir && len(index.Symbols) > opts.ChunkThreshold {
+		report, err = analyzeChunked(ctx, specItems, planItems, index, prof, opts)
+	} else {
+		report, err = analyzeOne(ctx, specItems, planItems, index, prof, opts, "")
+		if err == nil {
+			dedupeFindings(report, opts)
+		}
+	}
 	if err != nil {
-		return nil, fmt.Errorf("llm: create provider: %w", err)
+		return nil, err
 	}
+	if opts.SummaryOnly {
+		keepTopFindings(report, summaryFindings)
+	}
+	return report, nil
+}
 
-	sysPrompt := buildSystemPrompt(prof, opts.Strict)
-	userPrompt := buildUserPrompt(specItems, planItems, index)
+// Prompt is one system/user prompt pair that Analyze would send.
+type Prompt struct {
+	// Partition is the top-level directory the prompt covers when the index
+	// is chunked (see Options.ChunkByDir), or empty for a single prompt.
+	Partition string
+	System    string
+	User      string
+}
+
+// BuildPrompts returns the prompts Analyze would send for these inputs,
+// without calling a provider: one prompt, or one per partition when the
+// index is chunked. Repair prompts are not included since they depend on
+// the model's response.
+func BuildPrompts(
+	specItems []spec.Item,
+	planItems []plan.Item,
+	index codeindex.Index,
+	prof profile.Profile,
+	opts Options,
+) []Prompt {
+	if !opts.ChunkByDir || len(index.Symbols) <= opts.ChunkThreshold {
+		sys, user, _ := buildPrompts(specItems, planItems, index, prof, opts, "")
+		return []Prompt{{System: sys, User: user}}
+	}
+	parts := index.SplitByTopDir()
+	out := make([]Prompt, 0, len(parts))
+	for _, part := range parts {
+		sys, user, _ := buildPrompts(specItems, planItems, part.Index, prof, opts, scopeNote(part.Dir))
+		out = append(out, Prompt{Partition: part.Dir, System: sys, User: user})
+	}
+	return out
+}
+
+// buildPrompts assembles the system and user prompts for one call, and
+// returns the code inventory the user prompt contains. A non-empty scope is
+// prepended to the user prompt.
+func buildPrompts(
+	specItems []spec.Item,
+	planItems []plan.Item,
+	index codeindex.Index,
+	prof profile.Profile,
+	opts Options,
+	scope string,
+) (system, user, inventory string) {
+	inventory = index.SummaryForSpec(specItems, planItems, opts.MaxIndexBytes)
+	snippets := index.SnippetsForSpec(specItems, planItems, opts.SnippetBytes)
+	return BuildSystemPrompt(prof, opts), scope + buildUserPrompt(specItems, planItems, inventory, snippets), inventory
+}
+
+// analyzeOne runs a single prompt → validate → repair cycle, moving on to
+// each of opts.Fallbacks in turn if it fails. A non-empty scope is prepended
+// to the user prompt to tell the model the inventory is one partition of a
+// larger tree.
+func analyzeOne(
+	ctx context.Context,
+	specItems []spec.Item,
+	planItems []plan.Item,
+	index codeindex.Index,
+	prof profile.Profile,
+	opts Options,
+	scope string,
+) (*schema.PartialReport, error) {
+	sysPrompt, userPrompt, inventory := buildPrompts(specItems, planItems, index, prof, opts, scope)
+	if opts.Inventory != nil {
+		opts.Inventory.WriteString(inventory)
+	}
 
 	if opts.Debug {
-		// Debug prints prompts to stderr. No redaction is needed because code
-		// content is never included in the prompt — only file paths, symbol
-		// names, manifest text, and profile addendums. (Per PLAN.md §12.)
+		// Debug prints prompts to stderr. No redaction is applied. By default
+		// code content is never included in the prompt — only file paths,
+		// symbol names and signatures, manifest text, and profile addendums
+		// (per PLAN.md §12) — but with SnippetBytes set the prompt contains
+		// source snippets, and so does this dump.
 		fmt.Fprintf(os.Stderr, "=== DEBUG: system prompt ===\n%s\n", sysPrompt)
 		fmt.Fprintf(os.Stderr, "=== DEBUG: user prompt ===\n%s\n", userPrompt)
 	}
 
-	raw, err := provider.Complete(ctx, sysPrompt, userPrompt, opts.MaxTokens, opts.Temperature)
+	chain := append([]ProviderConfig{{opts.Provider, opts.Model}}, opts.Fallbacks...)
+	var lastErr error
+	for i, c := range chain {
+		copts := opts
+		copts.Provider, copts.Model = c.Name, c.Model
+		if i > 0 {
+			copts.Client = nil
+		}
+		report, err := analyzeWith(ctx, sysPrompt, userPrompt, index, copts)
+		if err == nil {
+			if len(chain) > 1 {
+				report.Meta.Model = c.Name + ":" + report.Meta.Model
+			}
+			return report, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil || i == len(chain)-1 {
+			break
+		}
+		fmt.Fprintf(os.Stderr, "warning: provider %s failed, falling back to %s: %v\n", c, chain[i+1], err)
+	}
+	return nil, lastErr
+}
+
+// analyzeWith runs the cache lookup → call → validate → repair cycle against
+// opts.Provider.
+func analyzeWith(ctx context.Context, sysPrompt, userPrompt string, index codeindex.Index, opts Options) (*schema.PartialReport, error) {
+	var key string
+	if opts.CacheDir != "" {
+		key = cacheKey(sysPrompt, userPrompt, opts)
+		raw, ok, err := readCache(opts.CacheDir, key)
+		if err != nil {
+			// An unreadable entry is treated as a miss, like a failed write.
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+		if ok {
+			// A cached entry is only ever written after it passed validation,
+			// but validate again: evidence paths are checked against the
+			// current index. No tokens were spent, so usage is zeroed over
+			// any counts the cached response carries.
+			if report, errs := validateResponse(raw, index, opts); report != nil && !NeedsRepair(errs) {
+				if opts.Record != nil {
+					opts.Record.add(sysPrompt, userPrompt, CompletionResult{Text: raw}, opts, true)
+				}
+				applyUsage(&report.Meta, Usage{})
+				applyResponseMeta(&report.Meta, CompletionResult{}, opts)
+				return report, nil
+			}
+		}
+	}
+
+	provider := opts.Client
+	if provider == nil {
+		var err error
+		provider, err = NewProvider(opts.Provider, opts.Model)
+		if err != nil {
+			return nil, fmt.Errorf("llm: create provider: %w", err)
+		}
+	}
+	if opts.Record != nil {
+		provider = &recordingProvider{p: provider, t: opts.Record, opts: opts}
+	}
+
+	res, err := completeWithRetry(ctx, provider, sysPrompt, userPrompt, opts)
 	if err != nil {
 		return nil, fmt.Errorf("llm: complete: %w", err)
 	}
+	usage := res.Usage
 
-	report, validationErrs := ValidateResponse(raw, index)
-	if report != nil && !needsRepair(validationErrs) {
+	report, validationErrs := validateResponse(res.Text, index, opts)
+	if report != nil && !NeedsRepair(validationErrs) {
 		// Non-fatal validation errors (e.g., evidence path mismatches) were
 		// applied in-place by ValidateResponse; return the adjusted report.
+		applyUsage(&report.Meta, usage)
+		applyResponseMeta(&report.Meta, res, opts)
+		storeCache(opts.CacheDir, key, res.Text)
 		return report, nil
 	}
 
 	// One repair attempt: include the original prompt and the invalid response
 	// so the LLM has full context.
-	repairPrompt := buildRepairPrompt(userPrompt, raw, validationErrs)
-	raw2, err := provider.Complete(ctx, sysPrompt, repairPrompt, opts.MaxTokens, opts.Temperature)
+	repairPrompt := buildRepairPrompt(userPrompt, res.Text, validationErrs)
+	res2, err := completeWithRetry(ctx, provider, sysPrompt, repairPrompt, opts)
 	if err != nil {
 		return nil, fmt.Errorf("llm: repair complete: %w", err)
 	}
+	usage = usage.add(res2.Usage)
 
-	report2, validationErrs2 := ValidateResponse(raw2, index)
-	if report2 != nil && !needsRepair(validationErrs2) {
+	report2, validationErrs2 := validateResponse(res2.Text, index, opts)
+	if report2 != nil && !NeedsRepair(validationErrs2) {
+		applyUsage(&report2.Meta, usage)
+		applyResponseMeta(&report2.Meta, res2, opts)
+		storeCache(opts.CacheDir, key, res2.Text)
 		return report2, nil
 	}
 
 	return nil, ErrInvalidModelOutput
 }
 
-// needsRepair returns true when validation errors include a parse or
-// required-field failure that requires a retry.
-func needsRepair(errs []ValidationError) bool {
+// storeCache records a validated raw response when caching is enabled. A
+// failed write only costs a future cache miss, so it is reported as a warning
+// rather than failing the run.
+func storeCache(dir, key, raw string) {
+	if dir == "" {
+		return
+	}
+	if err := writeCache(dir, key, raw); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+}
+
+// applyUsage records the provider-reported token usage in meta, overwriting
+// any values the model may have emitted itself.
+func applyUsage(meta *schema.Meta, u Usage) {
+	meta.PromptTokens = u.PromptTokens
+	meta.CompletionTokens = u.CompletionTokens
+	meta.TotalTokens = u.PromptTokens + u.CompletionTokens
+}
+
+// applyResponseMeta records the model and temperature that produced a
+// response in meta, overwriting whatever the model emitted itself. The
+// values the API reports in res win, since a provider may resolve an alias
+// or clamp the temperature; otherwise the requested values in opts are
+// used.
+func applyResponseMeta(meta *schema.Meta, res CompletionResult, opts Options) {
+	switch {
+	case res.Model != "":
+		meta.Model = res.Model
+	case opts.Model != "":
+		meta.Model = opts.Model
+	}
+	meta.Temperature = opts.Temperature
+	if res.Temperature != nil {
+		meta.Temperature = *res.Temperature
+	}
+}
+
+// NeedsRepair reports whether errs include a parse, schema, or
+// required-field failure, for which Analyze asks the model for a repaired
+// response. Other validation errors are notes on an accepted report.
+func NeedsRepair(errs []ValidationError) bool {
 	for _, e := range errs {
-		if e.Field == "json_parse" || e.Field == "required_field" {
+		if e.Field == "json_parse" || e.Field == "schema" || e.Field == "required_field" {
 			return true
 		}
 	}
@@ -151,9 +486,26 @@ func stripMarkdownFences(s string) string {
 // Leading/trailing markdown fences are stripped before parsing.
 // Non-fatal issues (e.g., fabricated evidence paths) are applied in-place
 // (confidence downgraded to LOW) and recorded as ValidationErrors.
-// Fatal issues (parse failure, missing required fields) are also recorded.
-// Returns nil report only on parse failure or missing required fields.
+// Fatal issues (parse failure, schema violations, missing required fields)
+// are also recorded. Returns nil report only on a fatal issue.
 func ValidateResponse(raw string, index codeindex.Index) (*schema.PartialReport, []ValidationError) {
+	return validateResponse(raw, index, Options{})
+}
+
+// ValidateResponseWithOptions is ValidateResponse with the settings in opts
+// that Analyze validates with, such as CodeRoot for resolving evidence paths
+// and the ID patterns.
+func ValidateResponseWithOptions(raw string, index codeindex.Index, opts Options) (*schema.PartialReport, []ValidationError) {
+	return validateResponse(raw, index, opts)
+}
+
+// validateResponse is ValidateResponse for the response shape opts asked
+// for. With opts.SummaryOnly it expects the reduced shape of
+// summaryOutputSchema: coverage is not required and is returned empty, and
+// traceability is dropped. With opts.Explain a non-blank rationale is
+// required.
+func validateResponse(raw string, index codeindex.Index, opts Options) (*schema.PartialReport, []ValidationError) {
+	summary := opts.SummaryOnly
 	var errs []ValidationError
 
 	raw = stripMarkdownFences(raw)
@@ -161,10 +513,11 @@ func ValidateResponse(raw string, index codeindex.Index) (*schema.PartialReport,
 	// 1. JSON parse. If parsing fails due to invalid escape sequences (common
 	// when LLM output includes regex patterns like \d+ inside JSON strings),
 	// attempt a one-shot sanitization before giving up.
-	var report schema.PartialReport
-	if err := json.Unmarshal([]byte(raw), &report); err != nil {
+	if !json.Valid([]byte(raw)) {
 		fixed := fixInvalidJSONEscapes(raw)
-		if err2 := json.Unmarshal([]byte(fixed), &report); err2 != nil {
+		if !json.Valid([]byte(fixed)) {
+			var v any
+			err := json.Unmarshal([]byte(raw), &v)
 			errs = append(errs, ValidationError{
 				Field:   "json_parse",
 				Message: err.Error(),
@@ -175,7 +528,34 @@ func ValidateResponse(raw string, index codeindex.Index) (*schema.PartialReport,
 		raw = fixed
 	}
 
-	// 2. Required field check.
+	// 2. Structural check against the embedded JSON Schema. This catches
+	// type mismatches and missing fields that json.Unmarshal would reject
+	// with a single opaque error or silently leave at their zero value.
+	sch := partialReportSchema
+	switch {
+	case summary:
+		sch = summaryReportSchema
+	case opts.Explain:
+		sch = explainReportSchema
+	}
+	errs = append(errs, validateSchema([]byte(raw), sch)...)
+
+	var report schema.PartialReport
+	if err := json.Unmarshal([]byte(raw), &report); err != nil {
+		if len(errs) == 0 {
+			errs = append(errs, ValidationError{
+				Field:   "json_parse",
+				Message: err.Error(),
+			})
+		}
+		return nil, errs
+	}
+
+	// 3. Required field check.
+	if summary {
+		report.Coverage = schema.Coverage{Spec: []schema.SpecCoverageEntry{}, Plan: []schema.PlanCoverageEntry{}}
+		report.Traceability = nil
+	}
 	if report.Coverage.Spec == nil {
 		errs = append(errs, ValidationError{
 			Field:   "required_field",
@@ -192,15 +572,21 @@ func ValidateResponse(raw string, index codeindex.Index) (*schema.PartialReport,
 		return nil, errs
 	}
 
-	// 3. Enum validation.
+	// 4. Enum validation.
 	errs = append(errs, validateEnums(&report)...)
 
-	// 4. ID format check.
-	errs = append(errs, validateIDs(&report)...)
+	// 5. ID format check — renumber findings with malformed IDs.
+	errs = append(errs, validateIDs(&report, opts)...)
 
-	// 5. Evidence path check — downgrade confidence on fabricated paths.
+	// 6. Evidence path and symbol check — normalize paths, then downgrade
+	// confidence on fabricated citations. Only deterministic checks record
+	// adjustments, so any the model supplied are dropped first.
+	clearAdjustments(&report)
 	filePaths := indexFilePaths(index)
-	validateEvidencePaths(&report, filePaths, &errs)
+	validateEvidencePaths(&report, filePaths, indexFileSymbols(index), opts.CodeRoot, index.PathPrefix, &errs)
+
+	// 7. Traceability check — drop links to unknown items.
+	validateTraceability(&report, &errs)
 
 	return &report, errs
 }
@@ -225,6 +611,20 @@ var (
 	violationIDRe = regexp.MustCompile(`^VIOLATION-\d+$`)
 )
 
+// idPatterns returns the patterns drift and violation IDs must match:
+// DriftIDPattern and ViolationIDPattern, or driftIDRe and violationIDRe
+// where those are nil.
+func (o Options) idPatterns() (driftRe, violationRe *regexp.Regexp) {
+	driftRe, violationRe = driftIDRe, violationIDRe
+	if o.DriftIDPattern != nil {
+		driftRe = o.DriftIDPattern
+	}
+	if o.ViolationIDPattern != nil {
+		violationRe = o.ViolationIDPattern
+	}
+	return driftRe, violationRe
+}
+
 // invalidJSONEscapeRe matches a backslash followed by any character that is not
 // a valid JSON string escape character ("\/bfnrtu). LLMs sometimes emit regex
 // patterns (e.g. \d+, \w+) unescaped inside JSON strings; this sanitizer
@@ -303,72 +703,249 @@ func validateEnums(r *schema.PartialReport) []ValidationError {
 	return errs
 }
 
-// validateIDs checks that drift and violation IDs match the expected formats.
-func validateIDs(r *schema.PartialReport) []ValidationError {
+// validateIDs checks that drift and violation IDs match the formats in opts,
+// or driftIDRe and violationIDRe when opts leaves them nil. A finding whose
+// ID does not match gets the next free DRIFT-NNN or VIOLATION-NNN ID (see
+// drift.NextID), so the report's IDs do not depend on how the model wrote
+// them. Each renumbering is noted in the returned errors.
+func validateIDs(r *schema.PartialReport, opts Options) []ValidationError {
+	driftRe, violationRe := opts.idPatterns()
 	var errs []ValidationError
+	nextDrift := drift.NextID(r.Drift)
 	for i, d := range r.Drift {
-		if !driftIDRe.MatchString(d.ID) {
+		if !driftRe.MatchString(d.ID) {
+			r.Drift[i].ID = nextDrift()
 			errs = append(errs, ValidationError{
 				Field:   fmt.Sprintf("drift[%d].id", i),
-				Message: fmt.Sprintf("id %q does not match DRIFT-\\d+", d.ID),
+				Message: fmt.Sprintf("id %q does not match %s; renumbered %s", d.ID, driftRe, r.Drift[i].ID),
 			})
 		}
 	}
+	nextViolation := drift.NextViolationID(r.Violations)
 	for i, v := range r.Violations {
-		if !violationIDRe.MatchString(v.ID) {
+		if !violationRe.MatchString(v.ID) {
+			r.Violations[i].ID = nextViolation()
 			errs = append(errs, ValidationError{
 				Field:   fmt.Sprintf("violations[%d].id", i),
-				Message: fmt.Sprintf("id %q does not match VIOLATION-\\d+", v.ID),
+				Message: fmt.Sprintf("id %q does not match %s; renumbered %s", v.ID, violationRe, r.Violations[i].ID),
 			})
 		}
 	}
 	return errs
 }
 
-// validateEvidencePaths checks each evidence path against the index. Paths not
-// found in the index have their confidence downgraded to LOW. Errors are appended
-// to errs; the report is modified in place.
-func validateEvidencePaths(r *schema.PartialReport, filePaths map[string]bool, errs *[]ValidationError) {
+// normalizeEvidencePath returns p in the form the index uses, cleaned and
+// with forward slashes, so "./store.go", "store.go/", and ".\store.go" all
+// become "store.go". If the cleaned path is not in filePaths but begins with
+// codeRoot, or with the trailing directories of codeRoot as in
+// "testdata/drift/store.go" for a root ending in testdata/drift, the prefix
+// is removed when what remains is indexed. When the index was built with a
+// path prefix, a path relative to the code root instead, such as "store.go"
+// for "internal/api/store.go", gets the prefix added.
+func normalizeEvidencePath(p, codeRoot, pathPrefix string, filePaths map[string]bool) string {
+	p = path.Clean(strings.ReplaceAll(p, "\\", "/"))
+	indexed := func(rel string) (string, bool) {
+		if filePaths[rel] {
+			return rel, true
+		}
+		if pathPrefix != "" && filePaths[path.Join(pathPrefix, rel)] {
+			return path.Join(pathPrefix, rel), true
+		}
+		return "", false
+	}
+	if q, ok := indexed(p); ok {
+		return q
+	}
+	if codeRoot == "" {
+		return p
+	}
+	root := path.Clean(strings.ReplaceAll(codeRoot, "\\", "/"))
+	dirs := strings.Split(root, "/")
+	for i := range dirs {
+		if dirs[i] == "." || dirs[i] == ".." {
+			continue
+		}
+		prefix := strings.Join(dirs[i:], "/") + "/"
+		if rel, ok := strings.CutPrefix(p, prefix); ok {
+			if q, ok := indexed(rel); ok {
+				return q
+			}
+		}
+	}
+	return p
+}
+
+// indexFileSymbols maps each indexed path to the symbols and test functions
+// extracted from it. Paths with nothing extracted are absent.
+func indexFileSymbols(index codeindex.Index) map[string]map[string]bool {
+	symbols := make(map[string]map[string]bool)
+	add := func(path, name string) {
+		if symbols[path] == nil {
+			symbols[path] = make(map[string]bool)
+		}
+		symbols[path][name] = true
+	}
+	for _, s := range index.Symbols {
+		add(s.Path, s.Symbol)
+	}
+	for _, t := range index.Tests {
+		add(t.Path, t.Function)
+	}
+	return symbols
+}
+
+// citedSymbolName reduces a cited symbol to the bare name the index stores:
+// a trailing "()" and any receiver or type qualifier ("Store.Get",
+// "Store::get") are removed.
+func citedSymbolName(symbol string) string {
+	name := strings.TrimSuffix(strings.TrimSpace(symbol), "()")
+	if i := strings.LastIndexAny(name, ".:"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// clearAdjustments removes the adjustments on every coverage entry and
+// evidence item in r.
+func clearAdjustments(r *schema.PartialReport) {
+	clearEvidence := func(list []schema.Evidence) {
+		for i := range list {
+			list[i].Adjustments = nil
+		}
+	}
+	for i := range r.Coverage.Spec {
+		r.Coverage.Spec[i].Adjustments = nil
+		clearEvidence(r.Coverage.Spec[i].Evidence)
+	}
+	for i := range r.Coverage.Plan {
+		r.Coverage.Plan[i].Adjustments = nil
+		clearEvidence(r.Coverage.Plan[i].Evidence)
+	}
+	for _, d := range r.Drift {
+		clearEvidence(d.Evidence)
+	}
+	for _, v := range r.Violations {
+		clearEvidence(v.Evidence)
+	}
+}
+
+// validateEvidencePaths checks each evidence path against the index, and each
+// cited symbol against the symbols indexed for its path. Paths are first
+// normalized with normalizeEvidencePath; a path that changed is rewritten
+// and noted. Evidence with an unknown path, or with a symbol not found in a
+// file that has indexed symbols, has its confidence downgraded to LOW.
+// Evidence without a symbol, and symbols in files the index extracts nothing
+// from, are not checked. Each change is recorded in the evidence's
+// Adjustments. Errors are appended to errs; the report is modified in place.
+func validateEvidencePaths(r *schema.PartialReport, filePaths map[string]bool, fileSymbols map[string]map[string]bool, codeRoot, pathPrefix string, errs *[]ValidationError) {
 	downgrade := func(ev *schema.Evidence, field string) {
 		if ev.Path == "" {
 			return // empty path: omitted evidence; skip validation
 		}
+		if p := normalizeEvidencePath(ev.Path, codeRoot, pathPrefix, filePaths); p != ev.Path {
+			*errs = append(*errs, ValidationError{
+				Field:   field + ".path",
+				Message: fmt.Sprintf("path %q normalized to %q", ev.Path, p),
+			})
+			ev.Adjustments = append(ev.Adjustments, fmt.Sprintf("path normalized from %q", ev.Path))
+			ev.Path = p
+		}
 		if !filePaths[ev.Path] {
 			*errs = append(*errs, ValidationError{
-				Field:   field,
+				Field:   field + ".path",
 				Message: fmt.Sprintf("path %q not found in code index; confidence downgraded to LOW", ev.Path),
 			})
 			ev.Confidence = schema.ConfidenceLow
+			ev.Adjustments = append(ev.Adjustments, "confidence downgraded: path not in index")
+			return
+		}
+		if ev.Symbol == "" {
+			return
+		}
+		if syms := fileSymbols[ev.Path]; syms != nil && !syms[citedSymbolName(ev.Symbol)] && !syms[ev.Symbol] {
+			*errs = append(*errs, ValidationError{
+				Field:   field + ".symbol",
+				Message: fmt.Sprintf("symbol %q not found in %s in the code index; confidence downgraded to LOW", ev.Symbol, ev.Path),
+			})
+			ev.Confidence = schema.ConfidenceLow
+			ev.Adjustments = append(ev.Adjustments, "confidence downgraded: symbol not in index")
 		}
 	}
 	for i := range r.Coverage.Spec {
 		for j := range r.Coverage.Spec[i].Evidence {
 			downgrade(&r.Coverage.Spec[i].Evidence[j],
-				fmt.Sprintf("coverage.spec[%d].evidence[%d].path", i, j))
+				fmt.Sprintf("coverage.spec[%d].evidence[%d]", i, j))
 		}
 	}
 	for i := range r.Coverage.Plan {
 		for j := range r.Coverage.Plan[i].Evidence {
 			downgrade(&r.Coverage.Plan[i].Evidence[j],
-				fmt.Sprintf("coverage.plan[%d].evidence[%d].path", i, j))
+				fmt.Sprintf("coverage.plan[%d].evidence[%d]", i, j))
 		}
 	}
 	for i := range r.Drift {
 		for j := range r.Drift[i].Evidence {
 			downgrade(&r.Drift[i].Evidence[j],
-				fmt.Sprintf("drift[%d].evidence[%d].path", i, j))
+				fmt.Sprintf("drift[%d].evidence[%d]", i, j))
 		}
 	}
 	for i := range r.Violations {
 		for j := range r.Violations[i].Evidence {
 			downgrade(&r.Violations[i].Evidence[j],
-				fmt.Sprintf("violations[%d].evidence[%d].path", i, j))
+				fmt.Sprintf("violations[%d].evidence[%d]", i, j))
 		}
 	}
 }
 
-// buildSystemPrompt assembles the LLM system prompt.
-func buildSystemPrompt(prof profile.Profile, strict bool) string {
+// validateTraceability removes traceability links whose plan or spec IDs do
+// not appear in the report's coverage, recording each removal in errs. The
+// report is modified in place.
+func validateTraceability(r *schema.PartialReport, errs *[]ValidationError) {
+	if r.Traceability == nil {
+		return
+	}
+	specIDs := make(map[string]bool, len(r.Coverage.Spec))
+	for _, e := range r.Coverage.Spec {
+		specIDs[e.ID] = true
+	}
+	planIDs := make(map[string]bool, len(r.Coverage.Plan))
+	for _, e := range r.Coverage.Plan {
+		planIDs[e.ID] = true
+	}
+	links := r.Traceability[:0]
+	for i, l := range r.Traceability {
+		if !planIDs[l.PlanID] {
+			*errs = append(*errs, ValidationError{
+				Field:   fmt.Sprintf("traceability[%d].plan_id", i),
+				Message: fmt.Sprintf("plan item %q not found in coverage.plan; link dropped", l.PlanID),
+			})
+			continue
+		}
+		specs := make([]string, 0, len(l.SpecIDs))
+		for j, id := range l.SpecIDs {
+			if !specIDs[id] {
+				*errs = append(*errs, ValidationError{
+					Field:   fmt.Sprintf("traceability[%d].spec_ids[%d]", i, j),
+					Message: fmt.Sprintf("spec item %q not found in coverage.spec; reference dropped", id),
+				})
+				continue
+			}
+			specs = append(specs, id)
+		}
+		l.SpecIDs = specs
+		links = append(links, l)
+	}
+	r.Traceability = links
+}
+
+// BuildSystemPrompt assembles the LLM system prompt: the output rules, the
+// strict-mode, require-tests, traceability, and explain instructions when
+// opts.Strict, opts.RequireTests, opts.Traceability, and opts.Explain are
+// set, the profile addendum, and the output schema. With opts.SummaryOnly the
+// reduced summaryOutputSchema replaces the full schema and the require-tests,
+// traceability, and explain instructions are left out. No other Options
+// fields affect it.
+func BuildSystemPrompt(prof profile.Profile, opts Options) string {
 	var sb strings.Builder
 
 	sb.WriteString("You are RealityCheck, an intent enforcement analyzer.\n\n")
@@ -382,18 +959,46 @@ func buildSystemPrompt(prof profile.Profile, strict bool) string {
 
 	sb.WriteString("Every drift finding and violation MUST cite at least one path from the CODE INVENTORY.\n\n")
 
-	if strict {
+	if opts.Strict {
 		sb.WriteString("Strict mode is active. Do not infer intent. " +
 			"Treat all unclear coverage as NOT_IMPLEMENTED. " +
 			"Treat all unverifiable evidence as absent.\n\n")
 	}
 
+	if opts.RequireTests && !opts.SummaryOnly {
+		sb.WriteString("Tests are required. For every IMPLEMENTED or PARTIAL spec item, also cite as evidence " +
+			"each test from the Tests section of the CODE INVENTORY that exercises it, using the test file " +
+			"as path and the test function as symbol. Do not cite tests that do not exercise the item.\n\n")
+	}
+
+	if opts.Traceability && !opts.SummaryOnly {
+		sb.WriteString("Traceability is requested. Add a top-level \"traceability\" array with one entry per PLAN item, " +
+			"listing the IDs of the SPEC items that plan item serves. Use [] for a plan item that serves no spec item. " +
+			"Only use IDs that appear in SPEC.md and PLAN.md below.\n\n")
+	}
+
+	if opts.Explain && !opts.SummaryOnly {
+		sb.WriteString("An explanation is requested. Add a top-level \"rationale\" string of two to four plain-English " +
+			"sentences explaining how well the code matches the spec and plan and what most affects that, written for " +
+			"a reader who is not an engineer. Do not use IDs, file paths, or symbol names in it. It must not be empty.\n\n")
+	}
+
 	if prof.SystemPromptAddendum != "" {
 		sb.WriteString(prof.SystemPromptAddendum)
 		sb.WriteString("\n\n")
 	}
 
+	if opts.SummaryOnly {
+		sb.WriteString(summaryOutputSchema)
+		return sb.String()
+	}
 	sb.WriteString(outputSchema)
+	if opts.Traceability {
+		sb.WriteString(traceabilitySchema)
+	}
+	if opts.Explain {
+		sb.WriteString(explainSchema)
+	}
 
 	return sb.String()
 }
@@ -450,28 +1055,78 @@ const outputSchema = `Output schema (JSON only):
 }
 `
 
-// buildUserPrompt assembles the LLM user prompt.
-func buildUserPrompt(specItems []spec.Item, planItems []plan.Item, index codeindex.Index) string {
+// traceabilitySchema extends outputSchema when Options.Traceability is set.
+const traceabilitySchema = `Also include this top-level field:
+{
+  "traceability": [
+    {"plan_id": "PLAN-001", "spec_ids": ["SPEC-001", "SPEC-003"]}
+  ]
+}
+`
+
+// explainSchema extends outputSchema when Options.Explain is set.
+const explainSchema = `Also include this top-level field:
+{
+  "rationale": "two to four plain-English sentences"
+}
+`
+
+// BuildUserPrompt assembles the LLM user prompt: the spec and plan items with
+// their line ranges, followed by the code inventory from
+// index.SummaryForSpec, which keeps the symbols the items mention when the
+// inventory must be truncated, and then by up to snippetBytes of source
+// snippets from index.SnippetsForSpec.
+// maxIndexBytes <= 0 uses codeindex.DefaultSummaryBytes.
+func BuildUserPrompt(specItems []spec.Item, planItems []plan.Item, index codeindex.Index, maxIndexBytes, snippetBytes int) string {
+	return buildUserPrompt(specItems, planItems,
+		index.SummaryForSpec(specItems, planItems, maxIndexBytes),
+		index.SnippetsForSpec(specItems, planItems, snippetBytes))
+}
+
+// buildUserPrompt is BuildUserPrompt with the inventory and snippets already
+// rendered.
+func buildUserPrompt(specItems []spec.Item, planItems []plan.Item, inventory, snippets string) string {
 	var sb strings.Builder
 
 	sb.WriteString("SPEC.md (with line numbers):\n")
-	for _, item := range specItems {
-		fmt.Fprintf(&sb, "  %d-%d: %s\n", item.LineStart, item.LineEnd, item.Text)
-	}
+	writePromptItems(&sb, specItems)
 
 	sb.WriteString("\nPLAN.md (with line numbers):\n")
-	for _, item := range planItems {
-		fmt.Fprintf(&sb, "  %d-%d: %s\n", item.LineStart, item.LineEnd, item.Text)
-	}
+	writePromptItems(&sb, planItems)
 
 	sb.WriteString("\nCODE INVENTORY:\n")
-	sb.WriteString(index.Summary())
+	sb.WriteString(inventory)
+
+	if snippets != "" {
+		sb.WriteString("\nCODE SNIPPETS (the first lines of each symbol's declaration):\n")
+		sb.WriteString(snippets)
+	}
 
 	sb.WriteString("\nProduce the JSON report now.")
 
 	return sb.String()
 }
 
+// writePromptItems writes one line per item with its line range. When the
+// items were merged from more than one file, each line is prefixed with its
+// source file so line numbers remain unambiguous.
+func writePromptItems(sb *strings.Builder, items []mdparse.Item) {
+	multi := false
+	for _, item := range items {
+		if item.Source != items[0].Source {
+			multi = true
+			break
+		}
+	}
+	for _, item := range items {
+		if multi {
+			fmt.Fprintf(sb, "  %s:%d-%d: %s\n", item.Source, item.LineStart, item.LineEnd, item.Text)
+		} else {
+			fmt.Fprintf(sb, "  %d-%d: %s\n", item.LineStart, item.LineEnd, item.Text)
+		}
+	}
+}
+
 // buildRepairPrompt constructs the repair message. It includes the original
 // user prompt and the previous invalid response so the LLM has full context.
 func buildRepairPrompt(originalUserPrompt, previousResponse string, errs []ValidationError) string {
@@ -496,6 +1151,8 @@ func defaultNewProvider(providerName, model string) (Provider, error) {
 		return newAnthropicProvider(model)
 	case "openai":
 		return newOpenAIProvider(model)
+	case "azure":
+		return newAzureProvider(model)
 	case "google":
 		return newGoogleProvider(model)
 	default:
@@ -526,8 +1183,50 @@ func (p *anthropicProvider) Complete(
 	systemPrompt, userPrompt string,
 	maxTokens int,
 	temperature float64,
-) (string, error) {
-	msg, err := p.client.Messages.New(ctx, anthropic.MessageNewParams{
+) (CompletionResult, error) {
+	msg, err := p.client.Messages.New(ctx, p.params(systemPrompt, userPrompt, maxTokens, temperature))
+	if err != nil {
+		return CompletionResult{}, fmt.Errorf("anthropic: messages.new: %w", err)
+	}
+	return anthropicResult(msg)
+}
+
+// CompleteStream implements StreamingProvider. The streamed events are
+// accumulated into a Message, so the result matches Complete.
+func (p *anthropicProvider) CompleteStream(
+	ctx context.Context,
+	systemPrompt, userPrompt string,
+	maxTokens int,
+	temperature float64,
+	w io.Writer,
+) (CompletionResult, error) {
+	stream := p.client.Messages.NewStreaming(ctx, p.params(systemPrompt, userPrompt, maxTokens, temperature))
+	defer stream.Close()
+	var msg anthropic.Message
+	for stream.Next() {
+		event := stream.Current()
+		if err := msg.Accumulate(event); err != nil {
+			return CompletionResult{}, fmt.Errorf("anthropic: messages.stream: %w", err)
+		}
+		if delta, ok := event.AsAny().(anthropic.ContentBlockDeltaEvent); ok {
+			if text, ok := delta.Delta.AsAny().(anthropic.TextDelta); ok {
+				_, _ = io.WriteString(w, text.Text) // progress output only
+			}
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return CompletionResult{}, fmt.Errorf("anthropic: messages.stream: %w", err)
+	}
+	return anthropicResult(&msg)
+}
+
+// params builds the request. The Messages API has no JSON output mode, so
+// the response relies on the system prompt; if fenced output becomes a
+// problem, the way to force JSON is to prefill the assistant turn with "{"
+// and prepend it to the returned text. stripMarkdownFences covers fences
+// in the meantime.
+func (p *anthropicProvider) params(systemPrompt, userPrompt string, maxTokens int, temperature float64) anthropic.MessageNewParams {
+	return anthropic.MessageNewParams{
 		Model:       anthropic.Model(p.model),
 		MaxTokens:   int64(maxTokens),
 		Temperature: anthropic.Float(temperature),
@@ -537,11 +1236,11 @@ func (p *anthropicProvider) Complete(
 		Messages: []anthropic.MessageParam{
 			anthropic.NewUserMessage(anthropic.NewTextBlock(userPrompt)),
 		},
-	})
-	if err != nil {
-		return "", fmt.Errorf("anthropic: messages.new: %w", err)
 	}
+}
 
+// anthropicResult joins the text blocks of msg.
+func anthropicResult(msg *anthropic.Message) (CompletionResult, error) {
 	var parts []string
 	for _, block := range msg.Content {
 		// block.Type is a string field from the Anthropic API; "text" is the
@@ -552,7 +1251,14 @@ func (p *anthropicProvider) Complete(
 		}
 	}
 	if len(parts) == 0 {
-		return "", fmt.Errorf("anthropic: response contained no text content blocks")
+		return CompletionResult{}, fmt.Errorf("anthropic: response contained no text content blocks")
 	}
-	return strings.Join(parts, ""), nil
+	return CompletionResult{
+		Text: strings.Join(parts, ""),
+		Usage: Usage{
+			PromptTokens:     int(msg.Usage.InputTokens),
+			CompletionTokens: int(msg.Usage.OutputTokens),
+		},
+		Model: string(msg.Model),
+	}, nil
 }
@@ -4,6 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/dshills/realitycheck/internal/codeindex"
@@ -15,21 +19,24 @@ import (
 
 // mockProvider is a test double for Provider.
 type mockProvider struct {
-	responses []string // returned in order; last entry is repeated if list exhausted
-	callCount int
+	responses   []string // returned in order; last entry is repeated if list exhausted
+	usage       Usage    // reported for every call
+	model       string   // reported for every call
+	temperature *float64 // reported for every call
+	callCount   int
 }
 
-func (m *mockProvider) Complete(_ context.Context, _, _ string, _ int, _ float64) (string, error) {
+func (m *mockProvider) Complete(_ context.Context, _, _ string, _ int, _ float64) (CompletionResult, error) {
 	if len(m.responses) == 0 {
 		m.callCount++
-		return "", fmt.Errorf("mockProvider: no responses configured")
+		return CompletionResult{}, fmt.Errorf("mockProvider: no responses configured")
 	}
 	idx := m.callCount
 	if idx >= len(m.responses) {
 		idx = len(m.responses) - 1
 	}
 	m.callCount++
-	return m.responses[idx], nil
+	return CompletionResult{Text: m.responses[idx], Usage: m.usage, Model: m.model, Temperature: m.temperature}, nil
 }
 
 // minimalValidResponse returns a valid JSON PartialReport with empty slices.
@@ -49,6 +56,11 @@ func minimalValidResponse() string {
 // responseWithPath returns a valid JSON PartialReport with one spec entry
 // citing the given evidence path.
 func responseWithPath(path string) string {
+	return responseWithEvidence(path, "Foo")
+}
+
+// responseWithEvidence is responseWithPath with the cited symbol given.
+func responseWithEvidence(path, sym string) string {
 	r := schema.PartialReport{
 		Coverage: schema.Coverage{
 			Spec: []schema.SpecCoverageEntry{
@@ -58,7 +70,7 @@ func responseWithPath(path string) string {
 					SpecReference: schema.Reference{LineStart: 1, LineEnd: 1},
 					Evidence: []schema.Evidence{{
 						Path:       path,
-						Symbol:     "Foo",
+						Symbol:     sym,
 						Confidence: schema.ConfidenceHigh,
 					}},
 				},
@@ -126,6 +138,26 @@ func TestValidateResponse_FabricatedPath(t *testing.T) {
 	if !found {
 		t.Error("expected a validation error for the fabricated evidence path")
 	}
+
+	if got := report.Coverage.Spec[0].Evidence[0].Adjustments; !slices.Equal(got, []string{"confidence downgraded: path not in index"}) {
+		t.Errorf("adjustments = %q, want the downgrade recorded", got)
+	}
+}
+
+func TestValidateResponse_ModelAdjustmentsCleared(t *testing.T) {
+	raw := strings.Replace(responseWithEvidence("internal/store/store.go", ""),
+		`"confidence":"HIGH"`, `"confidence":"HIGH","adjustments":["made up"]`, 1)
+	raw = strings.Replace(raw, `"status":"IMPLEMENTED"`, `"status":"IMPLEMENTED","adjustments":["made up"]`, 1)
+	if !strings.Contains(raw, "made up") {
+		t.Fatalf("fixture not rewritten: %s", raw)
+	}
+	report, errs := ValidateResponse(raw, testIndex())
+	if report == nil {
+		t.Fatalf("expected non-nil report; errs: %v", errs)
+	}
+	if e := report.Coverage.Spec[0]; e.Adjustments != nil || e.Evidence[0].Adjustments != nil {
+		t.Errorf("adjustments = %q, %q; want the model's adjustments dropped", e.Adjustments, e.Evidence[0].Adjustments)
+	}
 }
 
 func TestValidateResponse_ValidPath(t *testing.T) {
@@ -145,6 +177,137 @@ func TestValidateResponse_ValidPath(t *testing.T) {
 	}
 }
 
+func TestValidateResponse_FabricatedSymbol(t *testing.T) {
+	idx := testIndex()
+	idx.Symbols = []codeindex.SymbolEntry{{Path: "internal/store/store.go", Symbol: "Get"}}
+	idx.Tests = []codeindex.TestEntry{{Path: "internal/store/store.go", Function: "TestGet"}}
+
+	cases := []struct {
+		symbol  string
+		wantLow bool
+	}{
+		{"Get", false},
+		{"Store.Get", false},
+		{"Get()", false},
+		{"TestGet", false},
+		{"Foo", true},
+	}
+	for _, c := range cases {
+		report, errs := ValidateResponse(responseWithEvidence("internal/store/store.go", c.symbol), idx)
+		if report == nil {
+			t.Fatalf("%s: expected non-nil report; errs: %v", c.symbol, errs)
+		}
+		low := report.Coverage.Spec[0].Evidence[0].Confidence == schema.ConfidenceLow
+		if low != c.wantLow {
+			t.Errorf("symbol %q: downgraded = %v, want %v", c.symbol, low, c.wantLow)
+		}
+		found := false
+		for _, e := range errs {
+			if e.Field == "coverage.spec[0].evidence[0].symbol" {
+				found = true
+			}
+		}
+		if found != c.wantLow {
+			t.Errorf("symbol %q: validation error recorded = %v, want %v", c.symbol, found, c.wantLow)
+		}
+	}
+}
+
+func TestValidateResponse_SymbolInUnindexedFile(t *testing.T) {
+	// The index extracted no symbols from the file, so the symbol cannot
+	// be checked and is left alone.
+	report, errs := ValidateResponse(responseWithEvidence("internal/store/store.go", "Anything"), testIndex())
+	if report == nil {
+		t.Fatalf("expected non-nil report; errs: %v", errs)
+	}
+	if got := report.Coverage.Spec[0].Evidence[0].Confidence; got != schema.ConfidenceHigh {
+		t.Errorf("confidence = %q, want HIGH", got)
+	}
+}
+
+func TestValidateResponse_NormalizePath(t *testing.T) {
+	idx := codeindex.Index{
+		Files:   []codeindex.FileEntry{{Path: "store.go", Language: "Go"}},
+		Symbols: []codeindex.SymbolEntry{{Path: "store.go", Symbol: "Get"}},
+	}
+	opts := Options{CodeRoot: "../../testdata/drift"}
+
+	cases := []struct {
+		path     string
+		want     string
+		wantNote bool
+		wantLow  bool
+	}{
+		{"store.go", "store.go", false, false},
+		{"./store.go", "store.go", true, false},
+		{"store.go/", "store.go", true, false},
+		{`.\store.go`, "store.go", true, false},
+		{"testdata/drift/store.go", "store.go", true, false},
+		{`testdata\drift\store.go`, "store.go", true, false},
+		{"drift/store.go", "store.go", true, false},
+		{"other/store.go", "other/store.go", false, true},
+	}
+	for _, c := range cases {
+		report, errs := validateResponse(responseWithEvidence(c.path, "Get"), idx, opts)
+		if report == nil {
+			t.Fatalf("%s: expected non-nil report; errs: %v", c.path, errs)
+		}
+		ev := report.Coverage.Spec[0].Evidence[0]
+		if ev.Path != c.want {
+			t.Errorf("%s: path = %q, want %q", c.path, ev.Path, c.want)
+		}
+		if low := ev.Confidence == schema.ConfidenceLow; low != c.wantLow {
+			t.Errorf("%s: downgraded = %v, want %v", c.path, low, c.wantLow)
+		}
+		note := false
+		for _, e := range errs {
+			if strings.Contains(e.Message, "normalized to") {
+				note = true
+			}
+		}
+		if note != c.wantNote {
+			t.Errorf("%s: normalization noted = %v, want %v; errs: %v", c.path, note, c.wantNote, errs)
+		}
+		if NeedsRepair(errs) {
+			t.Errorf("%s: errors %v should not trigger repair", c.path, errs)
+		}
+	}
+
+	// Without a code root, only cleaning applies.
+	report, _ := ValidateResponse(responseWithEvidence("testdata/drift/store.go", "Get"), idx)
+	if got := report.Coverage.Spec[0].Evidence[0]; got.Path != "testdata/drift/store.go" || got.Confidence != schema.ConfidenceLow {
+		t.Errorf("no code root: evidence = %+v, want the path unchanged and downgraded", got)
+	}
+}
+
+func TestValidateResponse_NormalizePathPrefix(t *testing.T) {
+	idx := codeindex.Index{
+		Files:      []codeindex.FileEntry{{Path: "internal/api/store.go", Language: "Go"}},
+		Symbols:    []codeindex.SymbolEntry{{Path: "internal/api/store.go", Symbol: "Get"}},
+		PathPrefix: "internal/api",
+	}
+	opts := Options{CodeRoot: "/src/repo/internal/api"}
+
+	cases := []struct {
+		path, want string
+	}{
+		{"internal/api/store.go", "internal/api/store.go"},
+		{"store.go", "internal/api/store.go"},
+		{"./store.go", "internal/api/store.go"},
+		{"/src/repo/internal/api/store.go", "internal/api/store.go"},
+		{"api/other.go", "api/other.go"},
+	}
+	for _, c := range cases {
+		report, errs := validateResponse(responseWithEvidence(c.path, "Get"), idx, opts)
+		if report == nil {
+			t.Fatalf("%s: expected non-nil report; errs: %v", c.path, errs)
+		}
+		if got := report.Coverage.Spec[0].Evidence[0].Path; got != c.want {
+			t.Errorf("%s: path = %q, want %q", c.path, got, c.want)
+		}
+	}
+}
+
 func TestValidateResponse_InvalidJSON(t *testing.T) {
 	report, errs := ValidateResponse("not json", codeindex.Index{})
 	if report != nil {
@@ -175,6 +338,95 @@ func TestValidateResponse_MissingRequiredFields(t *testing.T) {
 	}
 }
 
+func TestValidateResponse_IDPattern(t *testing.T) {
+	r := schema.PartialReport{
+		Coverage: schema.Coverage{
+			Spec: []schema.SpecCoverageEntry{},
+			Plan: []schema.PlanCoverageEntry{},
+		},
+		Drift: []schema.DriftFinding{
+			{ID: "DRIFT-001", Severity: schema.SeverityInfo, Evidence: []schema.Evidence{}},
+			{ID: "DRIFT-001-a", Severity: schema.SeverityInfo, Evidence: []schema.Evidence{}},
+		},
+		Violations: []schema.Violation{
+			{ID: "VIOLATION-002-b", Severity: schema.SeverityWarn, Evidence: []schema.Evidence{}},
+		},
+	}
+	b, _ := json.Marshal(r)
+	idFields := func(errs []ValidationError) []string {
+		var out []string
+		for _, e := range errs {
+			if strings.HasSuffix(e.Field, ".id") {
+				out = append(out, e.Field)
+			}
+		}
+		return out
+	}
+
+	got, errs := ValidateResponse(string(b), codeindex.Index{})
+	if ids := idFields(errs); !reflect.DeepEqual(ids, []string{"drift[1].id", "violations[0].id"}) {
+		t.Errorf("default patterns: id errors = %v", ids)
+	}
+	if got.Drift[1].ID != "DRIFT-002" || got.Violations[0].ID != "VIOLATION-003" {
+		t.Errorf("default patterns: ids = %q, %q, want renumbered DRIFT-002, VIOLATION-003", got.Drift[1].ID, got.Violations[0].ID)
+	}
+
+	relaxed := Options{
+		DriftIDPattern:     regexp.MustCompile(`^DRIFT-\d+(-[a-z]+)?$`),
+		ViolationIDPattern: regexp.MustCompile(`^VIOLATION-\d+(-[a-z]+)?$`),
+	}
+	got, errs = validateResponse(string(b), codeindex.Index{}, relaxed)
+	if len(idFields(errs)) != 0 {
+		t.Errorf("relaxed patterns: id errors = %v", errs)
+	}
+	if got.Drift[1].ID != "DRIFT-001-a" {
+		t.Errorf("relaxed patterns: id = %q, want DRIFT-001-a kept", got.Drift[1].ID)
+	}
+
+	strict := Options{DriftIDPattern: regexp.MustCompile(`^DRIFT-\d{3}$`)}
+	_, errs = validateResponse(string(b), codeindex.Index{}, strict)
+	if got := idFields(errs); !reflect.DeepEqual(got, []string{"drift[1].id", "violations[0].id"}) {
+		t.Errorf("tightened drift pattern: id errors = %v", got)
+	}
+	for _, e := range errs {
+		if e.Field == "drift[1].id" && !strings.Contains(e.Message, `^DRIFT-\d{3}$`) {
+			t.Errorf("message = %q, want the pattern", e.Message)
+		}
+	}
+}
+
+func TestAnalyze_RenumbersMalformedIDs(t *testing.T) {
+	r := schema.PartialReport{
+		Coverage: schema.Coverage{Spec: []schema.SpecCoverageEntry{}, Plan: []schema.PlanCoverageEntry{}},
+		Drift: []schema.DriftFinding{
+			{ID: "DRIFT-004", Severity: schema.SeverityInfo, Description: "Extra metrics endpoint", Evidence: []schema.Evidence{}},
+			{ID: "drift one", Severity: schema.SeverityWarn, Description: "Undocumented retry loop", Evidence: []schema.Evidence{}},
+		},
+		Violations: []schema.Violation{},
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mp := &mockProvider{responses: []string{string(b)}}
+	installMock(t, mp)
+
+	report, err := Analyze(context.Background(), []spec.Item{}, []plan.Item{}, codeindex.Index{}, loadGeneralProfile(t), Options{})
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	var ids []string
+	for _, d := range report.Drift {
+		ids = append(ids, d.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"DRIFT-004", "DRIFT-005"}) {
+		t.Errorf("drift ids = %v, want the malformed ID renumbered DRIFT-005", ids)
+	}
+	if mp.callCount != 1 {
+		t.Errorf("provider calls = %d, want 1 (a malformed ID does not trigger repair)", mp.callCount)
+	}
+}
+
 func TestAnalyze_RepairTriggered(t *testing.T) {
 	// First response is invalid JSON; second is valid.
 	mp := &mockProvider{responses: []string{"bad json", minimalValidResponse()}}
@@ -219,6 +471,46 @@ func TestAnalyze_BothResponsesInvalid(t *testing.T) {
 	}
 }
 
+func TestAnalyze_ProviderFallback(t *testing.T) {
+	// The primary returns invalid output twice; the fallback succeeds.
+	mocks := map[string]*mockProvider{
+		"anthropic": {responses: []string{"bad json"}},
+		"openai":    {responses: []string{minimalValidResponse()}},
+		"google":    {},
+	}
+	orig := NewProvider
+	NewProvider = func(name, _ string) (Provider, error) {
+		if name == "azure" {
+			return nil, fmt.Errorf("no azure")
+		}
+		return mocks[name], nil
+	}
+	t.Cleanup(func() { NewProvider = orig })
+
+	prof := loadGeneralProfile(t)
+	opts := Options{
+		Provider: "anthropic", Model: "claude-test", MaxTokens: 100,
+		Fallbacks: []ProviderConfig{{"azure", "deploy"}, {"openai", "gpt-test"}},
+	}
+	report, err := Analyze(context.Background(), nil, nil, codeindex.Index{}, prof, opts)
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if report.Meta.Model != "openai:gpt-test" {
+		t.Errorf("Meta.Model = %q, want the provider that served the report", report.Meta.Model)
+	}
+	if mocks["anthropic"].callCount != 2 || mocks["openai"].callCount != 1 {
+		t.Errorf("calls: anthropic %d, openai %d; want 2 and 1", mocks["anthropic"].callCount, mocks["openai"].callCount)
+	}
+
+	// When every provider fails, the last failure is returned.
+	opts.Fallbacks = []ProviderConfig{{"google", "gemini-test"}}
+	_, err = Analyze(context.Background(), nil, nil, codeindex.Index{}, prof, opts)
+	if err == nil || !strings.Contains(err.Error(), "no responses configured") {
+		t.Errorf("err = %v, want the last provider's error", err)
+	}
+}
+
 func TestAnalyze_ValidResponse(t *testing.T) {
 	mp := &mockProvider{responses: []string{minimalValidResponse()}}
 	installMock(t, mp)
@@ -239,3 +531,223 @@ func TestAnalyze_ValidResponse(t *testing.T) {
 		t.Fatal("expected non-nil report")
 	}
 }
+
+func TestAnalyze_UsageSummedAcrossRepair(t *testing.T) {
+	mp := &mockProvider{
+		responses: []string{"bad json", minimalValidResponse()},
+		usage:     Usage{PromptTokens: 100, CompletionTokens: 20},
+	}
+	installMock(t, mp)
+
+	prof := loadGeneralProfile(t)
+	report, err := Analyze(
+		context.Background(),
+		[]spec.Item{},
+		[]plan.Item{},
+		codeindex.Index{},
+		prof,
+		Options{MaxTokens: 100, Temperature: 0.2, Model: "test-model"},
+	)
+	if err != nil {
+		t.Fatalf("expected repair to succeed, got error: %v", err)
+	}
+	if report.Meta.PromptTokens != 200 || report.Meta.CompletionTokens != 40 || report.Meta.TotalTokens != 240 {
+		t.Errorf("usage = %d/%d/%d, want 200/40/240 (initial + repair)",
+			report.Meta.PromptTokens, report.Meta.CompletionTokens, report.Meta.TotalTokens)
+	}
+}
+
+func TestAnalyze_ResponseMeta(t *testing.T) {
+	// The model claims its own meta; the report must not trust it.
+	claimed := `{"coverage":{"spec":[],"plan":[]},"drift":[],"violations":[],"meta":{"model":"made-up","temperature":0.9}}`
+	one := 1.0
+	cases := []struct {
+		name      string
+		mock      *mockProvider
+		wantModel string
+		wantTemp  float64
+	}{
+		{"requested values", &mockProvider{responses: []string{claimed}}, "test-model", 0.2},
+		{"effective values", &mockProvider{responses: []string{claimed}, model: "test-model-2024-01-01", temperature: &one}, "test-model-2024-01-01", 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			installMock(t, c.mock)
+			report, err := Analyze(context.Background(), nil, nil, codeindex.Index{}, loadGeneralProfile(t),
+				Options{MaxTokens: 100, Temperature: 0.2, Model: "test-model"})
+			if err != nil {
+				t.Fatalf("Analyze: %v", err)
+			}
+			if report.Meta.Model != c.wantModel || report.Meta.Temperature != c.wantTemp {
+				t.Errorf("meta = %s at %v, want %s at %v", report.Meta.Model, report.Meta.Temperature, c.wantModel, c.wantTemp)
+			}
+		})
+	}
+}
+
+func TestAnalyze_ExplainRequiresRationale(t *testing.T) {
+	// The first response has a blank rationale; the repair supplies one.
+	withRationale := func(text string) string {
+		var r schema.PartialReport
+		_ = json.Unmarshal([]byte(minimalValidResponse()), &r)
+		r.Rationale = text
+		b, _ := json.Marshal(r)
+		return string(b)
+	}
+	mp := &mockProvider{responses: []string{withRationale("  "), withRationale("The code does what the spec asks.")}}
+	installMock(t, mp)
+
+	prof := loadGeneralProfile(t)
+	report, err := Analyze(context.Background(), nil, nil, codeindex.Index{}, prof,
+		Options{MaxTokens: 100, Model: "test-model", Explain: true})
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if mp.callCount != 2 {
+		t.Errorf("calls = %d, want 2 (initial + repair)", mp.callCount)
+	}
+	if report.Rationale != "The code does what the spec asks." {
+		t.Errorf("rationale = %q", report.Rationale)
+	}
+
+	// Without Explain a missing rationale is fine.
+	if _, errs := ValidateResponse(minimalValidResponse(), codeindex.Index{}); NeedsRepair(errs) {
+		t.Errorf("errs = %v, want none without Explain", errs)
+	}
+}
+
+func TestBuildSystemPrompt(t *testing.T) {
+	prof := loadGeneralProfile(t)
+
+	base := BuildSystemPrompt(prof, Options{})
+	if !strings.Contains(base, prof.SystemPromptAddendum) {
+		t.Error("system prompt missing the profile addendum")
+	}
+	if !strings.Contains(base, `"coverage"`) {
+		t.Error("system prompt missing the output schema")
+	}
+	if strings.Contains(base, "Strict mode is active") || strings.Contains(base, "Tests are required") {
+		t.Error("default system prompt should not include strict or require-tests instructions")
+	}
+
+	strict := BuildSystemPrompt(prof, Options{Strict: true})
+	if !strings.Contains(strict, "Strict mode is active") {
+		t.Error("strict system prompt missing the strict-mode addendum")
+	}
+	tests := BuildSystemPrompt(prof, Options{RequireTests: true})
+	if !strings.Contains(tests, "Tests are required") {
+		t.Error("require-tests system prompt missing its instruction")
+	}
+
+	trace := BuildSystemPrompt(prof, Options{Traceability: true})
+	if !strings.Contains(trace, `"traceability"`) || strings.Contains(base, `"traceability"`) {
+		t.Error("traceability schema should appear only when Traceability is set")
+	}
+
+	explain := BuildSystemPrompt(prof, Options{Explain: true})
+	if !strings.Contains(explain, "An explanation is requested") || strings.Contains(base, `"rationale"`) {
+		t.Error("explain instruction should appear only when Explain is set")
+	}
+
+	// Only Strict, RequireTests, Traceability, and Explain shape the system
+	// prompt.
+	if BuildSystemPrompt(prof, Options{Model: "m", MaxTokens: 1, ChunkByDir: true}) != base {
+		t.Error("unrelated options changed the system prompt")
+	}
+}
+
+func TestValidateResponse_TraceabilityUnknownIDs(t *testing.T) {
+	r := schema.PartialReport{
+		Coverage: schema.Coverage{
+			Spec: []schema.SpecCoverageEntry{{ID: "SPEC-001", Status: schema.StatusImplemented, Evidence: []schema.Evidence{}}},
+			Plan: []schema.PlanCoverageEntry{{ID: "PLAN-001", Status: schema.StatusImplemented, Evidence: []schema.Evidence{}}},
+		},
+		Drift:      []schema.DriftFinding{},
+		Violations: []schema.Violation{},
+		Traceability: []schema.TraceLink{
+			{PlanID: "PLAN-001", SpecIDs: []string{"SPEC-001", "SPEC-009"}},
+			{PlanID: "PLAN-007", SpecIDs: []string{"SPEC-001"}},
+		},
+	}
+	b, _ := json.Marshal(r)
+
+	report, errs := ValidateResponse(string(b), testIndex())
+	if report == nil {
+		t.Fatalf("expected non-nil report; errs: %v", errs)
+	}
+	if len(report.Traceability) != 1 {
+		t.Fatalf("traceability = %+v, want only the PLAN-001 link", report.Traceability)
+	}
+	if got := report.Traceability[0].SpecIDs; len(got) != 1 || got[0] != "SPEC-001" {
+		t.Errorf("PLAN-001 spec_ids = %v, want [SPEC-001]", got)
+	}
+	fields := map[string]bool{}
+	for _, e := range errs {
+		fields[e.Field] = true
+	}
+	for _, f := range []string{"traceability[0].spec_ids[1]", "traceability[1].plan_id"} {
+		if !fields[f] {
+			t.Errorf("missing validation error for %s; errs: %v", f, errs)
+		}
+	}
+	if NeedsRepair(errs) {
+		t.Error("unknown traceability IDs should not trigger a repair")
+	}
+}
+
+func TestBuildUserPrompt(t *testing.T) {
+	specItems := []spec.Item{{ID: "SPEC-001", LineStart: 3, LineEnd: 4, Text: "Get returns a value."}}
+	planItems := []plan.Item{{ID: "PLAN-001", LineStart: 7, LineEnd: 7, Text: "Implement the store."}}
+	idx := codeindex.Index{
+		Files: []codeindex.FileEntry{
+			{Path: "cmd/app/main.go", Language: "Go"},
+			{Path: "internal/store/store.go", Language: "Go"},
+			{Path: "internal/store/store_test.go", Language: "Go"},
+		},
+		Symbols: []codeindex.SymbolEntry{{Path: "internal/store/store.go", Symbol: "Get", Exported: true}},
+		Tests:   []codeindex.TestEntry{{Path: "internal/store/store_test.go", Function: "TestGet"}},
+	}
+
+	got := BuildUserPrompt(specItems, planItems, idx, 0, 0)
+	for _, want := range []string{"3-4: Get returns a value.", "7-7: Implement the store.", "CODE INVENTORY:"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("user prompt missing %q", want)
+		}
+	}
+	for _, f := range idx.Files {
+		if !strings.Contains(got, f.Path) {
+			t.Errorf("user prompt missing indexed file %q", f.Path)
+		}
+	}
+}
+
+func TestNewAzureProvider_RequiresEnv(t *testing.T) {
+	t.Setenv("AZURE_OPENAI_API_KEY", "key")
+	t.Setenv("AZURE_OPENAI_ENDPOINT", "")
+	if _, err := defaultNewProvider("azure", "my-deployment"); err == nil || !strings.Contains(err.Error(), "AZURE_OPENAI_ENDPOINT") {
+		t.Errorf("missing endpoint: err = %v", err)
+	}
+	t.Setenv("AZURE_OPENAI_ENDPOINT", "https://example.openai.azure.com")
+	if _, err := defaultNewProvider("azure", "my-deployment"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	t.Setenv("AZURE_OPENAI_API_KEY", "")
+	if _, err := defaultNewProvider("azure", "my-deployment"); err == nil || !strings.Contains(err.Error(), "AZURE_OPENAI_API_KEY") {
+		t.Errorf("missing key: err = %v", err)
+	}
+}
+
+func TestOpenAIParams_JSONObjectMode(t *testing.T) {
+	p := &openaiProvider{model: "gpt-4o"}
+	params := p.params("system", "user", 100, 0.2)
+	if params.ResponseFormat.OfJSONObject == nil {
+		t.Fatal("ResponseFormat is not JSON object mode")
+	}
+	raw, err := json.Marshal(params)
+	if err != nil {
+		t.Fatalf("marshal params: %v", err)
+	}
+	if !strings.Contains(string(raw), `"response_format":{"type":"json_object"}`) {
+		t.Errorf("request body = %s, want a json_object response_format", raw)
+	}
+}
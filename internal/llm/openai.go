@@ -3,9 +3,11 @@ package llm
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 
 	openai "github.com/openai/openai-go"
+	"github.com/openai/openai-go/azure"
 	"github.com/openai/openai-go/option"
 	"github.com/openai/openai-go/shared"
 )
@@ -25,31 +27,109 @@ func newOpenAIProvider(model string) (Provider, error) {
 	return &openaiProvider{client: client, model: model}, nil
 }
 
+// defaultAzureAPIVersion is the Azure OpenAI REST API version used when
+// AZURE_OPENAI_API_VERSION is unset.
+const defaultAzureAPIVersion = "2024-10-21"
+
+// newAzureProvider returns an OpenAI provider that talks to an Azure OpenAI
+// resource. The endpoint comes from AZURE_OPENAI_ENDPOINT, and model is the
+// deployment name.
+func newAzureProvider(model string) (Provider, error) {
+	apiKey := os.Getenv("AZURE_OPENAI_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("llm: AZURE_OPENAI_API_KEY environment variable not set")
+	}
+	endpoint := os.Getenv("AZURE_OPENAI_ENDPOINT")
+	if endpoint == "" {
+		return nil, fmt.Errorf("llm: AZURE_OPENAI_ENDPOINT environment variable not set")
+	}
+	apiVersion := os.Getenv("AZURE_OPENAI_API_VERSION")
+	if apiVersion == "" {
+		apiVersion = defaultAzureAPIVersion
+	}
+	client := openai.NewClient(
+		azure.WithEndpoint(endpoint, apiVersion),
+		azure.WithAPIKey(apiKey),
+	)
+	return &openaiProvider{client: client, model: model}, nil
+}
+
 func (p *openaiProvider) Complete(
 	ctx context.Context,
 	systemPrompt, userPrompt string,
 	maxTokens int,
 	temperature float64,
-) (string, error) {
-	resp, err := p.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
-		Model:     shared.ChatModel(p.model),
-		MaxTokens: openai.Int(int64(maxTokens)),
+) (CompletionResult, error) {
+	resp, err := p.client.Chat.Completions.New(ctx, p.params(systemPrompt, userPrompt, maxTokens, temperature))
+	if err != nil {
+		return CompletionResult{}, fmt.Errorf("openai: chat.completions.new: %w", err)
+	}
+	return openaiResult(resp)
+}
+
+// CompleteStream implements StreamingProvider. The chunks are accumulated
+// into a ChatCompletion, and usage is requested in the final chunk, so the
+// result matches Complete.
+func (p *openaiProvider) CompleteStream(
+	ctx context.Context,
+	systemPrompt, userPrompt string,
+	maxTokens int,
+	temperature float64,
+	w io.Writer,
+) (CompletionResult, error) {
+	params := p.params(systemPrompt, userPrompt, maxTokens, temperature)
+	params.StreamOptions = openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)}
+	stream := p.client.Chat.Completions.NewStreaming(ctx, params)
+	defer stream.Close()
+	var acc openai.ChatCompletionAccumulator
+	for stream.Next() {
+		chunk := stream.Current()
+		if !acc.AddChunk(chunk) {
+			return CompletionResult{}, fmt.Errorf("openai: chat.completions.stream: could not accumulate chunk")
+		}
+		if len(chunk.Choices) > 0 {
+			_, _ = io.WriteString(w, chunk.Choices[0].Delta.Content) // progress output only
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return CompletionResult{}, fmt.Errorf("openai: chat.completions.stream: %w", err)
+	}
+	return openaiResult(&acc.ChatCompletion)
+}
+
+func (p *openaiProvider) params(systemPrompt, userPrompt string, maxTokens int, temperature float64) openai.ChatCompletionNewParams {
+	return openai.ChatCompletionNewParams{
+		Model:       shared.ChatModel(p.model),
+		MaxTokens:   openai.Int(int64(maxTokens)),
 		Temperature: openai.Float(temperature),
 		Messages: []openai.ChatCompletionMessageParamUnion{
 			openai.SystemMessage(systemPrompt),
 			openai.UserMessage(userPrompt),
 		},
-	})
-	if err != nil {
-		return "", fmt.Errorf("openai: chat.completions.new: %w", err)
+		// JSON object mode keeps the model from wrapping the response in
+		// markdown code fences. The system prompt asks for JSON, as the API
+		// requires in this mode.
+		ResponseFormat: openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONObject: &shared.ResponseFormatJSONObjectParam{},
+		},
 	}
+}
 
+// openaiResult extracts the first choice's content from resp.
+func openaiResult(resp *openai.ChatCompletion) (CompletionResult, error) {
 	if len(resp.Choices) == 0 {
-		return "", fmt.Errorf("openai: response contained no choices")
+		return CompletionResult{}, fmt.Errorf("openai: response contained no choices")
 	}
 	content := resp.Choices[0].Message.Content
 	if content == "" {
-		return "", fmt.Errorf("openai: response contained no content")
+		return CompletionResult{}, fmt.Errorf("openai: response contained no content")
 	}
-	return content, nil
+	return CompletionResult{
+		Text: content,
+		Usage: Usage{
+			PromptTokens:     int(resp.Usage.PromptTokens),
+			CompletionTokens: int(resp.Usage.CompletionTokens),
+		},
+		Model: resp.Model,
+	}, nil
 }
@@ -0,0 +1,112 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	anthropic "github.com/anthropics/anthropic-sdk-go"
+	openai "github.com/openai/openai-go"
+	"google.golang.org/api/googleapi"
+)
+
+// ErrEmptyResponse is returned when the provider answers successfully but
+// with empty or whitespace-only content, as a truncated stream can. It is a
+// transport problem rather than invalid model output, so it is retried like
+// a transient provider error and never sent for repair.
+var ErrEmptyResponse = errors.New("provider returned empty content")
+
+// retryableStatus lists the HTTP status codes treated as transient:
+// rate limiting (429), server errors (500, 502, 503), and Anthropic's
+// overloaded status (529).
+var retryableStatus = map[int]bool{
+	429: true,
+	500: true,
+	502: true,
+	503: true,
+	529: true,
+}
+
+// statusCode extracts the HTTP status code from a provider SDK error.
+func statusCode(err error) (int, bool) {
+	var ae *anthropic.Error
+	if errors.As(err, &ae) {
+		return ae.StatusCode, true
+	}
+	var oe *openai.Error
+	if errors.As(err, &oe) {
+		return oe.StatusCode, true
+	}
+	var ge *googleapi.Error
+	if errors.As(err, &ge) {
+		return ge.Code, true
+	}
+	return 0, false
+}
+
+// isRetryable reports whether err is a transient provider failure worth
+// retrying: an empty response, or an error with a retryable HTTP status.
+// Context cancellation and other errors (e.g. 401/403 authentication
+// failures) are permanent.
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, ErrEmptyResponse) {
+		return true
+	}
+	code, ok := statusCode(err)
+	return ok && retryableStatus[code]
+}
+
+// backoffDelay returns the delay before retry number attempt (0-based):
+// base doubled per attempt, plus up to 50% random jitter so that concurrent
+// CI jobs do not retry in lockstep.
+func backoffDelay(base time.Duration, attempt int) time.Duration {
+	d := base << attempt
+	if d <= 0 {
+		return 0
+	}
+	return d + rand.N(d/2+1)
+}
+
+// completeWithRetry calls p.Complete, or CompleteStream when opts.Stream is
+// set, retrying transient failures up to opts.MaxRetries times with
+// exponential backoff. A response with empty or whitespace-only text counts
+// as a failure with ErrEmptyResponse. It stops early when ctx is done. The
+// last error is returned when every attempt fails.
+func completeWithRetry(ctx context.Context, p Provider, systemPrompt, userPrompt string, opts Options) (CompletionResult, error) {
+	for attempt := 0; ; attempt++ {
+		res, err := complete(ctx, p, systemPrompt, userPrompt, opts)
+		if err == nil && strings.TrimSpace(res.Text) == "" {
+			err = ErrEmptyResponse
+		}
+		if err == nil {
+			return res, nil
+		}
+		if attempt >= opts.MaxRetries || !isRetryable(err) {
+			return CompletionResult{}, err
+		}
+		timer := time.NewTimer(backoffDelay(opts.RetryBaseDelay, attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return CompletionResult{}, fmt.Errorf("%w (retry aborted: %v)", err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// complete makes one provider call, streaming to opts.Stream when it is set.
+func complete(ctx context.Context, p Provider, systemPrompt, userPrompt string, opts Options) (CompletionResult, error) {
+	if opts.Stream == nil {
+		return p.Complete(ctx, systemPrompt, userPrompt, opts.MaxTokens, opts.Temperature)
+	}
+	res, err := CompleteStream(ctx, p, systemPrompt, userPrompt, opts.MaxTokens, opts.Temperature, opts.Stream)
+	_, _ = io.WriteString(opts.Stream, "\n") // end the progress output, even on error
+	return res, err
+}
@@ -0,0 +1,215 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	anthropic "github.com/anthropics/anthropic-sdk-go"
+	"google.golang.org/api/googleapi"
+
+	"github.com/dshills/realitycheck/internal/codeindex"
+)
+
+// flakyProvider fails with errs in order, then returns response.
+type flakyProvider struct {
+	errs      []error
+	response  string
+	callCount int
+}
+
+func (f *flakyProvider) Complete(_ context.Context, _, _ string, _ int, _ float64) (CompletionResult, error) {
+	f.callCount++
+	if f.callCount <= len(f.errs) {
+		return CompletionResult{}, f.errs[f.callCount-1]
+	}
+	return CompletionResult{Text: f.response}, nil
+}
+
+func TestIsRetryable(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"anthropic 429", &anthropic.Error{StatusCode: 429}, true},
+		{"anthropic 529 wrapped", fmt.Errorf("anthropic: messages.new: %w", &anthropic.Error{StatusCode: 529}), true},
+		{"anthropic 401", &anthropic.Error{StatusCode: 401}, false},
+		{"google 503", &googleapi.Error{Code: 503}, true},
+		{"google 403", &googleapi.Error{Code: 403}, false},
+		{"plain error", errors.New("boom"), false},
+		{"deadline", context.DeadlineExceeded, false},
+	}
+	for _, c := range cases {
+		if got := isRetryable(c.err); got != c.want {
+			t.Errorf("%s: isRetryable = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestCompleteWithRetry_RecoversFromTransientErrors(t *testing.T) {
+	p := &flakyProvider{
+		errs:     []error{&anthropic.Error{StatusCode: 429}, &anthropic.Error{StatusCode: 503}},
+		response: "ok",
+	}
+	got, err := completeWithRetry(context.Background(), p, "sys", "user",
+		Options{MaxRetries: 2, RetryBaseDelay: time.Millisecond})
+	if err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if got.Text != "ok" || p.callCount != 3 {
+		t.Errorf("got %q after %d calls, want %q after 3", got.Text, p.callCount, "ok")
+	}
+}
+
+func TestCompleteWithRetry_ExhaustsRetries(t *testing.T) {
+	p := &flakyProvider{errs: []error{
+		&anthropic.Error{StatusCode: 529},
+		&anthropic.Error{StatusCode: 529},
+		&anthropic.Error{StatusCode: 529},
+	}}
+	_, err := completeWithRetry(context.Background(), p, "sys", "user",
+		Options{MaxRetries: 1, RetryBaseDelay: time.Millisecond})
+	if err == nil {
+		t.Fatal("expected error after exhausting retries")
+	}
+	if p.callCount != 2 {
+		t.Errorf("callCount = %d, want 2 (initial + 1 retry)", p.callCount)
+	}
+}
+
+func TestCompleteWithRetry_PermanentErrorNotRetried(t *testing.T) {
+	p := &flakyProvider{errs: []error{&anthropic.Error{StatusCode: 401}}, response: "ok"}
+	_, err := completeWithRetry(context.Background(), p, "sys", "user",
+		Options{MaxRetries: 3, RetryBaseDelay: time.Millisecond})
+	if err == nil {
+		t.Fatal("expected auth error to be returned")
+	}
+	if p.callCount != 1 {
+		t.Errorf("callCount = %d, want 1 (no retry on auth failure)", p.callCount)
+	}
+}
+
+func TestCompleteWithRetry_StopsWhenContextDone(t *testing.T) {
+	p := &flakyProvider{errs: []error{&anthropic.Error{StatusCode: 429}}, response: "ok"}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := completeWithRetry(ctx, p, "sys", "user",
+		Options{MaxRetries: 3, RetryBaseDelay: time.Hour})
+	if err == nil {
+		t.Fatal("expected error when context is done before retry")
+	}
+	if p.callCount != 1 {
+		t.Errorf("callCount = %d, want 1", p.callCount)
+	}
+}
+
+func TestCompleteWithRetry_EmptyResponse(t *testing.T) {
+	// A blank response is retried like a transient error.
+	mp := &mockProvider{responses: []string{"", " \n\t", "ok"}}
+	got, err := completeWithRetry(context.Background(), mp, "sys", "user",
+		Options{MaxRetries: 2, RetryBaseDelay: time.Millisecond})
+	if err != nil || got.Text != "ok" || mp.callCount != 3 {
+		t.Fatalf("got %q, %v after %d calls; want %q after 3", got.Text, err, mp.callCount, "ok")
+	}
+
+	mp = &mockProvider{responses: []string{""}}
+	_, err = completeWithRetry(context.Background(), mp, "sys", "user",
+		Options{MaxRetries: 1, RetryBaseDelay: time.Millisecond})
+	if !errors.Is(err, ErrEmptyResponse) || err.Error() != "provider returned empty content" {
+		t.Errorf("err = %v, want ErrEmptyResponse", err)
+	}
+	if mp.callCount != 2 {
+		t.Errorf("callCount = %d, want 2 (initial + 1 retry)", mp.callCount)
+	}
+}
+
+func TestAnalyze_EmptyResponseIsNotRepaired(t *testing.T) {
+	mp := &mockProvider{responses: []string{""}}
+	installMock(t, mp)
+	_, err := Analyze(context.Background(), nil, nil, codeindex.Index{}, loadGeneralProfile(t), Options{Provider: "anthropic", Model: "test"})
+	if !errors.Is(err, ErrEmptyResponse) || errors.Is(err, ErrInvalidModelOutput) {
+		t.Errorf("err = %v, want ErrEmptyResponse rather than invalid output", err)
+	}
+	if mp.callCount != 1 {
+		t.Errorf("callCount = %d, want 1: no repair for an empty response", mp.callCount)
+	}
+}
+
+func TestBackoffDelay_Grows(t *testing.T) {
+	base := 100 * time.Millisecond
+	for attempt := 0; attempt < 4; attempt++ {
+		d := backoffDelay(base, attempt)
+		min := base << attempt
+		if d < min || d > min+min/2 {
+			t.Errorf("attempt %d: delay %v outside [%v, %v]", attempt, d, min, min+min/2)
+		}
+	}
+}
+
+// chunkedProvider streams response in fixed-size chunks.
+type chunkedProvider struct {
+	flakyProvider
+	chunk   int
+	streams int
+}
+
+func (c *chunkedProvider) CompleteStream(ctx context.Context, sys, user string, maxTokens int, temp float64, w io.Writer) (CompletionResult, error) {
+	c.streams++
+	res, err := c.Complete(ctx, sys, user, maxTokens, temp)
+	if err != nil {
+		return res, err
+	}
+	for s := res.Text; s != ""; {
+		n := min(c.chunk, len(s))
+		if _, err := io.WriteString(w, s[:n]); err != nil {
+			return CompletionResult{}, err
+		}
+		s = s[n:]
+	}
+	return res, nil
+}
+
+func TestCompleteWithRetry_Stream(t *testing.T) {
+	const response = `{"coverage":{}}`
+	var buf bytes.Buffer
+	p := &chunkedProvider{flakyProvider: flakyProvider{response: response}, chunk: 4}
+	res, err := completeWithRetry(context.Background(), p, "sys", "user", Options{Stream: &buf})
+	if err != nil {
+		t.Fatalf("completeWithRetry: %v", err)
+	}
+	if p.streams != 1 {
+		t.Errorf("CompleteStream calls = %d, want 1", p.streams)
+	}
+	if res.Text != response {
+		t.Errorf("result text = %q, want %q", res.Text, response)
+	}
+	if buf.String() != response+"\n" {
+		t.Errorf("stream output = %q, want the response and a newline", buf.String())
+	}
+
+	// Without Stream, the provider is not streamed.
+	p.callCount = 0
+	if _, err := completeWithRetry(context.Background(), p, "sys", "user", Options{}); err != nil {
+		t.Fatalf("completeWithRetry: %v", err)
+	}
+	if p.streams != 1 {
+		t.Errorf("CompleteStream calls = %d, want still 1", p.streams)
+	}
+}
+
+func TestCompleteStream_FallsBackToComplete(t *testing.T) {
+	var buf bytes.Buffer
+	p := &flakyProvider{response: "ok"}
+	res, err := CompleteStream(context.Background(), p, "sys", "user", 10, 0, &buf)
+	if err != nil {
+		t.Fatalf("CompleteStream: %v", err)
+	}
+	if res.Text != "ok" || buf.String() != "ok" {
+		t.Errorf("result %q, output %q; want both \"ok\"", res.Text, buf.String())
+	}
+}
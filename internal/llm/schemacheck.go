@@ -0,0 +1,116 @@
+package llm
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// partialReportSchemaJSON is the JSON Schema describing the structure of
+// schema.PartialReport as the model must emit it.
+//
+//go:embed partial_report.schema.json
+var partialReportSchemaJSON []byte
+
+const partialReportSchemaURL = "partial_report.schema.json"
+
+// partialReportSchema, summaryReportSchema, and explainReportSchema are
+// compiled once at package init; a failure here is a programming error in
+// the embedded schema.
+var (
+	partialReportSchema = mustCompileSchema(partialReportSchemaURL)
+	summaryReportSchema = mustCompileSchema(partialReportSchemaURL + "#/$defs/summaryReport")
+	explainReportSchema = mustCompileSchema(partialReportSchemaURL + "#/$defs/explainReport")
+)
+
+func mustCompileSchema(ref string) *jsonschema.Schema {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(partialReportSchemaJSON))
+	if err != nil {
+		panic(fmt.Sprintf("llm: parse embedded schema: %v", err))
+	}
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource(partialReportSchemaURL, doc); err != nil {
+		panic(fmt.Sprintf("llm: add embedded schema: %v", err))
+	}
+	sch, err := c.Compile(ref)
+	if err != nil {
+		panic(fmt.Sprintf("llm: compile embedded schema: %v", err))
+	}
+	return sch
+}
+
+// validateSchema checks raw against sch, one of the embedded schemas, and
+// returns one ValidationError (Field "schema") per structural failure, such
+// as a missing required property or a value of the wrong JSON type. raw must
+// already be syntactically valid JSON.
+func validateSchema(raw []byte, sch *jsonschema.Schema) []ValidationError {
+	inst, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return []ValidationError{{Field: "schema", Message: err.Error()}}
+	}
+	err = sch.Validate(inst)
+	if err == nil {
+		return nil
+	}
+	var verr *jsonschema.ValidationError
+	if !errors.As(err, &verr) {
+		return []ValidationError{{Field: "schema", Message: err.Error()}}
+	}
+
+	// Only leaf errors describe concrete failures; the others are "doesn't
+	// validate with ..." wrappers for an enclosing $ref or subschema.
+	printer := message.NewPrinter(language.English)
+	var msgs []string
+	seen := make(map[string]bool)
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) > 0 {
+			for _, c := range e.Causes {
+				walk(c)
+			}
+			return
+		}
+		msg := fmt.Sprintf("%s: %s", instancePath(e.InstanceLocation), e.ErrorKind.LocalizedString(printer))
+		if !seen[msg] {
+			seen[msg] = true
+			msgs = append(msgs, msg)
+		}
+	}
+	walk(verr)
+	sort.Strings(msgs)
+
+	errs := make([]ValidationError, 0, len(msgs))
+	for _, m := range msgs {
+		errs = append(errs, ValidationError{Field: "schema", Message: m})
+	}
+	return errs
+}
+
+// instancePath renders a JSON instance location such as
+// ["drift", "0", "blocking"] in the field notation used elsewhere in
+// validation messages ("drift[0].blocking").
+func instancePath(loc []string) string {
+	if len(loc) == 0 {
+		return "(root)"
+	}
+	var sb strings.Builder
+	for _, tok := range loc {
+		if _, err := strconv.Atoi(tok); err == nil {
+			fmt.Fprintf(&sb, "[%s]", tok)
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(tok)
+	}
+	return sb.String()
+}
@@ -0,0 +1,114 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/codeindex"
+)
+
+const schemaTestCoverage = `"coverage":{"spec":[],"plan":[]}`
+
+func schemaErrors(errs []ValidationError) []string {
+	var msgs []string
+	for _, e := range errs {
+		if e.Field == "schema" {
+			msgs = append(msgs, e.Message)
+		}
+	}
+	return msgs
+}
+
+func TestValidateResponse_SchemaTypeMismatch(t *testing.T) {
+	raw := `{` + schemaTestCoverage + `,"drift":[],"violations":[
+		{"id":"VIOLATION-001","severity":"CRITICAL","description":"d","evidence":[],"blocking":"true"}
+	]}`
+	report, errs := ValidateResponse(raw, codeindex.Index{})
+	if report != nil {
+		t.Error("expected nil report for a schema violation")
+	}
+	msgs := schemaErrors(errs)
+	if len(msgs) != 1 || !strings.HasPrefix(msgs[0], "violations[0].blocking:") {
+		t.Errorf("schema errors = %q, want one for violations[0].blocking", msgs)
+	}
+	if !NeedsRepair(errs) {
+		t.Error("schema errors should trigger repair")
+	}
+}
+
+func TestValidateResponse_SchemaMissingField(t *testing.T) {
+	raw := `{` + schemaTestCoverage + `,"drift":[
+		{"id":"DRIFT-001","severity":"WARN","description":"d","evidence":[]}
+	],"violations":[]}`
+	_, errs := ValidateResponse(raw, codeindex.Index{})
+	msgs := schemaErrors(errs)
+	if len(msgs) != 1 || !strings.Contains(msgs[0], "why_unjustified") {
+		t.Errorf("schema errors = %q, want one naming why_unjustified", msgs)
+	}
+}
+
+func TestValidateResponse_SchemaValid(t *testing.T) {
+	_, errs := ValidateResponse(responseWithPath("internal/store/store.go"), testIndex())
+	if msgs := schemaErrors(errs); len(msgs) != 0 {
+		t.Errorf("unexpected schema errors for a valid response: %q", msgs)
+	}
+}
+
+func TestValidateResponse_EnumKeepsTargetedMessage(t *testing.T) {
+	// Enum values are not in the schema, so an invalid status yields the
+	// targeted non-fatal message rather than a schema error.
+	raw := `{"coverage":{"spec":[{"id":"SPEC-001","status":"DONE","evidence":[]}],"plan":[]},"drift":[],"violations":[]}`
+	report, errs := ValidateResponse(raw, codeindex.Index{})
+	if report == nil {
+		t.Fatal("expected a report for an enum-only issue")
+	}
+	if msgs := schemaErrors(errs); len(msgs) != 0 {
+		t.Errorf("unexpected schema errors: %q", msgs)
+	}
+	found := false
+	for _, e := range errs {
+		if e.Field == "coverage.spec[0].status" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected targeted status error, got %v", errs)
+	}
+}
+
+func TestAnalyze_SchemaErrorTriggersRepair(t *testing.T) {
+	bad := `{` + schemaTestCoverage + `,"drift":[],"violations":[
+		{"id":"VIOLATION-001","severity":"CRITICAL","description":"d","evidence":[],"blocking":"true"}
+	]}`
+	mp := &mockProvider{responses: []string{bad, minimalValidResponse()}}
+	installMock(t, mp)
+
+	report, err := Analyze(context.Background(), nil, nil, codeindex.Index{}, loadGeneralProfile(t), Options{})
+	if err != nil {
+		t.Fatalf("Analyze error: %v", err)
+	}
+	if report == nil {
+		t.Fatal("expected report after repair")
+	}
+	if mp.callCount != 2 {
+		t.Errorf("expected 2 provider calls (initial + repair), got %d", mp.callCount)
+	}
+}
+
+func TestInstancePath(t *testing.T) {
+	cases := []struct {
+		loc  []string
+		want string
+	}{
+		{nil, "(root)"},
+		{[]string{"coverage", "spec"}, "coverage.spec"},
+		{[]string{"drift", "0", "blocking"}, "drift[0].blocking"},
+		{[]string{"violations", "2", "evidence", "1", "path"}, "violations[2].evidence[1].path"},
+	}
+	for _, c := range cases {
+		if got := instancePath(c.loc); got != c.want {
+			t.Errorf("instancePath(%q) = %q, want %q", c.loc, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package llm
+
+import (
+	"sort"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+// summaryFindings is the number of findings kept in summary mode.
+const summaryFindings = 3
+
+// summaryOutputSchema replaces outputSchema when Options.SummaryOnly is set.
+const summaryOutputSchema = `Summary mode is active. Do not report coverage. Return a short rationale for how well the
+code matches the spec and plan, and at most 3 findings in total across drift and
+violations: the most severe ones.
+
+Output schema (JSON only):
+{
+  "rationale": "one to three sentences",
+  "drift": [
+    {
+      "id": "DRIFT-001",
+      "severity": "INFO|WARN|CRITICAL",
+      "description": "...",
+      "evidence": [{"path": "relative/file.go", "symbol": "FuncName", "confidence": "HIGH|MEDIUM|LOW"}],
+      "why_unjustified": "..."
+    }
+  ],
+  "violations": [
+    {
+      "id": "VIOLATION-001",
+      "severity": "INFO|WARN|CRITICAL",
+      "description": "...",
+      "evidence": [{"path": "relative/file.go", "symbol": "FuncName", "confidence": "HIGH|MEDIUM|LOW"}],
+      "blocking": true
+    }
+  ],
+  "meta": {
+    "model": "<model-name>",
+    "temperature": 0.2
+  }
+}
+`
+
+// keepTopFindings trims r to the n most severe drift findings and
+// violations combined. Among findings of equal severity the model's order is
+// kept, violations first; the survivors stay in their original order.
+func keepTopFindings(r *schema.PartialReport, n int) {
+	if len(r.Drift)+len(r.Violations) <= n {
+		return
+	}
+	type ref struct {
+		drift bool
+		i     int
+		rank  int
+	}
+	refs := make([]ref, 0, len(r.Drift)+len(r.Violations))
+	for i, v := range r.Violations {
+		refs = append(refs, ref{i: i, rank: severityRank(v.Severity)})
+	}
+	for i, d := range r.Drift {
+		refs = append(refs, ref{drift: true, i: i, rank: severityRank(d.Severity)})
+	}
+	sort.SliceStable(refs, func(a, b int) bool { return refs[a].rank > refs[b].rank })
+
+	keepDrift := make(map[int]bool)
+	keepViol := make(map[int]bool)
+	for _, x := range refs[:n] {
+		if x.drift {
+			keepDrift[x.i] = true
+		} else {
+			keepViol[x.i] = true
+		}
+	}
+	drift := make([]schema.DriftFinding, 0, len(keepDrift))
+	for i, d := range r.Drift {
+		if keepDrift[i] {
+			drift = append(drift, d)
+		}
+	}
+	violations := make([]schema.Violation, 0, len(keepViol))
+	for i, v := range r.Violations {
+		if keepViol[i] {
+			violations = append(violations, v)
+		}
+	}
+	r.Drift, r.Violations = drift, violations
+}
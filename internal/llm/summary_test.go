@@ -0,0 +1,86 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+// summaryResponse is a reduced summary-mode response with four findings
+// citing the testIndex file.
+const summaryResponse = `{
+  "rationale": "Core storage is in place; eviction is undocumented.",
+  "drift": [
+    {"id":"DRIFT-001","severity":"INFO","description":"Extra debug logging","evidence":[{"path":"internal/store/store.go"}],"why_unjustified":"Not in spec."},
+    {"id":"DRIFT-002","severity":"WARN","description":"Undocumented TTL eviction","evidence":[{"path":"internal/store/store.go"}],"why_unjustified":"Not in spec."}
+  ],
+  "violations": [
+    {"id":"VIOLATION-001","severity":"CRITICAL","description":"Writes outside the store","evidence":[{"path":"internal/store/store.go"}],"blocking":true},
+    {"id":"VIOLATION-002","severity":"WARN","description":"Unbounded cache","evidence":[{"path":"internal/store/store.go"}],"blocking":false}
+  ]
+}`
+
+func TestBuildSystemPrompt_SummaryOnly(t *testing.T) {
+	prof := loadGeneralProfile(t)
+	sys := BuildSystemPrompt(prof, Options{SummaryOnly: true, Traceability: true, RequireTests: true})
+	if !strings.Contains(sys, `"rationale"`) || strings.Contains(sys, `"coverage"`) {
+		t.Error("summary system prompt should use the reduced schema")
+	}
+	if strings.Contains(sys, "Traceability is requested") || strings.Contains(sys, "Tests are required") {
+		t.Error("summary system prompt should leave out traceability and require-tests instructions")
+	}
+}
+
+func TestAnalyze_SummaryOnly(t *testing.T) {
+	mp := &mockProvider{responses: []string{summaryResponse}}
+	installMock(t, mp)
+
+	report, err := Analyze(context.Background(), nil, nil, testIndex(), loadGeneralProfile(t),
+		Options{MaxTokens: 100, Model: "test-model", SummaryOnly: true})
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if mp.callCount != 1 {
+		t.Errorf("calls = %d, want 1 (no repair for the reduced shape)", mp.callCount)
+	}
+	if report.Coverage.Spec == nil || report.Coverage.Plan == nil || len(report.Coverage.Spec)+len(report.Coverage.Plan) != 0 {
+		t.Errorf("coverage = %+v, want empty, non-nil arrays", report.Coverage)
+	}
+	if !strings.HasPrefix(report.Rationale, "Core storage") {
+		t.Errorf("rationale = %q", report.Rationale)
+	}
+	// The INFO drift finding is the least severe of four and is dropped.
+	if len(report.Drift) != 1 || report.Drift[0].Severity != schema.SeverityWarn || len(report.Violations) != 2 {
+		t.Errorf("drift = %+v, violations = %+v; want the three most severe", report.Drift, report.Violations)
+	}
+}
+
+func TestValidateResponse_SummaryRequiresRationale(t *testing.T) {
+	_, errs := validateResponse(`{"drift": []}`, testIndex(), Options{SummaryOnly: true})
+	if !NeedsRepair(errs) {
+		t.Errorf("errs = %v, want a schema error for the missing rationale", errs)
+	}
+	// The full schema still requires coverage.
+	if _, errs := ValidateResponse(summaryResponse, testIndex()); !NeedsRepair(errs) {
+		t.Error("a summary response should not pass full validation")
+	}
+}
+
+func TestKeepTopFindings(t *testing.T) {
+	r := &schema.PartialReport{
+		Drift: []schema.DriftFinding{
+			{ID: "DRIFT-001", Severity: schema.SeverityWarn},
+			{ID: "DRIFT-002", Severity: schema.SeverityCritical},
+		},
+		Violations: []schema.Violation{
+			{ID: "VIOLATION-001", Severity: schema.SeverityInfo},
+			{ID: "VIOLATION-002", Severity: schema.SeverityWarn},
+		},
+	}
+	keepTopFindings(r, 3)
+	if len(r.Drift) != 2 || r.Drift[0].ID != "DRIFT-001" || len(r.Violations) != 1 || r.Violations[0].ID != "VIOLATION-002" {
+		t.Errorf("drift = %+v, violations = %+v", r.Drift, r.Violations)
+	}
+}
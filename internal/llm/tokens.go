@@ -0,0 +1,53 @@
+package llm
+
+import "strings"
+
+// Tokenizer counts the tokens a model would see in a text.
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// ApproxTokenizer estimates token counts with the common rule of thumb of
+// about four bytes of English or code per token. Actual counts vary by
+// model, usually within a few tens of percent.
+type ApproxTokenizer struct{}
+
+// CountTokens returns len(text)/4, rounded up.
+func (ApproxTokenizer) CountTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// TokenizerFor returns the tokenizer used to estimate prompts for provider.
+// No provider's exact tokenizer is bundled, so every provider currently gets
+// ApproxTokenizer.
+func TokenizerFor(provider string) Tokenizer {
+	return ApproxTokenizer{}
+}
+
+// modelContextWindows maps model ID prefixes to context window sizes in
+// tokens. Longer prefixes must come first.
+var modelContextWindows = []struct {
+	prefix string
+	tokens int
+}{
+	{"gemini-1.5-pro", 2_000_000},
+	{"gemini-", 1_000_000},
+	{"gpt-4.1", 1_000_000},
+	{"gpt-4o", 128_000},
+	{"o1", 128_000},
+	{"o3", 200_000},
+	{"o4", 200_000},
+	{"claude-", 200_000},
+}
+
+// ContextWindow returns the context window of model in tokens, or 0 when the
+// model is unknown, as Azure deployment names are.
+func ContextWindow(model string) int {
+	model = strings.ToLower(model)
+	for _, m := range modelContextWindows {
+		if strings.HasPrefix(model, m.prefix) {
+			return m.tokens
+		}
+	}
+	return 0
+}
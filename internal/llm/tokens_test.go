@@ -0,0 +1,31 @@
+package llm
+
+import "testing"
+
+func TestApproxTokenizer(t *testing.T) {
+	cases := map[string]int{"": 0, "a": 1, "abcd": 1, "abcde": 2, "func main() {}": 4}
+	for text, want := range cases {
+		if got := (ApproxTokenizer{}).CountTokens(text); got != want {
+			t.Errorf("CountTokens(%q) = %d, want %d", text, got, want)
+		}
+	}
+	if _, ok := TokenizerFor("openai").(ApproxTokenizer); !ok {
+		t.Error("TokenizerFor(openai) should fall back to ApproxTokenizer")
+	}
+}
+
+func TestContextWindow(t *testing.T) {
+	cases := map[string]int{
+		"claude-opus-4-6":  200_000,
+		"gpt-4o-mini":      128_000,
+		"gpt-4.1":          1_000_000,
+		"gemini-1.5-pro":   2_000_000,
+		"gemini-2.5-flash": 1_000_000,
+		"my-deployment":    0,
+	}
+	for model, want := range cases {
+		if got := ContextWindow(model); got != want {
+			t.Errorf("ContextWindow(%q) = %d, want %d", model, got, want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Transcript records the provider responses of one run, with the prompts
+// that produced them, so the run can be replayed without a provider. Set
+// Options.Record to fill one in and pass it to NewReplayProvider to replay
+// it.
+type Transcript struct {
+	// Version is the version of the tool that recorded the transcript.
+	Version string           `json:"version"`
+	Calls   []TranscriptCall `json:"calls"`
+}
+
+// TranscriptCall is one response in a Transcript, in the order the calls
+// were made: the first call, then the repair call, if any, for each prompt.
+type TranscriptCall struct {
+	// Provider, Model, MaxTokens, and Temperature are the settings the
+	// call was made with.
+	Provider     string  `json:"provider"`
+	Model        string  `json:"model"`
+	MaxTokens    int     `json:"max_tokens"`
+	Temperature  float64 `json:"temperature"`
+	SystemPrompt string  `json:"system_prompt"`
+	UserPrompt   string  `json:"user_prompt"`
+	Response     string  `json:"response"`
+	// ResponseModel and ResponseTemperature are what the API reported, if
+	// anything; see CompletionResult.
+	ResponseModel       string   `json:"response_model,omitempty"`
+	ResponseTemperature *float64 `json:"response_temperature,omitempty"`
+	PromptTokens        int      `json:"prompt_tokens"`
+	CompletionTokens    int      `json:"completion_tokens"`
+	// Cached marks a response served from the response cache rather than
+	// the provider.
+	Cached bool `json:"cached,omitempty"`
+}
+
+// add appends a call made with opts to t.
+func (t *Transcript) add(sysPrompt, userPrompt string, res CompletionResult, opts Options, cached bool) {
+	t.Calls = append(t.Calls, TranscriptCall{
+		Provider:            opts.Provider,
+		Model:               opts.Model,
+		MaxTokens:           opts.MaxTokens,
+		Temperature:         opts.Temperature,
+		SystemPrompt:        sysPrompt,
+		UserPrompt:          userPrompt,
+		Response:            res.Text,
+		ResponseModel:       res.Model,
+		ResponseTemperature: res.Temperature,
+		PromptTokens:        res.Usage.PromptTokens,
+		CompletionTokens:    res.Usage.CompletionTokens,
+		Cached:              cached,
+	})
+}
+
+// WriteFile writes t to path as indented JSON.
+func (t *Transcript) WriteFile(path string) error {
+	b, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return fmt.Errorf("llm: marshal transcript: %w", err)
+	}
+	if err := os.WriteFile(path, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("llm: write transcript: %w", err)
+	}
+	return nil
+}
+
+// ReadTranscript reads a transcript written by Transcript.WriteFile. A
+// transcript with no calls is an error, since it cannot be replayed.
+func ReadTranscript(path string) (*Transcript, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("llm: read transcript: %w", err)
+	}
+	var t Transcript
+	if err := json.Unmarshal(b, &t); err != nil {
+		return nil, fmt.Errorf("llm: read transcript %s: %w", path, err)
+	}
+	if len(t.Calls) == 0 {
+		return nil, fmt.Errorf("llm: transcript %s has no calls", path)
+	}
+	return &t, nil
+}
+
+// recordingProvider passes calls through to p and adds each successful one
+// to t. Failed attempts, such as those retried, are not recorded.
+type recordingProvider struct {
+	p    Provider
+	t    *Transcript
+	opts Options
+}
+
+func (r *recordingProvider) Complete(ctx context.Context, systemPrompt, userPrompt string, maxTokens int, temperature float64) (CompletionResult, error) {
+	res, err := r.p.Complete(ctx, systemPrompt, userPrompt, maxTokens, temperature)
+	if err == nil {
+		r.t.add(systemPrompt, userPrompt, res, r.opts, false)
+	}
+	return res, err
+}
+
+func (r *recordingProvider) CompleteStream(ctx context.Context, systemPrompt, userPrompt string, maxTokens int, temperature float64, w io.Writer) (CompletionResult, error) {
+	res, err := CompleteStream(ctx, r.p, systemPrompt, userPrompt, maxTokens, temperature, w)
+	if err == nil {
+		r.t.add(systemPrompt, userPrompt, res, r.opts, false)
+	}
+	return res, err
+}
+
+// replayProvider returns the responses of a transcript in order.
+type replayProvider struct {
+	calls []TranscriptCall
+	next  int
+}
+
+// NewReplayProvider returns a Provider that answers each call with the
+// next response recorded in t, in order, whatever the prompts. A call whose
+// prompts differ from the recorded ones prints a warning to stderr, since
+// the replayed response may no longer fit. Calls beyond the end of t fail.
+// The recorded usage, model, and temperature are returned with each
+// response, so the report's meta matches the recorded run.
+func NewReplayProvider(t *Transcript) Provider {
+	return &replayProvider{calls: t.Calls}
+}
+
+func (p *replayProvider) Complete(_ context.Context, systemPrompt, userPrompt string, _ int, _ float64) (CompletionResult, error) {
+	if p.next >= len(p.calls) {
+		return CompletionResult{}, fmt.Errorf("replay: call %d has no recorded response; the transcript has %d", p.next+1, len(p.calls))
+	}
+	c := p.calls[p.next]
+	p.next++
+	if c.SystemPrompt != systemPrompt || c.UserPrompt != userPrompt {
+		fmt.Fprintf(os.Stderr, "warning: replay: the prompts for call %d differ from the recorded ones\n", p.next)
+	}
+	model, temp := c.ResponseModel, c.ResponseTemperature
+	if model == "" {
+		model = c.Model
+	}
+	if temp == nil {
+		temp = &c.Temperature
+	}
+	return CompletionResult{
+		Text:        c.Response,
+		Usage:       Usage{PromptTokens: c.PromptTokens, CompletionTokens: c.CompletionTokens},
+		Model:       model,
+		Temperature: temp,
+	}, nil
+}
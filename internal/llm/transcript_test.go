@@ -0,0 +1,81 @@
+package llm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/codeindex"
+	"github.com/dshills/realitycheck/internal/plan"
+	"github.com/dshills/realitycheck/internal/spec"
+)
+
+func TestTranscript_RecordReplay(t *testing.T) {
+	mp := &mockProvider{
+		responses: []string{"bad json", minimalValidResponse()},
+		usage:     Usage{PromptTokens: 10, CompletionTokens: 5},
+		model:     "test-model-2025",
+	}
+	installMock(t, mp)
+	prof := loadGeneralProfile(t)
+	rec := &Transcript{Version: "test"}
+	opts := Options{Provider: "anthropic", MaxTokens: 100, Temperature: 0.2, Model: "test-model", Record: rec}
+
+	recorded, err := Analyze(context.Background(), []spec.Item{}, []plan.Item{}, codeindex.Index{}, prof, opts)
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if len(rec.Calls) != 2 {
+		t.Fatalf("recorded %d calls, want the initial call and the repair", len(rec.Calls))
+	}
+	if c := rec.Calls[0]; c.Response != "bad json" || c.Model != "test-model" || c.ResponseModel != "test-model-2025" || c.PromptTokens != 10 || c.UserPrompt == "" {
+		t.Errorf("first call = %+v", c)
+	}
+
+	path := filepath.Join(t.TempDir(), "transcript.json")
+	if err := rec.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := ReadTranscript(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, rec) {
+		t.Errorf("round trip = %+v, want %+v", loaded, rec)
+	}
+
+	// The replay goes through the same validation and repair, so it ends
+	// with the same report, including usage summed over both calls.
+	opts.Record = nil
+	opts.Client = NewReplayProvider(loaded)
+	replayed, err := Analyze(context.Background(), []spec.Item{}, []plan.Item{}, codeindex.Index{}, prof, opts)
+	if err != nil {
+		t.Fatalf("Analyze with replay: %v", err)
+	}
+	if !reflect.DeepEqual(replayed, recorded) {
+		t.Errorf("replayed report = %+v, want %+v", replayed, recorded)
+	}
+	if mp.callCount != 2 {
+		t.Errorf("provider calls = %d, want none during replay", mp.callCount)
+	}
+
+	// A third call has nothing left to replay.
+	if _, err := opts.Client.Complete(context.Background(), "", "", 0, 0); err == nil {
+		t.Error("call beyond the transcript should fail")
+	}
+}
+
+func TestReadTranscript_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.json")
+	if err := os.WriteFile(empty, []byte(`{"version":"x","calls":[]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{empty, filepath.Join(dir, "missing.json")} {
+		if _, err := ReadTranscript(path); err == nil {
+			t.Errorf("ReadTranscript(%s) should fail", filepath.Base(path))
+		}
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -16,6 +18,11 @@ type Item struct {
 	LineStart int
 	LineEnd   int
 	Text      string
+	Source    string // path of the file the item came from; empty for ParseReader
+	// Label is the author's own number for a numbered item, such as "7" for
+	// "Step 7:" or "3" for "3. ...", as returned by Segmenter.Label. It is
+	// empty for other items or when the Segmenter has no Label function.
+	Label string
 }
 
 // IsNumberedItemFn determines whether a line starts a new numbered item.
@@ -28,21 +35,129 @@ type Segmenter struct {
 	// StripPrefix, if set, is called to strip the item prefix from a line before
 	// storing it as item text. Falls back to StripListPrefix if nil.
 	StripPrefix func(line string) string
+	// HonorExplicitIDs keeps IDs the author wrote at the start of an item,
+	// such as "SPEC-007: ...", so that they stay stable across edits. The
+	// label is removed from the item text. Other items are numbered
+	// automatically, skipping numbers already claimed by explicit IDs. An
+	// explicit ID used twice is an error.
+	HonorExplicitIDs bool
+	// Label, if set, is called with the first line of each top-level
+	// numbered item and its result stored as Item.Label.
+	Label func(line string) string
 }
 
 // ParseFile reads the file at path and segments it using s.
 func (s Segmenter) ParseFile(path string) ([]Item, error) {
+	items, err := s.parseFile(path, 0)
+	if err != nil {
+		return nil, err
+	}
+	return s.finish(items)
+}
+
+// ParseFiles parses each file in order and concatenates the resulting items.
+// Numbering continues across files rather than restarting at 001, so IDs
+// never collide; each item's Source records the file it came from.
+func (s Segmenter) ParseFiles(paths []string) ([]Item, error) {
+	var all []Item
+	for _, path := range paths {
+		items, err := s.parseFile(path, len(all))
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+	}
+	return s.finish(all)
+}
+
+// parseFile segments the file at path, numbering items after the first
+// `offset` IDs, and stamps each item with its source path.
+func (s Segmenter) parseFile(path string, offset int) ([]Item, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("mdparse: open %s: %w", path, err)
 	}
 	defer f.Close()
-	return s.ParseReader(f)
+	items, err := s.parseReader(f, offset)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].Source = path
+	}
+	return items, nil
 }
 
 // ParseReader reads from r and segments it using s.
 // This enables testing without requiring files on disk.
 func (s Segmenter) ParseReader(r io.Reader) ([]Item, error) {
+	items, err := s.parseReader(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	return s.finish(items)
+}
+
+// finish applies HonorExplicitIDs to the complete list of parsed items.
+func (s Segmenter) finish(items []Item) ([]Item, error) {
+	if !s.HonorExplicitIDs {
+		return items, nil
+	}
+	return assignExplicitIDs(items, s.IDPrefix)
+}
+
+// assignExplicitIDs renumbers items, keeping each explicit "<prefix>-N:"
+// label as the item's ID and numbering the rest from 1, skipping every N
+// an explicit label claims.
+func assignExplicitIDs(items []Item, prefix string) ([]Item, error) {
+	labelRe := regexp.MustCompile(`^(` + regexp.QuoteMeta(prefix) + `-(\d+)):\s*`)
+	explicit := make([]string, len(items))
+	taken := map[int]bool{}
+	firstLine := map[int]Item{}
+	for i, it := range items {
+		m := labelRe.FindStringSubmatch(it.Text)
+		if m == nil {
+			continue
+		}
+		n, err := strconv.Atoi(m[2])
+		if err != nil {
+			continue // too many digits to be a real ID; leave the text alone
+		}
+		if prev, dup := firstLine[n]; dup {
+			return nil, fmt.Errorf("mdparse: %s is used by the items at %s and %s",
+				m[1], location(prev), location(it))
+		}
+		firstLine[n] = it
+		taken[n] = true
+		explicit[i] = m[1]
+		items[i].Text = strings.TrimSpace(it.Text[len(m[0]):])
+	}
+
+	counter := 0
+	for i := range items {
+		if explicit[i] != "" {
+			items[i].ID = explicit[i]
+			continue
+		}
+		counter++
+		for taken[counter] {
+			counter++
+		}
+		items[i].ID = fmt.Sprintf("%s-%03d", prefix, counter)
+	}
+	return items, nil
+}
+
+// location describes where it came from, for error messages.
+func location(it Item) string {
+	if it.Source != "" {
+		return fmt.Sprintf("%s:%d", it.Source, it.LineStart)
+	}
+	return fmt.Sprintf("line %d", it.LineStart)
+}
+
+// parseReader is ParseReader with IDs numbered after the first `offset`.
+func (s Segmenter) parseReader(r io.Reader, offset int) ([]Item, error) {
 	var lines []string
 	scanner := bufio.NewScanner(r)
 	// Increase buffer to handle long lines (e.g. base64 content in code blocks).
@@ -64,7 +179,7 @@ func (s Segmenter) ParseReader(r io.Reader) ([]Item, error) {
 	if strip == nil {
 		strip = StripListPrefix
 	}
-	return segment(lines, s.IDPrefix, isNum, strip), nil
+	return segment(lines, s.IDPrefix, offset, isNum, strip, s.Label), nil
 }
 
 // fencePrefix returns the opening fence string (e.g. "```" or "~~~~") if line
@@ -128,6 +243,32 @@ func isClosingFence(line, openFence string) bool {
 	return rest == ""
 }
 
+// FencedLines reports, for each line, whether it belongs to a fenced code
+// block, the fence lines included. Fences are tracked across the whole
+// document, including those inside list items. open is the 1-based line of a fence
+// that is never closed, or 0.
+func FencedLines(lines []string) (fenced []bool, open int) {
+	fenced = make([]bool, len(lines))
+	var openFence string
+	for i, line := range lines {
+		switch {
+		case openFence != "":
+			fenced[i] = true
+			if isClosingFence(line, openFence) {
+				openFence = ""
+			}
+		case fencePrefix(line) != "":
+			fenced[i] = true
+			openFence = fencePrefix(line)
+			open = i + 1
+		}
+	}
+	if openFence == "" {
+		open = 0
+	}
+	return fenced, open
+}
+
 // collectContinuation collects indented continuation lines (and their fenced code
 // blocks) starting at lines[i]. addLn is called for each accepted line.
 // Returns the updated index into lines.
@@ -138,6 +279,9 @@ func isClosingFence(line, openFence string) bool {
 //   - A blank line terminates continuation (fence-free context only). Because the
 //     blank-line check runs after the fence check, blank lines inside a fenced
 //     code block are treated as code content and do not terminate the item.
+//     The exception is a blank line followed by an indented numbered sub-item
+//     ("   1. ..."): loose ordered sub-lists stay with their parent item rather
+//     than being flushed as siblings. The blank lines themselves are dropped.
 //   - A fence opener is only accepted as continuation when it is indented, to
 //     avoid silently merging document-level code blocks into the preceding list
 //     item. Once the fence is open, all subsequent lines (including blank lines)
@@ -172,8 +316,17 @@ func collectContinuation(lines []string, i int, addLn func(lineNum int, text str
 			i++
 			continue
 		}
-		// A blank line terminates continuation (fence-free context only).
+		// A blank line terminates continuation (fence-free context only),
+		// unless an indented numbered sub-item follows it.
 		if strings.TrimSpace(next) == "" {
+			j := i + 1
+			for j < len(lines) && strings.TrimSpace(lines[j]) == "" {
+				j++
+			}
+			if j < len(lines) && isNumberedSubItem(lines[j]) {
+				i = j
+				continue
+			}
 			break
 		}
 		if IsIndented(next) {
@@ -186,9 +339,15 @@ func collectContinuation(lines []string, i int, addLn func(lineNum int, text str
 	return i
 }
 
-func segment(lines []string, prefix string, isNum IsNumberedItemFn, strip func(string) string) []Item {
+// isNumberedSubItem reports whether line is an indented "N. " or "N) " item,
+// i.e. a step of an ordered sub-list nested under a list item.
+func isNumberedSubItem(line string) bool {
+	return IsIndented(line) && DefaultIsNumberedItem(line)
+}
+
+func segment(lines []string, prefix string, offset int, isNum IsNumberedItemFn, strip, label func(string) string) []Item {
 	var items []Item
-	counter := 0
+	counter := offset
 
 	nextID := func() string {
 		counter++
@@ -199,6 +358,7 @@ func segment(lines []string, prefix string, isNum IsNumberedItemFn, strip func(s
 		lineStart int
 		lineEnd   int // last consumed line (1-indexed), updated as lines are added
 		buf       []string
+		label     string
 	}
 
 	addLine := func(p *pending, lineNum int, text string) {
@@ -221,6 +381,7 @@ func segment(lines []string, prefix string, isNum IsNumberedItemFn, strip func(s
 			LineStart: p.lineStart,
 			LineEnd:   p.lineEnd,
 			Text:      text,
+			Label:     p.label,
 		})
 	}
 
@@ -266,9 +427,7 @@ func segment(lines []string, prefix string, isNum IsNumberedItemFn, strip func(s
 		}
 
 		// Any-level ATX heading — flush current item; heading itself is not an item.
-		// Limitation: setext-style headings (text underlined with --- or ===) are
-		// not supported. The underline is treated as a decorator and flushed, while
-		// the preceding text line becomes a standalone item.
+		// Setext-style headings are handled in the paragraph branch below.
 		if IsHeading(line) {
 			if cur != nil {
 				flush(cur)
@@ -296,6 +455,9 @@ func segment(lines []string, prefix string, isNum IsNumberedItemFn, strip func(s
 				flush(cur)
 			}
 			cur = &pending{lineStart: lineNum, lineEnd: lineNum}
+			if label != nil {
+				cur.label = label(line)
+			}
 			addLine(cur, lineNum, strip(line))
 			i++ // advance past the current item line
 			// collectContinuation is synchronous; cur is not reassigned until
@@ -337,6 +499,35 @@ func segment(lines []string, prefix string, isNum IsNumberedItemFn, strip func(s
 			continue
 		}
 
+		// GFM table — a header row followed by a delimiter row such as
+		// "---|:---:". Each data row becomes its own item, labelled by the
+		// header cells; the header and delimiter rows are consumed.
+		if i+1 < len(lines) && isTableHeader(line, lines[i+1]) {
+			if cur != nil {
+				flush(cur)
+				cur = nil
+			}
+			header := splitTableRow(line)
+			i += 2
+			for i < len(lines) && isTableRow(lines[i]) {
+				text := tableRowText(header, splitTableRow(lines[i]))
+				flush(&pending{lineStart: i + 1, lineEnd: i + 1, buf: []string{text}})
+				i++
+			}
+			continue
+		}
+
+		// Indented numbered sub-item — merge into current item, like an
+		// indented bullet.
+		if isNumberedSubItem(line) {
+			if cur == nil {
+				cur = &pending{lineStart: lineNum, lineEnd: lineNum}
+			}
+			addLine(cur, lineNum, strings.TrimSpace(line))
+			i++
+			continue
+		}
+
 		// Horizontal rule / decorator — flush.
 		if IsDecorator(line) {
 			if cur != nil {
@@ -347,6 +538,18 @@ func segment(lines []string, prefix string, isNum IsNumberedItemFn, strip func(s
 			continue
 		}
 
+		// Setext heading — a line that would start a new paragraph, immediately
+		// followed by a === or --- underline. The pair is a heading: neither line
+		// becomes an item. This must run before the decorator check sees the
+		// underline on the next iteration.
+		// Limitation: only single-line setext headings are recognized. When the
+		// text line continues an existing paragraph (cur != nil), the underline
+		// falls through to the decorator handler and flushes the paragraph.
+		if cur == nil && i+1 < len(lines) && IsSetextUnderline(lines[i+1]) {
+			i += 2
+			continue
+		}
+
 		// Paragraph / continuation. Note: paragraph lines are appended verbatim
 		// (preserving indentation), while list-item continuation lines are
 		// TrimSpace'd by collectContinuation. This asymmetry is intentional.
@@ -386,6 +589,78 @@ func DefaultIsNumberedItem(line string) bool {
 	return false
 }
 
+// IsTableDelimiter returns true for a GFM table delimiter row: cells of one
+// or more '-' with optional ':' alignment markers, separated by '|', e.g.
+// "| --- | :---: |" or "---|---".
+func IsTableDelimiter(line string) bool {
+	if !strings.Contains(line, "|") {
+		return false
+	}
+	for _, cell := range splitTableRow(line) {
+		c := strings.TrimSuffix(strings.TrimPrefix(cell, ":"), ":")
+		if c == "" || strings.Trim(c, "-") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// isTableHeader reports whether line and next open a GFM table: next is a
+// delimiter row with as many cells as line.
+func isTableHeader(line, next string) bool {
+	return strings.Contains(line, "|") && !IsTableDelimiter(line) && IsTableDelimiter(next) &&
+		len(splitTableRow(line)) == len(splitTableRow(next))
+}
+
+// isTableRow reports whether line continues a table. A blank line, a
+// heading, a fence, or a line without '|' ends it.
+func isTableRow(line string) bool {
+	return strings.Contains(line, "|") && !IsHeading(line) && fencePrefix(line) == ""
+}
+
+// splitTableRow returns the trimmed cells of a table row. Leading and
+// trailing pipes are optional, and an escaped pipe ("\|") stays in its cell.
+func splitTableRow(line string) []string {
+	t := strings.TrimSpace(line)
+	t = strings.TrimPrefix(t, "|")
+	if strings.HasSuffix(t, "|") && !strings.HasSuffix(t, `\|`) {
+		t = t[:len(t)-1]
+	}
+	var cells []string
+	var cell strings.Builder
+	for j := 0; j < len(t); j++ {
+		switch {
+		case t[j] == '\\' && j+1 < len(t) && t[j+1] == '|':
+			cell.WriteByte('|')
+			j++
+		case t[j] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(t[j])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// tableRowText labels each non-empty cell with its header, e.g.
+// "Field=email; Type=string; Required=yes". Cells beyond the header are
+// dropped, as in GFM.
+func tableRowText(header, cells []string) string {
+	var parts []string
+	for j, h := range header {
+		if j >= len(cells) || cells[j] == "" {
+			continue
+		}
+		if h == "" {
+			parts = append(parts, cells[j])
+			continue
+		}
+		parts = append(parts, h+"="+cells[j])
+	}
+	return strings.Join(parts, "; ")
+}
+
 // IsBullet returns true for lines starting with "- ", "* ", or "• " (after trim).
 // '•' is U+2022 BULLET (3 bytes in UTF-8); strings.HasPrefix operates on bytes
 // so the comparison is correct.
@@ -426,9 +701,9 @@ func IsHeading(line string) bool {
 // Requires all-same characters to avoid false positives on mixed-character lines.
 // Spaced patterns like "* * *" or "- - -" are not detected as decorators.
 //
-// Limitation: setext heading underlines (--- or === under text) are treated as
-// decorators and flushed; the preceding paragraph text becomes a standalone item.
-// Setext-style headings are not supported by this parser.
+// Setext heading underlines also satisfy IsDecorator; segment checks for them
+// (via IsSetextUnderline) on the line before, so an underline directly below
+// heading text is consumed as part of the heading and never reaches here.
 func IsDecorator(line string) bool {
 	trimmed := strings.TrimSpace(line)
 	if len(trimmed) == 0 {
@@ -452,6 +727,25 @@ func IsDecorator(line string) bool {
 	return count >= 3
 }
 
+// IsSetextUnderline returns true for a setext heading underline: three or
+// more '=' or '-' characters with nothing else on the line apart from up to
+// three leading spaces and any trailing spaces. The three-character minimum
+// matches IsDecorator and avoids treating a stray "-" or "=" as an underline.
+func IsSetextUnderline(line string) bool {
+	leading := 0
+	for leading < len(line) && line[leading] == ' ' {
+		leading++
+	}
+	if leading >= 4 {
+		return false
+	}
+	t := strings.TrimRight(line[leading:], " \t")
+	if len(t) < 3 || (t[0] != '=' && t[0] != '-') {
+		return false
+	}
+	return strings.Count(t, t[:1]) == len(t)
+}
+
 // StripListPrefix removes "N. ", "N) ", "- ", "* ", or "• " from the start of
 // a line. The '.' and ')' separators are ASCII (single-byte), so byte-level
 // indexing after the digit scan is safe. Returns the trimmed text unchanged if
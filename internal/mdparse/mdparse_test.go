@@ -19,6 +19,7 @@ func TestDefaultIsNumberedItem(t *testing.T) {
 		{"foo", false},
 		{"1.no space", false},
 		{"1)no space", false},
+		{"4 threads should share one pool", false},
 		{"  1. indented", true}, // indented but IsNumberedItem doesn't check indent
 		{"", false},
 	}
@@ -239,6 +240,121 @@ func TestSegmenter_NestedBulletsMerged(t *testing.T) {
 	}
 }
 
+func TestSegmenter_NumberedSubStepsMerged(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+	}{
+		{"tight", "1. Deploy the service.\n   1. Build the image.\n   2. Push the image.\n2. Verify health.\n"},
+		{"loose", "1. Deploy the service.\n\n   1. Build the image.\n\n   2. Push the image.\n\n2. Verify health.\n"},
+		{"tab indent", "1. Deploy the service.\n\t1) Build the image.\n\t2) Push the image.\n2. Verify health.\n"},
+	}
+	for _, c := range cases {
+		items := parse(t, c.src)
+		if len(items) != 2 {
+			t.Fatalf("%s: expected 2 items, got %d: %v", c.name, len(items), items)
+		}
+		if !strings.Contains(items[0].Text, "Deploy") || !strings.Contains(items[0].Text, "Build") || !strings.Contains(items[0].Text, "Push") {
+			t.Errorf("%s: sub-steps not merged: %q", c.name, items[0].Text)
+		}
+		if items[1].Text != "Verify health." {
+			t.Errorf("%s: item 1: %q", c.name, items[1].Text)
+		}
+	}
+}
+
+func TestSegmenter_NumberedSubStepsLineRange(t *testing.T) {
+	src := "1. Deploy the service.\n\n   1. Build the image.\n   2. Push the image.\n\nA closing paragraph.\n"
+	items := parse(t, src)
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d: %v", len(items), items)
+	}
+	if items[0].LineStart != 1 || items[0].LineEnd != 4 {
+		t.Errorf("item 0 lines: start=%d end=%d, want 1..4", items[0].LineStart, items[0].LineEnd)
+	}
+	if items[0].Text != "Deploy the service.\n1. Build the image.\n2. Push the image." {
+		t.Errorf("item 0 text: %q", items[0].Text)
+	}
+}
+
+func TestSegmenter_Table(t *testing.T) {
+	src := `## Request fields
+
+The request body has these fields:
+
+| Field | Type   | Required |
+|-------|:------:|----------|
+| email | string | yes      |
+| age   | int    |          |
+| tags  | a \| b | no       |
+
+## Responses
+
+- Returns 201 on success.
+`
+	items := parse(t, src)
+	want := []struct {
+		text string
+		line int
+	}{
+		{"The request body has these fields:", 3},
+		{"Field=email; Type=string; Required=yes", 7},
+		{"Field=age; Type=int", 8},
+		{"Field=tags; Type=a | b; Required=no", 9},
+		{"Returns 201 on success.", 13},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("expected %d items, got %d: %v", len(want), len(items), items)
+	}
+	for i, w := range want {
+		if items[i].Text != w.text {
+			t.Errorf("item %d text: %q, want %q", i, items[i].Text, w.text)
+		}
+		if items[i].LineStart != w.line || items[i].LineEnd != w.line {
+			t.Errorf("item %d lines: %d..%d, want %d", i, items[i].LineStart, items[i].LineEnd, w.line)
+		}
+	}
+}
+
+func TestSegmenter_TableWithoutOuterPipes(t *testing.T) {
+	src := "Name | Limit\n--- | ---\nuploads | 10 MB\n\nAfter the table.\n"
+	items := parse(t, src)
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d: %v", len(items), items)
+	}
+	if items[0].Text != "Name=uploads; Limit=10 MB" {
+		t.Errorf("item 0 text: %q", items[0].Text)
+	}
+}
+
+func TestSegmenter_PipeWithoutDelimiterIsParagraph(t *testing.T) {
+	src := "Use a | b when piping.\nSecond line.\n"
+	items := parse(t, src)
+	if len(items) != 1 || !strings.Contains(items[0].Text, "a | b") {
+		t.Fatalf("expected one paragraph item, got %v", items)
+	}
+}
+
+func TestIsTableDelimiter(t *testing.T) {
+	cases := []struct {
+		line string
+		want bool
+	}{
+		{"|---|---|", true},
+		{"--- | :---: | ---:", true},
+		{"|:-|", true},
+		{"---", false},
+		{"| --- | text |", false},
+		{"| | --- |", false},
+		{"", false},
+	}
+	for _, c := range cases {
+		if got := IsTableDelimiter(c.line); got != c.want {
+			t.Errorf("IsTableDelimiter(%q) = %v, want %v", c.line, got, c.want)
+		}
+	}
+}
+
 func TestSegmenter_Paragraph(t *testing.T) {
 	src := `Some standalone paragraph text.
 
@@ -332,3 +448,98 @@ func TestSegmenter_DecoratorFlushesCurrent(t *testing.T) {
 		t.Fatalf("expected 2 items, got %d", len(items))
 	}
 }
+
+func TestIsSetextUnderline(t *testing.T) {
+	cases := []struct {
+		line string
+		want bool
+	}{
+		{"===", true},
+		{"=====  ", true},
+		{"---", true},
+		{"   ------", true},
+		{"    ---", false}, // indented code block
+		{"==", false},
+		{"=-=", false},
+		{"- - -", false},
+		{"***", false},
+		{"", false},
+	}
+	for _, c := range cases {
+		if got := IsSetextUnderline(c.line); got != c.want {
+			t.Errorf("IsSetextUnderline(%q) = %v, want %v", c.line, got, c.want)
+		}
+	}
+}
+
+func TestSegmenter_SetextH1BeforeBulletList(t *testing.T) {
+	src := `Requirements
+============
+
+- First requirement.
+- Second requirement.
+`
+	items := parse(t, src)
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items (setext heading excluded), got %d: %v", len(items), items)
+	}
+	if items[0].Text != "First requirement." {
+		t.Errorf("items[0].Text = %q, want %q", items[0].Text, "First requirement.")
+	}
+	if items[0].ID != "T-001" {
+		t.Errorf("items[0].ID = %q, want T-001 (no phantom item)", items[0].ID)
+	}
+}
+
+func TestSegmenter_SetextH2NoBlankLine(t *testing.T) {
+	src := "Constraints\n-----------\n- Must be stateless.\n"
+	items := parse(t, src)
+	if len(items) != 1 {
+		t.Fatalf("expected 1 item (setext heading excluded), got %d: %v", len(items), items)
+	}
+	if items[0].LineStart != 3 {
+		t.Errorf("items[0].LineStart = %d, want 3", items[0].LineStart)
+	}
+}
+
+// --- Explicit IDs ---
+
+func TestParseReader_HonorExplicitIDs(t *testing.T) {
+	input := "1. SPEC-005: first\n2. second\n3. SPEC-001: third\n4. fourth\n"
+	s := Segmenter{IDPrefix: "SPEC", HonorExplicitIDs: true}
+	items, err := s.ParseReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseReader error: %v", err)
+	}
+	want := []struct{ id, text string }{
+		{"SPEC-005", "first"},
+		{"SPEC-002", "second"}, // SPEC-001 is claimed by the third item
+		{"SPEC-001", "third"},
+		{"SPEC-003", "fourth"},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("items = %d, want %d", len(items), len(want))
+	}
+	for i, w := range want {
+		if items[i].ID != w.id || items[i].Text != w.text {
+			t.Errorf("item[%d] = {%q %q}, want {%q %q}", i, items[i].ID, items[i].Text, w.id, w.text)
+		}
+	}
+
+	// Without the option, labels are ordinary text.
+	items, err = Segmenter{IDPrefix: "SPEC"}.ParseReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseReader error: %v", err)
+	}
+	if items[0].ID != "SPEC-001" || items[0].Text != "SPEC-005: first" {
+		t.Errorf("item[0] = {%q %q}, want {SPEC-001 \"SPEC-005: first\"}", items[0].ID, items[0].Text)
+	}
+}
+
+func TestParseReader_DuplicateExplicitID(t *testing.T) {
+	s := Segmenter{IDPrefix: "SPEC", HonorExplicitIDs: true}
+	_, err := s.ParseReader(strings.NewReader("- SPEC-002: one\n- SPEC-002: two\n"))
+	if err == nil || !strings.Contains(err.Error(), "SPEC-002") || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("err = %v, want a duplicate SPEC-002 error naming line 2", err)
+	}
+}
@@ -0,0 +1,62 @@
+// Package ordering checks plan coverage against the dependencies declared
+// between plan steps.
+package ordering
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dshills/realitycheck/internal/drift"
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+// Check returns a WARN drift finding for each IMPLEMENTED plan item that
+// depends on a NOT_IMPLEMENTED one. deps maps a plan item ID to the IDs it
+// depends on, as returned by plan.Dependencies. Findings follow the order of
+// planCov and cite the implemented item's evidence; their IDs are left empty
+// for the caller to assign.
+func Check(planCov []schema.PlanCoverageEntry, deps map[string][]string) []schema.DriftFinding {
+	status := make(map[string]schema.CoverageStatus, len(planCov))
+	for _, e := range planCov {
+		status[e.ID] = e.Status
+	}
+
+	var out []schema.DriftFinding
+	for _, e := range planCov {
+		if e.Status != schema.StatusImplemented {
+			continue
+		}
+		var missing []string
+		for _, dep := range deps[e.ID] {
+			if status[dep] == schema.StatusNotImplemented {
+				missing = append(missing, dep)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		list := strings.Join(missing, ", ")
+		out = append(out, schema.DriftFinding{
+			Severity:       schema.SeverityWarn,
+			Description:    fmt.Sprintf("%s is implemented but %s, which it depends on, is not.", e.ID, list),
+			Evidence:       e.Evidence,
+			WhyUnjustified: fmt.Sprintf("The plan orders %s before %s; implementing a later step first suggests out-of-order or speculative work.", list, e.ID),
+			Impact:         fmt.Sprintf("%s may rest on work that does not exist yet.", e.ID),
+			Recommendation: fmt.Sprintf("Implement %s, or update the plan if %s no longer depends on it.", list, e.ID),
+		})
+	}
+	return out
+}
+
+// Apply runs Check on report's plan coverage and appends the findings to
+// report.Drift, numbered by drift.NextID. It returns the number of
+// findings added.
+func Apply(report *schema.PartialReport, deps map[string][]string) int {
+	nextID := drift.NextID(report.Drift)
+	found := Check(report.Coverage.Plan, deps)
+	for _, d := range found {
+		d.ID = nextID()
+		report.Drift = append(report.Drift, d)
+	}
+	return len(found)
+}
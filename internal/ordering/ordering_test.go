@@ -0,0 +1,60 @@
+package ordering
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+func entry(id string, status schema.CoverageStatus) schema.PlanCoverageEntry {
+	return schema.PlanCoverageEntry{ID: id, Status: status, Evidence: []schema.Evidence{{Path: strings.ToLower(id) + ".go"}}}
+}
+
+func TestCheck(t *testing.T) {
+	cov := []schema.PlanCoverageEntry{
+		entry("PLAN-001", schema.StatusNotImplemented),
+		entry("PLAN-002", schema.StatusPartial),
+		entry("PLAN-003", schema.StatusImplemented),
+		entry("PLAN-004", schema.StatusImplemented),
+		entry("PLAN-005", schema.StatusNotImplemented),
+	}
+	deps := map[string][]string{
+		"PLAN-003": {"PLAN-001", "PLAN-002"}, // PARTIAL does not count
+		"PLAN-004": {"PLAN-003"},             // satisfied
+		"PLAN-005": {"PLAN-001"},             // not implemented itself
+	}
+	got := Check(cov, deps)
+	if len(got) != 1 {
+		t.Fatalf("Check returned %d findings, want 1: %+v", len(got), got)
+	}
+	d := got[0]
+	if d.Severity != schema.SeverityWarn || d.ID != "" {
+		t.Errorf("finding = %+v, want WARN with no ID", d)
+	}
+	if !strings.Contains(d.Description, "PLAN-003") || !strings.Contains(d.Description, "PLAN-001") || strings.Contains(d.Description, "PLAN-002") {
+		t.Errorf("description = %q", d.Description)
+	}
+	if len(d.Evidence) != 1 || d.Evidence[0].Path != "plan-003.go" {
+		t.Errorf("evidence = %+v, want the implemented item's evidence", d.Evidence)
+	}
+}
+
+func TestApply(t *testing.T) {
+	report := &schema.PartialReport{
+		Coverage: schema.Coverage{Plan: []schema.PlanCoverageEntry{
+			entry("PLAN-001", schema.StatusNotImplemented),
+			entry("PLAN-002", schema.StatusImplemented),
+		}},
+		Drift: []schema.DriftFinding{{ID: "DRIFT-004"}},
+	}
+	if n := Apply(report, map[string][]string{"PLAN-002": {"PLAN-001"}}); n != 1 {
+		t.Fatalf("Apply added %d findings, want 1", n)
+	}
+	if got := report.Drift[1].ID; got != "DRIFT-005" {
+		t.Errorf("new finding ID = %q, want DRIFT-005", got)
+	}
+	if n := Apply(report, nil); n != 0 {
+		t.Errorf("Apply with no dependencies added %d findings", n)
+	}
+}
@@ -0,0 +1,65 @@
+package plan
+
+import (
+	"regexp"
+	"strings"
+)
+
+// dependsRe matches a "depends on ..." clause, which runs to the end of the
+// sentence.
+var dependsRe = regexp.MustCompile(`(?i)\bdepends\s+on\s+([^.;\n]+)`)
+
+// depStepRe matches one "Step N" / "Sub-step Na" reference inside a clause;
+// depNumRe matches the bare numbers of a list such as "Steps 3, 4 and 5".
+var (
+	depStepRe = regexp.MustCompile(`(?i)\b(?:sub-)?steps?\s+(\d+[a-z]?)\b`)
+	depNumRe  = regexp.MustCompile(`(?i)(?:^|,|\band\b|&)\s*(\d+[a-z]?)\b`)
+	depIDRe   = regexp.MustCompile(`\bPLAN-\d+\b`)
+)
+
+// Dependencies extracts the ordering constraints declared in item text, such
+// as "depends on Step 3", "depends on Steps 3 and 4", or "depends on
+// PLAN-003". Step numbers are resolved against each item's Label, and the
+// result maps an item ID to the IDs it depends on, in the order written.
+// References that match no item, and self-references, are ignored.
+func Dependencies(items []Item) map[string][]string {
+	byLabel := make(map[string]string, len(items))
+	ids := make(map[string]bool, len(items))
+	for _, it := range items {
+		ids[it.ID] = true
+		// The first item with a label wins, so a repeated "1." in a later
+		// list does not shadow Step 1.
+		if it.Label != "" {
+			if _, dup := byLabel[it.Label]; !dup {
+				byLabel[it.Label] = it.ID
+			}
+		}
+	}
+
+	deps := map[string][]string{}
+	for _, it := range items {
+		seen := map[string]bool{it.ID: true}
+		add := func(id string) {
+			if id != "" && ids[id] && !seen[id] {
+				seen[id] = true
+				deps[it.ID] = append(deps[it.ID], id)
+			}
+		}
+		for _, m := range dependsRe.FindAllStringSubmatch(it.Text, -1) {
+			clause := m[1]
+			for _, id := range depIDRe.FindAllString(clause, -1) {
+				add(id)
+			}
+			if !depStepRe.MatchString(clause) {
+				continue
+			}
+			// Rewrite each "Step N" to ", N" so a single pattern picks up
+			// both explicit references and the bare numbers between them.
+			list := depStepRe.ReplaceAllString(clause, ", $1")
+			for _, n := range depNumRe.FindAllStringSubmatch(list, -1) {
+				add(byLabel[strings.ToLower(n[1])])
+			}
+		}
+	}
+	return deps
+}
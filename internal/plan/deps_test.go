@@ -0,0 +1,50 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseLabels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "PLAN.md")
+	content := "Step 1: Scaffold\n\nSub-step 1A: Add go.mod\n\n3. Write store\n\n- Bullet item\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	items, err := Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	var got []string
+	for _, it := range items {
+		got = append(got, it.Label)
+	}
+	if want := []string{"1", "1a", "3", ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("labels = %q, want %q", got, want)
+	}
+}
+
+func TestDependencies(t *testing.T) {
+	items := []Item{
+		{ID: "PLAN-001", Label: "1", Text: "Scaffold the module."},
+		{ID: "PLAN-002", Label: "2", Text: "Add storage. Depends on Step 1."},
+		{ID: "PLAN-003", Label: "3", Text: "Add the API; depends on steps 1, 2 and 7."},
+		{ID: "PLAN-004", Label: "4a", Text: "Wire it up (depends on PLAN-003 and Sub-step 4A)."},
+		{ID: "PLAN-005", Text: "Ship it. This depends on Step 2 and Step 3"},
+		{ID: "PLAN-006", Label: "1", Text: "Document. Depends on Step 1."},
+		{ID: "PLAN-007", Text: "No dependencies on anything; step 2 is unrelated."},
+	}
+	got := Dependencies(items)
+	want := map[string][]string{
+		"PLAN-002": {"PLAN-001"},
+		"PLAN-003": {"PLAN-001", "PLAN-002"}, // Step 7 does not exist
+		"PLAN-004": {"PLAN-003"},             // self-reference ignored
+		"PLAN-005": {"PLAN-002", "PLAN-003"},
+		"PLAN-006": {"PLAN-001"}, // the first "1" wins
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Dependencies = %v, want %v", got, want)
+	}
+}
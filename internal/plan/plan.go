@@ -36,15 +36,35 @@ func planStripPrefix(line string) string {
 	return mdparse.StripListPrefix(line)
 }
 
+// labelRe captures the step number of a "Step N:" / "Sub-step Na:" header
+// or a standard "N." / "N)" list item.
+var labelRe = regexp.MustCompile(`^(?:(?:Sub-step|Step)\s+(\d+[a-zA-Z]?):|(\d+)[.)])`)
+
+// planLabel returns the author's step number for a plan item's first line,
+// lowercased so that "7A" and "7a" refer to the same step.
+func planLabel(line string) string {
+	m := labelRe.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return ""
+	}
+	return strings.ToLower(m[1] + m[2])
+}
+
 // segmenter is a package-level value. mdparse.Segmenter contains no mutable
 // state; the counter is local to each segment() invocation, so concurrent
 // calls to Parse are safe.
 var segmenter = mdparse.Segmenter{
-	IDPrefix:       "PLAN",
-	IsNumberedItem: planIsNumberedItem,
-	StripPrefix:    planStripPrefix,
+	IDPrefix:         "PLAN",
+	IsNumberedItem:   planIsNumberedItem,
+	StripPrefix:      planStripPrefix,
+	HonorExplicitIDs: true,
+	Label:            planLabel,
 }
 
+// Segmenter returns the segmenter Parse uses, for tools such as the linter
+// that inspect the raw segments.
+func Segmenter() mdparse.Segmenter { return segmenter }
+
 // Parse reads the file at path and segments it into plan items.
 func Parse(path string) ([]Item, error) {
 	items, err := segmenter.ParseFile(path)
@@ -53,3 +73,14 @@ func Parse(path string) ([]Item, error) {
 	}
 	return items, nil
 }
+
+// ParseFiles parses each file in order and concatenates the plan items.
+// IDs continue numbering across files, and each item's Source records the
+// file it was parsed from.
+func ParseFiles(paths []string) ([]Item, error) {
+	items, err := segmenter.ParseFiles(paths)
+	if err != nil {
+		return nil, fmt.Errorf("plan: %w", err)
+	}
+	return items, nil
+}
@@ -3,7 +3,23 @@
 // to the system prompt sent to the LLM.
 package profile
 
-import "fmt"
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"github.com/dshills/realitycheck/internal/codeindex"
+	"github.com/dshills/realitycheck/internal/schema"
+)
 
 // Profile describes an intent enforcement strategy.
 type Profile struct {
@@ -13,6 +29,10 @@ type Profile struct {
 	// StrictDriftSeverity, when true, causes all drift findings to be escalated
 	// one severity level before scoring (WARN→CRITICAL, INFO→WARN).
 	StrictDriftSeverity bool
+	// EvidencePathAllow and EvidencePathDeny are path globs enforced on drift
+	// findings and violations after analysis; see EvidenceFilter.
+	EvidencePathAllow []string
+	EvidencePathDeny  []string
 }
 
 // builtins is the registry of built-in profiles keyed by name.
@@ -52,6 +72,8 @@ var builtins = map[string]Profile{
 			"implementation details have latitude as long as the exported API surface matches the " +
 			"spec. Flag any new exported symbol without spec backing as WARN drift.",
 		StrictDriftSeverity: false,
+		// Tests are not part of a library's API surface.
+		EvidencePathDeny: []string{"*_test.go", "testdata"},
 	},
 }
 
@@ -63,3 +85,174 @@ func Load(name string) (Profile, error) {
 	}
 	return p, nil
 }
+
+// fileProfile is the on-disk representation of a user-defined profile.
+// Field tags cover both YAML and TOML so one struct serves either format.
+type fileProfile struct {
+	Name                 string `yaml:"name" toml:"name"`
+	Description          string `yaml:"description" toml:"description"`
+	SystemPromptAddendum string `yaml:"system_prompt_addendum" toml:"system_prompt_addendum"`
+	StrictDriftSeverity  bool   `yaml:"strict_drift_severity" toml:"strict_drift_severity"`
+
+	EvidencePathAllow []string `yaml:"evidence_path_allow" toml:"evidence_path_allow"`
+	EvidencePathDeny  []string `yaml:"evidence_path_deny" toml:"evidence_path_deny"`
+}
+
+// LoadFile reads a user-defined profile from a YAML (.yaml, .yml) or TOML
+// (.toml) file. Unknown keys are rejected so that typos fail loudly rather
+// than being silently ignored, and system_prompt_addendum must be non-empty.
+// When name is omitted, the file's base name (without extension) is used.
+func LoadFile(path string) (Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Profile{}, fmt.Errorf("profile: read %s: %w", path, err)
+	}
+
+	var fp fileProfile
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&fp); err != nil && !errors.Is(err, io.EOF) {
+			return Profile{}, fmt.Errorf("profile: parse %s: %w", path, err)
+		}
+	case ".toml":
+		md, err := toml.Decode(string(data), &fp)
+		if err != nil {
+			return Profile{}, fmt.Errorf("profile: parse %s: %w", path, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			keys := make([]string, len(undecoded))
+			for i, k := range undecoded {
+				keys[i] = k.String()
+			}
+			return Profile{}, fmt.Errorf("profile: parse %s: unknown keys: %s", path, strings.Join(keys, ", "))
+		}
+	default:
+		return Profile{}, fmt.Errorf("profile: unsupported profile file extension %q (want .yaml, .yml, or .toml)", ext)
+	}
+
+	if strings.TrimSpace(fp.SystemPromptAddendum) == "" {
+		return Profile{}, fmt.Errorf("profile: %s: system_prompt_addendum is required", path)
+	}
+	if fp.Name == "" {
+		fp.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	p := Profile{
+		Name:                 fp.Name,
+		Description:          fp.Description,
+		SystemPromptAddendum: fp.SystemPromptAddendum,
+		StrictDriftSeverity:  fp.StrictDriftSeverity,
+		EvidencePathAllow:    fp.EvidencePathAllow,
+		EvidencePathDeny:     fp.EvidencePathDeny,
+	}
+	if _, err := p.EvidenceFilter(); err != nil {
+		return Profile{}, fmt.Errorf("profile: %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// EvidenceFilter is the compiled form of a profile's evidence path globs.
+// The zero value keeps every finding.
+type EvidenceFilter struct {
+	allow []*regexp.Regexp
+	deny  []*regexp.Regexp
+}
+
+// EvidenceFilter compiles p.EvidencePathAllow and p.EvidencePathDeny. A glob
+// without "/" matches a file or directory name at any depth, so "*_test.go"
+// matches every Go test file; a glob with "/" matches the full path from the
+// code root. A glob that matches a directory also matches the files under it.
+func (p Profile) EvidenceFilter() (EvidenceFilter, error) {
+	var f EvidenceFilter
+	var err error
+	if f.allow, err = compileEvidenceGlobs("evidence_path_allow", p.EvidencePathAllow); err != nil {
+		return EvidenceFilter{}, err
+	}
+	if f.deny, err = compileEvidenceGlobs("evidence_path_deny", p.EvidencePathDeny); err != nil {
+		return EvidenceFilter{}, err
+	}
+	return f, nil
+}
+
+func compileEvidenceGlobs(key string, globs []string) ([]*regexp.Regexp, error) {
+	var out []*regexp.Regexp
+	for _, g := range globs {
+		glob := strings.TrimSuffix(g, "/")
+		if glob == "" {
+			continue
+		}
+		if !strings.Contains(glob, "/") {
+			glob = "**/" + glob
+		}
+		re, err := codeindex.CompileGlob(glob)
+		if err != nil {
+			return nil, fmt.Errorf("%s pattern %q: %w", key, g, err)
+		}
+		out = append(out, re)
+	}
+	return out, nil
+}
+
+// Active reports whether f has any globs.
+func (f EvidenceFilter) Active() bool { return len(f.allow) > 0 || len(f.deny) > 0 }
+
+// Keep reports whether a finding citing evidence survives the filter. A
+// finding is dropped if it cites any denied path, or if allow globs are set
+// and none of the paths it cites is allowed. Findings that cite no paths
+// are kept.
+func (f EvidenceFilter) Keep(evidence []schema.Evidence) bool {
+	cited, allowed := false, false
+	for _, ev := range evidence {
+		if ev.Path == "" {
+			continue
+		}
+		p := filepath.ToSlash(ev.Path)
+		if matchEvidence(f.deny, p) {
+			return false
+		}
+		cited = true
+		if matchEvidence(f.allow, p) {
+			allowed = true
+		}
+	}
+	return !cited || len(f.allow) == 0 || allowed
+}
+
+// matchEvidence reports whether p, or one of its parent directories, matches
+// any of globs.
+func matchEvidence(globs []*regexp.Regexp, p string) bool {
+	for p != "." && p != "/" && p != "" {
+		for _, re := range globs {
+			if re.MatchString(p) {
+				return true
+			}
+		}
+		p = path.Dir(p)
+	}
+	return false
+}
+
+// FilterFindings removes the drift findings and violations in report that f
+// does not keep, and returns how many it removed.
+func (f EvidenceFilter) FilterFindings(report *schema.PartialReport) int {
+	if !f.Active() {
+		return 0
+	}
+	n := len(report.Drift) + len(report.Violations)
+	drift := report.Drift[:0]
+	for _, d := range report.Drift {
+		if f.Keep(d.Evidence) {
+			drift = append(drift, d)
+		}
+	}
+	violations := report.Violations[:0]
+	for _, v := range report.Violations {
+		if f.Keep(v.Evidence) {
+			violations = append(violations, v)
+		}
+	}
+	report.Drift, report.Violations = drift, violations
+	return n - len(drift) - len(violations)
+}
@@ -1,6 +1,13 @@
 package profile
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
 
 func TestLoad_AllBuiltins(t *testing.T) {
 	names := []string{"general", "strict-api", "data-pipeline", "library"}
@@ -49,3 +56,154 @@ func TestLoad_StrictDriftSeverity(t *testing.T) {
 		}
 	}
 }
+
+func writeProfileFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestLoadFile_YAML(t *testing.T) {
+	path := writeProfileFile(t, "payments.yaml", `name: payments
+description: Payments team rules.
+system_prompt_addendum: Flag any undeclared ledger write as CRITICAL drift.
+strict_drift_severity: true
+`)
+	p, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile error: %v", err)
+	}
+	if p.Name != "payments" {
+		t.Errorf("Name = %q, want %q", p.Name, "payments")
+	}
+	if !p.StrictDriftSeverity {
+		t.Error("StrictDriftSeverity = false, want true")
+	}
+	if !strings.Contains(p.SystemPromptAddendum, "ledger") {
+		t.Errorf("SystemPromptAddendum = %q, want ledger rule", p.SystemPromptAddendum)
+	}
+}
+
+func TestLoadFile_TOML(t *testing.T) {
+	path := writeProfileFile(t, "payments.toml", `description = "Payments team rules."
+system_prompt_addendum = "Flag any undeclared ledger write as CRITICAL drift."
+`)
+	p, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile error: %v", err)
+	}
+	// Name defaults to the file stem when omitted.
+	if p.Name != "payments" {
+		t.Errorf("Name = %q, want %q", p.Name, "payments")
+	}
+}
+
+func TestLoadFile_UnknownKey(t *testing.T) {
+	cases := map[string]string{
+		"bad.yaml": "system_prompt_addendum: rules\nsystem_prompt_addenda: typo\n",
+		"bad.toml": "system_prompt_addendum = \"rules\"\nstrict = true\n",
+	}
+	for name, content := range cases {
+		if _, err := LoadFile(writeProfileFile(t, name, content)); err == nil {
+			t.Errorf("LoadFile(%s) expected unknown-key error, got nil", name)
+		}
+	}
+}
+
+func TestLoadFile_EmptyAddendum(t *testing.T) {
+	path := writeProfileFile(t, "empty.yaml", "name: empty\nsystem_prompt_addendum: \"  \"\n")
+	if _, err := LoadFile(path); err == nil {
+		t.Fatal("LoadFile expected error for empty system_prompt_addendum, got nil")
+	}
+}
+
+func TestLoadFile_UnsupportedExtension(t *testing.T) {
+	path := writeProfileFile(t, "profile.json", `{"system_prompt_addendum":"rules"}`)
+	if _, err := LoadFile(path); err == nil {
+		t.Fatal("LoadFile expected error for .json extension, got nil")
+	}
+}
+
+func TestLoadFile_EvidencePaths(t *testing.T) {
+	path := writeProfileFile(t, "api.yaml", `system_prompt_addendum: rules
+evidence_path_allow: ["internal/api"]
+evidence_path_deny: ["*_test.go"]
+`)
+	p, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile error: %v", err)
+	}
+	if len(p.EvidencePathAllow) != 1 || len(p.EvidencePathDeny) != 1 {
+		t.Errorf("allow/deny = %v/%v", p.EvidencePathAllow, p.EvidencePathDeny)
+	}
+
+	bad := writeProfileFile(t, "bad.toml", "system_prompt_addendum = \"rules\"\nevidence_path_deny = [\"[z-a]\"]\n")
+	if _, err := LoadFile(bad); err == nil {
+		t.Error("LoadFile expected error for an invalid glob, got nil")
+	}
+}
+
+func TestEvidenceFilter_Keep(t *testing.T) {
+	f, err := Profile{
+		EvidencePathAllow: []string{"internal/api"},
+		EvidencePathDeny:  []string{"*_test.go"},
+	}.EvidenceFilter()
+	if err != nil {
+		t.Fatalf("EvidenceFilter error: %v", err)
+	}
+	ev := func(paths ...string) []schema.Evidence {
+		var out []schema.Evidence
+		for _, p := range paths {
+			out = append(out, schema.Evidence{Path: p})
+		}
+		return out
+	}
+	cases := []struct {
+		name     string
+		evidence []schema.Evidence
+		want     bool
+	}{
+		{"allowed", ev("internal/api/handler.go"), true},
+		{"not allowed", ev("cmd/main.go"), false},
+		{"one allowed path suffices", ev("cmd/main.go", "internal/api/routes.go"), true},
+		{"denied at any depth", ev("internal/api/handler.go", "internal/api/handler_test.go"), false},
+		{"no paths", nil, true},
+	}
+	for _, c := range cases {
+		if got := f.Keep(c.evidence); got != c.want {
+			t.Errorf("%s: Keep = %v, want %v", c.name, got, c.want)
+		}
+	}
+	if (EvidenceFilter{}).Active() {
+		t.Error("zero EvidenceFilter should be inactive")
+	}
+}
+
+func TestEvidenceFilter_LibraryDeniesTests(t *testing.T) {
+	p, err := Load("library")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := p.EvidenceFilter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := &schema.PartialReport{
+		Drift: []schema.DriftFinding{
+			{ID: "DRIFT-001", Evidence: []schema.Evidence{{Path: "store.go"}}},
+			{ID: "DRIFT-002", Evidence: []schema.Evidence{{Path: "store_test.go"}}},
+		},
+		Violations: []schema.Violation{
+			{ID: "VIOLATION-001", Evidence: []schema.Evidence{{Path: "pkg/testdata/golden.json"}}},
+		},
+	}
+	if n := f.FilterFindings(report); n != 2 {
+		t.Errorf("FilterFindings removed %d, want 2", n)
+	}
+	if len(report.Drift) != 1 || report.Drift[0].ID != "DRIFT-001" || len(report.Violations) != 0 {
+		t.Errorf("remaining drift = %+v, violations = %+v", report.Drift, report.Violations)
+	}
+}
@@ -0,0 +1,89 @@
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+// RenderAggregateJSON produces a pretty-printed JSON representation of an
+// aggregate report.
+func RenderAggregateJSON(agg schema.AggregateReport) ([]byte, error) {
+	b, err := json.MarshalIndent(agg, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("render: json marshal aggregate: %w", err)
+	}
+	return b, nil
+}
+
+// RenderAggregateYAML produces a YAML representation of an aggregate report.
+func RenderAggregateYAML(agg schema.AggregateReport) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(agg); err != nil {
+		return nil, fmt.Errorf("render: yaml marshal aggregate: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("render: yaml marshal aggregate: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderAggregateMarkdown produces a Markdown summary of an aggregate
+// report: the combined verdict and counts, then one table row per report.
+func RenderAggregateMarkdown(agg schema.AggregateReport) string {
+	var sb strings.Builder
+
+	sb.WriteString("## RealityCheck Aggregate\n\n")
+	fmt.Fprintf(&sb, "**Reports:** %d  \n", len(agg.Reports))
+	fmt.Fprintf(&sb, "**Worst verdict:** %s  \n", agg.Verdict)
+	fmt.Fprintf(&sb, "**Average score:** %s/100  \n", formatScore(agg.AverageScore))
+	fmt.Fprintf(&sb, "**Critical:** %d | **Warn:** %d | **Info:** %d\n\n",
+		agg.CriticalCount, agg.WarnCount, agg.InfoCount)
+
+	if len(agg.Reports) > 0 {
+		sb.WriteString("| Report | Code root | Verdict | Score | Critical | Warn | Info |\n")
+		sb.WriteString("|---|---|---|---|---|---|---|\n")
+		for _, e := range agg.Reports {
+			fmt.Fprintf(&sb, "| %s | %s | %s | %d | %d | %d | %d |\n",
+				mdEscape(e.Path), mdEscape(e.CodeRoot), e.Verdict, e.Score, e.CriticalCount, e.WarnCount, e.InfoCount)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// aggregateCSVHeader is the first row of RenderAggregateCSV output.
+var aggregateCSVHeader = []string{"path", "code_root", "verdict", "score", "critical", "warn", "info"}
+
+// RenderAggregateCSV produces one CSV row per report in agg, after a header
+// row.
+func RenderAggregateCSV(agg schema.AggregateReport) ([]byte, error) {
+	rows := [][]string{aggregateCSVHeader}
+	for _, e := range agg.Reports {
+		rows = append(rows, []string{
+			e.Path, e.CodeRoot, string(e.Verdict), strconv.Itoa(e.Score),
+			strconv.Itoa(e.CriticalCount), strconv.Itoa(e.WarnCount), strconv.Itoa(e.InfoCount),
+		})
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(rows); err != nil {
+		return nil, fmt.Errorf("render: csv aggregate: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// formatScore prints an average score without trailing zeros, e.g. "94" or
+// "96.5".
+func formatScore(s float64) string {
+	return strconv.FormatFloat(s, 'f', -1, 64)
+}
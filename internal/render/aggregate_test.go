@@ -0,0 +1,57 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+func sampleAggregate() schema.AggregateReport {
+	return schema.AggregateReport{
+		Tool:         "realitycheck",
+		Verdict:      schema.VerdictDriftDetected,
+		AverageScore: 96.5,
+		WarnCount:    1,
+		Reports: []schema.AggregateEntry{
+			{Path: "billing.json", CodeRoot: "services/billing", Verdict: schema.VerdictAligned, Score: 100},
+			{Path: "auth.json", CodeRoot: "services/auth", Verdict: schema.VerdictDriftDetected, Score: 93, WarnCount: 1},
+		},
+	}
+}
+
+func TestRenderAggregateMarkdown(t *testing.T) {
+	md := RenderAggregateMarkdown(sampleAggregate())
+	for _, want := range []string{
+		"**Worst verdict:** DRIFT_DETECTED",
+		"**Average score:** 96.5/100",
+		"| auth.json | services/auth | DRIFT_DETECTED | 93 | 0 | 1 | 0 |",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestRenderAggregateCSV(t *testing.T) {
+	out, err := RenderAggregateCSV(sampleAggregate())
+	if err != nil {
+		t.Fatalf("RenderAggregateCSV: %v", err)
+	}
+	want := "path,code_root,verdict,score,critical,warn,info\n" +
+		"billing.json,services/billing,ALIGNED,100,0,0,0\n" +
+		"auth.json,services/auth,DRIFT_DETECTED,93,0,1,0\n"
+	if string(out) != want {
+		t.Errorf("csv = %q, want %q", out, want)
+	}
+}
+
+func TestRenderAggregateYAML(t *testing.T) {
+	out, err := RenderAggregateYAML(sampleAggregate())
+	if err != nil {
+		t.Fatalf("RenderAggregateYAML: %v", err)
+	}
+	if !strings.Contains(string(out), "average_score: 96.5") {
+		t.Errorf("yaml = %s", out)
+	}
+}
@@ -0,0 +1,60 @@
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+// csvHeader is the first row of RenderCSV output.
+var csvHeader = []string{"id", "kind", "status", "notes", "evidence_paths", "suppressed"}
+
+// RenderCSV produces a CSV export for spreadsheet tracking, starting with a
+// header row. Each spec and plan coverage entry is a row of kind "spec" or
+// "plan" with its coverage status; drift findings and violations follow as
+// kind "drift" and "violation" with their severity as the status and their
+// description as the notes, and "true" or "false" in the suppressed column
+// for whether a baseline suppressed them. Evidence paths are de-duplicated
+// and joined with ";".
+func RenderCSV(report *schema.Report) ([]byte, error) {
+	if report == nil {
+		return nil, fmt.Errorf("render: nil report")
+	}
+	rows := [][]string{csvHeader}
+	for _, e := range report.Coverage.Spec {
+		rows = append(rows, []string{e.ID, "spec", string(e.Status), e.Notes, evidencePaths(e.Evidence), ""})
+	}
+	for _, e := range report.Coverage.Plan {
+		rows = append(rows, []string{e.ID, "plan", string(e.Status), e.Notes, evidencePaths(e.Evidence), ""})
+	}
+	for _, d := range report.Drift {
+		rows = append(rows, []string{d.ID, "drift", string(d.Severity), d.Description, evidencePaths(d.Evidence), strconv.FormatBool(d.Suppressed)})
+	}
+	for _, v := range report.Violations {
+		rows = append(rows, []string{v.ID, "violation", string(v.Severity), v.Description, evidencePaths(v.Evidence), strconv.FormatBool(v.Suppressed)})
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(rows); err != nil {
+		return nil, fmt.Errorf("render: csv: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// evidencePaths joins the distinct paths of evidence, in order, with ";".
+func evidencePaths(evidence []schema.Evidence) string {
+	seen := make(map[string]bool, len(evidence))
+	var paths []string
+	for _, ev := range evidence {
+		if ev.Path != "" && !seen[ev.Path] {
+			seen[ev.Path] = true
+			paths = append(paths, ev.Path)
+		}
+	}
+	return strings.Join(paths, ";")
+}
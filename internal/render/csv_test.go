@@ -0,0 +1,80 @@
+package render
+
+import (
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+func TestRenderCSV(t *testing.T) {
+	report := sampleReport()
+	report.Coverage.Spec[0].Evidence = []schema.Evidence{
+		{Path: "store.go", Symbol: "Get"},
+		{Path: "store.go", Symbol: "Set"},
+		{Path: "api.go"},
+	}
+	report.Coverage.Spec[1].Notes = "handles \"errors\", partly\nsee below"
+
+	b, err := RenderCSV(report)
+	if err != nil {
+		t.Fatalf("RenderCSV error: %v", err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(string(b))).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+
+	want := 1 + len(report.Coverage.Spec) + len(report.Coverage.Plan) + len(report.Drift) + len(report.Violations)
+	if len(rows) != want {
+		t.Fatalf("rows = %d, want %d", len(rows), want)
+	}
+	if !reflect.DeepEqual(rows[0], csvHeader) {
+		t.Errorf("header = %v, want %v", rows[0], csvHeader)
+	}
+	if got := rows[1]; !reflect.DeepEqual(got, []string{"SPEC-001", "spec", "IMPLEMENTED", "fully implemented", "store.go;api.go", ""}) {
+		t.Errorf("spec row = %v", got)
+	}
+	if got := rows[2][3]; got != report.Coverage.Spec[1].Notes {
+		t.Errorf("notes with quotes and newline = %q, want it preserved", got)
+	}
+	if got := rows[3][:3]; !reflect.DeepEqual(got, []string{"PLAN-001", "plan", "IMPLEMENTED"}) {
+		t.Errorf("plan row = %v", rows[3])
+	}
+	d := report.Drift[0]
+	if got := rows[4][:4]; !reflect.DeepEqual(got, []string{d.ID, "drift", string(d.Severity), d.Description}) {
+		t.Errorf("drift row = %v", rows[4])
+	}
+	v := report.Violations[0]
+	if got := rows[5][:3]; !reflect.DeepEqual(got, []string{v.ID, "violation", string(v.Severity)}) {
+		t.Errorf("violation row = %v", rows[5])
+	}
+	if got := rows[4][5]; got != "false" {
+		t.Errorf("drift suppressed = %q, want false", got)
+	}
+}
+
+func TestRenderCSV_Suppressed(t *testing.T) {
+	report := sampleReport()
+	report.Violations[0].Suppressed = true
+	b, err := RenderCSV(report)
+	if err != nil {
+		t.Fatalf("RenderCSV error: %v", err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(string(b))).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+	last := rows[len(rows)-1]
+	if last[0] != report.Violations[0].ID || last[5] != "true" {
+		t.Errorf("suppressed violation row = %v, want suppressed column true", last)
+	}
+}
+
+func TestRenderCSV_Nil(t *testing.T) {
+	if _, err := RenderCSV(nil); err == nil {
+		t.Error("expected error for nil report")
+	}
+}
@@ -0,0 +1,66 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dshills/realitycheck/internal/reportdiff"
+)
+
+// RenderDiffJSON produces a pretty-printed JSON representation of a report
+// comparison.
+func RenderDiffJSON(res reportdiff.Result) ([]byte, error) {
+	b, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("render: json marshal diff: %w", err)
+	}
+	return b, nil
+}
+
+// RenderDiffMarkdown produces a Markdown summary of a report comparison,
+// suitable for a PR comment.
+func RenderDiffMarkdown(res reportdiff.Result) string {
+	var sb strings.Builder
+
+	sb.WriteString("## RealityCheck Diff\n\n")
+	if res.OldVerdict == res.NewVerdict {
+		fmt.Fprintf(&sb, "**Verdict:** %s (unchanged)  \n", res.NewVerdict)
+	} else {
+		fmt.Fprintf(&sb, "**Verdict:** %s → %s  \n", res.OldVerdict, res.NewVerdict)
+	}
+	fmt.Fprintf(&sb, "**Score:** %d → %d (%+d)  \n", res.OldScore, res.NewScore, res.ScoreDelta)
+	if res.Regressed() {
+		sb.WriteString("**Result:** regression\n\n")
+	} else {
+		sb.WriteString("**Result:** no regression\n\n")
+	}
+
+	if len(res.NewDrift) > 0 {
+		sb.WriteString("## New Drift Findings\n\n")
+		for _, d := range res.NewDrift {
+			fmt.Fprintf(&sb, "- **%s** [%s] %s\n", d.ID, d.Severity, d.Description)
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(res.NewViolations) > 0 {
+		sb.WriteString("## New Violations\n\n")
+		for _, v := range res.NewViolations {
+			fmt.Fprintf(&sb, "- **%s** [%s] %s\n", v.ID, v.Severity, v.Description)
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(res.CoverageRegressions) > 0 {
+		sb.WriteString("## Coverage Regressions\n\n")
+		sb.WriteString("| Kind | ID | From | To |\n")
+		sb.WriteString("|---|---|---|---|\n")
+		for _, c := range res.CoverageRegressions {
+			fmt.Fprintf(&sb, "| %s | %s | %s | %s |\n", c.Kind, c.ID, c.From, c.To)
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,59 @@
+package render
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/reportdiff"
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+func sampleDiff() reportdiff.Result {
+	return reportdiff.Result{
+		OldVerdict: schema.VerdictPartiallyAligned,
+		NewVerdict: schema.VerdictDriftDetected,
+		OldScore:   100,
+		NewScore:   93,
+		ScoreDelta: -7,
+		NewDrift: []schema.DriftFinding{
+			{ID: "DRIFT-001", Severity: schema.SeverityWarn, Description: "Undocumented retry loop"},
+		},
+		NewViolations: []schema.Violation{},
+		CoverageRegressions: []reportdiff.CoverageChange{
+			{Kind: "spec", ID: "SPEC-002", From: schema.StatusImplemented, To: schema.StatusPartial},
+		},
+	}
+}
+
+func TestRenderDiffMarkdown(t *testing.T) {
+	md := RenderDiffMarkdown(sampleDiff())
+	for _, want := range []string{
+		"PARTIALLY_ALIGNED → DRIFT_DETECTED",
+		"100 → 93 (-7)",
+		"**Result:** regression",
+		"DRIFT-001",
+		"| spec | SPEC-002 | IMPLEMENTED | PARTIAL |",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("diff markdown missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "New Violations") {
+		t.Error("empty sections should be omitted")
+	}
+}
+
+func TestRenderDiffJSON_RoundTrip(t *testing.T) {
+	b, err := RenderDiffJSON(sampleDiff())
+	if err != nil {
+		t.Fatalf("RenderDiffJSON: %v", err)
+	}
+	var got reportdiff.Result
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got.ScoreDelta != -7 || len(got.NewDrift) != 1 || len(got.CoverageRegressions) != 1 {
+		t.Errorf("round-trip mismatch: %+v", got)
+	}
+}
@@ -0,0 +1,110 @@
+package render
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+// RenderGitHub produces GitHub Actions workflow commands, one per drift
+// finding and violation, such as
+//
+//	::warning file=internal/x.go,title=DRIFT-001::DRIFT-001: description
+//
+// Printed to stdout by a workflow step, each becomes an annotation on the
+// run and, for files in the diff, on the pull request. Severities map to
+// error, warning, and notice. Evidence carries no line numbers, so the
+// annotation is file-level on the first evidence path. A violation without
+// an evidence path is placed on its spec reference lines instead, in the
+// spec file they belong to (see referenceFile), and a finding with neither
+// is not tied to a file. Suppressed findings are left out.
+func RenderGitHub(report *schema.Report) ([]byte, error) {
+	if report == nil {
+		return nil, fmt.Errorf("render: nil report")
+	}
+	var b strings.Builder
+	for _, d := range report.Drift {
+		if d.Suppressed {
+			continue
+		}
+		writeAnnotation(&b, d.Severity, d.ID, d.Description, firstEvidencePath(d.Evidence), schema.Reference{})
+	}
+	for _, v := range report.Violations {
+		if v.Suppressed {
+			continue
+		}
+		file, ref := firstEvidencePath(v.Evidence), schema.Reference{}
+		if file == "" {
+			if f := referenceFile(v.SpecReference, report.Input.SpecFile, report.Input.SpecFiles); f != "" {
+				file, ref = f, v.SpecReference
+			}
+		}
+		writeAnnotation(&b, v.Severity, v.ID, v.Description, file, ref)
+	}
+	return []byte(b.String()), nil
+}
+
+// writeAnnotation writes one workflow command line to b. The line is only
+// given when ref has one; file is omitted when empty.
+func writeAnnotation(b *strings.Builder, sev schema.Severity, id, description, file string, ref schema.Reference) {
+	var props []string
+	if file != "" {
+		props = append(props, "file="+githubProperty(filepath.ToSlash(file)))
+		if ref.LineStart > 0 {
+			props = append(props, fmt.Sprintf("line=%d", ref.LineStart))
+			if ref.LineEnd > ref.LineStart {
+				props = append(props, fmt.Sprintf("endLine=%d", ref.LineEnd))
+			}
+		}
+	}
+	props = append(props, "title="+githubProperty(id))
+	fmt.Fprintf(b, "::%s %s::%s\n", githubLevel(sev), strings.Join(props, ","), githubData(id+": "+description))
+}
+
+// githubLevel maps a finding severity to a workflow command name.
+func githubLevel(s schema.Severity) string {
+	switch s {
+	case schema.SeverityCritical:
+		return "error"
+	case schema.SeverityWarn:
+		return "warning"
+	default:
+		return "notice"
+	}
+}
+
+// firstEvidencePath returns the first non-empty evidence path, or "".
+func firstEvidencePath(evidence []schema.Evidence) string {
+	for _, ev := range evidence {
+		if ev.Path != "" {
+			return ev.Path
+		}
+	}
+	return ""
+}
+
+// referenceFile returns the spec or plan file that ref points into:
+// ref.Source, or, for a report of a single file, that file. first and files
+// are the matching Input fields. It returns "" when the file is unknown.
+func referenceFile(ref schema.Reference, first string, files []string) string {
+	if ref.Source != "" {
+		return ref.Source
+	}
+	if len(files) > 1 {
+		return ""
+	}
+	return first
+}
+
+// githubData escapes a workflow command message.
+func githubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// githubProperty escapes a workflow command property value, which also may
+// not contain the ":" and "," that delimit properties.
+func githubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
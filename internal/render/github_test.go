@@ -0,0 +1,78 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+func TestRenderGitHub(t *testing.T) {
+	report := sampleReport()
+	report.Drift[0].Description = "retries: 3, then 100% fail\nsilently"
+	report.Drift = append(report.Drift, schema.DriftFinding{ID: "DRIFT-002", Severity: schema.SeverityCritical, Description: "no evidence"})
+	report.Violations = append(report.Violations, schema.Violation{
+		ID:            "VIOLATION-002",
+		Severity:      schema.SeverityWarn,
+		Description:   "spec limit exceeded",
+		SpecReference: schema.Reference{LineStart: 12, LineEnd: 14},
+	})
+
+	b, err := RenderGitHub(report)
+	if err != nil {
+		t.Fatalf("RenderGitHub error: %v", err)
+	}
+	want := []string{
+		"::warning file=internal/client/client.go,title=DRIFT-001::DRIFT-001: retries: 3, then 100%25 fail%0Asilently",
+		"::error title=DRIFT-002::DRIFT-002: no evidence",
+		"::notice file=internal/client/client.go,title=VIOLATION-001::VIOLATION-001: timeout exceeds spec limit",
+		"::warning file=SPEC.md,line=12,endLine=14,title=VIOLATION-002::VIOLATION-002: spec limit exceeded",
+	}
+	if got := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("output =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRenderGitHub_MultipleSpecFiles(t *testing.T) {
+	report := sampleReport()
+	report.Input.SpecFiles = []string{"SPEC.md", "docs/api.md"}
+	report.Violations = []schema.Violation{
+		{ID: "VIOLATION-001", Severity: schema.SeverityWarn, Description: "in api", SpecReference: schema.Reference{LineStart: 3, LineEnd: 4, Source: "docs/api.md"}},
+		{ID: "VIOLATION-002", Severity: schema.SeverityWarn, Description: "unknown file", SpecReference: schema.Reference{LineStart: 7, LineEnd: 7}},
+	}
+
+	b, err := RenderGitHub(report)
+	if err != nil {
+		t.Fatalf("RenderGitHub error: %v", err)
+	}
+	for _, want := range []string{
+		"::warning file=docs/api.md,line=3,endLine=4,title=VIOLATION-001::",
+		"::warning title=VIOLATION-002::",
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("output missing %q:\n%s", want, b)
+		}
+	}
+}
+
+func TestRenderGitHub_Suppressed(t *testing.T) {
+	report := sampleReport()
+	report.Drift[0].Suppressed = true
+
+	b, err := RenderGitHub(report)
+	if err != nil {
+		t.Fatalf("RenderGitHub error: %v", err)
+	}
+	if strings.Contains(string(b), "DRIFT-001") || !strings.Contains(string(b), "VIOLATION-001") {
+		t.Errorf("output = %q, want only the unsuppressed violation", b)
+	}
+	if _, err := RenderGitHub(nil); err == nil {
+		t.Error("RenderGitHub(nil) expected error, got nil")
+	}
+}
+
+func TestGitHubProperty(t *testing.T) {
+	if got := githubProperty("a:b,c%"); got != "a%3Ab%2Cc%25" {
+		t.Errorf("githubProperty = %q", got)
+	}
+}
@@ -0,0 +1,193 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/dshills/realitycheck/internal/schema"
+	"github.com/dshills/realitycheck/internal/verdict"
+)
+
+// HTMLOptions controls optional content in the HTML output.
+type HTMLOptions struct {
+	// ScoreWeights, if non-nil, are the weights the score was computed
+	// with; the severity legend shows each severity's penalty. When nil,
+	// as for a saved report whose weights are unknown, the legend omits
+	// the penalties.
+	ScoreWeights *verdict.ScoreWeights
+}
+
+// htmlStyle is the inline stylesheet for RenderHTML. The report must be
+// self-contained, so no external assets are referenced.
+const htmlStyle = `body{font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",Helvetica,Arial,sans-serif;margin:2rem auto;max-width:960px;color:#1f2328;line-height:1.5}
+h1,h2{border-bottom:1px solid #d0d7de;padding-bottom:.3rem}
+.banner{padding:1rem 1.25rem;border-radius:6px;color:#fff;margin-bottom:1rem}
+.banner h1{border:none;margin:0 0 .25rem 0}
+.rationale{font-size:1.1em;border-left:4px solid #d0d7de;padding:.25rem 1rem;margin:0 0 1rem 0}
+.verdict-ALIGNED{background:#1a7f37}
+.verdict-PARTIALLY_ALIGNED{background:#9a6700}
+.verdict-DRIFT_DETECTED{background:#bc4c00}
+.verdict-VIOLATION{background:#cf222e}
+.legend span{display:inline-block;margin-right:1rem}
+.sev{display:inline-block;padding:0 .4rem;border-radius:4px;color:#fff;font-size:.85em;font-weight:600}
+.sev-INFO{background:#0969da}
+.sev-WARN{background:#9a6700}
+.sev-CRITICAL{background:#cf222e}
+table{border-collapse:collapse;width:100%;margin-bottom:1rem}
+th,td{border:1px solid #d0d7de;padding:.35rem .6rem;text-align:left;vertical-align:top}
+th{background:#f6f8fa;cursor:pointer;user-select:none}
+details{border:1px solid #d0d7de;border-radius:6px;padding:.5rem .75rem;margin-bottom:.5rem}
+summary{cursor:pointer}
+code{background:#f6f8fa;padding:.1rem .3rem;border-radius:4px}`
+
+// htmlSortScript makes every table with class "sortable" sortable by clicking
+// a column header.
+const htmlSortScript = `document.querySelectorAll("table.sortable th").forEach(function(th,col){
+th.addEventListener("click",function(){
+var tbody=th.closest("table").tBodies[0];
+var asc=th.dataset.dir!=="asc";th.dataset.dir=asc?"asc":"desc";
+Array.from(tbody.rows).sort(function(a,b){
+var x=a.cells[col].textContent,y=b.cells[col].textContent;
+return asc?x.localeCompare(y):y.localeCompare(x);
+}).forEach(function(r){tbody.appendChild(r);});
+});
+});`
+
+// RenderHTML produces a self-contained HTML document for the report, with a
+// verdict-colored summary banner followed by the model's rationale when
+// present, sortable coverage tables, and collapsible sections for each drift
+// finding and violation. All text originating from the report is
+// HTML-escaped.
+func RenderHTML(report *schema.Report) string {
+	return RenderHTMLWithOptions(report, HTMLOptions{})
+}
+
+// RenderHTMLWithOptions is RenderHTML with optional content controlled by opts.
+func RenderHTMLWithOptions(report *schema.Report, opts HTMLOptions) string {
+	if report == nil {
+		return ""
+	}
+	esc := html.EscapeString
+	var sb strings.Builder
+
+	sb.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	sb.WriteString("<title>RealityCheck Report</title>\n")
+	fmt.Fprintf(&sb, "<style>\n%s\n</style>\n", htmlStyle)
+	sb.WriteString("</head>\n<body>\n")
+
+	// Summary banner.
+	fmt.Fprintf(&sb, "<div class=\"banner verdict-%s\">\n", esc(string(report.Summary.Verdict)))
+	sb.WriteString("<h1>RealityCheck Report</h1>\n")
+	fmt.Fprintf(&sb, "<div><strong>Verdict:</strong> %s &middot; <strong>Score:</strong> %d/100</div>\n",
+		esc(string(report.Summary.Verdict)), report.Summary.Score)
+	fmt.Fprintf(&sb, "<div><strong>Critical:</strong> %d | <strong>Warn:</strong> %d | <strong>Info:</strong> %d</div>\n",
+		report.Summary.CriticalCount, report.Summary.WarnCount, report.Summary.InfoCount)
+	sb.WriteString("</div>\n")
+	if report.Summary.Rationale != "" {
+		fmt.Fprintf(&sb, "<p class=\"rationale\">%s</p>\n", esc(report.Summary.Rationale))
+	}
+
+	// Severity legend.
+	sb.WriteString("<p class=\"legend\">")
+	var critPenalty, warnPenalty, infoPenalty string
+	if w := opts.ScoreWeights; w != nil {
+		critPenalty = fmt.Sprintf(" (−%d)", w.Critical)
+		warnPenalty = fmt.Sprintf(" (−%d)", w.Warn)
+		infoPenalty = fmt.Sprintf(" (−%d)", w.Info)
+	}
+	fmt.Fprintf(&sb, "<span><span class=\"sev sev-CRITICAL\">CRITICAL</span> blocks alignment%s</span>", critPenalty)
+	fmt.Fprintf(&sb, "<span><span class=\"sev sev-WARN\">WARN</span> needs attention%s</span>", warnPenalty)
+	fmt.Fprintf(&sb, "<span><span class=\"sev sev-INFO\">INFO</span> informational%s</span>", infoPenalty)
+	sb.WriteString("</p>\n")
+
+	// Coverage tables.
+	if len(report.Coverage.Spec) > 0 {
+		sb.WriteString("<h2>Spec Coverage</h2>\n")
+		writeHTMLCoverageHeader(&sb)
+		for _, e := range report.Coverage.Spec {
+			fmt.Fprintf(&sb, "<tr><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+				esc(e.ID), esc(string(e.Status)), esc(e.Notes))
+		}
+		sb.WriteString("</tbody>\n</table>\n")
+	}
+	if len(report.Coverage.Plan) > 0 {
+		sb.WriteString("<h2>Plan Coverage</h2>\n")
+		writeHTMLCoverageHeader(&sb)
+		for _, e := range report.Coverage.Plan {
+			fmt.Fprintf(&sb, "<tr><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+				esc(e.ID), esc(string(e.Status)), esc(e.Notes))
+		}
+		sb.WriteString("</tbody>\n</table>\n")
+	}
+
+	// Drift findings.
+	if len(report.Drift) > 0 {
+		sb.WriteString("<h2>Drift Findings</h2>\n")
+		for _, d := range report.Drift {
+			writeHTMLFindingSummary(&sb, d.ID, d.Severity, d.Suppressed, d.Description)
+			writeHTMLEvidence(&sb, d.Evidence)
+			if d.WhyUnjustified != "" {
+				fmt.Fprintf(&sb, "<p><strong>Why unjustified:</strong> %s</p>\n", esc(d.WhyUnjustified))
+			}
+			if d.Impact != "" {
+				fmt.Fprintf(&sb, "<p><strong>Impact:</strong> %s</p>\n", esc(d.Impact))
+			}
+			if d.Recommendation != "" {
+				fmt.Fprintf(&sb, "<p><strong>Recommendation:</strong> %s</p>\n", esc(d.Recommendation))
+			}
+			sb.WriteString("</details>\n")
+		}
+	}
+
+	// Violations.
+	if len(report.Violations) > 0 {
+		sb.WriteString("<h2>Violations</h2>\n")
+		for _, v := range report.Violations {
+			writeHTMLFindingSummary(&sb, v.ID, v.Severity, v.Suppressed, v.Description)
+			writeHTMLEvidence(&sb, v.Evidence)
+			if v.Impact != "" {
+				fmt.Fprintf(&sb, "<p><strong>Impact:</strong> %s</p>\n", esc(v.Impact))
+			}
+			blocking := "no"
+			if v.Blocking {
+				blocking = "yes"
+			}
+			fmt.Fprintf(&sb, "<p><strong>Blocking:</strong> %s</p>\n", blocking)
+			sb.WriteString("</details>\n")
+		}
+	}
+
+	fmt.Fprintf(&sb, "<script>\n%s\n</script>\n", htmlSortScript)
+	sb.WriteString("</body>\n</html>\n")
+	return sb.String()
+}
+
+// writeHTMLCoverageHeader opens a sortable coverage table.
+func writeHTMLCoverageHeader(sb *strings.Builder) {
+	sb.WriteString("<table class=\"sortable\">\n<thead><tr><th>ID</th><th>Status</th><th>Notes</th></tr></thead>\n<tbody>\n")
+}
+
+// writeHTMLFindingSummary opens a <details> block for a finding, marked as
+// Markdown marks it when a baseline suppressed it.
+func writeHTMLFindingSummary(sb *strings.Builder, id string, sev schema.Severity, suppressed bool, description string) {
+	fmt.Fprintf(sb, "<details>\n<summary><strong>%s</strong> <span class=\"sev sev-%s\">%s</span>%s — %s</summary>\n",
+		html.EscapeString(id), html.EscapeString(string(sev)), html.EscapeString(string(sev)), suppressedMark(suppressed), html.EscapeString(description))
+}
+
+// writeHTMLEvidence renders an evidence list into sb.
+func writeHTMLEvidence(sb *strings.Builder, evidence []schema.Evidence) {
+	if len(evidence) == 0 {
+		return
+	}
+	sb.WriteString("<p><strong>Evidence:</strong></p>\n<ul>\n")
+	for _, ev := range evidence {
+		if ev.Symbol != "" {
+			fmt.Fprintf(sb, "<li><code>%s</code>: <code>%s</code></li>\n",
+				html.EscapeString(ev.Path), html.EscapeString(ev.Symbol))
+		} else {
+			fmt.Fprintf(sb, "<li><code>%s</code></li>\n", html.EscapeString(ev.Path))
+		}
+	}
+	sb.WriteString("</ul>\n")
+}
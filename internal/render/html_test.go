@@ -0,0 +1,97 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/verdict"
+)
+
+func TestRenderHTML_ContainsAllIDs(t *testing.T) {
+	out := RenderHTML(sampleReport())
+	for _, id := range []string{"SPEC-001", "SPEC-002", "PLAN-001", "DRIFT-001", "VIOLATION-001"} {
+		if !strings.Contains(out, id) {
+			t.Errorf("HTML output missing ID %q", id)
+		}
+	}
+	if !strings.Contains(out, `class="banner verdict-DRIFT_DETECTED"`) {
+		t.Error("HTML output missing verdict-colored banner")
+	}
+	if !strings.Contains(out, "<code>internal/client/client.go</code>") {
+		t.Error("HTML output should render evidence paths as <code> spans")
+	}
+	if strings.Contains(out, "http://") || strings.Contains(out, "https://") {
+		t.Error("HTML output should not reference external assets")
+	}
+}
+
+func TestRenderHTML_EscapesText(t *testing.T) {
+	report := sampleReport()
+	report.Drift[0].Description = `<script>alert("x")</script>`
+	report.Coverage.Spec[0].Notes = "a & b"
+
+	out := RenderHTML(report)
+	if strings.Contains(out, `<script>alert`) {
+		t.Error("description was not HTML-escaped")
+	}
+	if !strings.Contains(out, "&lt;script&gt;") {
+		t.Error("expected escaped script tag in output")
+	}
+	if !strings.Contains(out, "a &amp; b") {
+		t.Error("expected escaped ampersand in notes")
+	}
+}
+
+func TestRenderHTML_NilReport(t *testing.T) {
+	if got := RenderHTML(nil); got != "" {
+		t.Errorf("RenderHTML(nil) = %q, want empty string", got)
+	}
+}
+
+func TestRenderHTML_Rationale(t *testing.T) {
+	report := sampleReport()
+	if strings.Contains(RenderHTML(report), `class="rationale"`) {
+		t.Error("rationale paragraph rendered without a rationale")
+	}
+	report.Summary.Rationale = "Mostly aligned & tested."
+	out := RenderHTML(report)
+	i := strings.Index(out, `<p class="rationale">Mostly aligned &amp; tested.</p>`)
+	if i < 0 || i > strings.Index(out, "Spec Coverage") {
+		t.Errorf("rationale missing or not above the coverage tables:\n%s", out)
+	}
+}
+
+func TestRenderHTMLWithOptions_LegendWeights(t *testing.T) {
+	w := verdict.ScoreWeights{Critical: 30, Warn: 10, Info: 1}
+	out := RenderHTMLWithOptions(sampleReport(), HTMLOptions{ScoreWeights: &w})
+	for _, want := range []string{"blocks alignment (−30)", "needs attention (−10)", "informational (−1)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("legend missing %q", want)
+		}
+	}
+
+	if out := RenderHTML(sampleReport()); strings.Contains(out, "(−") {
+		t.Error("legend should omit penalties when the weights are not known")
+	}
+}
+
+func TestRenderHTML_Suppressed(t *testing.T) {
+	report := sampleReport()
+	report.Drift[0].Suppressed = true
+	out := RenderHTML(report)
+	if !strings.Contains(out, "</span> (suppressed) — "+report.Drift[0].Description) {
+		t.Errorf("suppressed drift finding not marked:\n%s", out)
+	}
+	if strings.Count(out, "(suppressed)") != 1 {
+		t.Error("only the suppressed finding should be marked")
+	}
+}
+
+func TestRenderHTML_DriftImpact(t *testing.T) {
+	report := sampleReport()
+	report.Drift[0].Impact = "Writes bypass <auth>."
+	out := RenderHTML(report)
+	if !strings.Contains(out, "<p><strong>Impact:</strong> Writes bypass &lt;auth&gt;.</p>") {
+		t.Errorf("drift impact missing:\n%s", out)
+	}
+}
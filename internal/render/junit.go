@@ -0,0 +1,128 @@
+package render
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Time       string           `xml:"time,attr"`
+	Properties []junitProperty  `xml:"properties>property"`
+	Suites     []junitTestSuite `xml:"testsuite"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitZeroTime is used for every duration attribute; per-item latency is
+// not measured.
+const junitZeroTime = "0"
+
+// RenderJUnit produces a JUnit XML report for CI test panels. Each spec and
+// plan coverage entry is a testcase that passes only when IMPLEMENTED; each
+// unsuppressed CRITICAL violation is a failing testcase in a separate
+// "violations" suite.
+// The overall verdict and score are recorded as properties on <testsuites>.
+func RenderJUnit(report *schema.Report) ([]byte, error) {
+	if report == nil {
+		return nil, fmt.Errorf("render: nil report")
+	}
+
+	specSuite := junitTestSuite{Name: "spec", Time: junitZeroTime}
+	for _, e := range report.Coverage.Spec {
+		specSuite.add(coverageTestCase("spec", e.ID, e.Status, e.Notes))
+	}
+	planSuite := junitTestSuite{Name: "plan", Time: junitZeroTime}
+	for _, e := range report.Coverage.Plan {
+		planSuite.add(coverageTestCase("plan", e.ID, e.Status, e.Notes))
+	}
+	violationSuite := junitTestSuite{Name: "violations", Time: junitZeroTime}
+	for _, v := range report.Violations {
+		if v.Severity != schema.SeverityCritical || v.Suppressed {
+			continue
+		}
+		violationSuite.add(junitTestCase{
+			Name:      v.ID,
+			ClassName: "violations",
+			Time:      junitZeroTime,
+			Failure: &junitFailure{
+				Message: v.Description,
+				Type:    string(v.Severity),
+				Text:    v.Impact,
+			},
+		})
+	}
+
+	doc := junitTestSuites{
+		Name: report.Tool,
+		Time: junitZeroTime,
+		Properties: []junitProperty{
+			{Name: "verdict", Value: string(report.Summary.Verdict)},
+			{Name: "score", Value: strconv.Itoa(report.Summary.Score)},
+		},
+		Suites: []junitTestSuite{specSuite, planSuite, violationSuite},
+	}
+	for _, s := range doc.Suites {
+		doc.Tests += s.Tests
+		doc.Failures += s.Failures
+	}
+
+	b, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("render: junit marshal: %w", err)
+	}
+	return append([]byte(xml.Header), b...), nil
+}
+
+// add appends tc to the suite and updates the test and failure counts.
+func (s *junitTestSuite) add(tc junitTestCase) {
+	s.Cases = append(s.Cases, tc)
+	s.Tests++
+	if tc.Failure != nil {
+		s.Failures++
+	}
+}
+
+// coverageTestCase builds a testcase for a coverage entry. Any status other
+// than IMPLEMENTED is reported as a failure carrying the entry's notes.
+func coverageTestCase(class, id string, status schema.CoverageStatus, notes string) junitTestCase {
+	tc := junitTestCase{Name: id, ClassName: class, Time: junitZeroTime}
+	if status != schema.StatusImplemented {
+		msg := notes
+		if msg == "" {
+			msg = fmt.Sprintf("%s is %s", id, status)
+		}
+		tc.Failure = &junitFailure{Message: msg, Type: string(status)}
+	}
+	return tc
+}
@@ -0,0 +1,66 @@
+package render
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+func TestRenderJUnit_Suites(t *testing.T) {
+	report := sampleReport()
+	report.Violations = append(report.Violations, schema.Violation{
+		ID:          "VIOLATION-002",
+		Severity:    schema.SeverityCritical,
+		Description: "writes to disk despite read-only constraint",
+		Impact:      "data corruption",
+	})
+
+	b, err := RenderJUnit(report)
+	if err != nil {
+		t.Fatalf("RenderJUnit error: %v", err)
+	}
+	if !strings.HasPrefix(string(b), "<?xml") {
+		t.Error("expected XML declaration header")
+	}
+
+	var doc junitTestSuites
+	if err := xml.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("xml.Unmarshal error: %v", err)
+	}
+	if len(doc.Suites) != 3 {
+		t.Fatalf("suites = %d, want 3", len(doc.Suites))
+	}
+
+	spec := doc.Suites[0]
+	if spec.Name != "spec" || spec.Tests != 2 || spec.Failures != 1 {
+		t.Errorf("spec suite = {%q tests=%d failures=%d}, want {spec 2 1}", spec.Name, spec.Tests, spec.Failures)
+	}
+	if f := spec.Cases[1].Failure; f == nil || f.Message != "missing error handling" {
+		t.Errorf("SPEC-002 failure = %+v, want notes as message", f)
+	}
+
+	violations := doc.Suites[2]
+	// Only the CRITICAL violation is reported; the INFO one is omitted.
+	if violations.Name != "violations" || violations.Tests != 1 || violations.Cases[0].Name != "VIOLATION-002" {
+		t.Errorf("violations suite = %+v, want only VIOLATION-002", violations)
+	}
+
+	props := map[string]string{}
+	for _, p := range doc.Properties {
+		props[p.Name] = p.Value
+	}
+	if props["verdict"] != "DRIFT_DETECTED" || props["score"] != "80" {
+		t.Errorf("properties = %v, want verdict DRIFT_DETECTED and score 80", props)
+	}
+	if doc.Tests != 4 || doc.Failures != 2 {
+		t.Errorf("totals = tests %d failures %d, want 4 and 2", doc.Tests, doc.Failures)
+	}
+}
+
+func TestRenderJUnit_NilReport(t *testing.T) {
+	if _, err := RenderJUnit(nil); err == nil {
+		t.Fatal("RenderJUnit(nil) expected error, got nil")
+	}
+}
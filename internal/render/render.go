@@ -4,6 +4,8 @@ package render
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
 
 	"github.com/dshills/realitycheck/internal/schema"
@@ -22,10 +24,44 @@ func RenderJSON(report *schema.Report) ([]byte, error) {
 	return b, nil
 }
 
+// RenderJSONTo encodes the report to w as pretty-printed JSON followed by a
+// newline, without holding a second, indented copy of the output. The JSON
+// is the same as RenderJSON's.
+func RenderJSONTo(w io.Writer, report *schema.Report) error {
+	if report == nil {
+		return fmt.Errorf("render: nil report")
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("render: json encode: %w", err)
+	}
+	return nil
+}
+
+// Markdown coverage styles for MarkdownOptions.Style.
+const (
+	MarkdownStyleTable     = "table"
+	MarkdownStyleChecklist = "checklist"
+)
+
+// MarkdownOptions controls the layout of the Markdown output.
+type MarkdownOptions struct {
+	// Style is MarkdownStyleTable (the default when empty) or
+	// MarkdownStyleChecklist, which renders spec and plan coverage as GitHub
+	// task lists that can be pasted into a PR body.
+	Style string
+}
+
 // RenderMarkdown produces a GitHub-flavoured Markdown summary of the report,
 // suitable for PR comments or terminal output. Every finding ID present in
 // the report will appear in the output.
 func RenderMarkdown(report *schema.Report) string {
+	return RenderMarkdownWithOptions(report, MarkdownOptions{})
+}
+
+// RenderMarkdownWithOptions is RenderMarkdown with the layout controlled by opts.
+func RenderMarkdownWithOptions(report *schema.Report, opts MarkdownOptions) string {
 	if report == nil {
 		return ""
 	}
@@ -35,43 +71,60 @@ func RenderMarkdown(report *schema.Report) string {
 	sb.WriteString("## RealityCheck Report\n\n")
 	fmt.Fprintf(&sb, "**Verdict:** %s  \n", report.Summary.Verdict)
 	fmt.Fprintf(&sb, "**Score:** %d/100  \n", report.Summary.Score)
+	if line := coverageLine(report.Summary); line != "" {
+		fmt.Fprintf(&sb, "**Coverage:** %s  \n", line)
+	}
 	fmt.Fprintf(&sb, "**Critical:** %d | **Warn:** %d | **Info:** %d\n\n",
 		report.Summary.CriticalCount, report.Summary.WarnCount, report.Summary.InfoCount)
+	if report.Summary.Rationale != "" {
+		fmt.Fprintf(&sb, "**Rationale:** %s\n\n", mdInline(report.Summary.Rationale))
+	}
+	if report.Meta.TotalTokens > 0 {
+		fmt.Fprintf(&sb, "**Tokens:** %d prompt + %d completion = %d total\n\n",
+			report.Meta.PromptTokens, report.Meta.CompletionTokens, report.Meta.TotalTokens)
+	}
 
-	// Spec coverage table.
+	// Spec coverage.
 	if len(report.Coverage.Spec) > 0 {
-		sb.WriteString("## Spec Coverage\n\n")
-		sb.WriteString("| ID | Status | Notes |\n")
-		sb.WriteString("|---|---|---|\n")
-		for _, e := range report.Coverage.Spec {
-			fmt.Fprintf(&sb, "| %s | %s | %s |\n", e.ID, e.Status, mdEscape(e.Notes))
+		rows := make([]coverageRow, len(report.Coverage.Spec))
+		for i, e := range report.Coverage.Spec {
+			rows[i] = coverageRow{e.ID, e.Status, e.SpecReference.Quote, e.Notes}
 		}
-		sb.WriteString("\n")
+		sb.WriteString("## Spec Coverage\n\n")
+		writeCoverage(&sb, opts.Style, rows)
 	}
 
-	// Plan coverage table.
+	// Plan coverage.
 	if len(report.Coverage.Plan) > 0 {
-		sb.WriteString("## Plan Coverage\n\n")
-		sb.WriteString("| ID | Status | Notes |\n")
-		sb.WriteString("|---|---|---|\n")
-		for _, e := range report.Coverage.Plan {
-			fmt.Fprintf(&sb, "| %s | %s | %s |\n", e.ID, e.Status, mdEscape(e.Notes))
+		rows := make([]coverageRow, len(report.Coverage.Plan))
+		for i, e := range report.Coverage.Plan {
+			rows[i] = coverageRow{e.ID, e.Status, e.PlanReference.Quote, e.Notes}
 		}
-		sb.WriteString("\n")
+		sb.WriteString("## Plan Coverage\n\n")
+		writeCoverage(&sb, opts.Style, rows)
+	}
+
+	// Traceability matrix.
+	if len(report.Traceability) > 0 {
+		sb.WriteString("## Traceability\n\n")
+		writeTraceability(&sb, report)
 	}
 
 	// Drift findings.
 	if len(report.Drift) > 0 {
 		sb.WriteString("## Drift Findings\n\n")
 		for _, d := range report.Drift {
-			fmt.Fprintf(&sb, "<details>\n<summary><strong>%s</strong> [%s] — %s</summary>\n\n",
-				d.ID, d.Severity, mdEscape(d.Description))
+			fmt.Fprintf(&sb, "<details>\n<summary><strong>%s</strong> [%s]%s — %s</summary>\n\n",
+				d.ID, d.Severity, suppressedMark(d.Suppressed), mdEscape(d.Description))
 			writeEvidence(&sb, d.Evidence)
 			if d.WhyUnjustified != "" {
-				fmt.Fprintf(&sb, "**Why unjustified:** %s\n\n", mdEscape(d.WhyUnjustified))
+				fmt.Fprintf(&sb, "**Why unjustified:** %s\n\n", mdBlock(d.WhyUnjustified))
+			}
+			if d.Impact != "" {
+				fmt.Fprintf(&sb, "**Impact:** %s\n\n", mdBlock(d.Impact))
 			}
 			if d.Recommendation != "" {
-				fmt.Fprintf(&sb, "**Recommendation:** %s\n\n", mdEscape(d.Recommendation))
+				fmt.Fprintf(&sb, "**Recommendation:** %s\n\n", mdBlock(d.Recommendation))
 			}
 			sb.WriteString("</details>\n\n")
 		}
@@ -81,11 +134,11 @@ func RenderMarkdown(report *schema.Report) string {
 	if len(report.Violations) > 0 {
 		sb.WriteString("## Violations\n\n")
 		for _, v := range report.Violations {
-			fmt.Fprintf(&sb, "<details>\n<summary><strong>%s</strong> [%s] — %s</summary>\n\n",
-				v.ID, v.Severity, mdEscape(v.Description))
+			fmt.Fprintf(&sb, "<details>\n<summary><strong>%s</strong> [%s]%s — %s</summary>\n\n",
+				v.ID, v.Severity, suppressedMark(v.Suppressed), mdEscape(v.Description))
 			writeEvidence(&sb, v.Evidence)
 			if v.Impact != "" {
-				fmt.Fprintf(&sb, "**Impact:** %s\n\n", mdEscape(v.Impact))
+				fmt.Fprintf(&sb, "**Impact:** %s\n\n", mdBlock(v.Impact))
 			}
 			blocking := "no"
 			if v.Blocking {
@@ -99,6 +152,113 @@ func RenderMarkdown(report *schema.Report) string {
 	return sb.String()
 }
 
+// suppressedMark labels a finding suppressed by a baseline.
+func suppressedMark(suppressed bool) string {
+	if suppressed {
+		return " (suppressed)"
+	}
+	return ""
+}
+
+// coverageRow is the part of a spec or plan coverage entry shown in Markdown.
+type coverageRow struct {
+	ID     string
+	Status schema.CoverageStatus
+	Quote  string
+	Notes  string
+}
+
+// writeCoverage renders coverage rows as a table or, for
+// MarkdownStyleChecklist, as a task list: IMPLEMENTED items are checked,
+// everything else is unchecked, and notes follow an em dash.
+func writeCoverage(sb *strings.Builder, style string, rows []coverageRow) {
+	if style != MarkdownStyleChecklist {
+		sb.WriteString("| ID | Status | Notes |\n")
+		sb.WriteString("|---|---|---|\n")
+		for _, r := range rows {
+			fmt.Fprintf(sb, "| %s | %s | %s |\n", r.ID, r.Status, mdEscape(r.Notes))
+		}
+		sb.WriteString("\n")
+		return
+	}
+	for _, r := range rows {
+		box := " "
+		if r.Status == schema.StatusImplemented {
+			box = "x"
+		}
+		fmt.Fprintf(sb, "- [%s] %s", box, r.ID)
+		if r.Status == schema.StatusPartial {
+			sb.WriteString(" (partial)")
+		}
+		if r.Quote != "" {
+			fmt.Fprintf(sb, " %s", mdInline(r.Quote))
+		}
+		if r.Notes != "" {
+			fmt.Fprintf(sb, " — %s", mdInline(r.Notes))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+}
+
+// writeTraceability renders report.Traceability as a table with one row per
+// spec item, one column per plan item, and ✓ where the plan item serves the
+// spec item. Rows and columns follow coverage order; IDs found only in links
+// are appended.
+func writeTraceability(sb *strings.Builder, report *schema.Report) {
+	var specIDs, planIDs []string
+	seenSpec := map[string]bool{}
+	seenPlan := map[string]bool{}
+	addSpec := func(id string) {
+		if !seenSpec[id] {
+			seenSpec[id] = true
+			specIDs = append(specIDs, id)
+		}
+	}
+	addPlan := func(id string) {
+		if !seenPlan[id] {
+			seenPlan[id] = true
+			planIDs = append(planIDs, id)
+		}
+	}
+	for _, e := range report.Coverage.Spec {
+		addSpec(e.ID)
+	}
+	for _, e := range report.Coverage.Plan {
+		addPlan(e.ID)
+	}
+	linked := map[[2]string]bool{}
+	for _, l := range report.Traceability {
+		addPlan(l.PlanID)
+		for _, id := range l.SpecIDs {
+			addSpec(id)
+			linked[[2]string{id, l.PlanID}] = true
+		}
+	}
+
+	sb.WriteString("| Spec |")
+	for _, p := range planIDs {
+		fmt.Fprintf(sb, " %s |", p)
+	}
+	sb.WriteString("\n|---|")
+	for range planIDs {
+		sb.WriteString(":-:|")
+	}
+	sb.WriteString("\n")
+	for _, s := range specIDs {
+		fmt.Fprintf(sb, "| %s |", s)
+		for _, p := range planIDs {
+			if linked[[2]string{s, p}] {
+				sb.WriteString(" ✓ |")
+			} else {
+				sb.WriteString("  |")
+			}
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+}
+
 // writeEvidence renders an evidence list into sb.
 func writeEvidence(sb *strings.Builder, evidence []schema.Evidence) {
 	if len(evidence) == 0 {
@@ -115,6 +275,12 @@ func writeEvidence(sb *strings.Builder, evidence []schema.Evidence) {
 	sb.WriteString("\n")
 }
 
+// mdInline flattens s onto one line so it cannot end a list item early.
+func mdInline(s string) string {
+	s = strings.ReplaceAll(s, "\r", "")
+	return strings.Join(strings.Fields(s), " ")
+}
+
 // mdEscape replaces characters that would break Markdown table cells.
 func mdEscape(s string) string {
 	s = strings.ReplaceAll(s, "|", "\\|")
@@ -122,3 +288,28 @@ func mdEscape(s string) string {
 	s = strings.ReplaceAll(s, "\r", "")
 	return s
 }
+
+// mdBlock is mdEscape for text outside table cells, such as the body of a
+// <details> section: line breaks are kept as Markdown hard breaks, so a
+// multi-paragraph note stays readable.
+func mdBlock(s string) string {
+	s = strings.ReplaceAll(s, "\r", "")
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	for i, l := range lines {
+		lines[i] = strings.ReplaceAll(strings.TrimRight(l, " \t"), "|", "\\|")
+	}
+	return strings.Join(lines, "  \n")
+}
+
+// coverageLine describes the coverage percentages in sum, such as "62.5% of
+// spec items, 100% of plan items", or returns "" when there are none.
+func coverageLine(sum schema.Summary) string {
+	var parts []string
+	if sum.SpecCoveragePct != nil {
+		parts = append(parts, strconv.FormatFloat(*sum.SpecCoveragePct, 'f', -1, 64)+"% of spec items")
+	}
+	if sum.PlanCoveragePct != nil {
+		parts = append(parts, strconv.FormatFloat(*sum.PlanCoveragePct, 'f', -1, 64)+"% of plan items")
+	}
+	return strings.Join(parts, ", ")
+}
@@ -1,6 +1,7 @@
 package render
 
 import (
+	"bytes"
 	"encoding/json"
 	"strings"
 	"testing"
@@ -148,6 +149,15 @@ func TestRenderMarkdown_Summary(t *testing.T) {
 	if !strings.Contains(md, "80") {
 		t.Error("markdown missing score 80")
 	}
+	if strings.Contains(md, "**Coverage:**") {
+		t.Error("markdown has a coverage line without percentages")
+	}
+
+	spec, plan := 62.5, 100.0
+	report.Summary.SpecCoveragePct, report.Summary.PlanCoveragePct = &spec, &plan
+	if md := RenderMarkdown(report); !strings.Contains(md, "**Coverage:** 62.5% of spec items, 100% of plan items  \n") {
+		t.Errorf("markdown missing coverage line:\n%s", md)
+	}
 }
 
 func TestRenderMarkdown_CoverageTable(t *testing.T) {
@@ -168,8 +178,39 @@ func TestRenderMarkdown_CoverageTable(t *testing.T) {
 	}
 }
 
+func TestRenderMarkdown_Checklist(t *testing.T) {
+	report := sampleReport()
+	report.Coverage.Spec[0].SpecReference.Quote = "Get returns the value"
+	report.Coverage.Spec = append(report.Coverage.Spec, schema.SpecCoverageEntry{
+		ID: "SPEC-003", Status: schema.StatusNotImplemented,
+	})
+	md := RenderMarkdownWithOptions(report, MarkdownOptions{Style: MarkdownStyleChecklist})
+
+	for _, want := range []string{
+		"- [x] SPEC-001 Get returns the value — fully implemented\n",
+		"- [ ] SPEC-002 (partial) — missing error handling\n",
+		"- [ ] SPEC-003\n",
+		"- [x] PLAN-001\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("checklist missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "| ID | Status | Notes |") {
+		t.Error("checklist style should not render coverage tables")
+	}
+	// Findings are unaffected by the style.
+	if !strings.Contains(md, "DRIFT-001") || !strings.Contains(md, "VIOLATION-001") {
+		t.Error("checklist output missing findings")
+	}
+	if md := RenderMarkdownWithOptions(report, MarkdownOptions{Style: MarkdownStyleTable}); md != RenderMarkdown(report) {
+		t.Error("table style should match RenderMarkdown")
+	}
+}
+
 func TestRenderMarkdown_DriftSection(t *testing.T) {
 	report := sampleReport()
+	report.Drift[0].Impact = "retries double the load"
 	md := RenderMarkdown(report)
 	if !strings.Contains(md, "Drift Findings") {
 		t.Error("markdown missing Drift Findings section")
@@ -180,11 +221,26 @@ func TestRenderMarkdown_DriftSection(t *testing.T) {
 	if !strings.Contains(md, "no spec or plan authorizes") {
 		t.Error("markdown missing WhyUnjustified text")
 	}
+	if !strings.Contains(md, "**Impact:** retries double the load") {
+		t.Error("markdown missing drift Impact text")
+	}
 	if !strings.Contains(md, "add to spec or remove") {
 		t.Error("markdown missing Recommendation text")
 	}
 }
 
+func TestRenderMarkdown_Suppressed(t *testing.T) {
+	report := sampleReport()
+	report.Drift[0].Suppressed = true
+	md := RenderMarkdown(report)
+	if !strings.Contains(md, "[WARN] (suppressed) —") {
+		t.Errorf("markdown missing suppressed label:\n%s", md)
+	}
+	if strings.Contains(md, "[INFO] (suppressed)") {
+		t.Error("unsuppressed violation labelled suppressed")
+	}
+}
+
 func TestRenderMarkdown_ViolationsSection(t *testing.T) {
 	report := sampleReport()
 	md := RenderMarkdown(report)
@@ -245,6 +301,25 @@ func TestRenderJSON_NilReport(t *testing.T) {
 	if err == nil {
 		t.Error("expected error for nil report, got nil")
 	}
+	if err := RenderJSONTo(&bytes.Buffer{}, nil); err == nil {
+		t.Error("RenderJSONTo: expected error for nil report, got nil")
+	}
+}
+
+func TestRenderJSONTo_MatchesRenderJSON(t *testing.T) {
+	report := sampleReport()
+	report.Drift[0].Description = "Writes <html> & more"
+	want, err := RenderJSON(report)
+	if err != nil {
+		t.Fatalf("RenderJSON error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := RenderJSONTo(&buf, report); err != nil {
+		t.Fatalf("RenderJSONTo error: %v", err)
+	}
+	if got := buf.String(); got != string(want)+"\n" {
+		t.Errorf("RenderJSONTo output differs from RenderJSON:\n%s\nwant:\n%s", got, want)
+	}
 }
 
 func TestRenderMarkdown_NilReport(t *testing.T) {
@@ -267,3 +342,74 @@ func TestMdEscape(t *testing.T) {
 		}
 	}
 }
+
+func TestMdBlock(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"one line", "one line"},
+		{"a|b", `a\|b`},
+		{"first\r\nsecond", "first  \nsecond"},
+		{"para one\n\npara two\n", "para one  \n  \npara two"},
+		{"", ""},
+	}
+	for _, c := range cases {
+		if got := mdBlock(c.in); got != c.want {
+			t.Errorf("mdBlock(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestRenderMarkdown_MultiLineDriftNotes(t *testing.T) {
+	report := sampleReport()
+	report.Drift[0].WhyUnjustified = "Not in the spec.\nThe plan never mentions it."
+	md := RenderMarkdown(report)
+	if !strings.Contains(md, "**Why unjustified:** Not in the spec.  \nThe plan never mentions it.\n") {
+		t.Errorf("two-line note not rendered as two lines:\n%s", md)
+	}
+}
+
+func TestRenderMarkdown_TokenUsage(t *testing.T) {
+	report := sampleReport()
+	if md := RenderMarkdown(report); strings.Contains(md, "**Tokens:**") {
+		t.Error("token line should be omitted when usage is unknown")
+	}
+	report.Meta.PromptTokens = 1200
+	report.Meta.CompletionTokens = 300
+	report.Meta.TotalTokens = 1500
+	md := RenderMarkdown(report)
+	if !strings.Contains(md, "**Tokens:** 1200 prompt + 300 completion = 1500 total") {
+		t.Errorf("markdown missing token usage line:\n%s", md)
+	}
+}
+
+func TestRenderMarkdown_SummaryMode(t *testing.T) {
+	report := sampleReport()
+	report.Coverage = schema.Coverage{Spec: []schema.SpecCoverageEntry{}, Plan: []schema.PlanCoverageEntry{}}
+	report.Summary.Rationale = "Mostly aligned;\none undocumented cache."
+	md := RenderMarkdown(report)
+	if !strings.Contains(md, "**Rationale:** Mostly aligned; one undocumented cache.") {
+		t.Errorf("markdown missing rationale line:\n%s", md)
+	}
+	if strings.Contains(md, "Coverage") {
+		t.Errorf("coverage sections rendered without entries:\n%s", md)
+	}
+}
+
+func TestRenderMarkdown_Traceability(t *testing.T) {
+	report := sampleReport()
+	if strings.Contains(RenderMarkdown(report), "## Traceability") {
+		t.Error("traceability section rendered without links")
+	}
+
+	report.Traceability = []schema.TraceLink{{PlanID: "PLAN-001", SpecIDs: []string{"SPEC-002"}}}
+	md := RenderMarkdown(report)
+	for _, want := range []string{
+		"## Traceability",
+		"| Spec | PLAN-001 |",
+		"| SPEC-001 |  |",
+		"| SPEC-002 | ✓ |",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+// SARIF 2.1.0 constants. Only the subset of the format needed by GitHub code
+// scanning is modelled below.
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// SARIFOptions controls optional content in the SARIF output.
+type SARIFOptions struct {
+	// IncludeGaps emits an additional result for every NOT_IMPLEMENTED spec or
+	// plan coverage entry so reviewers see gaps alongside drift and violations.
+	IncludeGaps bool
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	Level        string             `json:"level"`
+	Message      sarifMessage       `json:"message"`
+	Locations    []sarifLocation    `json:"locations,omitempty"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+// sarifSuppression marks a result accepted outside the source, here by a
+// baseline file, so code scanning does not raise an alert for it.
+type sarifSuppression struct {
+	Kind string `json:"kind"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine,omitempty"`
+}
+
+// sarifRuleDescriptions documents each rule ID that RenderSARIF can emit.
+var sarifRuleDescriptions = map[string]string{
+	"DRIFT":     "Code behavior exists without spec or plan authorization.",
+	"VIOLATION": "Code behavior contradicts a declared spec constraint.",
+	"SPEC":      "Spec requirement is not implemented.",
+	"PLAN":      "Plan step is not implemented.",
+}
+
+// RenderSARIF produces a SARIF 2.1.0 log of drift findings and violations,
+// suitable for upload to GitHub code scanning.
+func RenderSARIF(report *schema.Report) ([]byte, error) {
+	return RenderSARIFWithOptions(report, SARIFOptions{})
+}
+
+// RenderSARIFWithOptions is RenderSARIF with optional content controlled by opts.
+func RenderSARIFWithOptions(report *schema.Report, opts SARIFOptions) ([]byte, error) {
+	if report == nil {
+		return nil, fmt.Errorf("render: nil report")
+	}
+
+	in := report.Input
+	results := make([]sarifResult, 0, len(report.Drift)+len(report.Violations))
+	for _, d := range report.Drift {
+		results = append(results, sarifResult{
+			RuleID:       sarifRuleID(d.ID),
+			Level:        sarifLevel(d.Severity),
+			Message:      sarifMessage{Text: fmt.Sprintf("%s: %s", d.ID, d.Description)},
+			Locations:    evidenceLocations(d.Evidence),
+			Suppressions: sarifSuppressions(d.Suppressed),
+		})
+	}
+	for _, v := range report.Violations {
+		locs := evidenceLocations(v.Evidence)
+		if loc, ok := referenceLocation(referenceFile(v.SpecReference, in.SpecFile, in.SpecFiles), v.SpecReference); ok {
+			locs = append(locs, loc)
+		}
+		results = append(results, sarifResult{
+			RuleID:       sarifRuleID(v.ID),
+			Level:        sarifLevel(v.Severity),
+			Message:      sarifMessage{Text: fmt.Sprintf("%s: %s", v.ID, v.Description)},
+			Locations:    locs,
+			Suppressions: sarifSuppressions(v.Suppressed),
+		})
+	}
+	if opts.IncludeGaps {
+		for _, e := range report.Coverage.Spec {
+			if e.Status == schema.StatusNotImplemented {
+				results = append(results, gapResult(e.ID, e.Notes, referenceFile(e.SpecReference, in.SpecFile, in.SpecFiles), e.SpecReference))
+			}
+		}
+		for _, e := range report.Coverage.Plan {
+			if e.Status == schema.StatusNotImplemented {
+				results = append(results, gapResult(e.ID, e.Notes, referenceFile(e.PlanReference, in.PlanFile, in.PlanFiles), e.PlanReference))
+			}
+		}
+	}
+
+	log := sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           report.Tool,
+				Version:        report.Version,
+				InformationURI: "https://github.com/dshills/realitycheck",
+				Rules:          sarifRules(results),
+			}},
+			Results: results,
+		}},
+	}
+	b, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("render: sarif marshal: %w", err)
+	}
+	return b, nil
+}
+
+// gapResult builds a SARIF result for a NOT_IMPLEMENTED coverage entry.
+func gapResult(id, notes, file string, ref schema.Reference) sarifResult {
+	text := fmt.Sprintf("%s is NOT_IMPLEMENTED", id)
+	if notes != "" {
+		text += ": " + notes
+	}
+	r := sarifResult{
+		RuleID:  sarifRuleID(id),
+		Level:   "warning",
+		Message: sarifMessage{Text: text},
+	}
+	if loc, ok := referenceLocation(file, ref); ok {
+		r.Locations = []sarifLocation{loc}
+	}
+	return r
+}
+
+// sarifRuleID derives a rule ID from a finding ID by stripping the numeric
+// suffix (e.g. "DRIFT-003" → "DRIFT").
+func sarifRuleID(id string) string {
+	if i := strings.LastIndex(id, "-"); i > 0 {
+		return id[:i]
+	}
+	return id
+}
+
+// sarifLevel maps a finding severity to a SARIF result level.
+// sarifSuppressions returns an external suppression for a finding suppressed
+// by a baseline, or nil.
+func sarifSuppressions(suppressed bool) []sarifSuppression {
+	if !suppressed {
+		return nil
+	}
+	return []sarifSuppression{{Kind: "external"}}
+}
+
+func sarifLevel(s schema.Severity) string {
+	switch s {
+	case schema.SeverityCritical:
+		return "error"
+	case schema.SeverityWarn:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifRules returns one rule descriptor per distinct rule ID in results,
+// in order of first appearance.
+func sarifRules(results []sarifResult) []sarifRule {
+	seen := make(map[string]bool)
+	rules := []sarifRule{}
+	for _, r := range results {
+		if seen[r.RuleID] {
+			continue
+		}
+		seen[r.RuleID] = true
+		desc := sarifRuleDescriptions[r.RuleID]
+		if desc == "" {
+			desc = r.RuleID
+		}
+		rules = append(rules, sarifRule{ID: r.RuleID, ShortDescription: sarifMessage{Text: desc}})
+	}
+	return rules
+}
+
+// evidenceLocations converts evidence citations to SARIF locations. Evidence
+// with an empty path is skipped.
+func evidenceLocations(evidence []schema.Evidence) []sarifLocation {
+	var locs []sarifLocation
+	for _, ev := range evidence {
+		if ev.Path == "" {
+			continue
+		}
+		locs = append(locs, sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(ev.Path)},
+		}})
+	}
+	return locs
+}
+
+// referenceLocation converts a spec or plan line reference into a SARIF
+// location within file. ok is false when file is empty or the reference
+// carries no line numbers.
+func referenceLocation(file string, ref schema.Reference) (loc sarifLocation, ok bool) {
+	if file == "" || ref.LineStart <= 0 {
+		return sarifLocation{}, false
+	}
+	region := &sarifRegion{StartLine: ref.LineStart}
+	if ref.LineEnd >= ref.LineStart {
+		region.EndLine = ref.LineEnd
+	}
+	return sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(file)},
+		Region:           region,
+	}}, true
+}
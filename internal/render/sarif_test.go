@@ -0,0 +1,139 @@
+package render
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+func TestRenderSARIF_Results(t *testing.T) {
+	report := sampleReport()
+	report.Violations[0].SpecReference = schema.Reference{LineStart: 12, LineEnd: 14}
+
+	b, err := RenderSARIF(report)
+	if err != nil {
+		t.Fatalf("RenderSARIF error: %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(b, &log); err != nil {
+		t.Fatalf("json.Unmarshal error: %v", err)
+	}
+	if log.Version != "2.1.0" {
+		t.Errorf("version = %q, want 2.1.0", log.Version)
+	}
+	if len(log.Runs) != 1 {
+		t.Fatalf("runs = %d, want 1", len(log.Runs))
+	}
+	results := log.Runs[0].Results
+	if len(results) != 2 {
+		t.Fatalf("results = %d, want 2 (one drift, one violation)", len(results))
+	}
+
+	d := results[0]
+	if d.RuleID != "DRIFT" || d.Level != "warning" {
+		t.Errorf("drift result = {%q, %q}, want {DRIFT, warning}", d.RuleID, d.Level)
+	}
+	if len(d.Locations) != 1 || d.Locations[0].PhysicalLocation.ArtifactLocation.URI != "internal/client/client.go" {
+		t.Errorf("drift locations = %+v, want evidence path", d.Locations)
+	}
+
+	v := results[1]
+	if v.RuleID != "VIOLATION" || v.Level != "note" {
+		t.Errorf("violation result = {%q, %q}, want {VIOLATION, note}", v.RuleID, v.Level)
+	}
+	if len(v.Locations) != 2 {
+		t.Fatalf("violation locations = %d, want 2 (evidence + spec reference)", len(v.Locations))
+	}
+	ref := v.Locations[1].PhysicalLocation
+	if ref.ArtifactLocation.URI != "SPEC.md" || ref.Region == nil || ref.Region.StartLine != 12 || ref.Region.EndLine != 14 {
+		t.Errorf("spec reference location = %+v, want SPEC.md lines 12-14", ref)
+	}
+}
+
+func TestRenderSARIF_Suppressed(t *testing.T) {
+	report := sampleReport()
+	report.Drift[0].Suppressed = true
+
+	b, err := RenderSARIF(report)
+	if err != nil {
+		t.Fatalf("RenderSARIF error: %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(b, &log); err != nil {
+		t.Fatalf("json.Unmarshal error: %v", err)
+	}
+	results := log.Runs[0].Results
+	if len(results[0].Suppressions) != 1 || results[0].Suppressions[0].Kind != "external" {
+		t.Errorf("suppressed drift suppressions = %+v, want one external", results[0].Suppressions)
+	}
+	if results[1].Suppressions != nil {
+		t.Errorf("violation suppressions = %+v, want none", results[1].Suppressions)
+	}
+}
+
+func TestRenderSARIF_IncludeGaps(t *testing.T) {
+	report := sampleReport()
+	report.Coverage.Spec[1].Status = schema.StatusNotImplemented
+
+	without, err := RenderSARIF(report)
+	if err != nil {
+		t.Fatalf("RenderSARIF error: %v", err)
+	}
+	with, err := RenderSARIFWithOptions(report, SARIFOptions{IncludeGaps: true})
+	if err != nil {
+		t.Fatalf("RenderSARIFWithOptions error: %v", err)
+	}
+
+	var a, b sarifLog
+	if err := json.Unmarshal(without, &a); err != nil {
+		t.Fatalf("json.Unmarshal error: %v", err)
+	}
+	if err := json.Unmarshal(with, &b); err != nil {
+		t.Fatalf("json.Unmarshal error: %v", err)
+	}
+	if got := len(b.Runs[0].Results) - len(a.Runs[0].Results); got != 1 {
+		t.Fatalf("gap results = %d, want 1", got)
+	}
+	gap := b.Runs[0].Results[len(b.Runs[0].Results)-1]
+	if gap.RuleID != "SPEC" {
+		t.Errorf("gap ruleId = %q, want SPEC", gap.RuleID)
+	}
+}
+
+func TestRenderSARIF_MultipleSpecFiles(t *testing.T) {
+	report := sampleReport()
+	report.Input.SpecFiles = []string{"SPEC.md", "docs/api.md"}
+	report.Coverage.Spec[1].Status = schema.StatusNotImplemented
+	report.Coverage.Spec[1].SpecReference = schema.Reference{LineStart: 4, LineEnd: 6, Source: "docs/api.md"}
+	report.Violations[0].SpecReference = schema.Reference{LineStart: 9, LineEnd: 9, Source: "docs/api.md"}
+
+	b, err := RenderSARIFWithOptions(report, SARIFOptions{IncludeGaps: true})
+	if err != nil {
+		t.Fatalf("RenderSARIFWithOptions error: %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(b, &log); err != nil {
+		t.Fatalf("json.Unmarshal error: %v", err)
+	}
+	var uris []string
+	for _, r := range log.Runs[0].Results {
+		for _, loc := range r.Locations {
+			if loc.PhysicalLocation.Region != nil {
+				uris = append(uris, r.RuleID+" "+loc.PhysicalLocation.ArtifactLocation.URI)
+			}
+		}
+	}
+	for _, want := range []string{"VIOLATION docs/api.md", "SPEC docs/api.md"} {
+		if !slices.Contains(uris, want) {
+			t.Errorf("locations %v missing %q", uris, want)
+		}
+	}
+}
+
+func TestRenderSARIF_NilReport(t *testing.T) {
+	if _, err := RenderSARIF(nil); err == nil {
+		t.Fatal("RenderSARIF(nil) expected error, got nil")
+	}
+}
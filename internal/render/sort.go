@@ -0,0 +1,54 @@
+package render
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+// SortReport puts the report's findings and coverage in a canonical order so
+// that reports on the same input diff cleanly whatever order the model used.
+// Drift findings and violations are sorted by severity, most severe first,
+// then by ID, then by description; coverage entries are sorted by ID. The
+// sort is stable and changes nothing but the order.
+func SortReport(report *schema.Report) {
+	if report == nil {
+		return
+	}
+	slices.SortStableFunc(report.Drift, func(a, b schema.DriftFinding) int {
+		return compareFindings(a.Severity, b.Severity, a.ID, b.ID, a.Description, b.Description)
+	})
+	slices.SortStableFunc(report.Violations, func(a, b schema.Violation) int {
+		return compareFindings(a.Severity, b.Severity, a.ID, b.ID, a.Description, b.Description)
+	})
+	slices.SortStableFunc(report.Coverage.Spec, func(a, b schema.SpecCoverageEntry) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	slices.SortStableFunc(report.Coverage.Plan, func(a, b schema.PlanCoverageEntry) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+}
+
+// compareFindings orders two findings by severity descending, then ID, then
+// description.
+func compareFindings(sevA, sevB schema.Severity, idA, idB, descA, descB string) int {
+	return cmp.Or(
+		cmp.Compare(severityRank(sevB), severityRank(sevA)),
+		cmp.Compare(idA, idB),
+		cmp.Compare(descA, descB),
+	)
+}
+
+// severityRank orders severities from INFO up; unknown severities sort last.
+func severityRank(s schema.Severity) int {
+	switch s {
+	case schema.SeverityCritical:
+		return 3
+	case schema.SeverityWarn:
+		return 2
+	case schema.SeverityInfo:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,51 @@
+package render
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+func TestSortReport(t *testing.T) {
+	report := &schema.Report{
+		Coverage: schema.Coverage{
+			Spec: []schema.SpecCoverageEntry{{ID: "SPEC-003"}, {ID: "SPEC-001"}, {ID: "SPEC-002"}},
+			Plan: []schema.PlanCoverageEntry{{ID: "PLAN-002"}, {ID: "PLAN-001"}},
+		},
+		Drift: []schema.DriftFinding{
+			{ID: "DRIFT-002", Severity: schema.SeverityInfo},
+			{ID: "DRIFT-003", Severity: schema.SeverityWarn, Description: "b"},
+			{ID: "DRIFT-001", Severity: schema.SeverityWarn},
+			{ID: "DRIFT-003", Severity: schema.SeverityWarn, Description: "a"},
+			{ID: "DRIFT-004", Severity: schema.SeverityCritical},
+		},
+		Violations: []schema.Violation{
+			{ID: "VIOLATION-001", Severity: schema.SeverityWarn},
+			{ID: "VIOLATION-002", Severity: schema.SeverityCritical},
+		},
+	}
+	SortReport(report)
+
+	var drift []string
+	for _, d := range report.Drift {
+		drift = append(drift, d.ID+d.Description)
+	}
+	want := []string{"DRIFT-004", "DRIFT-001", "DRIFT-003a", "DRIFT-003b", "DRIFT-002"}
+	if !slices.Equal(drift, want) {
+		t.Errorf("drift order = %v, want %v", drift, want)
+	}
+	if report.Violations[0].ID != "VIOLATION-002" {
+		t.Errorf("violations[0] = %s, want the CRITICAL violation first", report.Violations[0].ID)
+	}
+	for i, id := range []string{"SPEC-001", "SPEC-002", "SPEC-003"} {
+		if report.Coverage.Spec[i].ID != id {
+			t.Errorf("coverage.spec[%d] = %s, want %s", i, report.Coverage.Spec[i].ID, id)
+		}
+	}
+	if report.Coverage.Plan[0].ID != "PLAN-001" {
+		t.Errorf("coverage.plan[0] = %s, want PLAN-001", report.Coverage.Plan[0].ID)
+	}
+
+	SortReport(nil) // must not panic
+}
@@ -0,0 +1,58 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dshills/realitycheck/internal/specdiff"
+)
+
+// RenderSpecDiffJSON produces a pretty-printed JSON representation of a spec
+// comparison.
+func RenderSpecDiffJSON(res specdiff.Result) ([]byte, error) {
+	b, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("render: json marshal spec diff: %w", err)
+	}
+	return b, nil
+}
+
+// RenderSpecDiffMarkdown produces a Markdown summary of a spec comparison.
+// Added items come first, since they are the ones likely to need new
+// implementation.
+func RenderSpecDiffMarkdown(res specdiff.Result) string {
+	var sb strings.Builder
+
+	sb.WriteString("## RealityCheck Spec Diff\n\n")
+	fmt.Fprintf(&sb, "**Added:** %d · **Changed:** %d · **Removed:** %d · **Unchanged:** %d\n\n",
+		len(res.Added), len(res.Changed), len(res.Removed), res.Unchanged)
+
+	if len(res.Added) > 0 {
+		sb.WriteString("## Added (likely needs implementation)\n\n")
+		for _, it := range res.Added {
+			fmt.Fprintf(&sb, "- **%s** (line %d) %s\n", it.ID, it.LineStart, mdInline(it.Text))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(res.Changed) > 0 {
+		sb.WriteString("## Changed\n\n")
+		sb.WriteString("| Old | New | Similarity | Text |\n")
+		sb.WriteString("|---|---|---|---|\n")
+		for _, c := range res.Changed {
+			fmt.Fprintf(&sb, "| %s | %s | %.2f | %s |\n", c.Old.ID, c.New.ID, c.Similarity, mdEscape(mdInline(c.New.Text)))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(res.Removed) > 0 {
+		sb.WriteString("## Removed\n\n")
+		for _, it := range res.Removed {
+			fmt.Fprintf(&sb, "- **%s** (line %d) %s\n", it.ID, it.LineStart, mdInline(it.Text))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,56 @@
+package render
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/specdiff"
+)
+
+func sampleSpecDiff() specdiff.Result {
+	return specdiff.Result{
+		Added: []specdiff.Item{{ID: "SPEC-003", LineStart: 7, LineEnd: 7, Text: "Admins can export\naudit logs"}},
+		Changed: []specdiff.Change{{
+			Old:        specdiff.Item{ID: "SPEC-001", Text: "Sessions expire after 30 minutes"},
+			New:        specdiff.Item{ID: "SPEC-001", Text: "Sessions expire after 60 minutes | idle"},
+			Similarity: 0.67,
+		}},
+		Removed:   []specdiff.Item{},
+		Unchanged: 4,
+	}
+}
+
+func TestRenderSpecDiffMarkdown(t *testing.T) {
+	md := RenderSpecDiffMarkdown(sampleSpecDiff())
+	for _, want := range []string{
+		"**Added:** 1 · **Changed:** 1 · **Removed:** 0 · **Unchanged:** 4",
+		"## Added (likely needs implementation)",
+		"- **SPEC-003** (line 7) Admins can export audit logs",
+		"| SPEC-001 | SPEC-001 | 0.67 | Sessions expire after 60 minutes \\| idle |",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("spec diff markdown missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "## Removed") {
+		t.Error("empty sections should be omitted")
+	}
+}
+
+func TestRenderSpecDiffJSON_RoundTrip(t *testing.T) {
+	b, err := RenderSpecDiffJSON(sampleSpecDiff())
+	if err != nil {
+		t.Fatalf("RenderSpecDiffJSON: %v", err)
+	}
+	var got specdiff.Result
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(got.Added) != 1 || got.Added[0].ID != "SPEC-003" || got.Unchanged != 4 {
+		t.Errorf("round trip = %+v", got)
+	}
+	if !strings.Contains(string(b), `"removed": []`) {
+		t.Errorf("empty removed list should render as []:\n%s", b)
+	}
+}
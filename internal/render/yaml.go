@@ -0,0 +1,28 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/dshills/realitycheck/internal/schema"
+	"gopkg.in/yaml.v3"
+)
+
+// RenderYAML produces a YAML representation of the report. Field names match
+// the JSON tags and fields appear in struct order, so output is stable across
+// runs. The output round-trips through yaml.Unmarshal back to an equal Report.
+func RenderYAML(report *schema.Report) ([]byte, error) {
+	if report == nil {
+		return nil, fmt.Errorf("render: nil report")
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(report); err != nil {
+		return nil, fmt.Errorf("render: yaml marshal: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("render: yaml marshal: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,55 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/schema"
+	"gopkg.in/yaml.v3"
+)
+
+func TestRenderYAML_RoundTrip(t *testing.T) {
+	report := sampleReport()
+	b, err := RenderYAML(report)
+	if err != nil {
+		t.Fatalf("RenderYAML error: %v", err)
+	}
+	var got schema.Report
+	if err := yaml.Unmarshal(b, &got); err != nil {
+		t.Fatalf("yaml.Unmarshal error: %v", err)
+	}
+	// nil slices decode as empty ones, so compare the re-rendered output.
+	again, err := RenderYAML(&got)
+	if err != nil {
+		t.Fatalf("RenderYAML of decoded report: %v", err)
+	}
+	if string(again) != string(b) {
+		t.Errorf("round-trip mismatch:\ngot:\n%s\nwant:\n%s", again, b)
+	}
+	if got.Summary.Verdict != report.Summary.Verdict || got.Drift[0].WhyUnjustified != report.Drift[0].WhyUnjustified {
+		t.Errorf("decoded report = %+v", got)
+	}
+}
+
+func TestRenderYAML_FieldNames(t *testing.T) {
+	b, err := RenderYAML(sampleReport())
+	if err != nil {
+		t.Fatalf("RenderYAML error: %v", err)
+	}
+	out := string(b)
+	for _, want := range []string{"spec_file: SPEC.md", "why_unjustified:", "critical_count: 0"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("yaml missing %q", want)
+		}
+	}
+	// Fields keep struct order, matching the JSON output.
+	if strings.Index(out, "tool:") > strings.Index(out, "summary:") {
+		t.Error("yaml fields out of struct order")
+	}
+}
+
+func TestRenderYAML_NilReport(t *testing.T) {
+	if _, err := RenderYAML(nil); err == nil {
+		t.Error("expected error for nil report")
+	}
+}
@@ -0,0 +1,177 @@
+// Package reportdiff compares two RealityCheck reports to find regressions.
+//
+// Finding IDs (DRIFT-001, VIOLATION-001) are regenerated on every run, so
+// drift findings and violations are matched across reports by description
+// similarity plus evidence path overlap rather than by ID. Spec and plan
+// coverage IDs are derived from the spec and plan files and are matched by ID.
+package reportdiff
+
+import (
+	"sort"
+
+	"github.com/dshills/realitycheck/internal/schema"
+	"github.com/dshills/realitycheck/internal/textsim"
+	"github.com/dshills/realitycheck/internal/verdict"
+)
+
+// MatchThreshold is the minimum similarity for two findings to be treated as
+// the same finding across runs.
+const MatchThreshold = 0.5
+
+// Similarity weights: description wording dominates, evidence paths break ties
+// between similarly worded findings in different files.
+const (
+	descriptionWeight = 0.6
+	evidenceWeight    = 0.4
+)
+
+// CoverageChange records a coverage entry whose status got worse.
+type CoverageChange struct {
+	Kind string                `json:"kind"` // "spec" or "plan"
+	ID   string                `json:"id"`
+	From schema.CoverageStatus `json:"from"`
+	To   schema.CoverageStatus `json:"to"`
+}
+
+// Result is the comparison of a new report against an old one.
+type Result struct {
+	OldVerdict          schema.Verdict        `json:"old_verdict"`
+	NewVerdict          schema.Verdict        `json:"new_verdict"`
+	OldScore            int                   `json:"old_score"`
+	NewScore            int                   `json:"new_score"`
+	ScoreDelta          int                   `json:"score_delta"`
+	NewDrift            []schema.DriftFinding `json:"new_drift"`
+	NewViolations       []schema.Violation    `json:"new_violations"`
+	CoverageRegressions []CoverageChange      `json:"coverage_regressions"`
+}
+
+// Regressed reports whether the new report is strictly worse than the old
+// one: a worse verdict, a lower score, a newly introduced drift finding or
+// violation, or a coverage entry that is no longer IMPLEMENTED.
+func (r Result) Regressed() bool {
+	return verdict.VerdictOrdinal(r.NewVerdict) > verdict.VerdictOrdinal(r.OldVerdict) ||
+		r.ScoreDelta < 0 ||
+		len(r.NewDrift) > 0 ||
+		len(r.NewViolations) > 0 ||
+		len(r.CoverageRegressions) > 0
+}
+
+// Compare diffs newReport against oldReport.
+func Compare(oldReport, newReport schema.Report) Result {
+	res := Result{
+		OldVerdict:          oldReport.Summary.Verdict,
+		NewVerdict:          newReport.Summary.Verdict,
+		OldScore:            oldReport.Summary.Score,
+		NewScore:            newReport.Summary.Score,
+		ScoreDelta:          newReport.Summary.Score - oldReport.Summary.Score,
+		NewDrift:            []schema.DriftFinding{},
+		NewViolations:       []schema.Violation{},
+		CoverageRegressions: []CoverageChange{},
+	}
+
+	oldDrift := make([]finding, len(oldReport.Drift))
+	for i, d := range oldReport.Drift {
+		oldDrift[i] = newFinding(d.Description, d.Evidence)
+	}
+	newDrift := make([]finding, len(newReport.Drift))
+	for i, d := range newReport.Drift {
+		newDrift[i] = newFinding(d.Description, d.Evidence)
+	}
+	for _, i := range unmatched(oldDrift, newDrift) {
+		res.NewDrift = append(res.NewDrift, newReport.Drift[i])
+	}
+
+	oldViol := make([]finding, len(oldReport.Violations))
+	for i, v := range oldReport.Violations {
+		oldViol[i] = newFinding(v.Description, v.Evidence)
+	}
+	newViol := make([]finding, len(newReport.Violations))
+	for i, v := range newReport.Violations {
+		newViol[i] = newFinding(v.Description, v.Evidence)
+	}
+	for _, i := range unmatched(oldViol, newViol) {
+		res.NewViolations = append(res.NewViolations, newReport.Violations[i])
+	}
+
+	oldSpec := make(map[string]schema.CoverageStatus, len(oldReport.Coverage.Spec))
+	for _, e := range oldReport.Coverage.Spec {
+		oldSpec[e.ID] = e.Status
+	}
+	for _, e := range newReport.Coverage.Spec {
+		if from, ok := oldSpec[e.ID]; ok && from == schema.StatusImplemented && e.Status != schema.StatusImplemented {
+			res.CoverageRegressions = append(res.CoverageRegressions, CoverageChange{Kind: "spec", ID: e.ID, From: from, To: e.Status})
+		}
+	}
+	oldPlan := make(map[string]schema.CoverageStatus, len(oldReport.Coverage.Plan))
+	for _, e := range oldReport.Coverage.Plan {
+		oldPlan[e.ID] = e.Status
+	}
+	for _, e := range newReport.Coverage.Plan {
+		if from, ok := oldPlan[e.ID]; ok && from == schema.StatusImplemented && e.Status != schema.StatusImplemented {
+			res.CoverageRegressions = append(res.CoverageRegressions, CoverageChange{Kind: "plan", ID: e.ID, From: from, To: e.Status})
+		}
+	}
+
+	return res
+}
+
+// finding is the matching key for a drift finding or violation.
+type finding struct {
+	words map[string]bool
+	paths map[string]bool
+}
+
+func newFinding(description string, evidence []schema.Evidence) finding {
+	f := finding{words: textsim.Set(textsim.Words(description)), paths: make(map[string]bool)}
+	for _, ev := range evidence {
+		f.paths[ev.Path] = true
+	}
+	return f
+}
+
+// similarity scores two findings in [0, 1]. When neither finding cites
+// evidence, the score is description similarity alone.
+func similarity(a, b finding) float64 {
+	desc := textsim.Jaccard(a.words, b.words)
+	if len(a.paths) == 0 && len(b.paths) == 0 {
+		return desc
+	}
+	return descriptionWeight*desc + evidenceWeight*textsim.Jaccard(a.paths, b.paths)
+}
+
+// unmatched pairs old and new findings greedily, best match first, and
+// returns the indexes of new findings with no old counterpart at or above
+// MatchThreshold, in their original order.
+func unmatched(old, cur []finding) []int {
+	type pair struct {
+		o, n  int
+		score float64
+	}
+	var pairs []pair
+	for n := range cur {
+		for o := range old {
+			if s := similarity(old[o], cur[n]); s >= MatchThreshold {
+				pairs = append(pairs, pair{o, n, s})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].score > pairs[j].score })
+
+	usedOld := make(map[int]bool)
+	matchedNew := make(map[int]bool)
+	for _, p := range pairs {
+		if usedOld[p.o] || matchedNew[p.n] {
+			continue
+		}
+		usedOld[p.o] = true
+		matchedNew[p.n] = true
+	}
+
+	var out []int
+	for n := range cur {
+		if !matchedNew[n] {
+			out = append(out, n)
+		}
+	}
+	return out
+}
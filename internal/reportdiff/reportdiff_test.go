@@ -0,0 +1,150 @@
+package reportdiff
+
+import (
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+func baseReport() schema.Report {
+	return schema.Report{
+		Tool:    "realitycheck",
+		Summary: schema.Summary{Verdict: schema.VerdictDriftDetected, Score: 93},
+		Coverage: schema.Coverage{
+			Spec: []schema.SpecCoverageEntry{
+				{ID: "SPEC-001", Status: schema.StatusImplemented},
+				{ID: "SPEC-002", Status: schema.StatusPartial},
+			},
+			Plan: []schema.PlanCoverageEntry{
+				{ID: "PLAN-001", Status: schema.StatusImplemented},
+			},
+		},
+		Drift: []schema.DriftFinding{{
+			ID:          "DRIFT-001",
+			Severity:    schema.SeverityWarn,
+			Description: "Undocumented retry loop in HTTP client",
+			Evidence:    []schema.Evidence{{Path: "internal/client/client.go"}},
+		}},
+		Violations: []schema.Violation{},
+	}
+}
+
+func TestCompare_Identical(t *testing.T) {
+	res := Compare(baseReport(), baseReport())
+	if res.Regressed() {
+		t.Errorf("identical reports should not regress: %+v", res)
+	}
+	if res.ScoreDelta != 0 {
+		t.Errorf("ScoreDelta = %d, want 0", res.ScoreDelta)
+	}
+}
+
+func TestCompare_RenumberedFindingMatches(t *testing.T) {
+	newR := baseReport()
+	// Same finding, new ID and slightly different wording.
+	newR.Drift[0].ID = "DRIFT-007"
+	newR.Drift[0].Description = "Undocumented retry loop in the HTTP client"
+	res := Compare(baseReport(), newR)
+	if len(res.NewDrift) != 0 {
+		t.Errorf("renumbered finding should match the old one, got new drift %+v", res.NewDrift)
+	}
+}
+
+func TestCompare_NewFindings(t *testing.T) {
+	newR := baseReport()
+	newR.Drift = append(newR.Drift, schema.DriftFinding{
+		ID:          "DRIFT-002",
+		Severity:    schema.SeverityCritical,
+		Description: "Writes audit rows to an external database",
+		Evidence:    []schema.Evidence{{Path: "internal/audit/audit.go"}},
+	})
+	newR.Violations = []schema.Violation{{
+		ID:          "VIOLATION-001",
+		Severity:    schema.SeverityCritical,
+		Description: "Passwords stored in plain text",
+		Evidence:    []schema.Evidence{{Path: "internal/auth/store.go"}},
+	}}
+	newR.Summary.Score = 53
+	res := Compare(baseReport(), newR)
+	if len(res.NewDrift) != 1 || res.NewDrift[0].ID != "DRIFT-002" {
+		t.Errorf("NewDrift = %+v, want only DRIFT-002", res.NewDrift)
+	}
+	if len(res.NewViolations) != 1 || res.NewViolations[0].ID != "VIOLATION-001" {
+		t.Errorf("NewViolations = %+v, want only VIOLATION-001", res.NewViolations)
+	}
+	if res.ScoreDelta != -40 {
+		t.Errorf("ScoreDelta = %d, want -40", res.ScoreDelta)
+	}
+	if !res.Regressed() {
+		t.Error("new findings should be a regression")
+	}
+}
+
+func TestCompare_SameWordingDifferentFile(t *testing.T) {
+	// Identical description but disjoint evidence: 0.6*1 + 0.4*0 = 0.6, still
+	// a match; a mostly different description in another file is not.
+	newR := baseReport()
+	newR.Drift[0].Evidence = []schema.Evidence{{Path: "internal/other/other.go"}}
+	if res := Compare(baseReport(), newR); len(res.NewDrift) != 0 {
+		t.Errorf("same wording should still match, got %+v", res.NewDrift)
+	}
+	newR.Drift[0].Description = "Undocumented cache layer"
+	if res := Compare(baseReport(), newR); len(res.NewDrift) != 1 {
+		t.Errorf("different wording in a different file should not match, got %+v", res.NewDrift)
+	}
+}
+
+func TestCompare_EachOldFindingMatchesOnce(t *testing.T) {
+	newR := baseReport()
+	dup := newR.Drift[0]
+	dup.ID = "DRIFT-002"
+	newR.Drift = append(newR.Drift, dup)
+	res := Compare(baseReport(), newR)
+	if len(res.NewDrift) != 1 || res.NewDrift[0].ID != "DRIFT-002" {
+		t.Errorf("second copy of a finding should be new, got %+v", res.NewDrift)
+	}
+}
+
+func TestCompare_CoverageRegressions(t *testing.T) {
+	newR := baseReport()
+	newR.Coverage.Spec[0].Status = schema.StatusPartial        // IMPLEMENTED → PARTIAL: regression
+	newR.Coverage.Spec[1].Status = schema.StatusNotImplemented // PARTIAL → NOT_IMPLEMENTED: not tracked
+	newR.Coverage.Plan[0].Status = schema.StatusUnclear        // IMPLEMENTED → UNCLEAR: regression
+	res := Compare(baseReport(), newR)
+	want := []CoverageChange{
+		{Kind: "spec", ID: "SPEC-001", From: schema.StatusImplemented, To: schema.StatusPartial},
+		{Kind: "plan", ID: "PLAN-001", From: schema.StatusImplemented, To: schema.StatusUnclear},
+	}
+	if len(res.CoverageRegressions) != len(want) {
+		t.Fatalf("CoverageRegressions = %+v, want %+v", res.CoverageRegressions, want)
+	}
+	for i := range want {
+		if res.CoverageRegressions[i] != want[i] {
+			t.Errorf("CoverageRegressions[%d] = %+v, want %+v", i, res.CoverageRegressions[i], want[i])
+		}
+	}
+	if !res.Regressed() {
+		t.Error("coverage regressions should be a regression")
+	}
+}
+
+func TestCompare_Improvement(t *testing.T) {
+	newR := baseReport()
+	newR.Drift = nil
+	newR.Summary = schema.Summary{Verdict: schema.VerdictPartiallyAligned, Score: 100}
+	res := Compare(baseReport(), newR)
+	if res.Regressed() {
+		t.Errorf("resolved finding should not be a regression: %+v", res)
+	}
+	if res.ScoreDelta != 7 {
+		t.Errorf("ScoreDelta = %d, want 7", res.ScoreDelta)
+	}
+}
+
+func TestCompare_WorseVerdictAlone(t *testing.T) {
+	newR := baseReport()
+	newR.Summary.Verdict = schema.VerdictViolation
+	if !Compare(baseReport(), newR).Regressed() {
+		t.Error("a worse verdict should be a regression")
+	}
+}
@@ -0,0 +1,255 @@
+// Package rules applies deterministic, user-configured checks to an analyzed
+// report. Rules run after the LLM stage and add violations that do not depend
+// on the model noticing a problem.
+package rules
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/dshills/realitycheck/internal/codeindex"
+	"github.com/dshills/realitycheck/internal/drift"
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+// Rule is a deterministic check over an analyzed report and the code index.
+// Apply returns new violations; IDs are assigned by ApplyAll.
+type Rule interface {
+	Apply(report *schema.PartialReport, index codeindex.Index) []schema.Violation
+}
+
+// Built-in rule types for Config.Type.
+const (
+	// TypeEvidencePath forbids spec and plan items with one of Statuses from
+	// citing evidence under Paths.
+	TypeEvidencePath = "evidence-path"
+	// TypeSymbolPattern forbids indexed symbols matching Pattern, optionally
+	// only under Paths.
+	TypeSymbolPattern = "symbol-pattern"
+)
+
+// Config is the declarative form of a rule, as read from the config file.
+type Config struct {
+	// Name identifies the rule in violation descriptions.
+	Name string `yaml:"name"`
+	// Type is TypeEvidencePath or TypeSymbolPattern.
+	Type string `yaml:"type"`
+	// Paths are globs relative to the code root (see codeindex.CompileGlob).
+	// A path also matches when one of its parent directories matches, so
+	// "legacy" covers everything under legacy/.
+	Paths []string `yaml:"paths"`
+	// Statuses lists the coverage statuses an evidence-path rule checks.
+	// Defaults to IMPLEMENTED.
+	Statuses []string `yaml:"statuses"`
+	// Pattern is the regular expression a symbol-pattern rule matches
+	// against symbol names.
+	Pattern string `yaml:"pattern"`
+	// ExportedOnly limits a symbol-pattern rule to exported symbols.
+	ExportedOnly bool `yaml:"exported_only"`
+	// Severity is INFO, WARN (the default), or CRITICAL.
+	Severity string `yaml:"severity"`
+	// Blocking is copied to every violation the rule produces.
+	Blocking bool `yaml:"blocking"`
+	// Message, if set, is used as the violation's impact.
+	Message string `yaml:"message"`
+}
+
+// Compile validates cfgs and builds the corresponding rules.
+func Compile(cfgs []Config) ([]Rule, error) {
+	out := make([]Rule, 0, len(cfgs))
+	for i, c := range cfgs {
+		r, err := compile(c)
+		if err != nil {
+			return nil, fmt.Errorf("rules: rule %d (%q): %w", i+1, c.Name, err)
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+func compile(c Config) (Rule, error) {
+	if c.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	b := base{name: c.Name, blocking: c.Blocking, message: c.Message}
+	switch sev := schema.Severity(strings.ToUpper(c.Severity)); sev {
+	case "":
+		b.severity = schema.SeverityWarn
+	case schema.SeverityInfo, schema.SeverityWarn, schema.SeverityCritical:
+		b.severity = sev
+	default:
+		return nil, fmt.Errorf("severity %q is not valid (INFO|WARN|CRITICAL)", c.Severity)
+	}
+	paths, err := compileGlobs(c.Paths)
+	if err != nil {
+		return nil, err
+	}
+
+	switch c.Type {
+	case TypeEvidencePath:
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("%s rule needs at least one path", c.Type)
+		}
+		if c.Pattern != "" || c.ExportedOnly {
+			return nil, fmt.Errorf("pattern and exported_only apply only to %s rules", TypeSymbolPattern)
+		}
+		statuses := map[schema.CoverageStatus]bool{}
+		for _, s := range c.Statuses {
+			st := schema.CoverageStatus(strings.ToUpper(s))
+			switch st {
+			case schema.StatusImplemented, schema.StatusPartial, schema.StatusNotImplemented, schema.StatusUnclear:
+				statuses[st] = true
+			default:
+				return nil, fmt.Errorf("status %q is not valid", s)
+			}
+		}
+		if len(statuses) == 0 {
+			statuses[schema.StatusImplemented] = true
+		}
+		return &evidencePathRule{base: b, paths: paths, statuses: statuses}, nil
+	case TypeSymbolPattern:
+		if c.Pattern == "" {
+			return nil, fmt.Errorf("%s rule needs a pattern", c.Type)
+		}
+		if len(c.Statuses) > 0 {
+			return nil, fmt.Errorf("statuses apply only to %s rules", TypeEvidencePath)
+		}
+		re, err := regexp.Compile(c.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("pattern: %w", err)
+		}
+		return &symbolPatternRule{base: b, paths: paths, pattern: re, exportedOnly: c.ExportedOnly}, nil
+	case "":
+		return nil, fmt.Errorf("type is required (%s|%s)", TypeEvidencePath, TypeSymbolPattern)
+	default:
+		return nil, fmt.Errorf("unknown type %q (%s|%s)", c.Type, TypeEvidencePath, TypeSymbolPattern)
+	}
+}
+
+// ApplyAll runs each rule against report and appends the violations they
+// return, numbered by drift.NextViolationID. It returns the number of
+// violations added.
+func ApplyAll(rules []Rule, report *schema.PartialReport, index codeindex.Index) int {
+	nextID := drift.NextViolationID(report.Violations)
+	added := 0
+	for _, r := range rules {
+		for _, v := range r.Apply(report, index) {
+			v.ID = nextID()
+			report.Violations = append(report.Violations, v)
+			added++
+		}
+	}
+	return added
+}
+
+// base holds the settings shared by every built-in rule.
+type base struct {
+	name     string
+	severity schema.Severity
+	blocking bool
+	message  string
+}
+
+func (b base) violation(description string, ref schema.Reference, evidence []schema.Evidence) schema.Violation {
+	return schema.Violation{
+		Severity:      b.severity,
+		Description:   fmt.Sprintf("Rule %q: %s", b.name, description),
+		SpecReference: ref,
+		Evidence:      evidence,
+		Impact:        b.message,
+		Blocking:      b.blocking,
+	}
+}
+
+// evidencePathRule reports each coverage entry with a checked status that
+// cites evidence under a forbidden path.
+type evidencePathRule struct {
+	base
+	paths    []*regexp.Regexp
+	statuses map[schema.CoverageStatus]bool
+}
+
+func (r *evidencePathRule) Apply(report *schema.PartialReport, _ codeindex.Index) []schema.Violation {
+	var out []schema.Violation
+	check := func(id string, status schema.CoverageStatus, ref schema.Reference, evidence []schema.Evidence) {
+		if !r.statuses[status] {
+			return
+		}
+		var bad []schema.Evidence
+		var paths []string
+		for _, ev := range evidence {
+			if matchPath(r.paths, ev.Path) {
+				bad = append(bad, ev)
+				paths = append(paths, ev.Path)
+			}
+		}
+		if len(bad) > 0 {
+			desc := fmt.Sprintf("%s is %s with evidence from a forbidden path: %s", id, status, strings.Join(paths, ", "))
+			out = append(out, r.violation(desc, ref, bad))
+		}
+	}
+	for _, e := range report.Coverage.Spec {
+		check(e.ID, e.Status, e.SpecReference, e.Evidence)
+	}
+	for _, e := range report.Coverage.Plan {
+		check(e.ID, e.Status, e.PlanReference, e.Evidence)
+	}
+	return out
+}
+
+// symbolPatternRule reports every indexed symbol matching its pattern as
+// one violation.
+type symbolPatternRule struct {
+	base
+	paths        []*regexp.Regexp
+	pattern      *regexp.Regexp
+	exportedOnly bool
+}
+
+func (r *symbolPatternRule) Apply(_ *schema.PartialReport, index codeindex.Index) []schema.Violation {
+	var evidence []schema.Evidence
+	for _, s := range index.Symbols {
+		if r.exportedOnly && !s.Exported {
+			continue
+		}
+		if len(r.paths) > 0 && !matchPath(r.paths, s.Path) {
+			continue
+		}
+		if r.pattern.MatchString(s.Symbol) {
+			evidence = append(evidence, schema.Evidence{Path: s.Path, Symbol: s.Symbol, Confidence: schema.ConfidenceHigh})
+		}
+	}
+	if len(evidence) == 0 {
+		return nil
+	}
+	desc := fmt.Sprintf("%d symbol(s) match the forbidden pattern %s", len(evidence), r.pattern)
+	return []schema.Violation{r.violation(desc, schema.Reference{}, evidence)}
+}
+
+func compileGlobs(globs []string) ([]*regexp.Regexp, error) {
+	out := make([]*regexp.Regexp, 0, len(globs))
+	for _, g := range globs {
+		re, err := codeindex.CompileGlob(strings.TrimSuffix(g, "/"))
+		if err != nil {
+			return nil, fmt.Errorf("path %q: %w", g, err)
+		}
+		out = append(out, re)
+	}
+	return out, nil
+}
+
+// matchPath reports whether p, or one of its parent directories, matches
+// any of globs.
+func matchPath(globs []*regexp.Regexp, p string) bool {
+	for p != "." && p != "/" && p != "" {
+		for _, re := range globs {
+			if re.MatchString(p) {
+				return true
+			}
+		}
+		p = path.Dir(p)
+	}
+	return false
+}
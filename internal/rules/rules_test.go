@@ -0,0 +1,161 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/codeindex"
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+func sampleReport() *schema.PartialReport {
+	return &schema.PartialReport{
+		Coverage: schema.Coverage{
+			Spec: []schema.SpecCoverageEntry{
+				{ID: "SPEC-001", Status: schema.StatusImplemented, SpecReference: schema.Reference{LineStart: 3, LineEnd: 3},
+					Evidence: []schema.Evidence{{Path: "store/store.go"}, {Path: "legacy/old/store.go", Symbol: "Get"}}},
+				{ID: "SPEC-002", Status: schema.StatusPartial,
+					Evidence: []schema.Evidence{{Path: "legacy/cache.go"}}},
+				{ID: "SPEC-003", Status: schema.StatusImplemented,
+					Evidence: []schema.Evidence{{Path: "store/cache.go"}}},
+			},
+			Plan: []schema.PlanCoverageEntry{
+				{ID: "PLAN-001", Status: schema.StatusImplemented, Evidence: []schema.Evidence{{Path: "legacy/x.go"}}},
+			},
+		},
+		Violations: []schema.Violation{{ID: "VIOLATION-002", Severity: schema.SeverityInfo}},
+	}
+}
+
+func sampleIndex() codeindex.Index {
+	return codeindex.Index{
+		Symbols: []codeindex.SymbolEntry{
+			{Path: "store/store.go", Symbol: "Get", Exported: true},
+			{Path: "store/debug.go", Symbol: "DebugDump", Exported: true},
+			{Path: "store/debug.go", Symbol: "debugTrace"},
+			{Path: "cmd/tool/main.go", Symbol: "DebugFlags", Exported: true},
+		},
+	}
+}
+
+func TestEvidencePathRule(t *testing.T) {
+	rs, err := Compile([]Config{{Name: "no-legacy", Type: TypeEvidencePath, Paths: []string{"legacy"}, Severity: "critical", Blocking: true, Message: "legacy code is frozen"}})
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	got := rs[0].Apply(sampleReport(), codeindex.Index{})
+	if len(got) != 2 {
+		t.Fatalf("violations = %+v, want SPEC-001 and PLAN-001", got)
+	}
+	v := got[0]
+	if !strings.Contains(v.Description, `"no-legacy"`) || !strings.Contains(v.Description, "SPEC-001") {
+		t.Errorf("description = %q", v.Description)
+	}
+	if v.Severity != schema.SeverityCritical || !v.Blocking || v.Impact != "legacy code is frozen" {
+		t.Errorf("violation settings not applied: %+v", v)
+	}
+	if len(v.Evidence) != 1 || v.Evidence[0].Path != "legacy/old/store.go" {
+		t.Errorf("evidence should be only the forbidden path: %+v", v.Evidence)
+	}
+	if v.SpecReference.LineStart != 3 {
+		t.Errorf("spec reference not copied: %+v", v.SpecReference)
+	}
+	if !strings.Contains(got[1].Description, "PLAN-001") {
+		t.Errorf("second violation = %q, want PLAN-001", got[1].Description)
+	}
+}
+
+func TestEvidencePathRule_Statuses(t *testing.T) {
+	rs, err := Compile([]Config{{Name: "r", Type: TypeEvidencePath, Paths: []string{"legacy/**/*.go"}, Statuses: []string{"partial"}}})
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	got := rs[0].Apply(sampleReport(), codeindex.Index{})
+	if len(got) != 1 || !strings.Contains(got[0].Description, "SPEC-002") {
+		t.Errorf("violations = %+v, want only SPEC-002", got)
+	}
+	if got[0].Severity != schema.SeverityWarn {
+		t.Errorf("default severity = %s, want WARN", got[0].Severity)
+	}
+}
+
+func TestSymbolPatternRule(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  Config
+		want []string
+	}{
+		{"all", Config{Pattern: "(?i)^debug"}, []string{"DebugDump", "debugTrace", "DebugFlags"}},
+		{"exported only", Config{Pattern: "(?i)^debug", ExportedOnly: true}, []string{"DebugDump", "DebugFlags"}},
+		{"scoped to paths", Config{Pattern: "^Debug", Paths: []string{"store/*"}}, []string{"DebugDump"}},
+		{"no match", Config{Pattern: "^Nope$"}, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.cfg.Name, c.cfg.Type = "no-debug", TypeSymbolPattern
+			rs, err := Compile([]Config{c.cfg})
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+			got := rs[0].Apply(&schema.PartialReport{}, sampleIndex())
+			if len(c.want) == 0 {
+				if len(got) != 0 {
+					t.Errorf("violations = %+v, want none", got)
+				}
+				return
+			}
+			if len(got) != 1 {
+				t.Fatalf("violations = %+v, want one", got)
+			}
+			var syms []string
+			for _, ev := range got[0].Evidence {
+				syms = append(syms, ev.Symbol)
+			}
+			if strings.Join(syms, ",") != strings.Join(c.want, ",") {
+				t.Errorf("symbols = %v, want %v", syms, c.want)
+			}
+		})
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	cases := map[string]Config{
+		"missing name":         {Type: TypeEvidencePath, Paths: []string{"x"}},
+		"missing type":         {Name: "r"},
+		"unknown type":         {Name: "r", Type: "magic"},
+		"bad severity":         {Name: "r", Type: TypeEvidencePath, Paths: []string{"x"}, Severity: "LOUD"},
+		"path rule no paths":   {Name: "r", Type: TypeEvidencePath},
+		"path rule pattern":    {Name: "r", Type: TypeEvidencePath, Paths: []string{"x"}, Pattern: "y"},
+		"bad status":           {Name: "r", Type: TypeEvidencePath, Paths: []string{"x"}, Statuses: []string{"DONE"}},
+		"symbol no pattern":    {Name: "r", Type: TypeSymbolPattern},
+		"symbol bad pattern":   {Name: "r", Type: TypeSymbolPattern, Pattern: "("},
+		"symbol with statuses": {Name: "r", Type: TypeSymbolPattern, Pattern: "x", Statuses: []string{"PARTIAL"}},
+	}
+	for name, c := range cases {
+		if _, err := Compile([]Config{c}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestApplyAll_NumbersAfterExisting(t *testing.T) {
+	rs, err := Compile([]Config{
+		{Name: "no-legacy", Type: TypeEvidencePath, Paths: []string{"legacy"}},
+		{Name: "no-debug", Type: TypeSymbolPattern, Pattern: "^Debug"},
+	})
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	report := sampleReport()
+	if n := ApplyAll(rs, report, sampleIndex()); n != 3 {
+		t.Fatalf("added = %d, want 3", n)
+	}
+	var ids []string
+	for _, v := range report.Violations {
+		ids = append(ids, v.ID)
+	}
+	want := "VIOLATION-002,VIOLATION-003,VIOLATION-004,VIOLATION-005"
+	if strings.Join(ids, ",") != want {
+		t.Errorf("ids = %v, want %s", ids, want)
+	}
+}
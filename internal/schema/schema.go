@@ -5,10 +5,10 @@ package schema
 type Verdict string
 
 const (
-	VerdictAligned         Verdict = "ALIGNED"
+	VerdictAligned          Verdict = "ALIGNED"
 	VerdictPartiallyAligned Verdict = "PARTIALLY_ALIGNED"
-	VerdictDriftDetected   Verdict = "DRIFT_DETECTED"
-	VerdictViolation       Verdict = "VIOLATION"
+	VerdictDriftDetected    Verdict = "DRIFT_DETECTED"
+	VerdictViolation        Verdict = "VIOLATION"
 )
 
 // CoverageStatus represents the implementation status of a spec or plan item.
@@ -39,107 +39,217 @@ const (
 	ConfidenceLow    Confidence = "LOW"
 )
 
-// Report is the top-level output document.
+// Report is the top-level output document. The yaml tags mirror the json
+// tags so YAML output uses the same field names.
 type Report struct {
-	Tool       string     `json:"tool"`
-	Version    string     `json:"version"`
-	Input      Input      `json:"input"`
-	Summary    Summary    `json:"summary"`
-	Coverage   Coverage   `json:"coverage"`
-	Drift      []DriftFinding `json:"drift"`
-	Violations []Violation    `json:"violations"`
-	Meta       Meta       `json:"meta"`
+	Tool       string         `json:"tool" yaml:"tool"`
+	Version    string         `json:"version" yaml:"version"`
+	Input      Input          `json:"input" yaml:"input"`
+	Summary    Summary        `json:"summary" yaml:"summary"`
+	Coverage   Coverage       `json:"coverage" yaml:"coverage"`
+	Drift      []DriftFinding `json:"drift" yaml:"drift"`
+	Violations []Violation    `json:"violations" yaml:"violations"`
+	// Traceability is present only when the run asked for it.
+	Traceability []TraceLink `json:"traceability,omitempty" yaml:"traceability,omitempty"`
+	// IndexStats describes the code index; absent in reports from older
+	// versions.
+	IndexStats *IndexStats `json:"index_stats,omitempty" yaml:"index_stats,omitempty"`
+	Meta       Meta        `json:"meta" yaml:"meta"`
+}
+
+// IndexStats counts what the code index found and reports whether the code
+// inventory sent to the model was truncated to fit the byte limit.
+type IndexStats struct {
+	Files           int            `json:"files" yaml:"files"`
+	FilesByLanguage map[string]int `json:"files_by_language" yaml:"files_by_language"`
+	Symbols         int            `json:"symbols" yaml:"symbols"`
+	Tests           int            `json:"tests" yaml:"tests"`
+	SummaryBytes    int            `json:"summary_bytes" yaml:"summary_bytes"`
+	Truncated       bool           `json:"truncated" yaml:"truncated"`
 }
 
 // Input records the parameters used for this run.
+// SpecFile and PlanFile hold the first file given; SpecFiles and PlanFiles
+// list every file when several were merged into one run.
 type Input struct {
-	SpecFile  string `json:"spec_file"`
-	PlanFile  string `json:"plan_file"`
-	CodeRoot  string `json:"code_root"`
-	Profile   string `json:"profile"`
-	Strict    bool   `json:"strict"`
+	SpecFile        string   `json:"spec_file" yaml:"spec_file"`
+	PlanFile        string   `json:"plan_file" yaml:"plan_file"`
+	SpecFiles       []string `json:"spec_files,omitempty" yaml:"spec_files,omitempty"`
+	PlanFiles       []string `json:"plan_files,omitempty" yaml:"plan_files,omitempty"`
+	CodeRoot        string   `json:"code_root" yaml:"code_root"`
+	PathPrefix      string   `json:"path_prefix,omitempty" yaml:"path_prefix,omitempty"`
+	Profile         string   `json:"profile" yaml:"profile"`
+	Strict          bool     `json:"strict" yaml:"strict"`
+	RequireTests    bool     `json:"require_tests,omitempty" yaml:"require_tests,omitempty"`
+	RequireEvidence bool     `json:"require_evidence,omitempty" yaml:"require_evidence,omitempty"`
+	ScanTodos       bool     `json:"scan_todos,omitempty" yaml:"scan_todos,omitempty"`
+	CheckPlanOrder  bool     `json:"check_plan_order,omitempty" yaml:"check_plan_order,omitempty"`
+	PartialAsWarn   bool     `json:"partial_as_warn,omitempty" yaml:"partial_as_warn,omitempty"`
+	MinConfidence   string   `json:"min_confidence,omitempty" yaml:"min_confidence,omitempty"`
+	Mode            string   `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Since           string   `json:"since,omitempty" yaml:"since,omitempty"`
 }
 
 // Summary holds the computed verdict and issue counts.
+// UnmetSpecIDs and UnmetPlanIDs list, sorted, the items whose coverage is
+// NOT_IMPLEMENTED or PARTIAL. IntentGaps lists, sorted, the NOT_IMPLEMENTED
+// spec items that no plan item serves. It is absent when there are none, and
+// when there are plan items but no traceability links to tell which spec
+// items they serve. SuppressedCount is the number of findings a baseline
+// suppressed; they are not in the severity counts.
 type Summary struct {
-	Verdict       Verdict `json:"verdict"`
-	Score         int     `json:"score"`
-	CriticalCount int     `json:"critical_count"`
-	WarnCount     int     `json:"warn_count"`
-	InfoCount     int     `json:"info_count"`
+	Verdict         Verdict  `json:"verdict" yaml:"verdict"`
+	Score           int      `json:"score" yaml:"score"`
+	CriticalCount   int      `json:"critical_count" yaml:"critical_count"`
+	WarnCount       int      `json:"warn_count" yaml:"warn_count"`
+	InfoCount       int      `json:"info_count" yaml:"info_count"`
+	UnmetSpecIDs    []string `json:"unmet_spec_ids" yaml:"unmet_spec_ids"`
+	UnmetPlanIDs    []string `json:"unmet_plan_ids" yaml:"unmet_plan_ids"`
+	IntentGaps      []string `json:"intent_gaps,omitempty" yaml:"intent_gaps,omitempty"`
+	SuppressedCount int      `json:"suppressed_count,omitempty" yaml:"suppressed_count,omitempty"`
+	// SpecCoveragePct and PlanCoveragePct are the percentage of spec and
+	// plan items implemented, counting PARTIAL as half, to one decimal
+	// place. They are absent when there is no coverage, as in summary mode.
+	SpecCoveragePct *float64 `json:"spec_coverage_pct,omitempty" yaml:"spec_coverage_pct,omitempty"`
+	PlanCoveragePct *float64 `json:"plan_coverage_pct,omitempty" yaml:"plan_coverage_pct,omitempty"`
+	// Rationale is the model's short assessment, present in summary mode
+	// and when an explanation was requested.
+	Rationale string `json:"rationale,omitempty" yaml:"rationale,omitempty"`
 }
 
 // Coverage holds all spec and plan coverage entries.
 type Coverage struct {
-	Spec []SpecCoverageEntry `json:"spec"`
-	Plan []PlanCoverageEntry `json:"plan"`
+	Spec []SpecCoverageEntry `json:"spec" yaml:"spec"`
+	Plan []PlanCoverageEntry `json:"plan" yaml:"plan"`
 }
 
 // SpecCoverageEntry describes the implementation status of one spec item.
 type SpecCoverageEntry struct {
-	ID            string         `json:"id"`
-	Status        CoverageStatus `json:"status"`
-	SpecReference Reference      `json:"spec_reference"`
-	Evidence      []Evidence     `json:"evidence"`
-	Notes         string         `json:"notes,omitempty"`
+	ID            string         `json:"id" yaml:"id"`
+	Status        CoverageStatus `json:"status" yaml:"status"`
+	SpecReference Reference      `json:"spec_reference" yaml:"spec_reference"`
+	Evidence      []Evidence     `json:"evidence" yaml:"evidence"`
+	Notes         string         `json:"notes,omitempty" yaml:"notes,omitempty"`
+	// Weight is the importance of the spec item, taken from the spec rather
+	// than the model. It is omitted for the default weight of 1.
+	Weight int `json:"weight,omitempty" yaml:"weight,omitempty"`
+	// Adjustments records, in order, the deterministic changes made to the
+	// entry after the model reported it.
+	Adjustments []string `json:"adjustments,omitempty" yaml:"adjustments,omitempty"`
 }
 
 // PlanCoverageEntry describes the implementation status of one plan item.
 type PlanCoverageEntry struct {
-	ID            string         `json:"id"`
-	Status        CoverageStatus `json:"status"`
-	PlanReference Reference      `json:"plan_reference"`
-	Evidence      []Evidence     `json:"evidence"`
-	Notes         string         `json:"notes,omitempty"`
+	ID            string         `json:"id" yaml:"id"`
+	Status        CoverageStatus `json:"status" yaml:"status"`
+	PlanReference Reference      `json:"plan_reference" yaml:"plan_reference"`
+	Evidence      []Evidence     `json:"evidence" yaml:"evidence"`
+	Notes         string         `json:"notes,omitempty" yaml:"notes,omitempty"`
+	Adjustments   []string       `json:"adjustments,omitempty" yaml:"adjustments,omitempty"`
 }
 
 // Reference points to a location in a spec or plan file.
+// Source is the file the lines are in. It is taken from the parsed spec and
+// plan rather than the model, and is empty when it cannot be determined.
 type Reference struct {
-	LineStart int    `json:"line_start"`
-	LineEnd   int    `json:"line_end"`
-	Quote     string `json:"quote,omitempty"`
+	LineStart int    `json:"line_start" yaml:"line_start"`
+	LineEnd   int    `json:"line_end" yaml:"line_end"`
+	Quote     string `json:"quote,omitempty" yaml:"quote,omitempty"`
+	Source    string `json:"source,omitempty" yaml:"source,omitempty"`
 }
 
 // Evidence cites a code artifact supporting a finding.
+// Adjustments has the same meaning as on SpecCoverageEntry.
 type Evidence struct {
-	Path       string     `json:"path"`
-	Symbol     string     `json:"symbol,omitempty"`
-	Confidence Confidence `json:"confidence,omitempty"`
+	Path        string     `json:"path" yaml:"path"`
+	Symbol      string     `json:"symbol,omitempty" yaml:"symbol,omitempty"`
+	Confidence  Confidence `json:"confidence,omitempty" yaml:"confidence,omitempty"`
+	Adjustments []string   `json:"adjustments,omitempty" yaml:"adjustments,omitempty"`
 }
 
 // DriftFinding represents code behavior that exists without spec/plan authorization.
+// Suppressed marks a finding accepted by a baseline file; it is reported but
+// not counted toward the score or verdict.
 type DriftFinding struct {
-	ID             string     `json:"id"`
-	Severity       Severity   `json:"severity"`
-	Description    string     `json:"description"`
-	Evidence       []Evidence `json:"evidence"`
-	WhyUnjustified string     `json:"why_unjustified"`
-	Impact         string     `json:"impact"`
-	Recommendation string     `json:"recommendation"`
+	ID             string     `json:"id" yaml:"id"`
+	Severity       Severity   `json:"severity" yaml:"severity"`
+	Description    string     `json:"description" yaml:"description"`
+	Evidence       []Evidence `json:"evidence" yaml:"evidence"`
+	WhyUnjustified string     `json:"why_unjustified" yaml:"why_unjustified"`
+	Impact         string     `json:"impact" yaml:"impact"`
+	Recommendation string     `json:"recommendation" yaml:"recommendation"`
+	Suppressed     bool       `json:"suppressed,omitempty" yaml:"suppressed,omitempty"`
 }
 
 // Violation represents code behavior that contradicts declared spec constraints.
+// Suppressed has the same meaning as on DriftFinding.
 type Violation struct {
-	ID            string     `json:"id"`
-	Severity      Severity   `json:"severity"`
-	Description   string     `json:"description"`
-	SpecReference Reference  `json:"spec_reference"`
-	Evidence      []Evidence `json:"evidence"`
-	Impact        string     `json:"impact"`
-	Blocking      bool       `json:"blocking"`
+	ID            string     `json:"id" yaml:"id"`
+	Severity      Severity   `json:"severity" yaml:"severity"`
+	Description   string     `json:"description" yaml:"description"`
+	SpecReference Reference  `json:"spec_reference" yaml:"spec_reference"`
+	Evidence      []Evidence `json:"evidence" yaml:"evidence"`
+	Impact        string     `json:"impact" yaml:"impact"`
+	Blocking      bool       `json:"blocking" yaml:"blocking"`
+	Suppressed    bool       `json:"suppressed,omitempty" yaml:"suppressed,omitempty"`
+}
+
+// TraceLink records which spec items a plan item serves.
+type TraceLink struct {
+	PlanID  string   `json:"plan_id" yaml:"plan_id"`
+	SpecIDs []string `json:"spec_ids" yaml:"spec_ids"`
 }
 
 // Meta records information about the LLM call.
+// Token counts are provider-reported and summed across the initial and
+// repair calls.
 type Meta struct {
-	Model       string  `json:"model"`
-	Temperature float64 `json:"temperature"`
+	Model            string  `json:"model" yaml:"model"`
+	Temperature      float64 `json:"temperature" yaml:"temperature"`
+	PromptTokens     int     `json:"prompt_tokens" yaml:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens" yaml:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens" yaml:"total_tokens"`
+	// Inventory is the code inventory the model was sent, exactly as sent,
+	// when the run asked for it to be embedded.
+	Inventory string `json:"inventory,omitempty" yaml:"inventory,omitempty"`
 }
 
 // PartialReport contains only the fields populated by the LLM.
 // The CLI merges these with locally computed fields to produce a final Report.
 type PartialReport struct {
-	Coverage   Coverage       `json:"coverage"`
-	Drift      []DriftFinding `json:"drift"`
-	Violations []Violation    `json:"violations"`
-	Meta       Meta           `json:"meta"`
+	Coverage     Coverage       `json:"coverage" yaml:"coverage"`
+	Drift        []DriftFinding `json:"drift" yaml:"drift"`
+	Violations   []Violation    `json:"violations" yaml:"violations"`
+	Traceability []TraceLink    `json:"traceability,omitempty" yaml:"traceability,omitempty"`
+	// Rationale is set only in summary mode or when an explanation was
+	// requested.
+	Rationale string `json:"rationale,omitempty" yaml:"rationale,omitempty"`
+	Meta      Meta   `json:"meta" yaml:"meta"`
+}
+
+// AggregateReport rolls several reports up into one summary, as produced by
+// `realitycheck aggregate`. Verdict is the worst verdict among the reports,
+// the counts are totals, and AverageScore is the mean score rounded to one
+// decimal place.
+type AggregateReport struct {
+	Tool          string           `json:"tool" yaml:"tool"`
+	Version       string           `json:"version" yaml:"version"`
+	Verdict       Verdict          `json:"verdict" yaml:"verdict"`
+	AverageScore  float64          `json:"average_score" yaml:"average_score"`
+	CriticalCount int              `json:"critical_count" yaml:"critical_count"`
+	WarnCount     int              `json:"warn_count" yaml:"warn_count"`
+	InfoCount     int              `json:"info_count" yaml:"info_count"`
+	Reports       []AggregateEntry `json:"reports" yaml:"reports"`
+}
+
+// AggregateEntry summarizes one report in an AggregateReport. Path is the
+// report file it was read from.
+type AggregateEntry struct {
+	Path          string  `json:"path" yaml:"path"`
+	CodeRoot      string  `json:"code_root" yaml:"code_root"`
+	Verdict       Verdict `json:"verdict" yaml:"verdict"`
+	Score         int     `json:"score" yaml:"score"`
+	CriticalCount int     `json:"critical_count" yaml:"critical_count"`
+	WarnCount     int     `json:"warn_count" yaml:"warn_count"`
+	InfoCount     int     `json:"info_count" yaml:"info_count"`
 }
@@ -3,6 +3,9 @@ package spec
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/dshills/realitycheck/internal/mdparse"
 )
@@ -14,16 +17,110 @@ type Item = mdparse.Item
 // state; the counter is local to each segment() invocation, so concurrent
 // calls to Parse are safe.
 var segmenter = mdparse.Segmenter{
-	IDPrefix:       "SPEC",
-	IsNumberedItem: mdparse.DefaultIsNumberedItem,
-	StripPrefix:    mdparse.StripListPrefix,
+	IDPrefix:         "SPEC",
+	IsNumberedItem:   mdparse.DefaultIsNumberedItem,
+	StripPrefix:      mdparse.StripListPrefix,
+	HonorExplicitIDs: true,
 }
 
-// Parse reads the file at path and segments it into spec items.
+// Segmenter returns the segmenter Parse uses, for tools such as the linter
+// that inspect the raw segments.
+func Segmenter() mdparse.Segmenter { return segmenter }
+
+// Parse reads the file at path and segments it into spec items. Weight
+// tags are validated and removed from the item text; see ParseFilesWeighted.
 func Parse(path string) ([]Item, error) {
 	items, err := segmenter.ParseFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("spec: %w", err)
 	}
+	if _, err := takeWeights(items, false); err != nil {
+		return nil, err
+	}
 	return items, nil
 }
+
+// ParseFiles parses each file in order and concatenates the spec items.
+// IDs continue numbering across files, and each item's Source records the
+// file it was parsed from.
+func ParseFiles(paths []string) ([]Item, error) {
+	items, _, err := ParseFilesWeighted(paths, false)
+	return items, err
+}
+
+// ParseFilesWeighted is ParseFiles that also returns the weight of each
+// item, keyed by item ID; see Weight. With keywords set, requirement
+// keywords weigh items that have no weight tag.
+func ParseFilesWeighted(paths []string, keywords bool) ([]Item, map[string]int, error) {
+	items, err := segmenter.ParseFiles(paths)
+	if err != nil {
+		return nil, nil, fmt.Errorf("spec: %w", err)
+	}
+	weights, err := takeWeights(items, keywords)
+	if err != nil {
+		return nil, nil, err
+	}
+	return items, weights, nil
+}
+
+// DefaultWeight is the weight of an item with no weight tag or keyword.
+const DefaultWeight = 1
+
+// weightTagRe matches an explicit weight tag such as "[weight:3]".
+var weightTagRe = regexp.MustCompile(`(?i)\s*\[weight:\s*(\d+)\s*\]`)
+
+// Requirement keywords in the sense of RFC 2119. Only the uppercase forms
+// count, so "may" in ordinary prose does not weigh an item. "NOT REQUIRED"
+// states that something is optional, so it is removed before matching.
+var (
+	mustRe        = regexp.MustCompile(`\b(?:MUST|SHALL|REQUIRED)\b`)
+	shouldRe      = regexp.MustCompile(`\b(?:SHOULD|RECOMMENDED)\b`)
+	notRequiredRe = regexp.MustCompile(`\bNOT\s+REQUIRED\b`)
+)
+
+// Weight returns the importance of a spec item with the given text: the N
+// of a "[weight:N]" tag if there is one, else DefaultWeight. With keywords
+// set, an untagged item using MUST, SHALL, or REQUIRED weighs 3, one using
+// SHOULD or RECOMMENDED weighs 2, and any other, including MAY, OPTIONAL,
+// and NOT REQUIRED, weighs DefaultWeight. A tag with N below 1 is ignored
+// here; Parse rejects it.
+func Weight(text string, keywords bool) int {
+	if m := weightTagRe.FindStringSubmatch(text); m != nil {
+		if n, err := strconv.Atoi(m[1]); err == nil && n >= 1 {
+			return n
+		}
+	}
+	if !keywords {
+		return DefaultWeight
+	}
+	text = notRequiredRe.ReplaceAllString(text, "")
+	switch {
+	case mustRe.MatchString(text):
+		return 3
+	case shouldRe.MatchString(text):
+		return 2
+	default:
+		return DefaultWeight
+	}
+}
+
+// takeWeights returns each item's weight, keyed by ID, and removes its
+// weight tag from the text, so the tag is not sent to the model as part of
+// the requirement.
+func takeWeights(items []Item, keywords bool) (map[string]int, error) {
+	weights := make(map[string]int, len(items))
+	for i, it := range items {
+		tags := weightTagRe.FindAllStringSubmatch(it.Text, -1)
+		if len(tags) > 1 {
+			return nil, fmt.Errorf("spec: %s: more than one weight tag", it.ID)
+		}
+		if len(tags) == 1 {
+			if n, err := strconv.Atoi(tags[0][1]); err != nil || n < 1 {
+				return nil, fmt.Errorf("spec: %s: weight tag %q: weight must be a whole number of at least 1", it.ID, strings.TrimSpace(tags[0][0]))
+			}
+		}
+		weights[it.ID] = Weight(it.Text, keywords)
+		items[i].Text = weightTagRe.ReplaceAllString(it.Text, "")
+	}
+	return weights, nil
+}
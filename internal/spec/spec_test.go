@@ -2,6 +2,8 @@ package spec
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -64,3 +66,97 @@ func TestParseNotFound(t *testing.T) {
 		t.Fatal("expected error for missing file")
 	}
 }
+
+func TestParseFiles_ContinuesNumbering(t *testing.T) {
+	paths := []string{"../../testdata/spec_fixture.md", "../../testdata/aligned/SPEC.md"}
+	first, err := Parse(paths[0])
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	items, err := ParseFiles(paths)
+	if err != nil {
+		t.Fatalf("ParseFiles error: %v", err)
+	}
+	if len(items) <= len(first) {
+		t.Fatalf("expected items from both files, got %d", len(items))
+	}
+	for i, item := range items {
+		want := fmt.Sprintf("SPEC-%03d", i+1)
+		if item.ID != want {
+			t.Errorf("item[%d].ID = %q, want %q", i, item.ID, want)
+		}
+		wantSource := paths[0]
+		if i >= len(first) {
+			wantSource = paths[1]
+		}
+		if item.Source != wantSource {
+			t.Errorf("item[%d].Source = %q, want %q", i, item.Source, wantSource)
+		}
+	}
+}
+
+func TestParseFiles_ExplicitIDsAcrossFiles(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.md")
+	b := filepath.Join(dir, "b.md")
+	if err := os.WriteFile(a, []byte("- SPEC-002: Reads are cached.\n- Writes are logged.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte("- Deletes are soft.\n- SPEC-002: Duplicate.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := ParseFiles([]string{a})
+	if err != nil {
+		t.Fatalf("ParseFiles error: %v", err)
+	}
+	if items[0].ID != "SPEC-002" || items[1].ID != "SPEC-001" {
+		t.Errorf("IDs = %s, %s; want SPEC-002, SPEC-001", items[0].ID, items[1].ID)
+	}
+
+	// The same explicit ID in two files is reported with both locations.
+	_, err = ParseFiles([]string{a, b})
+	if err == nil || !strings.Contains(err.Error(), a+":1") || !strings.Contains(err.Error(), b+":2") {
+		t.Errorf("err = %v, want a duplicate error naming both files", err)
+	}
+}
+
+func TestParseWeights(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "SPEC.md")
+	src := "- The service MUST be stateless.\n- Responses SHOULD be cached.\n- Clients MAY retry.\n- Logs may be verbose. [weight:4]\n- Errors are wrapped.\n- Tracing is NOT REQUIRED.\n"
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		keywords bool
+		want     []int
+	}{
+		// Without keyword weighting only the tag counts, so existing specs
+		// score as before.
+		{false, []int{1, 1, 1, 4, 1, 1}},
+		{true, []int{3, 2, 1, 4, 1, 1}},
+	}
+	for _, c := range cases {
+		items, weights, err := ParseFilesWeighted([]string{path}, c.keywords)
+		if err != nil {
+			t.Fatalf("ParseFilesWeighted error: %v", err)
+		}
+		for i, item := range items {
+			if weights[item.ID] != c.want[i] {
+				t.Errorf("keywords=%v: %s weight = %d, want %d", c.keywords, item.ID, weights[item.ID], c.want[i])
+			}
+		}
+		if items[3].Text != "Logs may be verbose." {
+			t.Errorf("weight tag left in text: %q", items[3].Text)
+		}
+	}
+
+	for _, bad := range []string{"- Logs. [weight:0]\n", "- Logs. [weight:2] [weight:3]\n"} {
+		if err := os.WriteFile(path, []byte(bad), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Parse(path); err == nil {
+			t.Errorf("Parse(%q): expected error", bad)
+		}
+	}
+}
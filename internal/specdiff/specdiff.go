@@ -0,0 +1,133 @@
+// Package specdiff compares two versions of a spec to find requirements that
+// were added, removed, or reworded.
+//
+// Auto-assigned item IDs (SPEC-001, SPEC-002, ...) shift whenever an item is
+// inserted or deleted, so items are matched by text rather than by ID: first
+// by exact normalized text, then by word similarity.
+package specdiff
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/dshills/realitycheck/internal/spec"
+	"github.com/dshills/realitycheck/internal/textsim"
+)
+
+// MatchThreshold is the minimum word similarity for an old and a new item to
+// be treated as the same requirement, reworded.
+const MatchThreshold = 0.5
+
+// Item is a spec item as reported in a Result.
+type Item struct {
+	ID        string `json:"id"`
+	LineStart int    `json:"line_start"`
+	LineEnd   int    `json:"line_end"`
+	Text      string `json:"text"`
+}
+
+// Change pairs an item in the old spec with its reworded counterpart in the
+// new spec.
+type Change struct {
+	Old        Item    `json:"old"`
+	New        Item    `json:"new"`
+	Similarity float64 `json:"similarity"`
+}
+
+// Result is the comparison of a new spec against an old one. Added items are
+// the ones most likely to need new implementation.
+type Result struct {
+	Added     []Item   `json:"added"`
+	Removed   []Item   `json:"removed"`
+	Changed   []Change `json:"changed"`
+	Unchanged int      `json:"unchanged"`
+}
+
+// Compare diffs newItems against oldItems. Added and Changed follow the order
+// of newItems; Removed follows the order of oldItems.
+func Compare(oldItems, newItems []spec.Item) Result {
+	res := Result{Added: []Item{}, Removed: []Item{}, Changed: []Change{}}
+
+	oldWords := make([][]string, len(oldItems))
+	newWords := make([][]string, len(newItems))
+	for i, it := range oldItems {
+		oldWords[i] = textsim.Words(it.Text)
+	}
+	for i, it := range newItems {
+		newWords[i] = textsim.Words(it.Text)
+	}
+
+	// Exact matches first, so that a reworded neighbour cannot claim an item
+	// whose text did not change.
+	byText := make(map[string][]int)
+	for o := range oldItems {
+		key := strings.Join(oldWords[o], " ")
+		byText[key] = append(byText[key], o)
+	}
+	oldMatched := make([]bool, len(oldItems))
+	newMatched := make([]bool, len(newItems))
+	for n := range newItems {
+		key := strings.Join(newWords[n], " ")
+		if q := byText[key]; len(q) > 0 {
+			byText[key] = q[1:]
+			oldMatched[q[0]] = true
+			newMatched[n] = true
+			res.Unchanged++
+		}
+	}
+
+	// Pair the rest greedily, best match first.
+	type pair struct {
+		o, n  int
+		score float64
+	}
+	var pairs []pair
+	for n := range newItems {
+		if newMatched[n] {
+			continue
+		}
+		nset := textsim.Set(newWords[n])
+		for o := range oldItems {
+			if oldMatched[o] {
+				continue
+			}
+			if s := textsim.Jaccard(textsim.Set(oldWords[o]), nset); s >= MatchThreshold {
+				pairs = append(pairs, pair{o, n, s})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].score > pairs[j].score })
+
+	pairedWith := make(map[int]pair)
+	for _, p := range pairs {
+		if oldMatched[p.o] || newMatched[p.n] {
+			continue
+		}
+		oldMatched[p.o] = true
+		newMatched[p.n] = true
+		pairedWith[p.n] = p
+	}
+
+	for n, it := range newItems {
+		if p, ok := pairedWith[n]; ok {
+			res.Changed = append(res.Changed, Change{
+				Old:        toItem(oldItems[p.o]),
+				New:        toItem(it),
+				Similarity: math.Round(p.score*100) / 100,
+			})
+		} else if !newMatched[n] {
+			res.Added = append(res.Added, toItem(it))
+		}
+	}
+	for o, it := range oldItems {
+		if !oldMatched[o] {
+			res.Removed = append(res.Removed, toItem(it))
+		}
+	}
+	return res
+}
+
+func toItem(it spec.Item) Item {
+	return Item{ID: it.ID, LineStart: it.LineStart, LineEnd: it.LineEnd, Text: it.Text}
+}
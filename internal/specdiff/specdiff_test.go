@@ -0,0 +1,76 @@
+package specdiff
+
+import (
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/spec"
+)
+
+func items(texts ...string) []spec.Item {
+	out := make([]spec.Item, len(texts))
+	for i, t := range texts {
+		out[i] = spec.Item{ID: "SPEC-00" + string(rune('1'+i)), LineStart: i + 1, LineEnd: i + 1, Text: t}
+	}
+	return out
+}
+
+func TestCompare_Identical(t *testing.T) {
+	s := items("Users can log in with email", "Sessions expire after 30 minutes")
+	res := Compare(s, s)
+	if res.Unchanged != 2 || len(res.Added)+len(res.Removed)+len(res.Changed) != 0 {
+		t.Errorf("identical specs: %+v", res)
+	}
+}
+
+func TestCompare_InsertionShiftsIDs(t *testing.T) {
+	oldSpec := items("Users can log in with email", "Sessions expire after 30 minutes")
+	newSpec := items("Users can log in with email", "Admins can export audit logs as CSV", "Sessions **expire** after 30 minutes.")
+	res := Compare(oldSpec, newSpec)
+	if res.Unchanged != 2 {
+		t.Errorf("Unchanged = %d, want 2 (formatting changes are not edits)", res.Unchanged)
+	}
+	if len(res.Added) != 1 || res.Added[0].ID != "SPEC-002" {
+		t.Errorf("Added = %+v, want the inserted SPEC-002", res.Added)
+	}
+	if len(res.Removed) != 0 || len(res.Changed) != 0 {
+		t.Errorf("Removed = %+v, Changed = %+v, want none", res.Removed, res.Changed)
+	}
+}
+
+func TestCompare_ChangedAndRemoved(t *testing.T) {
+	oldSpec := items("Sessions expire after 30 minutes of inactivity", "Passwords are stored with bcrypt")
+	newSpec := items("Sessions expire after 60 minutes of inactivity", "Admins can export audit logs")
+	res := Compare(oldSpec, newSpec)
+	if len(res.Changed) != 1 {
+		t.Fatalf("Changed = %+v, want one reworded item", res.Changed)
+	}
+	c := res.Changed[0]
+	if c.Old.ID != "SPEC-001" || c.New.ID != "SPEC-001" || c.Similarity < MatchThreshold || c.Similarity >= 1 {
+		t.Errorf("Changed[0] = %+v", c)
+	}
+	if len(res.Added) != 1 || res.Added[0].Text != "Admins can export audit logs" {
+		t.Errorf("Added = %+v", res.Added)
+	}
+	if len(res.Removed) != 1 || res.Removed[0].Text != "Passwords are stored with bcrypt" {
+		t.Errorf("Removed = %+v", res.Removed)
+	}
+}
+
+func TestCompare_BestMatchWins(t *testing.T) {
+	oldSpec := items("Export reports as CSV files", "Export reports as PDF files")
+	newSpec := items("Export reports as PDF documents")
+	res := Compare(oldSpec, newSpec)
+	if len(res.Changed) != 1 || res.Changed[0].Old.Text != "Export reports as PDF files" {
+		t.Fatalf("Changed = %+v, want pairing with the PDF item", res.Changed)
+	}
+	if len(res.Removed) != 1 || res.Removed[0].Text != "Export reports as CSV files" {
+		t.Errorf("Removed = %+v", res.Removed)
+	}
+}
+
+func TestCompare_Empty(t *testing.T) {
+	res := Compare(nil, nil)
+	if res.Added == nil || res.Removed == nil || res.Changed == nil {
+		t.Error("result slices should be non-nil so JSON renders [] rather than null")
+	}
+}
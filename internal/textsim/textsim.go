@@ -0,0 +1,39 @@
+// Package textsim provides the word-overlap similarity that specdiff and
+// reportdiff use to match items across two versions of a document.
+package textsim
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Words lowercases text and splits it into letter and digit runs, so that
+// punctuation, Markdown emphasis, and whitespace changes do not count as edits.
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Set returns the distinct elements of items as a set.
+func Set(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, it := range items {
+		set[it] = true
+	}
+	return set
+}
+
+// Jaccard returns |a ∩ b| / |a ∪ b|, or 0 when both sets are empty.
+func Jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+	inter := 0
+	for k := range a {
+		if b[k] {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(a)+len(b)-inter)
+}
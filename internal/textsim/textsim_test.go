@@ -0,0 +1,31 @@
+package textsim
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	got := Words("The **store** MUST persist users, (v2).")
+	want := []string{"the", "store", "must", "persist", "users", "v2"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Words = %v, want %v", got, want)
+	}
+}
+
+func TestJaccard(t *testing.T) {
+	tests := []struct {
+		a, b []string
+		want float64
+	}{
+		{nil, nil, 0},
+		{[]string{"a", "b"}, []string{"a", "b"}, 1},
+		{[]string{"a", "b"}, []string{"b", "c"}, 1.0 / 3},
+		{[]string{"a"}, []string{"b"}, 0},
+	}
+	for _, tt := range tests {
+		if got := Jaccard(Set(tt.a), Set(tt.b)); got != tt.want {
+			t.Errorf("Jaccard(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+// ErrUnsupported is returned by Run on platforms without terminal support.
+var ErrUnsupported = errors.New("tui: interactive terminal not supported on this platform")
+
+// Screen control sequences: the alternate screen keeps the user's
+// scrollback intact, and the cursor is hidden while the viewer runs.
+const (
+	enterScreen = "\x1b[?1049h\x1b[?25l"
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+	clearScreen = "\x1b[H\x1b[2J"
+)
+
+// Run shows report on the terminal in and out until the user quits. in is
+// put in raw mode for the duration and restored afterwards, also on error.
+func Run(in, out *os.File, report *schema.Report) error {
+	if !IsTerminal(in) || !IsTerminal(out) {
+		return errors.New("tui: stdin and stdout must be a terminal")
+	}
+	restore, err := makeRaw(int(in.Fd()))
+	if err != nil {
+		return fmt.Errorf("tui: %w", err)
+	}
+	defer restore()
+	fmt.Fprint(out, enterScreen)
+	defer fmt.Fprint(out, leaveScreen)
+
+	resized := make(chan os.Signal, 1)
+	if len(resizeSignals) > 0 {
+		signal.Notify(resized, resizeSignals...)
+		defer signal.Stop(resized)
+	}
+	keys := make(chan []Key)
+	readErr := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, err := in.Read(buf)
+			if err != nil {
+				readErr <- err
+				return
+			}
+			keys <- DecodeKeys(buf[:n])
+		}
+	}()
+
+	m := New(report)
+	for {
+		w, h, err := termSize(int(out.Fd()))
+		if err != nil {
+			return fmt.Errorf("tui: %w", err)
+		}
+		fmt.Fprint(out, clearScreen+m.View(w, h))
+		select {
+		case ks := <-keys:
+			for _, k := range ks {
+				m.HandleKey(k, h-2)
+			}
+			if m.Done() {
+				return nil
+			}
+		case <-resized:
+		case err := <-readErr:
+			return fmt.Errorf("tui: read input: %w", err)
+		}
+	}
+}
+
+// DecodeKeys returns the keys in one read from a terminal in raw mode.
+// Arrow, Home, End, and Page keys arrive as escape sequences; j/k/h/l move
+// like the arrows, g/G jump to the top and bottom, and q, Esc, and Ctrl-C
+// quit. Unknown input is dropped.
+func DecodeKeys(b []byte) []Key {
+	var keys []Key
+	for len(b) > 0 {
+		if b[0] == 0x1b && len(b) >= 3 && (b[1] == '[' || b[1] == 'O') {
+			k, n := decodeEscape(b[2:])
+			if k != KeyNone {
+				keys = append(keys, k)
+			}
+			b = b[2+n:]
+			continue
+		}
+		switch b[0] {
+		case 'k':
+			keys = append(keys, KeyUp)
+		case 'j':
+			keys = append(keys, KeyDown)
+		case 'h':
+			keys = append(keys, KeyLeft)
+		case 'l':
+			keys = append(keys, KeyRight)
+		case '\r', '\n', ' ':
+			keys = append(keys, KeyEnter)
+		case 'g':
+			keys = append(keys, KeyHome)
+		case 'G':
+			keys = append(keys, KeyEnd)
+		case 'q', 0x1b, 0x03:
+			keys = append(keys, KeyQuit)
+		}
+		b = b[1:]
+	}
+	return keys
+}
+
+// decodeEscape decodes the part of a CSI or SS3 sequence after "ESC [" or
+// "ESC O" and returns the key and the number of bytes consumed.
+func decodeEscape(b []byte) (Key, int) {
+	switch b[0] {
+	case 'A':
+		return KeyUp, 1
+	case 'B':
+		return KeyDown, 1
+	case 'C':
+		return KeyRight, 1
+	case 'D':
+		return KeyLeft, 1
+	case 'H':
+		return KeyHome, 1
+	case 'F':
+		return KeyEnd, 1
+	}
+	// Sequences such as "5~" (Page Up): digits up to a final "~".
+	for i, c := range b {
+		if c >= '0' && c <= '9' || c == ';' {
+			continue
+		}
+		if c != '~' {
+			return KeyNone, i + 1
+		}
+		switch string(b[:i]) {
+		case "1", "7":
+			return KeyHome, i + 1
+		case "4", "8":
+			return KeyEnd, i + 1
+		case "5":
+			return KeyPageUp, i + 1
+		case "6":
+			return KeyPageDown, i + 1
+		}
+		return KeyNone, i + 1
+	}
+	return KeyNone, len(b)
+}
@@ -0,0 +1,14 @@
+//go:build !linux && !darwin
+
+package tui
+
+import "os"
+
+var resizeSignals []os.Signal
+
+// IsTerminal reports whether f is a terminal; it is always false here.
+func IsTerminal(*os.File) bool { return false }
+
+func makeRaw(int) (func(), error) { return nil, ErrUnsupported }
+
+func termSize(int) (int, int, error) { return 0, 0, ErrUnsupported }
@@ -0,0 +1,404 @@
+// Package tui is an interactive terminal viewer for a report. The report is
+// shown as a tree (summary, spec and plan coverage, drift, violations) next
+// to a detail pane for the selected node. Model holds the state and renders
+// frames as plain strings; Run drives it on a terminal in raw mode.
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+// Key is a decoded key press.
+type Key int
+
+const (
+	KeyNone Key = iota
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyEnter
+	KeyHome
+	KeyEnd
+	KeyPageUp
+	KeyPageDown
+	KeyQuit
+)
+
+// node is one row of the tree. Sections have children; leaves are the
+// summary, coverage entries, and findings.
+type node struct {
+	label    string
+	detail   string
+	children []*node
+	parent   *node
+	depth    int
+	open     bool
+}
+
+// Model is the viewer state: the tree, the selected row, and the first row
+// shown.
+type Model struct {
+	header string
+	roots  []*node
+	rows   []*node // visible rows, in display order
+	cursor int
+	top    int
+	done   bool
+}
+
+// New returns a Model for report with every section collapsed and the
+// summary selected.
+func New(report *schema.Report) *Model {
+	m := &Model{
+		header: fmt.Sprintf("RealityCheck  %s  score %d/100", report.Summary.Verdict, report.Summary.Score),
+	}
+	m.roots = append(m.roots, &node{label: "Summary", detail: summaryDetail(report)})
+
+	spec := section(fmt.Sprintf("Spec coverage (%d)", len(report.Coverage.Spec)))
+	for _, e := range report.Coverage.Spec {
+		spec.add(&node{
+			label:  fmt.Sprintf("%s %s", e.ID, e.Status),
+			detail: coverageDetail(e.ID, e.Status, e.SpecReference, e.Evidence, e.Notes),
+		})
+	}
+	plan := section(fmt.Sprintf("Plan coverage (%d)", len(report.Coverage.Plan)))
+	for _, e := range report.Coverage.Plan {
+		plan.add(&node{
+			label:  fmt.Sprintf("%s %s", e.ID, e.Status),
+			detail: coverageDetail(e.ID, e.Status, e.PlanReference, e.Evidence, e.Notes),
+		})
+	}
+	drift := section(fmt.Sprintf("Drift (%d)", len(report.Drift)))
+	for _, d := range report.Drift {
+		drift.add(&node{label: findingLabel(d.ID, d.Severity, d.Description), detail: driftDetail(d)})
+	}
+	violations := section(fmt.Sprintf("Violations (%d)", len(report.Violations)))
+	for _, v := range report.Violations {
+		violations.add(&node{label: findingLabel(v.ID, v.Severity, v.Description), detail: violationDetail(v)})
+	}
+	m.roots = append(m.roots, spec, plan, drift, violations)
+	m.flatten()
+	return m
+}
+
+// section returns an empty tree section; add fills it.
+func section(label string) *node {
+	return &node{label: label, detail: label + "\n\nNothing to show."}
+}
+
+func (n *node) add(child *node) {
+	child.parent = n
+	child.depth = n.depth + 1
+	n.children = append(n.children, child)
+	n.detail = n.label + "\n\nPress Enter or → to expand."
+}
+
+// flatten rebuilds the visible rows from the open sections.
+func (m *Model) flatten() {
+	m.rows = m.rows[:0]
+	var walk func([]*node)
+	walk = func(nodes []*node) {
+		for _, n := range nodes {
+			m.rows = append(m.rows, n)
+			if n.open {
+				walk(n.children)
+			}
+		}
+	}
+	walk(m.roots)
+}
+
+// Done reports whether the user asked to quit.
+func (m *Model) Done() bool { return m.done }
+
+// Selected returns the label of the selected row.
+func (m *Model) Selected() string { return m.rows[m.cursor].label }
+
+// HandleKey updates the model for one key press. page is the number of tree
+// rows on screen, used by KeyPageUp and KeyPageDown.
+func (m *Model) HandleKey(k Key, page int) {
+	cur := m.rows[m.cursor]
+	switch k {
+	case KeyUp:
+		m.move(-1)
+	case KeyDown:
+		m.move(1)
+	case KeyPageUp:
+		m.move(-max(page, 1))
+	case KeyPageDown:
+		m.move(max(page, 1))
+	case KeyHome:
+		m.cursor = 0
+	case KeyEnd:
+		m.cursor = len(m.rows) - 1
+	case KeyRight, KeyEnter:
+		if len(cur.children) == 0 {
+			return
+		}
+		if cur.open && k == KeyEnter {
+			cur.open = false
+		} else if !cur.open {
+			cur.open = true
+		} else {
+			m.move(1)
+			return
+		}
+		m.flatten()
+	case KeyLeft:
+		switch {
+		case cur.open:
+			cur.open = false
+			m.flatten()
+		case cur.parent != nil:
+			cur.parent.open = false
+			m.flatten()
+			m.cursor = m.indexOf(cur.parent)
+		}
+	case KeyQuit:
+		m.done = true
+	}
+}
+
+func (m *Model) move(delta int) {
+	m.cursor = min(max(m.cursor+delta, 0), len(m.rows)-1)
+}
+
+func (m *Model) indexOf(n *node) int {
+	for i, r := range m.rows {
+		if r == n {
+			return i
+		}
+	}
+	return 0
+}
+
+// Layout constants for View.
+const (
+	minTreeWidth = 24
+	separator    = " │ "
+	footer       = "↑/↓ move  →/Enter expand  ← collapse  PgUp/PgDn page  q quit"
+)
+
+// View renders a width×height frame: a header line, the tree and detail
+// panes, and a key help line. Lines are separated by "\r\n" so the frame can
+// be written to a terminal in raw mode. The selected row is shown in reverse
+// video.
+func (m *Model) View(width, height int) string {
+	width = max(width, minTreeWidth*2)
+	height = max(height, 4)
+	body := height - 2
+	treeWidth := max(width*2/5, minTreeWidth)
+	detailWidth := width - treeWidth - runeLen(separator)
+
+	// Keep the cursor on screen.
+	if m.cursor < m.top {
+		m.top = m.cursor
+	}
+	if m.cursor >= m.top+body {
+		m.top = m.cursor - body + 1
+	}
+
+	detail := wrap(m.rows[m.cursor].detail, detailWidth)
+
+	var sb strings.Builder
+	sb.WriteString(fit(m.header, width))
+	for i := range body {
+		sb.WriteString("\r\n")
+		row := ""
+		if r := m.top + i; r < len(m.rows) {
+			row = fit(treeRow(m.rows[r]), treeWidth)
+			if r == m.cursor {
+				row = "\x1b[7m" + row + "\x1b[0m"
+			}
+		} else {
+			row = strings.Repeat(" ", treeWidth)
+		}
+		sb.WriteString(row)
+		sb.WriteString(separator)
+		if i < len(detail) {
+			sb.WriteString(detail[i])
+		}
+	}
+	sb.WriteString("\r\n")
+	sb.WriteString(fit(footer, width))
+	return sb.String()
+}
+
+// treeRow renders n with its indentation and an open/closed marker.
+func treeRow(n *node) string {
+	marker := "  "
+	if len(n.children) > 0 {
+		marker = "▸ "
+		if n.open {
+			marker = "▾ "
+		}
+	}
+	return strings.Repeat("  ", n.depth) + marker + strings.Join(strings.Fields(clean(n.label)), " ")
+}
+
+// clean replaces control characters other than newline with spaces. Report
+// text comes from the model and must not be able to move the cursor or
+// change terminal modes.
+func clean(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r != '\n' && (r < 0x20 || r >= 0x7f && r < 0xa0) {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+func runeLen(s string) int { return len([]rune(s)) }
+
+// fit pads or truncates s to exactly w runes.
+func fit(s string, w int) string {
+	r := []rune(s)
+	if len(r) > w {
+		if w <= 1 {
+			return string(r[:w])
+		}
+		return string(r[:w-1]) + "…"
+	}
+	return s + strings.Repeat(" ", w-len(r))
+}
+
+// wrap breaks text into lines of at most w runes, at spaces where possible.
+// Newlines in text are kept.
+func wrap(text string, w int) []string {
+	var out []string
+	for _, para := range strings.Split(clean(text), "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			for runeLen(word) > w {
+				if line != "" {
+					out = append(out, line)
+					line = ""
+				}
+				r := []rune(word)
+				out = append(out, string(r[:w]))
+				word = string(r[w:])
+			}
+			switch {
+			case line == "":
+				line = word
+			case runeLen(line)+1+runeLen(word) <= w:
+				line += " " + word
+			default:
+				out = append(out, line)
+				line = word
+			}
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+func findingLabel(id string, sev schema.Severity, description string) string {
+	return fmt.Sprintf("%s [%s] %s", id, sev, description)
+}
+
+func summaryDetail(r *schema.Report) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Verdict: %s\nScore: %d/100\n", r.Summary.Verdict, r.Summary.Score)
+	fmt.Fprintf(&sb, "Critical: %d  Warn: %d  Info: %d\n", r.Summary.CriticalCount, r.Summary.WarnCount, r.Summary.InfoCount)
+	if r.Summary.SuppressedCount > 0 {
+		fmt.Fprintf(&sb, "Suppressed: %d\n", r.Summary.SuppressedCount)
+	}
+	if len(r.Summary.UnmetSpecIDs) > 0 {
+		fmt.Fprintf(&sb, "Unmet spec items: %s\n", strings.Join(r.Summary.UnmetSpecIDs, ", "))
+	}
+	if len(r.Summary.UnmetPlanIDs) > 0 {
+		fmt.Fprintf(&sb, "Unmet plan items: %s\n", strings.Join(r.Summary.UnmetPlanIDs, ", "))
+	}
+	if len(r.Summary.IntentGaps) > 0 {
+		fmt.Fprintf(&sb, "Unplanned spec items: %s\n", strings.Join(r.Summary.IntentGaps, ", "))
+	}
+	if r.Summary.Rationale != "" {
+		fmt.Fprintf(&sb, "\n%s\n", r.Summary.Rationale)
+	}
+	fmt.Fprintf(&sb, "\nSpec: %s\nPlan: %s\nCode root: %s\nProfile: %s\n",
+		r.Input.SpecFile, r.Input.PlanFile, r.Input.CodeRoot, r.Input.Profile)
+	if r.Meta.Model != "" {
+		fmt.Fprintf(&sb, "Model: %s\n", r.Meta.Model)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func coverageDetail(id string, status schema.CoverageStatus, ref schema.Reference, evidence []schema.Evidence, notes string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s  %s\n", id, status)
+	writeReference(&sb, ref)
+	writeEvidence(&sb, evidence)
+	writeField(&sb, "Notes", notes)
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func driftDetail(d schema.DriftFinding) string {
+	var sb strings.Builder
+	writeFindingHead(&sb, d.ID, d.Severity, d.Suppressed, d.Description)
+	writeEvidence(&sb, d.Evidence)
+	writeField(&sb, "Why unjustified", d.WhyUnjustified)
+	writeField(&sb, "Impact", d.Impact)
+	writeField(&sb, "Recommendation", d.Recommendation)
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func violationDetail(v schema.Violation) string {
+	var sb strings.Builder
+	writeFindingHead(&sb, v.ID, v.Severity, v.Suppressed, v.Description)
+	if v.Blocking {
+		sb.WriteString("Blocking\n")
+	}
+	writeReference(&sb, v.SpecReference)
+	writeEvidence(&sb, v.Evidence)
+	writeField(&sb, "Impact", v.Impact)
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func writeFindingHead(sb *strings.Builder, id string, sev schema.Severity, suppressed bool, description string) {
+	fmt.Fprintf(sb, "%s  %s", id, sev)
+	if suppressed {
+		sb.WriteString("  (suppressed)")
+	}
+	fmt.Fprintf(sb, "\n\n%s\n", description)
+}
+
+func writeReference(sb *strings.Builder, ref schema.Reference) {
+	if ref.LineStart == 0 {
+		return
+	}
+	fmt.Fprintf(sb, "\nLines %d-%d", ref.LineStart, ref.LineEnd)
+	if ref.Quote != "" {
+		fmt.Fprintf(sb, ": %q", ref.Quote)
+	}
+	sb.WriteString("\n")
+}
+
+func writeEvidence(sb *strings.Builder, evidence []schema.Evidence) {
+	if len(evidence) == 0 {
+		sb.WriteString("\nEvidence: none\n")
+		return
+	}
+	sb.WriteString("\nEvidence:\n")
+	for _, ev := range evidence {
+		line := "- " + ev.Path
+		if ev.Symbol != "" {
+			line += " (" + ev.Symbol + ")"
+		}
+		if ev.Confidence != "" {
+			line += " [" + string(ev.Confidence) + "]"
+		}
+		sb.WriteString(line + "\n")
+	}
+}
+
+func writeField(sb *strings.Builder, name, value string) {
+	if value == "" {
+		return
+	}
+	fmt.Fprintf(sb, "\n%s:\n%s\n", name, value)
+}
@@ -0,0 +1,103 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+func sampleReport() *schema.Report {
+	return &schema.Report{
+		Tool:    "realitycheck",
+		Summary: schema.Summary{Verdict: schema.VerdictDriftDetected, Score: 73, WarnCount: 1, Rationale: "Mostly there."},
+		Coverage: schema.Coverage{
+			Spec: []schema.SpecCoverageEntry{
+				{ID: "SPEC-001", Status: schema.StatusImplemented, Evidence: []schema.Evidence{{Path: "store.go", Symbol: "Get", Confidence: schema.ConfidenceHigh}}},
+			},
+		},
+		Drift: []schema.DriftFinding{{
+			ID: "DRIFT-001", Severity: schema.SeverityWarn, Description: "Undocumented TTL eviction",
+			Evidence:       []schema.Evidence{{Path: "cache.go"}},
+			WhyUnjustified: "Not in spec.", Impact: "Entries vanish.", Recommendation: "Document the TTL.",
+		}},
+		Violations: []schema.Violation{{ID: "VIOLATION-001", Severity: schema.SeverityCritical, Description: "Writes \x1b[2Jeverywhere", Blocking: true}},
+	}
+}
+
+func TestModel_Navigation(t *testing.T) {
+	m := New(sampleReport())
+	if m.Selected() != "Summary" || len(m.rows) != 5 {
+		t.Fatalf("selected %q with %d rows, want Summary and five collapsed sections", m.Selected(), len(m.rows))
+	}
+
+	// Down to Drift, expand it, and select the finding.
+	for range 3 {
+		m.HandleKey(KeyDown, 10)
+	}
+	m.HandleKey(KeyRight, 10)
+	m.HandleKey(KeyRight, 10)
+	if got := m.Selected(); !strings.HasPrefix(got, "DRIFT-001 [WARN]") {
+		t.Fatalf("selected %q, want the drift finding", got)
+	}
+	view := m.View(120, 30)
+	for _, want := range []string{"cache.go", "Why unjustified:", "Entries vanish.", "Document the TTL."} {
+		if !strings.Contains(view, want) {
+			t.Errorf("detail pane missing %q:\n%s", want, view)
+		}
+	}
+
+	// Left from a finding collapses its section and selects it.
+	m.HandleKey(KeyLeft, 10)
+	if got := m.Selected(); got != "Drift (1)" || len(m.rows) != 5 {
+		t.Errorf("selected %q with %d rows, want the collapsed section", got, len(m.rows))
+	}
+
+	m.HandleKey(KeyEnd, 10)
+	m.HandleKey(KeyDown, 10)
+	if got := m.Selected(); got != "Violations (1)" {
+		t.Errorf("selected %q, want the last row", got)
+	}
+	m.HandleKey(KeyQuit, 10)
+	if !m.Done() {
+		t.Error("quit key should end the viewer")
+	}
+}
+
+func TestModel_ViewFitsAndIsSanitized(t *testing.T) {
+	m := New(sampleReport())
+	m.HandleKey(KeyEnd, 10)
+	m.HandleKey(KeyEnter, 10)
+	m.HandleKey(KeyDown, 10)
+	view := m.View(80, 12)
+	lines := strings.Split(view, "\r\n")
+	if len(lines) != 12 {
+		t.Errorf("view has %d lines, want 12", len(lines))
+	}
+	if strings.Contains(view, "\x1b[2J") {
+		t.Error("control characters from the report reached the terminal")
+	}
+	if !strings.Contains(view, "Blocking") {
+		t.Errorf("violation detail missing:\n%s", view)
+	}
+}
+
+func TestDecodeKeys(t *testing.T) {
+	got := DecodeKeys([]byte("jk\x1b[A\x1b[B\x1b[C\x1b[D\x1b[5~\x1b[6~\x1bOHG\rq\x1b[Zx"))
+	want := []Key{KeyDown, KeyUp, KeyUp, KeyDown, KeyRight, KeyLeft, KeyPageUp, KeyPageDown, KeyHome, KeyEnd, KeyEnter, KeyQuit}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeKeys = %v, want %v", got, want)
+	}
+	if got := DecodeKeys([]byte{0x1b}); !reflect.DeepEqual(got, []Key{KeyQuit}) {
+		t.Errorf("lone Esc = %v, want quit", got)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	got := wrap("one two three\n\nabcdefgh", 5)
+	want := []string{"one", "two", "three", "", "abcde", "fgh"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrap = %q, want %q", got, want)
+	}
+}
@@ -3,13 +3,76 @@
 package verdict
 
 import (
+	"fmt"
+
 	"github.com/dshills/realitycheck/internal/schema"
 )
 
+// ScoreWeights is the number of points subtracted from 100 per finding at
+// each severity, and per unit of weight of unmet weighted spec items (see
+// CoveragePenalty). Weights affect the score only, never the verdict.
+type ScoreWeights struct {
+	Critical int
+	Warn     int
+	Info     int
+	Unmet    int
+}
+
+// DefaultScoreWeights are the standard weights: 20 per CRITICAL, 7 per WARN,
+// 2 per INFO, and 5 per unit of unmet spec item weight above 1.
+var DefaultScoreWeights = ScoreWeights{Critical: 20, Warn: 7, Info: 2, Unmet: 5}
+
+// Validate returns an error if any weight is negative.
+func (w ScoreWeights) Validate() error {
+	if w.Critical < 0 || w.Warn < 0 || w.Info < 0 || w.Unmet < 0 {
+		return fmt.Errorf("verdict: score weights must be non-negative (critical=%d warn=%d info=%d unmet=%d)",
+			w.Critical, w.Warn, w.Info, w.Unmet)
+	}
+	return nil
+}
+
 // ComputeScore calculates the alignment score from finding counts.
 // Start at 100; subtract 20 per CRITICAL, 7 per WARN, 2 per INFO; clamp to [0, 100].
 func ComputeScore(criticalCount, warnCount, infoCount int) int {
-	score := 100 - (criticalCount * 20) - (warnCount * 7) - (infoCount * 2)
+	return ComputeScoreWithWeights(criticalCount, warnCount, infoCount, DefaultScoreWeights)
+}
+
+// ComputeScoreWithWeights is ComputeScore with caller-supplied weights.
+// Start at 100; subtract each count times its weight; clamp to [0, 100].
+func ComputeScoreWithWeights(criticalCount, warnCount, infoCount int, w ScoreWeights) int {
+	return clampScore(100 - (criticalCount * w.Critical) - (warnCount * w.Warn) - (infoCount * w.Info))
+}
+
+// ComputeReportScore is ComputeScoreWithWeights, less the CoveragePenalty of
+// spec; clamped to [0, 100].
+func ComputeReportScore(criticalCount, warnCount, infoCount int, spec []schema.SpecCoverageEntry, w ScoreWeights) int {
+	return clampScore(100 - (criticalCount * w.Critical) - (warnCount * w.Warn) - (infoCount * w.Info) - CoveragePenalty(spec, w))
+}
+
+// CoveragePenalty returns the points unmet weighted spec items cost: w.Unmet
+// for each unit of weight above the default of 1 of every NOT_IMPLEMENTED or
+// UNCLEAR item, and half that for a PARTIAL item, rounded down in total. An
+// unmet MUST item (weight 3) thus costs more than an unmet MAY item, and
+// items of the default weight cost nothing, as they did before weights.
+func CoveragePenalty(spec []schema.SpecCoverageEntry, w ScoreWeights) int {
+	halves := 0
+	for _, e := range spec {
+		extra := e.Weight - 1
+		if extra <= 0 {
+			continue
+		}
+		switch e.Status {
+		case schema.StatusNotImplemented, schema.StatusUnclear:
+			halves += 2 * extra
+		case schema.StatusPartial:
+			halves += extra
+		}
+	}
+	return halves * w.Unmet / 2
+}
+
+// clampScore clamps score to [0, 100].
+func clampScore(score int) int {
 	if score < 0 {
 		return 0
 	}
@@ -46,27 +109,31 @@ func VerdictOrdinal(v schema.Verdict) int {
 //  4. Any PARTIAL, NOT_IMPLEMENTED, or UNCLEAR coverage (spec or plan) → PARTIALLY_ALIGNED
 //  5. Otherwise → ALIGNED
 //
+// Suppressed drift findings and violations are ignored by rules 1–3.
+//
 // Note on rule 2: CRITICAL drift represents unauthorized behavior of the highest
 // severity and is treated equivalently to a CRITICAL violation. This is an
 // intentional design decision documented in the PLAN.
 func DetermineVerdict(report *schema.PartialReport) schema.Verdict {
 	// Rule 1: CRITICAL violation.
 	for _, v := range report.Violations {
-		if v.Severity == schema.SeverityCritical {
+		if v.Severity == schema.SeverityCritical && !v.Suppressed {
 			return schema.VerdictViolation
 		}
 	}
 
 	// Rule 2: CRITICAL drift.
 	for _, d := range report.Drift {
-		if d.Severity == schema.SeverityCritical {
+		if d.Severity == schema.SeverityCritical && !d.Suppressed {
 			return schema.VerdictViolation
 		}
 	}
 
 	// Rule 3: Any drift.
-	if len(report.Drift) > 0 {
-		return schema.VerdictDriftDetected
+	for _, d := range report.Drift {
+		if !d.Suppressed {
+			return schema.VerdictDriftDetected
+		}
 	}
 
 	// Rule 4: Any non-IMPLEMENTED coverage.
@@ -90,9 +157,12 @@ func DetermineVerdict(report *schema.PartialReport) schema.Verdict {
 }
 
 // CountSeverities aggregates severity counts across all drift findings
-// and violations in the report.
+// and violations in the report. Suppressed findings are not counted.
 func CountSeverities(report *schema.PartialReport) (critical, warn, info int) {
 	for _, d := range report.Drift {
+		if d.Suppressed {
+			continue
+		}
 		switch d.Severity {
 		case schema.SeverityCritical:
 			critical++
@@ -103,6 +173,9 @@ func CountSeverities(report *schema.PartialReport) (critical, warn, info int) {
 		}
 	}
 	for _, v := range report.Violations {
+		if v.Suppressed {
+			continue
+		}
 		switch v.Severity {
 		case schema.SeverityCritical:
 			critical++
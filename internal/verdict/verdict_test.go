@@ -27,6 +27,61 @@ func TestComputeScore(t *testing.T) {
 	}
 }
 
+func TestComputeScoreWithWeights(t *testing.T) {
+	heavyWarn := ScoreWeights{Critical: 20, Warn: 15, Info: 0}
+	cases := []struct {
+		crit, warn, info int
+		w                ScoreWeights
+		want             int
+	}{
+		{1, 1, 1, DefaultScoreWeights, 71}, // matches ComputeScore
+		{0, 2, 0, heavyWarn, 70},           // 100 - 30
+		{0, 0, 10, heavyWarn, 100},         // INFO weighted at zero
+		{0, 7, 0, heavyWarn, 0},            // 100 - 105, clamped to 0
+		{3, 3, 3, ScoreWeights{}, 100},     // all-zero weights
+	}
+	for _, c := range cases {
+		got := ComputeScoreWithWeights(c.crit, c.warn, c.info, c.w)
+		if got != c.want {
+			t.Errorf("ComputeScoreWithWeights(%d, %d, %d, %+v) = %d, want %d", c.crit, c.warn, c.info, c.w, got, c.want)
+		}
+	}
+}
+
+func TestScoreWeights_Validate(t *testing.T) {
+	if err := DefaultScoreWeights.Validate(); err != nil {
+		t.Errorf("default weights should be valid: %v", err)
+	}
+	if err := (ScoreWeights{}).Validate(); err != nil {
+		t.Errorf("zero weights should be valid: %v", err)
+	}
+	if err := (ScoreWeights{Critical: 20, Warn: -1, Info: 2}).Validate(); err == nil {
+		t.Error("negative weight should be rejected")
+	}
+}
+
+func TestComputeReportScore_Weighted(t *testing.T) {
+	unmet := func(weight int, status schema.CoverageStatus) []schema.SpecCoverageEntry {
+		return []schema.SpecCoverageEntry{{ID: "SPEC-001", Status: status, Weight: weight}}
+	}
+	w := DefaultScoreWeights
+	must := ComputeReportScore(0, 1, 0, unmet(3, schema.StatusNotImplemented), w)
+	may := ComputeReportScore(0, 1, 0, unmet(0, schema.StatusNotImplemented), w)
+	if must != 83 || may != 93 {
+		t.Errorf("unmet MUST score = %d, unmet MAY score = %d; want 83 and 93", must, may)
+	}
+	if got := ComputeReportScore(0, 0, 0, unmet(3, schema.StatusPartial), w); got != 95 {
+		t.Errorf("PARTIAL MUST score = %d, want 95", got)
+	}
+	if got := ComputeReportScore(0, 0, 0, unmet(3, schema.StatusImplemented), w); got != 100 {
+		t.Errorf("IMPLEMENTED MUST score = %d, want 100", got)
+	}
+	// Without an Unmet weight, coverage does not affect the score.
+	if got := ComputeReportScore(0, 1, 0, unmet(3, schema.StatusNotImplemented), ScoreWeights{Warn: 7}); got != 93 {
+		t.Errorf("score with zero Unmet weight = %d, want 93", got)
+	}
+}
+
 func TestVerdictOrdinal(t *testing.T) {
 	ordinals := []struct {
 		v schema.Verdict
@@ -80,6 +135,20 @@ func TestDetermineVerdict_WarnDrift(t *testing.T) {
 	}
 }
 
+func TestDetermineVerdict_SuppressedIgnored(t *testing.T) {
+	r := &schema.PartialReport{
+		Drift:      []schema.DriftFinding{{ID: "DRIFT-001", Severity: schema.SeverityCritical, Suppressed: true}},
+		Violations: []schema.Violation{{ID: "VIOLATION-001", Severity: schema.SeverityCritical, Suppressed: true}},
+		Coverage:   schema.Coverage{Spec: []schema.SpecCoverageEntry{}, Plan: []schema.PlanCoverageEntry{}},
+	}
+	if got := DetermineVerdict(r); got != schema.VerdictAligned {
+		t.Errorf("DetermineVerdict with only suppressed findings = %q, want ALIGNED", got)
+	}
+	if c, w, i := CountSeverities(r); c+w+i != 0 {
+		t.Errorf("CountSeverities counted suppressed findings: %d/%d/%d", c, w, i)
+	}
+}
+
 func TestDetermineVerdict_PartialCoverage(t *testing.T) {
 	r := &schema.PartialReport{
 		Coverage: schema.Coverage{
@@ -0,0 +1,1187 @@
+// Package realitycheck is the library entrypoint for embedding RealityCheck
+// in another Go program. Run performs the same analysis as
+// `realitycheck check` and returns the assembled report instead of writing
+// it anywhere; rendering and exit policy (--fail-on) are left to the caller.
+//
+// The report and provider types are aliases of the internal schema and llm
+// types, so callers can name them without importing internal packages.
+package realitycheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dshills/realitycheck/internal/apisurface"
+	"github.com/dshills/realitycheck/internal/baseline"
+	"github.com/dshills/realitycheck/internal/codeindex"
+	"github.com/dshills/realitycheck/internal/coverage"
+	"github.com/dshills/realitycheck/internal/drift"
+	"github.com/dshills/realitycheck/internal/gaps"
+	"github.com/dshills/realitycheck/internal/gitdiff"
+	"github.com/dshills/realitycheck/internal/llm"
+	"github.com/dshills/realitycheck/internal/ordering"
+	"github.com/dshills/realitycheck/internal/plan"
+	"github.com/dshills/realitycheck/internal/profile"
+	"github.com/dshills/realitycheck/internal/rules"
+	"github.com/dshills/realitycheck/internal/schema"
+	"github.com/dshills/realitycheck/internal/spec"
+	"github.com/dshills/realitycheck/internal/verdict"
+)
+
+// Version is the tool version recorded in every report.
+const Version = "0.1.0"
+
+type (
+	// Report is the full analysis result.
+	Report = schema.Report
+	// Provider is an LLM backend. Implement it to inject a client of your own.
+	Provider = llm.Provider
+	// StreamingProvider is a Provider that can stream its response; see
+	// RunConfig.Stream.
+	StreamingProvider = llm.StreamingProvider
+	// CompletionResult is the output of a single Provider.Complete call.
+	CompletionResult = llm.CompletionResult
+	// Usage records the token counts a provider reported for one completion.
+	Usage = llm.Usage
+	// Prompt is one system/user prompt pair, as returned by Prompts.
+	Prompt = llm.Prompt
+	// RuleConfig declares a deterministic rule; see RunConfig.Rules.
+	RuleConfig = rules.Config
+	// ScoreWeights is the number of points subtracted per finding at each
+	// severity.
+	ScoreWeights = verdict.ScoreWeights
+	// ValidationError is one problem found in a model response; see
+	// ValidateResponse.
+	ValidationError = llm.ValidationError
+)
+
+// Error categories returned by Run. Use errors.Is to classify a failure.
+var (
+	// ErrInvalidInput reports a bad RunConfig or an unreadable spec, plan,
+	// code root, or profile.
+	ErrInvalidInput = errors.New("invalid input")
+	// ErrMissingAPIKey reports that the provider's API key environment
+	// variable is unset and neither Offline nor LLM was given.
+	ErrMissingAPIKey = errors.New("missing API key")
+	// ErrProvider reports a failure talking to the LLM provider.
+	ErrProvider = errors.New("LLM provider error")
+	// ErrInvalidModelOutput reports that the model's response failed
+	// validation even after the repair attempt.
+	ErrInvalidModelOutput = llm.ErrInvalidModelOutput
+)
+
+// RunConfig carries the inputs for Run. It mirrors the flags of
+// `realitycheck check` minus those that only affect output.
+type RunConfig struct {
+	// SpecFiles and PlanFiles are required. Items from later files continue
+	// numbering from earlier ones.
+	SpecFiles []string
+	PlanFiles []string
+	// CodeRoot is the directory to analyze. Defaults to the working directory.
+	CodeRoot string
+
+	// ProfileName selects a built-in profile (default "general").
+	// ProfileFile, if set, loads a YAML or TOML profile and takes precedence.
+	ProfileName string
+	ProfileFile string
+
+	// Provider is "anthropic" (default), "openai", "azure", or "google".
+	// Model defaults per provider, except for azure, where it is the
+	// deployment name and is required.
+	//
+	// Provider may also be a comma-separated fallback chain such as
+	// "anthropic,openai:gpt-4.1": each provider is tried in order until one
+	// returns a valid report, and Report.Meta.Model records which one did
+	// as "name:model". An entry's ":model" suffix overrides its default
+	// model; Model applies to the first entry when it has no suffix.
+	Provider string
+	Model    string
+	// LLM, if non-nil, is used instead of constructing a client for the
+	// first provider, and the API key check is skipped.
+	LLM Provider
+
+	Strict bool
+	// RequireTests downgrades IMPLEMENTED spec items to PARTIAL when no
+	// test file is cited as evidence, unless the item's text says
+	// "no test required".
+	RequireTests bool
+	// RequireEvidence downgrades IMPLEMENTED spec and plan items that cite
+	// no evidence to UNCLEAR. It runs before RequireTests and
+	// CheckPlanOrder.
+	RequireEvidence bool
+	// ScanTodos scans source files for TODO and FIXME comments that name
+	// spec or plan IDs, e.g. "// TODO: implement SPEC-003". They are listed
+	// in the code inventory, and the items they name are marked
+	// NOT_IMPLEMENTED whatever the model reported.
+	ScanTodos bool
+	// PartialAsWarn adds a WARN drift finding for each PARTIAL or UNCLEAR
+	// spec item, citing its location and notes, so incomplete items count
+	// toward the severity counts and score. As drift findings, they also
+	// make the verdict at least DRIFT_DETECTED. It runs after
+	// RequireEvidence and RequireTests.
+	PartialAsWarn bool
+	// CheckPlanOrder adds a WARN drift finding for each IMPLEMENTED plan
+	// item that depends on a NOT_IMPLEMENTED one. Dependencies are declared
+	// in plan item text, e.g. "depends on Step 3" or "depends on PLAN-003".
+	CheckPlanOrder bool
+	// BaselineIndex, if set, is a code index saved by an earlier run with
+	// SaveIndex. Each exported symbol added since then that no spec item
+	// cites as evidence becomes a WARN drift finding. Exported symbols are
+	// only known for Go. The check is skipped in summary mode, which
+	// reports no coverage.
+	BaselineIndex string
+	// SaveIndex, if set, writes the code index built for this run to this
+	// file, for a later run's BaselineIndex.
+	SaveIndex string
+	// Traceability asks the model to map each plan item to the spec items
+	// it serves; the links are returned in Report.Traceability. It costs
+	// extra output tokens.
+	Traceability bool
+	// Explain asks the model for a plain-English rationale of the verdict,
+	// returned in Report.Summary.Rationale. A response without one is
+	// repaired. It costs extra output tokens; summary mode always returns a
+	// rationale.
+	Explain bool
+	// DriftIDPattern and ViolationIDPattern, if non-nil, replace the
+	// patterns the model's drift and violation IDs must match, by default
+	// ^DRIFT-\d+$ and ^VIOLATION-\d+$. A finding whose ID does not match
+	// is renumbered with the next free DRIFT-NNN or VIOLATION-NNN ID.
+	DriftIDPattern     *regexp.Regexp
+	ViolationIDPattern *regexp.Regexp
+	// MinConfidence ("HIGH", "MEDIUM", "LOW") lowers the severity of model
+	// drift findings and violations one step when all of their evidence is
+	// below it; findings with no evidence count as LOW. It runs after
+	// strict-mode escalation and before rules and scoring. Empty disables it.
+	MinConfidence string
+	// Rules are deterministic checks applied after the LLM stage. Their
+	// violations are added to the report before scoring.
+	Rules []RuleConfig
+	// Baseline, if set, is a baseline file of accepted findings. Matching
+	// drift findings and violations, including rule violations, are marked
+	// suppressed and left out of the score and verdict.
+	Baseline string
+	// SeverityThreshold ("INFO", "WARN", "CRITICAL") drops lower-severity
+	// findings from the report. Counts, score, and verdict are computed
+	// before filtering.
+	SeverityThreshold string
+	// ScoreWeights overrides the scoring weights; nil uses the defaults.
+	// Unmet weighted spec items only cost points when Unmet is set, as it is
+	// in DefaultScoreWeights.
+	ScoreWeights *ScoreWeights
+	// KeywordWeights weighs spec items without a "[weight:N]" tag by their
+	// RFC 2119 keywords, so that an unmet MUST item costs points; see
+	// spec.Weight. Without it, only tagged items weigh more than the
+	// default.
+	KeywordWeights bool
+
+	// Mode is "full" (the default) or "summary". Summary mode is a cheap
+	// pre-check: the model returns a short rationale and its three most
+	// severe findings, coverage is left empty, and RequireTests and
+	// Traceability have no effect.
+	Mode string
+
+	// MaxTokens defaults to 4096, or 1024 in summary mode. Temperature is
+	// used as given.
+	MaxTokens   int
+	Temperature float64
+	// MaxRetries and RetryBaseDelay control retries of transient provider
+	// errors; zero values mean no retries and no delay.
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+	// Timeout, if positive, bounds the whole LLM stage: the initial call,
+	// retries, the repair call, and every partition when chunking. Zero
+	// means no limit beyond ctx.
+	Timeout time.Duration
+	// CacheDir, if set, enables the response cache in that directory. The
+	// code index is cached there too, so later runs re-scan only new and
+	// changed files.
+	CacheDir string
+	// ChunkByDir analyzes the code one top-level directory per LLM call
+	// when the index has more than ChunkThreshold symbols, then merges the
+	// results.
+	ChunkByDir     bool
+	ChunkThreshold int
+	// MaxIndexBytes caps the code inventory sent to the model. Zero derives
+	// the limit from Model; see DefaultMaxIndexBytes.
+	MaxIndexBytes int
+	// MaxManifestBytes caps the text of each dependency manifest in the
+	// inventory; a longer one is cut with a notice giving its full size.
+	// Zero uses codeindex.DefaultManifestBytes.
+	MaxManifestBytes int
+	// MaxPromptTokens, if positive, caps the estimated size of each prompt,
+	// system and user combined; a larger prompt is ErrInvalidInput before
+	// the provider is called. The estimate uses llm.TokenizerFor. Whatever
+	// the cap, a warning is printed to stderr when a prompt nears the
+	// model's context window (see llm.ContextWindow).
+	MaxPromptTokens int
+
+	// IgnorePatterns excludes paths from the code index in addition to the
+	// built-in ignore list; see codeindex.BuildOptions.IgnorePatterns.
+	IgnorePatterns []string
+	// Include and Exclude scope the code index to matching paths; see
+	// codeindex.BuildOptions. An index left empty is ErrInvalidInput.
+	Include []string
+	Exclude []string
+	// PathPrefix, if set, is prepended to every indexed path, so that the
+	// evidence paths in the report are relative to a directory above
+	// CodeRoot, such as the repository root; see
+	// codeindex.BuildOptions.PathPrefix. An absolute prefix, or one starting
+	// with "..", is ErrInvalidInput.
+	PathPrefix string
+	// LangMap overrides the language of files matching a glob, as
+	// "glob=Language" entries, several to an entry if separated by commas;
+	// see codeindex.ParseLanguageOverrides and
+	// codeindex.BuildOptions.LanguageOverrides. A malformed entry is
+	// ErrInvalidInput.
+	LangMap []string
+	// Since, if set, is a git revision. Files changed since it are marked
+	// in the code index so the model can focus on new drift. When CodeRoot
+	// is not in a git work tree, a warning is printed to stderr and the
+	// files are left unmarked.
+	Since        string
+	UseGitignore bool
+	GoAST        bool
+	// Concurrency is the number of files scanned at once while building
+	// the code index. Zero uses GOMAXPROCS.
+	Concurrency int
+	// IncludeSnippets sends a few lines of source for each symbol, starting
+	// at its declaration, so the model can check behavior instead of
+	// inferring it from names. This sends code to the provider. Snippets
+	// get their own budget of half of MaxIndexBytes.
+	IncludeSnippets bool
+	// EmbedInventory stores the code inventory sent to the model, after any
+	// truncation, in the report's Meta.Inventory, so a saved report shows
+	// the file and symbol list it was produced from. With ChunkByDir the
+	// partitions' inventories follow one another, each under a
+	// "=== partition: dir ===" line. The inventory can be large.
+	EmbedInventory bool
+	// Offline skips the API key pre-flight check.
+	Offline bool
+	// Record, if set, writes every model response of the run to this file
+	// as a JSON transcript, with the prompts and settings that produced it
+	// and the reported model and token usage. It is written even when the
+	// response fails validation, so the failure can be replayed.
+	Record string
+	// Replay, if set, is a transcript written by Record. Its responses
+	// answer the run's provider calls in order, and are validated exactly
+	// like live ones. Replay implies Offline and replaces LLM, the fallback
+	// chain, and the response cache.
+	Replay string
+	// Debug dumps the assembled prompt to stderr.
+	Debug bool
+	// Stream, if non-nil, makes every provider call stream its response and
+	// receives the text as it arrives. The anthropic, openai, and azure
+	// providers stream; others write each response once it is complete.
+	// The report is the same as without streaming.
+	Stream io.Writer
+	// Logger, if non-nil, receives structured records of key run events:
+	// item counts and index size at info level, provider call duration and
+	// token usage, and the verdict. Progress steps are logged at debug.
+	Logger *slog.Logger
+	// Log, if non-nil and Logger is nil, receives the same records in
+	// slog's text format at info level.
+	Log io.Writer
+
+	// fallbacks holds the providers after the first in a Provider chain;
+	// normalize fills it in.
+	fallbacks []llm.ProviderConfig
+}
+
+// Run analyzes cfg.CodeRoot against the spec and plan files and returns the
+// assembled report. It never writes the report or exits the process.
+func Run(ctx context.Context, cfg RunConfig) (*Report, error) {
+	start := time.Now()
+
+	if err := normalize(&cfg); err != nil {
+		return nil, err
+	}
+	compiledRules, err := rules.Compile(cfg.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+	var base *baseline.File
+	if cfg.Baseline != "" {
+		b, err := baseline.Load(cfg.Baseline)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+		}
+		base = &b
+	}
+	var baseIndex *codeindex.Index
+	if cfg.BaselineIndex != "" {
+		idx, ok, err := codeindex.ReadCache(cfg.BaselineIndex)
+		if err == nil && !ok {
+			err = fmt.Errorf("baseline index %s does not exist", cfg.BaselineIndex)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+		}
+		baseIndex = &idx
+	}
+	if cfg.Replay != "" {
+		p, err := newReplayProvider(cfg.Replay)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+		}
+		cfg.LLM, cfg.fallbacks, cfg.Offline = p, nil, true
+	}
+	// Pre-flight API key check for every provider in the chain, skipped
+	// when offline. An injected provider replaces the first one, so its key
+	// is not needed.
+	if !cfg.Offline {
+		if err := checkAPIKeys(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	logger := runLogger(cfg)
+	in, err := prepare(ctx, cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.SaveIndex != "" {
+		if err := codeindex.WriteCache(cfg.SaveIndex, in.index); err != nil {
+			return nil, fmt.Errorf("save index: %w", err)
+		}
+	}
+	evidenceFilter, err := in.profile.EvidenceFilter()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+	if err := checkPromptSize(cfg, in, logger); err != nil {
+		return nil, err
+	}
+	logger.Debug("calling LLM", "provider", cfg.Provider, "model", in.opts.Model)
+	llmStart := time.Now()
+	llmCtx := ctx
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		llmCtx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+	partial, err := llm.Analyze(llmCtx, in.specItems, in.planItems, in.index, in.profile, in.opts)
+	if in.opts.Record != nil {
+		// Written even when the analysis failed, so a response that failed
+		// validation can be replayed.
+		if werr := in.opts.Record.WriteFile(cfg.Record); werr != nil {
+			if err == nil {
+				return nil, fmt.Errorf("record: %w", werr)
+			}
+			fmt.Fprintf(os.Stderr, "warning: record: %v\n", werr)
+		}
+	}
+	if err != nil {
+		if errors.Is(err, llm.ErrInvalidModelOutput) {
+			return nil, err
+		}
+		// Check the context rather than err: SDKs do not all wrap
+		// context.DeadlineExceeded. A deadline on the caller's ctx is not
+		// ours to report.
+		if cfg.Timeout > 0 && errors.Is(llmCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil, fmt.Errorf("%w: LLM analysis did not finish within the %s timeout: %w", ErrProvider, cfg.Timeout, err)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrProvider, err)
+	}
+	logger.Info("LLM analysis finished",
+		"provider", cfg.Provider,
+		"model", partial.Meta.Model,
+		"duration", time.Since(llmStart),
+		"prompt_tokens", partial.Meta.PromptTokens,
+		"completion_tokens", partial.Meta.CompletionTokens,
+		"total_tokens", partial.Meta.TotalTokens)
+
+	// The inventory comes from the prompts, never from the model.
+	partial.Meta.Inventory = ""
+	if in.opts.Inventory != nil {
+		partial.Meta.Inventory = in.opts.Inventory.String()
+	}
+
+	// Every parsed item must appear in the report, even if the model left it
+	// out. Summary mode reports no coverage at all.
+	if cfg.Mode != "summary" {
+		if ids := coverage.FillMissing(&partial.Coverage, in.specItems, in.planItems); len(ids) > 0 {
+			logger.Info("added UNCLEAR coverage for items the model did not evaluate", "ids", ids)
+		}
+	}
+
+	// Mark items that a TODO comment acknowledges as unimplemented.
+	if cfg.ScanTodos {
+		if ids := coverage.AcknowledgeTodos(&partial.Coverage, todoLocations(in.index)); len(ids) > 0 {
+			logger.Info("marked items acknowledged by TODO comments NOT_IMPLEMENTED", "ids", ids)
+		}
+	}
+
+	// Drop findings that cite paths the profile excludes.
+	if n := evidenceFilter.FilterFindings(partial); n > 0 {
+		logger.Info("profile dropped findings by evidence path", "profile", in.profile.Name, "count", n)
+	}
+
+	// Apply strict-mode severity escalation to drift findings.
+	if cfg.Strict {
+		for i, d := range partial.Drift {
+			partial.Drift[i] = drift.EscalateSeverity(d, true)
+		}
+	}
+
+	// Downgrade findings that rest only on weak evidence. This runs after
+	// strict escalation so that --strict cannot undo it.
+	if cfg.MinConfidence != "" {
+		minConf := schema.Confidence(cfg.MinConfidence)
+		n := 0
+		for i, d := range partial.Drift {
+			if d.Severity != schema.SeverityInfo && drift.WeakEvidence(d.Evidence, minConf) {
+				partial.Drift[i].Severity = drift.DowngradeSeverity(d.Severity)
+				n++
+			}
+		}
+		for i, v := range partial.Violations {
+			if v.Severity != schema.SeverityInfo && drift.WeakEvidence(v.Evidence, minConf) {
+				partial.Violations[i].Severity = drift.DowngradeSeverity(v.Severity)
+				n++
+			}
+		}
+		if n > 0 {
+			logger.Info("downgraded findings with weak evidence", "min_confidence", cfg.MinConfidence, "count", n)
+		}
+	}
+
+	// Downgrade implemented items that cite no evidence.
+	if cfg.RequireEvidence {
+		if ids := coverage.RequireEvidence(&partial.Coverage); len(ids) > 0 {
+			logger.Info("downgraded items without evidence to UNCLEAR", "ids", ids)
+		}
+	}
+
+	// Downgrade implemented but untested spec items.
+	if cfg.RequireTests {
+		testFiles := make(map[string]bool, len(in.index.Tests))
+		for _, t := range in.index.Tests {
+			testFiles[t.Path] = true
+		}
+		exempt := make(map[string]bool)
+		for _, item := range in.specItems {
+			if coverage.ExemptFromTests(item.Text) {
+				exempt[item.ID] = true
+			}
+		}
+		if ids := coverage.RequireTests(partial.Coverage.Spec, testFiles, exempt); len(ids) > 0 {
+			logger.Info("downgraded untested spec items to PARTIAL", "ids", ids)
+		}
+	}
+
+	// Count incomplete spec items as findings.
+	if cfg.PartialAsWarn {
+		if n := gaps.Apply(partial, specLocations(in.specItems)); n > 0 {
+			logger.Info("partial-as-warn added drift findings", "count", n)
+		}
+	}
+
+	// Flag plan steps implemented ahead of the steps they depend on.
+	if cfg.CheckPlanOrder {
+		if n := ordering.Apply(partial, plan.Dependencies(in.planItems)); n > 0 {
+			logger.Info("plan order check added drift findings", "count", n)
+		}
+	}
+
+	// Flag exported symbols added since the baseline index without spec
+	// backing.
+	if baseIndex != nil && cfg.Mode != "summary" {
+		if n := apisurface.Apply(partial, *baseIndex, in.index); n > 0 {
+			logger.Info("new exported symbols without spec backing", "count", n)
+		}
+	}
+
+	// Add violations from deterministic rules.
+	if n := rules.ApplyAll(compiledRules, partial, in.index); n > 0 {
+		logger.Info("rules added violations", "count", n)
+	}
+
+	// Suppress findings accepted by the baseline. Only the baseline may
+	// suppress a finding, so any flag the model set is cleared first.
+	for i := range partial.Drift {
+		partial.Drift[i].Suppressed = false
+	}
+	for i := range partial.Violations {
+		partial.Violations[i].Suppressed = false
+	}
+	suppressed := 0
+	if base != nil {
+		suppressed = base.Apply(partial)
+		logger.Info("suppressed baseline findings", "baseline", cfg.Baseline, "count", suppressed)
+	}
+
+	// Carry each spec item's weight into its coverage entry; the default
+	// weight is left out.
+	for i, e := range partial.Coverage.Spec {
+		partial.Coverage.Spec[i].Weight = 0
+		if w := in.specWeights[e.ID]; w > spec.DefaultWeight {
+			partial.Coverage.Spec[i].Weight = w
+		}
+	}
+
+	// Record which spec or plan file each reference points into, so that
+	// renderers can locate it when several files were merged.
+	setReferenceSources(partial, in.specItems, in.planItems)
+
+	// Count, score, and determine verdict on all unsuppressed findings.
+	// Severity filtering below removes findings from the report only and
+	// does not affect these computed values. Unmet weighted spec items also
+	// cost points; see verdict.CoveragePenalty.
+	crit, warn, info := verdict.CountSeverities(partial)
+	score := verdict.ComputeReportScore(crit, warn, info, partial.Coverage.Spec, *cfg.ScoreWeights)
+	verd := verdict.DetermineVerdict(partial)
+	logger.Info("verdict",
+		"verdict", verd,
+		"score", score,
+		"critical", crit,
+		"warn", warn,
+		"info", info,
+		"duration", time.Since(start))
+
+	filteredDrift := partial.Drift
+	filteredViolations := partial.Violations
+	if cfg.SeverityThreshold != "" {
+		thresh := schema.Severity(cfg.SeverityThreshold)
+		filteredDrift = filterDrift(partial.Drift, thresh)
+		filteredViolations = filterViolations(partial.Violations, thresh)
+	}
+
+	return &schema.Report{
+		Tool:    "realitycheck",
+		Version: Version,
+		Input: schema.Input{
+			SpecFile:        cfg.SpecFiles[0],
+			PlanFile:        cfg.PlanFiles[0],
+			SpecFiles:       cfg.SpecFiles,
+			PlanFiles:       cfg.PlanFiles,
+			CodeRoot:        cfg.CodeRoot,
+			PathPrefix:      in.index.PathPrefix,
+			Profile:         in.profile.Name,
+			Strict:          cfg.Strict,
+			RequireTests:    cfg.RequireTests,
+			RequireEvidence: cfg.RequireEvidence,
+			ScanTodos:       cfg.ScanTodos,
+			CheckPlanOrder:  cfg.CheckPlanOrder,
+			PartialAsWarn:   cfg.PartialAsWarn,
+			MinConfidence:   cfg.MinConfidence,
+			Mode:            cfg.Mode,
+			Since:           in.index.ChangedSince,
+		},
+		Summary: schema.Summary{
+			Verdict:       verd,
+			Score:         score,
+			CriticalCount: crit,
+			WarnCount:     warn,
+			InfoCount:     info,
+			UnmetSpecIDs:  coverage.UnmetSpecIDs(partial.Coverage.Spec),
+			UnmetPlanIDs:  coverage.UnmetPlanIDs(partial.Coverage.Plan),
+			IntentGaps:    coverage.FindIntentGaps(partial.Coverage.Spec, partial.Coverage.Plan, partial.Traceability),
+
+			SpecCoveragePct: coverage.Percent(coverage.SummarizeSpecCoverage(partial.Coverage.Spec)),
+			PlanCoveragePct: coverage.Percent(coverage.SummarizePlanCoverage(partial.Coverage.Plan)),
+
+			SuppressedCount: suppressed,
+			Rationale:       partial.Rationale,
+		},
+		Coverage:     partial.Coverage,
+		Drift:        filteredDrift,
+		Violations:   filteredViolations,
+		Traceability: partial.Traceability,
+		IndexStats:   indexStats(in.index, in.opts),
+		Meta:         partial.Meta,
+	}, nil
+}
+
+// specLocations maps each spec item ID to its location, such as
+// "SPEC.md:12-14".
+func specLocations(items []spec.Item) map[string]string {
+	out := make(map[string]string, len(items))
+	for _, it := range items {
+		if it.Source == "" {
+			continue
+		}
+		loc := fmt.Sprintf("%s:%d", it.Source, it.LineStart)
+		if it.LineEnd > it.LineStart {
+			loc += fmt.Sprintf("-%d", it.LineEnd)
+		}
+		out[it.ID] = loc
+	}
+	return out
+}
+
+// setReferenceSources sets the Source of each coverage entry's reference to
+// the file its item came from. A violation's spec reference gets the file
+// of the spec item containing its first line; see referenceSource.
+func setReferenceSources(r *schema.PartialReport, specItems []spec.Item, planItems []plan.Item) {
+	sources := make(map[string]string, len(specItems)+len(planItems))
+	for _, it := range specItems {
+		sources[it.ID] = it.Source
+	}
+	for _, it := range planItems {
+		sources[it.ID] = it.Source
+	}
+	for i, e := range r.Coverage.Spec {
+		r.Coverage.Spec[i].SpecReference.Source = sources[e.ID]
+	}
+	for i, e := range r.Coverage.Plan {
+		r.Coverage.Plan[i].PlanReference.Source = sources[e.ID]
+	}
+	for i, v := range r.Violations {
+		r.Violations[i].SpecReference.Source = referenceSource(specItems, v.SpecReference)
+	}
+}
+
+// referenceSource returns the file that ref, a reference the model made
+// without naming a file, points into: the only spec file, or else the file
+// of the one spec item whose lines contain ref.LineStart. It returns "" when
+// items from several files contain that line.
+func referenceSource(items []spec.Item, ref schema.Reference) string {
+	single := ""
+	for i, it := range items {
+		if i == 0 {
+			single = it.Source
+		} else if it.Source != single {
+			single = ""
+			break
+		}
+	}
+	if single != "" {
+		return single
+	}
+	source := ""
+	for _, it := range items {
+		if ref.LineStart < it.LineStart || ref.LineStart > it.LineEnd {
+			continue
+		}
+		if source != "" && source != it.Source {
+			return ""
+		}
+		source = it.Source
+	}
+	return source
+}
+
+// todoLocations maps each item ID named by a TODO comment in index to the
+// comments naming it.
+func todoLocations(index codeindex.Index) map[string][]coverage.Todo {
+	out := make(map[string][]coverage.Todo)
+	for _, t := range index.Todos {
+		for _, id := range t.IDs {
+			out[id] = append(out[id], coverage.Todo{Path: t.Path, Line: t.Line})
+		}
+	}
+	return out
+}
+
+// indexOptions maps the code index fields of cfg onto codeindex options.
+func indexOptions(cfg RunConfig) (codeindex.BuildOptions, error) {
+	langs, err := codeindex.ParseLanguageOverrides(cfg.LangMap)
+	if err != nil {
+		return codeindex.BuildOptions{}, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+	opts := codeindex.BuildOptions{
+		IgnorePatterns:    cfg.IgnorePatterns,
+		Include:           cfg.Include,
+		Exclude:           cfg.Exclude,
+		RespectGitignore:  cfg.UseGitignore,
+		UseAST:            cfg.GoAST,
+		ScanTodos:         cfg.ScanTodos,
+		Concurrency:       cfg.Concurrency,
+		LanguageOverrides: langs,
+		PathPrefix:        cfg.PathPrefix,
+	}
+	if cfg.IncludeSnippets {
+		opts.SnippetLines = codeindex.DefaultSnippetLines
+	}
+	return opts, nil
+}
+
+// buildIndex builds the code index for cfg.CodeRoot. With a cache
+// directory, the index from the previous run is loaded from it so that only
+// new and changed files are re-scanned (see codeindex.BuildIncremental), and
+// the new index is stored for the next run. An unreadable or unwritable
+// cache entry only costs a full scan, so it is reported as a warning.
+func buildIndex(cfg RunConfig, opts codeindex.BuildOptions, logger *slog.Logger) (codeindex.Index, error) {
+	if cfg.CacheDir == "" {
+		return codeindex.BuildWithOptions(cfg.CodeRoot, opts)
+	}
+	path := codeindex.CacheFile(cfg.CacheDir, cfg.CodeRoot, Version)
+	prev, ok, err := codeindex.ReadCache(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	var idx codeindex.Index
+	if ok {
+		logger.Debug("reusing cached code index", "path", path)
+		idx, err = codeindex.BuildIncremental(cfg.CodeRoot, opts, prev)
+	} else {
+		idx, err = codeindex.BuildWithOptions(cfg.CodeRoot, opts)
+	}
+	if err != nil {
+		return codeindex.Index{}, err
+	}
+	if err := codeindex.WriteCache(path, idx); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	return idx, nil
+}
+
+// checkPromptSize estimates the tokens of each prompt Run will send and logs
+// the largest and the total. It warns on stderr when the largest fills more
+// than 90% of the model's context window, and fails with ErrInvalidInput
+// when it exceeds cfg.MaxPromptTokens.
+func checkPromptSize(cfg RunConfig, in *inputs, logger *slog.Logger) error {
+	tok := llm.TokenizerFor(in.opts.Provider)
+	largest, total := 0, 0
+	for _, p := range llm.BuildPrompts(in.specItems, in.planItems, in.index, in.profile, in.opts) {
+		n := tok.CountTokens(p.System) + tok.CountTokens(p.User)
+		largest = max(largest, n)
+		total += n
+	}
+	logger.Info("estimated prompt tokens", "largest", largest, "total", total)
+	if window := llm.ContextWindow(in.opts.Model); window > 0 && largest > window*9/10 {
+		fmt.Fprintf(os.Stderr, "warning: prompt of ~%d tokens is near the %d-token context window of %s\n", largest, window, in.opts.Model)
+	}
+	if cfg.MaxPromptTokens > 0 && largest > cfg.MaxPromptTokens {
+		return fmt.Errorf("%w: prompt of ~%d tokens exceeds the limit of %d tokens", ErrInvalidInput, largest, cfg.MaxPromptTokens)
+	}
+	return nil
+}
+
+// indexStats summarizes index for the report. When the run is chunked, the
+// inventory counts as truncated if any directory's summary was.
+func indexStats(index codeindex.Index, opts llm.Options) *schema.IndexStats {
+	st := index.Stats(opts.MaxIndexBytes)
+	if opts.ChunkByDir && len(index.Symbols) > opts.ChunkThreshold {
+		st.Truncated = false
+		for _, part := range index.SplitByTopDir() {
+			if part.Index.Stats(opts.MaxIndexBytes).Truncated {
+				st.Truncated = true
+				break
+			}
+		}
+	}
+	return &schema.IndexStats{
+		Files:           st.Files,
+		FilesByLanguage: st.FilesByLanguage,
+		Symbols:         st.Symbols,
+		Tests:           st.Tests,
+		SummaryBytes:    st.SummaryBytes,
+		Truncated:       st.Truncated,
+	}
+}
+
+// Prompts returns the prompts Run would send for cfg without calling a
+// provider: one, or one per top-level directory when cfg.ChunkByDir applies.
+// No API key is needed. Inputs are validated and parsed exactly as in Run.
+func Prompts(cfg RunConfig) ([]Prompt, error) {
+	if err := normalize(&cfg); err != nil {
+		return nil, err
+	}
+	if _, err := rules.Compile(cfg.Rules); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+	in, err := prepare(context.Background(), cfg, runLogger(cfg))
+	if err != nil {
+		return nil, err
+	}
+	return llm.BuildPrompts(in.specItems, in.planItems, in.index, in.profile, in.opts), nil
+}
+
+// ValidateResponse validates raw, a saved model response, as Run would: the
+// code index of cfg.CodeRoot (default ".") is built with the same options,
+// and evidence paths and IDs are checked with the same settings. Only the
+// code index, CodeRoot, and ID pattern fields of cfg are used. repair
+// reports whether Run would ask the model for a repaired response; errs
+// lists every validation error, including the non-fatal notes.
+func ValidateResponse(raw string, cfg RunConfig) (errs []ValidationError, repair bool, err error) {
+	if cfg.CodeRoot == "" {
+		cfg.CodeRoot = "."
+	}
+	buildOpts, err := indexOptions(cfg)
+	if err != nil {
+		return nil, false, err
+	}
+	idx, err := codeindex.BuildWithOptions(cfg.CodeRoot, buildOpts)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: build code index: %w", ErrInvalidInput, err)
+	}
+	report, errs := llm.ValidateResponseWithOptions(raw, idx, llm.Options{
+		CodeRoot:           cfg.CodeRoot,
+		DriftIDPattern:     cfg.DriftIDPattern,
+		ViolationIDPattern: cfg.ViolationIDPattern,
+	})
+	return errs, report == nil || llm.NeedsRepair(errs), nil
+}
+
+// runLogger returns cfg.Logger, a text logger on cfg.Log, or a logger that
+// discards everything.
+func runLogger(cfg RunConfig) *slog.Logger {
+	switch {
+	case cfg.Logger != nil:
+		return cfg.Logger
+	case cfg.Log != nil:
+		return slog.New(slog.NewTextHandler(cfg.Log, nil))
+	default:
+		return slog.New(slog.DiscardHandler)
+	}
+}
+
+// inputs is everything llm.Analyze needs, as prepared from a RunConfig.
+type inputs struct {
+	specItems []spec.Item
+	// specWeights is the weight of each spec item, keyed by ID.
+	specWeights map[string]int
+	planItems   []plan.Item
+	index       codeindex.Index
+	profile     profile.Profile
+	opts        llm.Options
+}
+
+// prepare parses the spec and plan, indexes the code, loads the profile, and
+// builds the LLM options. cfg must already be normalized.
+func prepare(ctx context.Context, cfg RunConfig, logger *slog.Logger) (*inputs, error) {
+
+	// Parse SPEC.md. Multiple files are concatenated with IDs continuing
+	// across files.
+	logger.Debug("parsing spec", "files", cfg.SpecFiles)
+	specItems, specWeights, err := spec.ParseFilesWeighted(cfg.SpecFiles, cfg.KeywordWeights)
+	if err != nil {
+		return nil, fmt.Errorf("%w: parse spec: %w", ErrInvalidInput, err)
+	}
+	logger.Info("parsed spec", "items", len(specItems))
+
+	// Parse PLAN.md.
+	logger.Debug("parsing plan", "files", cfg.PlanFiles)
+	planItems, err := plan.ParseFiles(cfg.PlanFiles)
+	if err != nil {
+		return nil, fmt.Errorf("%w: parse plan: %w", ErrInvalidInput, err)
+	}
+	logger.Info("parsed plan", "items", len(planItems))
+
+	// Build code index.
+	logger.Debug("building code index", "root", cfg.CodeRoot)
+	buildOpts, err := indexOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := buildIndex(cfg, buildOpts, logger)
+	if err != nil {
+		return nil, fmt.Errorf("%w: build code index: %w", ErrInvalidInput, err)
+	}
+	idx.ManifestBytes = cfg.MaxManifestBytes
+	logger.Info("indexed code",
+		"files", len(idx.Files),
+		"symbols", len(idx.Symbols),
+		"tests", len(idx.Tests))
+
+	// Mark files changed since cfg.Since.
+	if cfg.Since != "" {
+		changed, err := gitdiff.ChangedFiles(ctx, cfg.CodeRoot, cfg.Since)
+		switch {
+		case errors.Is(err, gitdiff.ErrNotRepository):
+			fmt.Fprintf(os.Stderr, "warning: %v; changed files will not be marked\n", err)
+		case err != nil:
+			return nil, fmt.Errorf("%w: changed files since %s: %w", ErrInvalidInput, cfg.Since, err)
+		default:
+			logger.Info("marked changed files", "since", cfg.Since, "files", idx.MarkChanged(cfg.Since, changed))
+		}
+	}
+
+	// Load profile. A profile file takes precedence over a profile name.
+	logger.Debug("loading profile")
+	var prof profile.Profile
+	if cfg.ProfileFile != "" {
+		prof, err = profile.LoadFile(cfg.ProfileFile)
+	} else {
+		prof, err = profile.Load(cfg.ProfileName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+
+	// Debug causes the prompt to be dumped to stderr inside llm.Analyze.
+	opts := llm.Options{
+		Provider:     cfg.Provider,
+		CodeRoot:     cfg.CodeRoot,
+		Strict:       cfg.Strict,
+		RequireTests: cfg.RequireTests,
+		Traceability: cfg.Traceability,
+		Explain:      cfg.Explain,
+		SummaryOnly:  cfg.Mode == "summary",
+		MaxTokens:    cfg.MaxTokens,
+		Temperature:  cfg.Temperature,
+		Model:        cfg.Model,
+		Debug:        cfg.Debug,
+
+		MaxRetries:     cfg.MaxRetries,
+		RetryBaseDelay: cfg.RetryBaseDelay,
+
+		Client:    cfg.LLM,
+		Fallbacks: cfg.fallbacks,
+		Stream:    cfg.Stream,
+
+		DriftIDPattern:     cfg.DriftIDPattern,
+		ViolationIDPattern: cfg.ViolationIDPattern,
+
+		ChunkByDir:     cfg.ChunkByDir,
+		ChunkThreshold: cfg.ChunkThreshold,
+		MaxIndexBytes:  cfg.MaxIndexBytes,
+	}
+	if cfg.IncludeSnippets {
+		opts.SnippetBytes = cfg.MaxIndexBytes / 2
+	}
+	if cfg.CacheDir != "" && cfg.Replay == "" {
+		opts.CacheDir = cfg.CacheDir
+		opts.CacheVersion = Version
+	}
+	if cfg.EmbedInventory {
+		opts.Inventory = new(strings.Builder)
+	}
+	if cfg.Record != "" {
+		opts.Record = &llm.Transcript{Version: Version}
+	}
+
+	return &inputs{specItems, specWeights, planItems, idx, prof, opts}, nil
+}
+
+// normalize validates cfg and fills in defaults in place.
+func normalize(cfg *RunConfig) error {
+	if len(cfg.SpecFiles) == 0 {
+		return fmt.Errorf("%w: at least one spec file is required", ErrInvalidInput)
+	}
+	if len(cfg.PlanFiles) == 0 {
+		return fmt.Errorf("%w: at least one plan file is required", ErrInvalidInput)
+	}
+	for _, path := range cfg.SpecFiles {
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("%w: spec file %q not found: %w", ErrInvalidInput, path, err)
+		}
+	}
+	for _, path := range cfg.PlanFiles {
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("%w: plan file %q not found: %w", ErrInvalidInput, path, err)
+		}
+	}
+	if cfg.CodeRoot == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("%w: cannot determine cwd: %w", ErrInvalidInput, err)
+		}
+		cfg.CodeRoot = cwd
+	}
+	if cfg.ProfileName == "" {
+		cfg.ProfileName = "general"
+	}
+	chain, err := parseProviders(cfg.Provider, cfg.Model)
+	if err != nil {
+		return err
+	}
+	cfg.Provider, cfg.Model = chain[0].Name, chain[0].Model
+	cfg.fallbacks = chain[1:]
+	switch strings.ToLower(cfg.Mode) {
+	case "", "full":
+		cfg.Mode = ""
+	case "summary":
+		cfg.Mode = "summary"
+	default:
+		return fmt.Errorf("%w: mode %q is not valid (full|summary)", ErrInvalidInput, cfg.Mode)
+	}
+	if cfg.MaxTokens == 0 {
+		cfg.MaxTokens = 4096
+		if cfg.Mode == "summary" {
+			cfg.MaxTokens = 1024
+		}
+	}
+	if cfg.MaxRetries < 0 {
+		return fmt.Errorf("%w: max retries must be >= 0, got %d", ErrInvalidInput, cfg.MaxRetries)
+	}
+	if cfg.Timeout < 0 {
+		return fmt.Errorf("%w: timeout must be >= 0, got %s", ErrInvalidInput, cfg.Timeout)
+	}
+	if cfg.ChunkThreshold < 0 {
+		return fmt.Errorf("%w: chunk threshold must be >= 0, got %d", ErrInvalidInput, cfg.ChunkThreshold)
+	}
+	switch {
+	case cfg.MaxIndexBytes < 0:
+		return fmt.Errorf("%w: max index bytes must be >= 0, got %d", ErrInvalidInput, cfg.MaxIndexBytes)
+	case cfg.MaxIndexBytes == 0:
+		cfg.MaxIndexBytes = DefaultMaxIndexBytes(cfg.Model)
+	}
+	if cfg.MaxManifestBytes < 0 {
+		return fmt.Errorf("%w: max manifest bytes must be >= 0, got %d", ErrInvalidInput, cfg.MaxManifestBytes)
+	}
+	if cfg.MaxPromptTokens < 0 {
+		return fmt.Errorf("%w: max prompt tokens must be >= 0, got %d", ErrInvalidInput, cfg.MaxPromptTokens)
+	}
+	if cfg.ScoreWeights == nil {
+		w := verdict.DefaultScoreWeights
+		cfg.ScoreWeights = &w
+	}
+	if err := cfg.ScoreWeights.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+	cfg.MinConfidence = strings.ToUpper(cfg.MinConfidence)
+	switch schema.Confidence(cfg.MinConfidence) {
+	case "", schema.ConfidenceHigh, schema.ConfidenceMedium, schema.ConfidenceLow:
+		// valid
+	default:
+		return fmt.Errorf("%w: min confidence %q is not valid (HIGH|MEDIUM|LOW)", ErrInvalidInput, cfg.MinConfidence)
+	}
+	cfg.SeverityThreshold = strings.ToUpper(cfg.SeverityThreshold)
+	switch schema.Severity(cfg.SeverityThreshold) {
+	case "", schema.SeverityInfo, schema.SeverityWarn, schema.SeverityCritical:
+		// valid
+	default:
+		return fmt.Errorf("%w: severity threshold %q is not valid (INFO|WARN|CRITICAL)", ErrInvalidInput, cfg.SeverityThreshold)
+	}
+	return nil
+}
+
+// newReplayProvider returns a provider that replays the transcript at path;
+// see RunConfig.Replay.
+func newReplayProvider(path string) (Provider, error) {
+	t, err := llm.ReadTranscript(path)
+	if err != nil {
+		return nil, err
+	}
+	return llm.NewReplayProvider(t), nil
+}
+
+// checkAPIKeys reports ErrMissingAPIKey for the first provider in cfg's
+// chain whose API key environment variable is unset.
+func checkAPIKeys(cfg RunConfig) error {
+	providers := make([]string, 0, 1+len(cfg.fallbacks))
+	if cfg.LLM == nil {
+		providers = append(providers, cfg.Provider)
+	}
+	for _, f := range cfg.fallbacks {
+		providers = append(providers, f.Name)
+	}
+	for _, p := range providers {
+		if envVar := APIKeyEnvVar(p); os.Getenv(envVar) == "" {
+			return fmt.Errorf("%w: %s is not set", ErrMissingAPIKey, envVar)
+		}
+	}
+	return nil
+}
+
+// parseProviders parses a comma-separated provider chain. Each entry is a
+// provider name with an optional ":model" suffix; model is used for the
+// first entry when it has no suffix, and the others get DefaultModel.
+func parseProviders(list, model string) ([]llm.ProviderConfig, error) {
+	var chain []llm.ProviderConfig
+	for i, entry := range strings.Split(list, ",") {
+		name, m, _ := strings.Cut(strings.TrimSpace(entry), ":")
+		name = strings.ToLower(strings.TrimSpace(name))
+		m = strings.TrimSpace(m)
+		switch name {
+		case "":
+			if i > 0 {
+				return nil, fmt.Errorf("%w: provider list %q has an empty entry", ErrInvalidInput, list)
+			}
+			name = "anthropic"
+		case "anthropic", "openai", "azure", "google":
+			// valid
+		default:
+			return nil, fmt.Errorf("%w: provider %q is not valid (anthropic|openai|azure|google)", ErrInvalidInput, name)
+		}
+		if m == "" && i == 0 {
+			m = model
+		}
+		if m == "" {
+			m = DefaultModel(name)
+		}
+		if m == "" {
+			return nil, fmt.Errorf("%w: model is required for provider %s (the deployment name)", ErrInvalidInput, name)
+		}
+		chain = append(chain, llm.ProviderConfig{Name: name, Model: m})
+	}
+	return chain, nil
+}
+
+// APIKeyEnvVar returns the environment variable holding the API key for
+// provider.
+func APIKeyEnvVar(provider string) string {
+	switch strings.ToLower(provider) {
+	case "openai":
+		return "OPENAI_API_KEY"
+	case "azure":
+		return "AZURE_OPENAI_API_KEY"
+	case "google":
+		return "GOOGLE_API_KEY"
+	default:
+		return "ANTHROPIC_API_KEY"
+	}
+}
+
+// DefaultModel returns the default model ID for provider. It returns "" for
+// azure, whose models are named by deployment.
+func DefaultModel(provider string) string {
+	switch strings.ToLower(provider) {
+	case "openai":
+		return "gpt-4o"
+	case "azure":
+		return ""
+	case "google":
+		return "gemini-2.5-flash"
+	default:
+		return "claude-opus-4-6"
+	}
+}
+
+// DefaultMaxIndexBytes returns the code inventory byte limit for model: one
+// byte per token of the model's context window (see llm.ContextWindow), i.e.
+// roughly a quarter of the window, leaving room for the spec, plan, and
+// response. Unknown models get codeindex.DefaultSummaryBytes.
+func DefaultMaxIndexBytes(model string) int {
+	if window := llm.ContextWindow(model); window > 0 {
+		return window
+	}
+	return codeindex.DefaultSummaryBytes
+}
+
+// severityOrdinal returns a numeric ordering for severity comparison.
+func severityOrdinal(s schema.Severity) int {
+	switch s {
+	case schema.SeverityInfo:
+		return 0
+	case schema.SeverityWarn:
+		return 1
+	case schema.SeverityCritical:
+		return 2
+	default:
+		return -1
+	}
+}
+
+// filterDrift returns a new slice containing only findings at or above threshold.
+func filterDrift(findings []schema.DriftFinding, threshold schema.Severity) []schema.DriftFinding {
+	thresh := severityOrdinal(threshold)
+	out := make([]schema.DriftFinding, 0, len(findings))
+	for _, d := range findings {
+		if severityOrdinal(d.Severity) >= thresh {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// filterViolations returns a new slice containing only violations at or above threshold.
+func filterViolations(violations []schema.Violation, threshold schema.Severity) []schema.Violation {
+	thresh := severityOrdinal(threshold)
+	out := make([]schema.Violation, 0, len(violations))
+	for _, v := range violations {
+		if severityOrdinal(v.Severity) >= thresh {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,927 @@
+package realitycheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dshills/realitycheck/internal/baseline"
+	"github.com/dshills/realitycheck/internal/codeindex"
+	"github.com/dshills/realitycheck/internal/coverage"
+	"github.com/dshills/realitycheck/internal/llm"
+	"github.com/dshills/realitycheck/internal/schema"
+)
+
+// stubProvider returns a fixed response, or err if set.
+type stubProvider struct {
+	text      string
+	err       error
+	calls     int
+	maxTokens int // from the last call
+}
+
+func (p *stubProvider) Complete(_ context.Context, _, _ string, maxTokens int, _ float64) (CompletionResult, error) {
+	p.calls++
+	p.maxTokens = maxTokens
+	if p.err != nil {
+		return CompletionResult{}, p.err
+	}
+	return CompletionResult{Text: p.text, Usage: Usage{PromptTokens: 100, CompletionTokens: 20}}, nil
+}
+
+// hangingProvider blocks until its context is done.
+type hangingProvider struct{}
+
+func (hangingProvider) Complete(ctx context.Context, _, _ string, _ int, _ float64) (CompletionResult, error) {
+	<-ctx.Done()
+	return CompletionResult{}, ctx.Err()
+}
+
+// driftResponse has one WARN and one INFO drift finding citing store.go.
+const driftResponse = `{
+  "coverage": {
+    "spec": [{"id":"SPEC-001","status":"IMPLEMENTED","spec_reference":{"line_start":5,"line_end":5},"evidence":[{"path":"store.go","symbol":"Get","confidence":"HIGH"}]}],
+    "plan": []
+  },
+  "drift": [
+    {"id":"DRIFT-001","severity":"WARN","description":"Undocumented TTL eviction","evidence":[{"path":"store.go"}],"why_unjustified":"Not in spec.","impact":"","recommendation":""},
+    {"id":"DRIFT-002","severity":"INFO","description":"Extra debug logging","evidence":[{"path":"store.go"}],"why_unjustified":"Not in spec.","impact":"","recommendation":""}
+  ],
+  "violations": [],
+  "meta": {"model": "stub", "temperature": 0.2}
+}`
+
+func fixtureConfig(p Provider) RunConfig {
+	return RunConfig{
+		SpecFiles:   []string{"testdata/aligned/SPEC.md"},
+		PlanFiles:   []string{"testdata/aligned/PLAN.md"},
+		CodeRoot:    "testdata/aligned",
+		LLM:         p,
+		Temperature: 0.2,
+	}
+}
+
+func TestRun_InjectedProvider(t *testing.T) {
+	// An injected provider needs no API key.
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	p := &stubProvider{text: driftResponse}
+	var log bytes.Buffer
+	cfg := fixtureConfig(p)
+	cfg.Log = &log
+
+	report, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if p.calls != 1 {
+		t.Errorf("provider calls = %d, want 1", p.calls)
+	}
+	if report.Tool != "realitycheck" || report.Version != Version {
+		t.Errorf("tool/version = %q/%q", report.Tool, report.Version)
+	}
+	if report.Summary.Verdict != schema.VerdictDriftDetected {
+		t.Errorf("verdict = %s, want DRIFT_DETECTED", report.Summary.Verdict)
+	}
+	if report.Summary.Score != 91 { // 100 - 7 - 2
+		t.Errorf("score = %d, want 91", report.Summary.Score)
+	}
+	if report.Input.Profile != "general" {
+		t.Errorf("profile = %q, want default general", report.Input.Profile)
+	}
+	if report.Meta.TotalTokens != 120 {
+		t.Errorf("total tokens = %d, want 120", report.Meta.TotalTokens)
+	}
+	if st := report.IndexStats; st == nil || st.Files == 0 || st.FilesByLanguage["Go"] == 0 || st.Truncated {
+		t.Errorf("index stats = %+v, want Go files and no truncation", st)
+	}
+	if log.Len() == 0 {
+		t.Error("expected an execution trace on Log")
+	}
+}
+
+func TestRun_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := fixtureConfig(&stubProvider{text: driftResponse})
+	cfg.Logger = slog.New(slog.NewJSONHandler(&buf, nil))
+
+	if _, err := Run(context.Background(), cfg); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	records := map[string]map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("log line %q: %v", line, err)
+		}
+		records[rec["msg"].(string)] = rec
+	}
+	if rec := records["parsed spec"]; rec == nil || rec["items"] == nil {
+		t.Errorf("missing parsed spec record with items: %v", rec)
+	}
+	if rec := records["LLM analysis finished"]; rec == nil || rec["total_tokens"] != float64(120) || rec["duration"] == nil {
+		t.Errorf("LLM record = %v, want duration and total_tokens 120", rec)
+	}
+	if rec := records["verdict"]; rec == nil || rec["verdict"] != string(schema.VerdictDriftDetected) || rec["score"] != float64(91) {
+		t.Errorf("verdict record = %v", rec)
+	}
+	if _, ok := records["calling LLM"]; ok {
+		t.Error("debug records should not be written at the default info level")
+	}
+}
+
+func TestRun_SeverityThresholdAndWeights(t *testing.T) {
+	cfg := fixtureConfig(&stubProvider{text: driftResponse})
+	cfg.SeverityThreshold = "warn"
+	cfg.ScoreWeights = &ScoreWeights{Critical: 20, Warn: 10, Info: 0}
+
+	report, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(report.Drift) != 1 || report.Drift[0].ID != "DRIFT-001" {
+		t.Errorf("drift after WARN threshold = %+v, want only DRIFT-001", report.Drift)
+	}
+	// Counts and score cover all findings, not just the filtered ones.
+	if report.Summary.InfoCount != 1 {
+		t.Errorf("info count = %d, want 1", report.Summary.InfoCount)
+	}
+	if report.Summary.Score != 90 {
+		t.Errorf("score = %d, want 90", report.Summary.Score)
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	cases := []struct {
+		name    string
+		mutate  func(*RunConfig)
+		wantErr error
+	}{
+		{"no spec", func(c *RunConfig) { c.SpecFiles = nil }, ErrInvalidInput},
+		{"missing plan file", func(c *RunConfig) { c.PlanFiles = []string{"testdata/nope.md"} }, ErrInvalidInput},
+		{"bad provider", func(c *RunConfig) { c.Provider = "mistral" }, ErrInvalidInput},
+		{"azure without deployment", func(c *RunConfig) { c.Provider = "azure" }, ErrInvalidInput},
+		{"azure fallback without deployment", func(c *RunConfig) { c.Provider = "anthropic,azure" }, ErrInvalidInput},
+		{"empty provider entry", func(c *RunConfig) { c.Provider = "anthropic,,openai" }, ErrInvalidInput},
+		{"fallback API key missing", func(c *RunConfig) { c.Provider = "anthropic,openai" }, ErrMissingAPIKey},
+		{"bad threshold", func(c *RunConfig) { c.SeverityThreshold = "LOUD" }, ErrInvalidInput},
+		{"bad min confidence", func(c *RunConfig) { c.MinConfidence = "SURE" }, ErrInvalidInput},
+		{"bad mode", func(c *RunConfig) { c.Mode = "quick" }, ErrInvalidInput},
+		{"negative timeout", func(c *RunConfig) { c.Timeout = -time.Second }, ErrInvalidInput},
+		{"negative max index bytes", func(c *RunConfig) { c.MaxIndexBytes = -1 }, ErrInvalidInput},
+		{"negative max manifest bytes", func(c *RunConfig) { c.MaxManifestBytes = -1 }, ErrInvalidInput},
+		{"negative max prompt tokens", func(c *RunConfig) { c.MaxPromptTokens = -1 }, ErrInvalidInput},
+		{"include matches nothing", func(c *RunConfig) { c.Include = []string{"nope/**"} }, ErrInvalidInput},
+		{"bad lang map", func(c *RunConfig) { c.LangMap = []string{"*.h"} }, ErrInvalidInput},
+		{"bad rule", func(c *RunConfig) { c.Rules = []RuleConfig{{Name: "r", Type: "magic"}} }, ErrInvalidInput},
+		{"negative weight", func(c *RunConfig) { c.ScoreWeights = &ScoreWeights{Warn: -1} }, ErrInvalidInput},
+		{"unknown profile", func(c *RunConfig) { c.ProfileName = "nope" }, ErrInvalidInput},
+		{"missing API key", func(c *RunConfig) { c.LLM = nil }, ErrMissingAPIKey},
+		{"provider failure", func(c *RunConfig) { c.LLM = &stubProvider{err: errors.New("boom")} }, ErrProvider},
+		{"invalid output", func(c *RunConfig) { c.LLM = &stubProvider{text: "not json"} }, ErrInvalidModelOutput},
+	}
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	t.Setenv("OPENAI_API_KEY", "")
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := fixtureConfig(&stubProvider{text: driftResponse})
+			c.mutate(&cfg)
+			report, err := Run(context.Background(), cfg)
+			if !errors.Is(err, c.wantErr) {
+				t.Errorf("error = %v, want %v", err, c.wantErr)
+			}
+			if report != nil {
+				t.Error("expected nil report on error")
+			}
+		})
+	}
+}
+
+func TestParseProviders(t *testing.T) {
+	got, err := parseProviders("Anthropic, openai:gpt-4.1 ,google", "claude-test")
+	if err != nil {
+		t.Fatalf("parseProviders: %v", err)
+	}
+	want := []llm.ProviderConfig{{Name: "anthropic", Model: "claude-test"}, {Name: "openai", Model: "gpt-4.1"}, {Name: "google", Model: "gemini-2.5-flash"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("chain = %v, want %v", got, want)
+	}
+	// An explicit model on the first entry wins over the Model setting.
+	got, err = parseProviders("azure:my-deploy", "ignored")
+	if err != nil || got[0].Model != "my-deploy" {
+		t.Errorf("chain = %v, err = %v; want azure:my-deploy", got, err)
+	}
+}
+
+func TestRun_FillsMissingCoverage(t *testing.T) {
+	// driftResponse covers SPEC-001 only.
+	report, err := Run(context.Background(), fixtureConfig(&stubProvider{text: driftResponse}))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(report.Coverage.Spec) != 3 || len(report.Coverage.Plan) != 3 {
+		t.Fatalf("coverage has %d spec and %d plan entries, want 3 and 3", len(report.Coverage.Spec), len(report.Coverage.Plan))
+	}
+	var spec2 *schema.SpecCoverageEntry
+	for i, e := range report.Coverage.Spec {
+		if e.ID == "SPEC-002" {
+			spec2 = &report.Coverage.Spec[i]
+		}
+	}
+	if spec2 == nil || spec2.Status != schema.StatusUnclear || !strings.Contains(spec2.Notes, "not evaluated by model") {
+		t.Fatalf("SPEC-002 = %+v, want an UNCLEAR entry noting it was not evaluated", spec2)
+	}
+	if spec2.SpecReference.LineStart != 6 {
+		t.Errorf("SPEC-002 reference = %+v, want the item's line", spec2.SpecReference)
+	}
+	if report.Coverage.Spec[0].ID != "SPEC-001" || report.Coverage.Spec[0].Status != schema.StatusImplemented {
+		t.Errorf("model entry changed: %+v", report.Coverage.Spec[0])
+	}
+}
+
+func TestRun_CheckPlanOrder(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "PLAN.md")
+	plan := "1. Implement Get method on Store struct.\n2. Implement Set method on Store struct.\n3. Implement Delete method. Depends on Step 1.\n"
+	if err := os.WriteFile(planFile, []byte(plan), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p := &stubProvider{text: `{
+  "coverage": {
+    "spec": [],
+    "plan": [
+      {"id":"PLAN-001","status":"NOT_IMPLEMENTED","plan_reference":{"line_start":1,"line_end":1},"evidence":[]},
+      {"id":"PLAN-002","status":"IMPLEMENTED","plan_reference":{"line_start":2,"line_end":2},"evidence":[{"path":"store.go","symbol":"Set"}]},
+      {"id":"PLAN-003","status":"IMPLEMENTED","plan_reference":{"line_start":3,"line_end":3},"evidence":[{"path":"store.go","symbol":"Delete"}]}
+    ]
+  },
+  "drift": [{"id":"DRIFT-001","severity":"INFO","description":"Extra debug logging","evidence":[{"path":"store.go"}],"why_unjustified":"Not in spec."}],
+  "violations": []
+}`}
+	cfg := fixtureConfig(p)
+	cfg.PlanFiles = []string{planFile}
+
+	report, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(report.Drift) != 1 {
+		t.Fatalf("drift = %+v, want only the model finding without CheckPlanOrder", report.Drift)
+	}
+
+	cfg.CheckPlanOrder = true
+	report, err = Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(report.Drift) != 2 {
+		t.Fatalf("drift = %+v, want an ordering finding", report.Drift)
+	}
+	d := report.Drift[1]
+	if d.ID != "DRIFT-002" || d.Severity != schema.SeverityWarn || !strings.Contains(d.Description, "PLAN-003") {
+		t.Errorf("ordering finding = %+v", d)
+	}
+	if !report.Input.CheckPlanOrder || report.Summary.WarnCount != 1 {
+		t.Errorf("input = %+v, warn count = %d", report.Input, report.Summary.WarnCount)
+	}
+}
+
+func TestRun_SummaryMode(t *testing.T) {
+	p := &stubProvider{text: `{
+  "rationale": "Storage matches the spec apart from eviction.",
+  "drift": [{"id":"DRIFT-001","severity":"WARN","description":"Undocumented TTL eviction","evidence":[{"path":"store.go"}],"why_unjustified":"Not in spec."}],
+  "violations": []
+}`}
+	cfg := fixtureConfig(p)
+	cfg.Mode = "Summary"
+
+	report, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if p.maxTokens != 1024 {
+		t.Errorf("max tokens = %d, want the summary default 1024", p.maxTokens)
+	}
+	if report.Input.Mode != "summary" || report.Summary.Rationale == "" {
+		t.Errorf("mode = %q, rationale = %q", report.Input.Mode, report.Summary.Rationale)
+	}
+	if len(report.Coverage.Spec) != 0 || report.Summary.Verdict != schema.VerdictDriftDetected {
+		t.Errorf("coverage = %+v, verdict = %s; want no coverage and DRIFT_DETECTED", report.Coverage, report.Summary.Verdict)
+	}
+	if report.Summary.SpecCoveragePct != nil || report.Summary.PlanCoveragePct != nil {
+		t.Error("coverage percentages should be absent in summary mode")
+	}
+}
+
+func TestRun_RequireTests(t *testing.T) {
+	// The fixture has no test files, so the IMPLEMENTED spec item that
+	// cites only store.go is downgraded.
+	cfg := fixtureConfig(&stubProvider{text: driftResponse})
+	cfg.RequireTests = true
+
+	report, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got := report.Coverage.Spec[0].Status; got != schema.StatusPartial {
+		t.Errorf("SPEC-001 status = %s, want PARTIAL", got)
+	}
+	if !report.Input.RequireTests {
+		t.Error("input.require_tests should record the setting")
+	}
+	if got := report.Summary.UnmetSpecIDs; len(got) != 1 || got[0] != "SPEC-001" {
+		t.Errorf("unmet spec IDs = %v, want [SPEC-001]", got)
+	}
+	if got := report.Summary.SpecCoveragePct; got == nil || *got >= 100 {
+		t.Errorf("spec coverage pct = %v, want below 100 with a PARTIAL item", got)
+	}
+}
+
+func TestRun_PartialAsWarn(t *testing.T) {
+	// --require-tests makes SPEC-001 PARTIAL; --partial-as-warn counts it.
+	cfg := fixtureConfig(&stubProvider{text: driftResponse})
+	cfg.RequireTests = true
+	without, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	cfg = fixtureConfig(&stubProvider{text: driftResponse})
+	cfg.RequireTests = true
+	cfg.PartialAsWarn = true
+	report, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	// SPEC-002 and SPEC-003, which the model left out, are UNCLEAR.
+	added := report.Drift[len(without.Drift):]
+	if len(added) != 3 {
+		t.Fatalf("added drift = %+v, want one finding each for SPEC-001 to SPEC-003", added)
+	}
+	if d := added[0]; d.ID != "DRIFT-003" || d.Severity != schema.SeverityWarn || !strings.HasPrefix(d.Description, "SPEC-001 (testdata/aligned/SPEC.md:5) is PARTIAL: ") {
+		t.Errorf("finding = %+v, want WARN for SPEC-001 with its location", d)
+	}
+	if report.Summary.WarnCount != without.Summary.WarnCount+3 || report.Summary.Score >= without.Summary.Score {
+		t.Errorf("summary = %+v, want three more WARN and a lower score than %+v", report.Summary, without.Summary)
+	}
+	if report.Summary.Verdict != schema.VerdictDriftDetected {
+		t.Errorf("verdict = %s, want DRIFT_DETECTED", report.Summary.Verdict)
+	}
+	if !report.Input.PartialAsWarn {
+		t.Error("input.partial_as_warn should record the setting")
+	}
+}
+
+func TestRun_WeightedSpec(t *testing.T) {
+	without, err := Run(context.Background(), fixtureConfig(&stubProvider{text: driftResponse}))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	// SPEC-002 and SPEC-003, which the model left out, are UNCLEAR.
+	specPath := filepath.Join(t.TempDir(), "SPEC.md")
+	src := "# Key-Value Store Spec\n\n## Operations\n\n" +
+		"- The store must support Get(key) returning a value.\n" +
+		"- The store MUST support Set(key, value) to store data.\n" +
+		"- The store must support Delete(key) to remove data. [weight:2]\n"
+	if err := os.WriteFile(specPath, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := fixtureConfig(&stubProvider{text: driftResponse})
+	cfg.SpecFiles = []string{specPath}
+
+	// Keywords only weigh items with KeywordWeights; the tag always counts.
+	report, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if want := without.Summary.Score - 5; report.Summary.Score != want {
+		t.Errorf("score without keyword weights = %d, want %d: 5 for the weight:2 item only", report.Summary.Score, want)
+	}
+
+	cfg.KeywordWeights = true
+	report, err = Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if want := without.Summary.Score - 15; report.Summary.Score != want {
+		t.Errorf("score = %d, want %d: 10 for the unmet MUST item and 5 for the weight:2 item", report.Summary.Score, want)
+	}
+	var weights []int
+	for _, e := range report.Coverage.Spec {
+		weights = append(weights, e.Weight)
+	}
+	if !reflect.DeepEqual(weights, []int{0, 3, 2}) {
+		t.Errorf("coverage weights = %v, want [0 3 2]", weights)
+	}
+	if report.Summary.Verdict != without.Summary.Verdict {
+		t.Errorf("verdict = %s, want %s: weights affect the score only", report.Summary.Verdict, without.Summary.Verdict)
+	}
+}
+
+func TestRun_ReferenceSources(t *testing.T) {
+	apiPath := filepath.Join(t.TempDir(), "api.md")
+	if err := os.WriteFile(apiPath, []byte("# API\n\n\n\n\n\n\n\n\n- Requests are limited to 1 MB.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	resp := `{
+  "coverage": {
+    "spec": [{"id":"SPEC-004","status":"NOT_IMPLEMENTED","spec_reference":{"line_start":10,"line_end":10},"evidence":[]}],
+    "plan": []
+  },
+  "drift": [],
+  "violations": [
+    {"id":"VIOLATION-001","severity":"WARN","description":"No request limit","spec_reference":{"line_start":10,"line_end":10},"evidence":[],"impact":"","blocking":false},
+    {"id":"VIOLATION-002","severity":"WARN","description":"Get is slow","spec_reference":{"line_start":5,"line_end":5},"evidence":[],"impact":"","blocking":false}
+  ],
+  "meta": {"model": "stub", "temperature": 0.2}
+}`
+	cfg := fixtureConfig(&stubProvider{text: resp})
+	cfg.SpecFiles = append(cfg.SpecFiles, apiPath)
+	report, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	for _, e := range report.Coverage.Spec {
+		want := "testdata/aligned/SPEC.md"
+		if e.ID == "SPEC-004" {
+			want = apiPath
+		}
+		if e.SpecReference.Source != want {
+			t.Errorf("%s source = %q, want %q", e.ID, e.SpecReference.Source, want)
+		}
+	}
+	var sources []string
+	for _, v := range report.Violations {
+		sources = append(sources, v.SpecReference.Source)
+	}
+	if want := []string{apiPath, "testdata/aligned/SPEC.md"}; !reflect.DeepEqual(sources, want) {
+		t.Errorf("violation sources = %v, want %v", sources, want)
+	}
+}
+
+func TestRun_EmbedInventory(t *testing.T) {
+	report, err := Run(context.Background(), fixtureConfig(&stubProvider{text: driftResponse}))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if report.Meta.Inventory != "" {
+		t.Errorf("inventory should be empty by default, got %q", report.Meta.Inventory)
+	}
+
+	cfg := fixtureConfig(&stubProvider{text: driftResponse})
+	cfg.EmbedInventory = true
+	report, err = Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !strings.Contains(report.Meta.Inventory, "store.go") || !strings.Contains(report.Meta.Inventory, "=== Symbols ===") {
+		t.Errorf("inventory = %q, want the file and symbol list", report.Meta.Inventory)
+	}
+}
+
+func TestRun_IntentGaps(t *testing.T) {
+	const response = `{
+  "coverage": {
+    "spec": [
+      {"id":"SPEC-001","status":"IMPLEMENTED","spec_reference":{"line_start":5,"line_end":5},"evidence":[{"path":"store.go","symbol":"Get"}]},
+      {"id":"SPEC-002","status":"NOT_IMPLEMENTED","spec_reference":{"line_start":6,"line_end":6},"evidence":[]},
+      {"id":"SPEC-003","status":"NOT_IMPLEMENTED","spec_reference":{"line_start":7,"line_end":7},"evidence":[]}
+    ],
+    "plan": [
+      {"id":"PLAN-001","status":"IMPLEMENTED","plan_reference":{"line_start":5,"line_end":5},"evidence":[{"path":"store.go","symbol":"Get"}]},
+      {"id":"PLAN-002","status":"NOT_IMPLEMENTED","plan_reference":{"line_start":6,"line_end":6},"evidence":[]}
+    ]
+  },
+  "drift": [],
+  "violations": []%s
+}`
+	links := `,
+  "traceability": [
+    {"plan_id":"PLAN-001","spec_ids":["SPEC-001"]},
+    {"plan_id":"PLAN-002","spec_ids":["SPEC-002"]}
+  ]`
+	cfg := fixtureConfig(&stubProvider{text: fmt.Sprintf(response, links)})
+	cfg.Traceability = true
+
+	report, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got := report.Summary.IntentGaps; !slices.Equal(got, []string{"SPEC-003"}) {
+		t.Errorf("intent_gaps = %v, want [SPEC-003]", got)
+	}
+
+	// Without links, planned and unplanned items cannot be told apart.
+	report, err = Run(context.Background(), fixtureConfig(&stubProvider{text: fmt.Sprintf(response, "")}))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got := report.Summary.IntentGaps; got != nil {
+		t.Errorf("intent_gaps without traceability = %v, want none", got)
+	}
+}
+
+func TestRun_RequireEvidence(t *testing.T) {
+	p := &stubProvider{text: `{
+  "coverage": {
+    "spec": [
+      {"id":"SPEC-001","status":"IMPLEMENTED","spec_reference":{"line_start":5,"line_end":5},"evidence":[{"path":"store.go","symbol":"Get"}]},
+      {"id":"SPEC-002","status":"IMPLEMENTED","spec_reference":{"line_start":6,"line_end":6},"evidence":[]}
+    ],
+    "plan": []
+  },
+  "drift": [],
+  "violations": []
+}`}
+	cfg := fixtureConfig(p)
+	cfg.RequireEvidence = true
+
+	report, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got := report.Coverage.Spec[1]; got.Status != schema.StatusUnclear || !strings.Contains(got.Notes, "no evidence") {
+		t.Errorf("SPEC-002 = %+v, want UNCLEAR with the downgrade noted", got)
+	}
+	if got := report.Coverage.Spec[1].Adjustments; !slices.Equal(got, []string{coverage.AdjustNoEvidence}) {
+		t.Errorf("SPEC-002 adjustments = %q, want the downgrade recorded", got)
+	}
+	if got := report.Coverage.Spec[0].Status; got != schema.StatusImplemented {
+		t.Errorf("SPEC-001 status = %s, want IMPLEMENTED", got)
+	}
+	if !report.Input.RequireEvidence {
+		t.Error("input.require_evidence should record the setting")
+	}
+}
+
+func TestRun_ScanTodos(t *testing.T) {
+	root := t.TempDir()
+	src := "package store\n\n// TODO: implement SPEC-001 once the backend exists.\nfunc Get(key string) string { return \"\" }\n"
+	if err := os.WriteFile(filepath.Join(root, "store.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p := &stubProvider{text: driftResponse}
+	cfg := fixtureConfig(p)
+	cfg.CodeRoot = root
+	cfg.ScanTodos = true
+
+	report, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	got := report.Coverage.Spec[0]
+	if got.Status != schema.StatusNotImplemented || !strings.Contains(got.Notes, "TODO at store.go:3") {
+		t.Errorf("SPEC-001 = %+v, want NOT_IMPLEMENTED with the TODO noted", got)
+	}
+	if !slices.Contains(report.Summary.UnmetSpecIDs, "SPEC-001") {
+		t.Errorf("unmet spec IDs = %v, want SPEC-001", report.Summary.UnmetSpecIDs)
+	}
+	if !report.Input.ScanTodos {
+		t.Error("input.scan_todos should record the setting")
+	}
+}
+
+func TestRun_BaselineIndex(t *testing.T) {
+	root := t.TempDir()
+	write := func(src string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, "store.go"), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("package store\n\nfunc Get() {}\n")
+	cfg := fixtureConfig(&stubProvider{text: driftResponse})
+	cfg.CodeRoot = root
+	cfg.SaveIndex = filepath.Join(t.TempDir(), "base.index")
+	if _, err := Run(context.Background(), cfg); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	// Set is new and exported; helper is not exported.
+	write("package store\n\nfunc Get() {}\n\nfunc Set() {}\n\nfunc helper() {}\n")
+	cfg.BaselineIndex, cfg.SaveIndex = cfg.SaveIndex, ""
+	report, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(report.Drift) != 3 {
+		t.Fatalf("drift = %+v, want the model's two findings and one for Set", report.Drift)
+	}
+	d := report.Drift[2]
+	if d.ID != "DRIFT-003" || d.Severity != schema.SeverityWarn || d.Evidence[0].Symbol != "Set" {
+		t.Errorf("API finding = %+v", d)
+	}
+
+	cfg.BaselineIndex = filepath.Join(root, "missing.index")
+	if _, err := Run(context.Background(), cfg); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("missing baseline index: err = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestRun_RecordReplay(t *testing.T) {
+	transcript := filepath.Join(t.TempDir(), "run.json")
+	p := &stubProvider{text: driftResponse}
+	cfg := fixtureConfig(p)
+	cfg.Record = transcript
+	recorded, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	// The replay needs neither a provider nor an API key.
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	cfg = fixtureConfig(nil)
+	cfg.Replay = transcript
+	replayed, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run with Replay: %v", err)
+	}
+	if p.calls != 1 {
+		t.Errorf("provider calls = %d, want 1", p.calls)
+	}
+	if !reflect.DeepEqual(replayed.Drift, recorded.Drift) || replayed.Summary.Score != recorded.Summary.Score {
+		t.Errorf("replayed drift = %+v, want %+v", replayed.Drift, recorded.Drift)
+	}
+
+	cfg.Replay = filepath.Join(t.TempDir(), "missing.json")
+	if _, err := Run(context.Background(), cfg); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("missing transcript: err = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestRun_Explain(t *testing.T) {
+	p := &stubProvider{text: `{
+  "coverage": {"spec": [], "plan": []},
+  "drift": [],
+  "violations": [],
+  "rationale": "Everything the spec asks for is in place."
+}`}
+	cfg := fixtureConfig(p)
+	cfg.Explain = true
+
+	report, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if report.Summary.Rationale != "Everything the spec asks for is in place." {
+		t.Errorf("rationale = %q", report.Summary.Rationale)
+	}
+
+	// A response without a rationale is repaired, then rejected.
+	p = &stubProvider{text: `{"coverage": {"spec": [], "plan": []}}`}
+	cfg = fixtureConfig(p)
+	cfg.Explain = true
+	if _, err := Run(context.Background(), cfg); !errors.Is(err, ErrInvalidModelOutput) || p.calls != 2 {
+		t.Errorf("err = %v after %d calls, want ErrInvalidModelOutput after a repair", err, p.calls)
+	}
+}
+
+func TestRun_CachesCodeIndex(t *testing.T) {
+	p := &stubProvider{text: driftResponse}
+	cfg := fixtureConfig(p)
+	cfg.CacheDir = t.TempDir()
+
+	first, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	cached, ok, err := codeindex.ReadCache(codeindex.CacheFile(cfg.CacheDir, cfg.CodeRoot, Version))
+	if !ok || err != nil || len(cached.Stamps) == 0 {
+		t.Fatalf("cached index: ok = %v, err = %v, stamps = %d", ok, err, len(cached.Stamps))
+	}
+
+	// The second run builds incrementally from the cached index.
+	second, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("second Run: %v", err)
+	}
+	if !reflect.DeepEqual(first.IndexStats, second.IndexStats) {
+		t.Errorf("index stats = %+v, want %+v", second.IndexStats, first.IndexStats)
+	}
+}
+
+func TestRun_MinConfidence(t *testing.T) {
+	// The fixture's drift evidence has no confidence, so it counts as LOW.
+	cases := []struct {
+		name   string
+		strict bool
+		want   []schema.Severity
+	}{
+		{"default", false, []schema.Severity{schema.SeverityInfo, schema.SeverityInfo}},
+		{"after strict escalation", true, []schema.Severity{schema.SeverityWarn, schema.SeverityInfo}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := fixtureConfig(&stubProvider{text: driftResponse})
+			cfg.MinConfidence = "medium"
+			cfg.Strict = c.strict
+
+			report, err := Run(context.Background(), cfg)
+			if err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+			for i, d := range report.Drift {
+				if d.Severity != c.want[i] {
+					t.Errorf("%s severity = %s, want %s", d.ID, d.Severity, c.want[i])
+				}
+			}
+			if report.Input.MinConfidence != "MEDIUM" {
+				t.Errorf("input.min_confidence = %q, want MEDIUM", report.Input.MinConfidence)
+			}
+		})
+	}
+}
+
+func TestPrompts(t *testing.T) {
+	// Prompts needs neither an API key nor a provider.
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	prompts, err := Prompts(fixtureConfig(nil))
+	if err != nil {
+		t.Fatalf("Prompts: %v", err)
+	}
+	if len(prompts) != 1 {
+		t.Fatalf("got %d prompts, want 1", len(prompts))
+	}
+	if !strings.Contains(prompts[0].User, "store.go") || prompts[0].System == "" {
+		t.Errorf("unexpected prompt: %+v", prompts[0])
+	}
+
+	if strings.Contains(prompts[0].User, "s.data[key] = value") {
+		t.Error("source code in the prompt without IncludeSnippets")
+	}
+	cfg := fixtureConfig(nil)
+	cfg.IncludeSnippets = true
+	prompts, err = Prompts(cfg)
+	if err != nil {
+		t.Fatalf("Prompts: %v", err)
+	}
+	if !strings.Contains(prompts[0].User, "CODE SNIPPETS") || !strings.Contains(prompts[0].User, "s.data[key] = value") {
+		t.Errorf("IncludeSnippets prompt has no snippets:\n%s", prompts[0].User)
+	}
+
+	cfg = fixtureConfig(nil)
+	cfg.SpecFiles = nil
+	if _, err := Prompts(cfg); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("error = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestRun_Timeout(t *testing.T) {
+	cfg := fixtureConfig(hangingProvider{})
+	cfg.Timeout = 50 * time.Millisecond
+
+	start := time.Now()
+	_, err := Run(context.Background(), cfg)
+	if !errors.Is(err, ErrProvider) {
+		t.Fatalf("error = %v, want ErrProvider", err)
+	}
+	if !strings.Contains(err.Error(), "50ms timeout") {
+		t.Errorf("error should name the limit: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Run took %s; the timeout was not applied", elapsed)
+	}
+}
+
+func TestRun_Rules(t *testing.T) {
+	cfg := fixtureConfig(&stubProvider{text: driftResponse})
+	cfg.Rules = []RuleConfig{{Name: "no-store", Type: "evidence-path", Paths: []string{"store.go"}, Severity: "CRITICAL"}}
+
+	report, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(report.Violations) != 1 || report.Violations[0].ID != "VIOLATION-001" {
+		t.Fatalf("violations = %+v, want one rule violation", report.Violations)
+	}
+	// The rule violation is scored and drives the verdict.
+	if report.Summary.CriticalCount != 1 || report.Summary.Verdict != schema.VerdictViolation {
+		t.Errorf("summary = %+v, want one CRITICAL and VIOLATION", report.Summary)
+	}
+}
+
+func TestRun_ProfileEvidencePaths(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.yaml")
+	if err := os.WriteFile(path, []byte("system_prompt_addendum: rules\nevidence_path_allow: [internal/api]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := fixtureConfig(&stubProvider{text: driftResponse})
+	cfg.ProfileFile = path
+
+	report, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	// Both findings cite only store.go, outside the allow-list.
+	if len(report.Drift) != 0 || report.Summary.WarnCount+report.Summary.InfoCount != 0 {
+		t.Errorf("drift = %+v, summary = %+v; want findings dropped", report.Drift, report.Summary)
+	}
+}
+
+func TestRun_Baseline(t *testing.T) {
+	base := baseline.FromReport(schema.Report{Drift: []schema.DriftFinding{
+		{Severity: schema.SeverityWarn, Description: "Undocumented TTL eviction", Evidence: []schema.Evidence{{Path: "store.go"}}},
+	}})
+	b, err := base.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := fixtureConfig(&stubProvider{text: driftResponse})
+	cfg.Baseline = path
+
+	report, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(report.Drift) != 2 || !report.Drift[0].Suppressed || report.Drift[1].Suppressed {
+		t.Fatalf("drift = %+v, want the WARN finding suppressed and still listed", report.Drift)
+	}
+	// Only the INFO finding is scored.
+	if report.Summary.Score != 98 || report.Summary.WarnCount != 0 || report.Summary.SuppressedCount != 1 {
+		t.Errorf("summary = %+v, want score 98, no WARN, one suppressed", report.Summary)
+	}
+
+	cfg.Baseline = filepath.Join(t.TempDir(), "missing.json")
+	if _, err := Run(context.Background(), cfg); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("missing baseline: err = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestRun_MaxPromptTokens(t *testing.T) {
+	p := &stubProvider{text: driftResponse}
+	var log bytes.Buffer
+	cfg := fixtureConfig(p)
+	cfg.Log = &log
+	cfg.MaxPromptTokens = 100
+
+	_, err := Run(context.Background(), cfg)
+	if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "exceeds the limit of 100 tokens") {
+		t.Fatalf("err = %v, want ErrInvalidInput for the prompt size", err)
+	}
+	if p.calls != 0 {
+		t.Errorf("provider calls = %d, want 0", p.calls)
+	}
+	if !strings.Contains(log.String(), "estimated prompt tokens") {
+		t.Errorf("log should record the estimate; got:\n%s", log.String())
+	}
+
+	cfg.MaxPromptTokens = 100_000
+	if _, err := Run(context.Background(), cfg); err != nil {
+		t.Errorf("prompt under the limit: %v", err)
+	}
+}
+
+func TestRun_PathPrefix(t *testing.T) {
+	cfg := fixtureConfig(&stubProvider{text: driftResponse})
+	cfg.PathPrefix = "testdata/aligned"
+
+	report, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if report.Input.PathPrefix != "testdata/aligned" {
+		t.Errorf("input.path_prefix = %q", report.Input.PathPrefix)
+	}
+	// The model cited store.go relative to the code root; the report uses
+	// the prefixed path and keeps the citation's confidence.
+	ev := report.Coverage.Spec[0].Evidence[0]
+	if ev.Path != "testdata/aligned/store.go" || ev.Confidence != schema.ConfidenceHigh {
+		t.Errorf("evidence = %+v, want the prefixed path at HIGH confidence", ev)
+	}
+
+	cfg.PathPrefix = "../outside"
+	if _, err := Run(context.Background(), cfg); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("err = %v, want ErrInvalidInput for a prefix starting with ..", err)
+	}
+}
+
+func TestDefaultMaxIndexBytes(t *testing.T) {
+	cases := map[string]int{
+		"claude-opus-4-6":      200_000,
+		"gpt-4o":               128_000,
+		"gpt-4o-mini":          128_000,
+		"gpt-4.1":              1_000_000,
+		"gemini-2.5-flash":     1_000_000,
+		"gemini-1.5-pro":       2_000_000,
+		"some-local-model":     codeindex.DefaultSummaryBytes,
+		"Claude-Sonnet-Custom": 200_000,
+	}
+	for model, want := range cases {
+		if got := DefaultMaxIndexBytes(model); got != want {
+			t.Errorf("DefaultMaxIndexBytes(%q) = %d, want %d", model, got, want)
+		}
+	}
+}